@@ -0,0 +1,83 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Account struct {
+	// RequireVerifiedEmail refuses to log in accounts whose email address
+	// hasn't been verified yet.
+	RequireVerifiedEmail bool
+
+	VerifyEmailTTL   time.Duration
+	ResetPasswordTTL time.Duration
+
+	// LinkBaseUrl is where the links sent by email point to, usually the
+	// frontend that posts the token back to the api.
+	LinkBaseUrl string
+}
+
+func (cfg *Account) LoadFromEnv(file ...string) error {
+	if err := cfg.lookupEnv(); err != nil {
+		return err
+	}
+
+	if reflect.ValueOf(*cfg).IsZero() {
+		if err := godotenv.Load(file...); err == nil {
+			if err := cfg.lookupEnv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.VerifyEmailTTL == 0 {
+		cfg.VerifyEmailTTL = 24 * time.Hour
+	}
+
+	if cfg.ResetPasswordTTL == 0 {
+		cfg.ResetPasswordTTL = time.Hour
+	}
+
+	if cfg.LinkBaseUrl == "" {
+		cfg.LinkBaseUrl = "http://localhost:8000"
+	}
+
+	return nil
+}
+
+func (cfg *Account) lookupEnv() error {
+	if required, ok := os.LookupEnv("ACCOUNT_REQUIRE_VERIFIED_EMAIL"); ok && required != "" {
+		if cnv, err := strconv.ParseBool(required); err != nil {
+			return err
+		} else {
+			cfg.RequireVerifiedEmail = cnv
+		}
+	}
+
+	if ttl, ok := os.LookupEnv("ACCOUNT_VERIFY_EMAIL_TTL"); ok && ttl != "" {
+		if cnv, err := time.ParseDuration(ttl); err != nil {
+			return err
+		} else {
+			cfg.VerifyEmailTTL = cnv
+		}
+	}
+
+	if ttl, ok := os.LookupEnv("ACCOUNT_RESET_PASSWORD_TTL"); ok && ttl != "" {
+		if cnv, err := time.ParseDuration(ttl); err != nil {
+			return err
+		} else {
+			cfg.ResetPasswordTTL = cnv
+		}
+	}
+
+	if url, ok := os.LookupEnv("ACCOUNT_LINK_BASE_URL"); ok {
+		cfg.LinkBaseUrl = url
+	}
+
+	return nil
+}
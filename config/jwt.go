@@ -1,27 +1,97 @@
 package config
 
 import (
+	"errors"
 	"os"
-	"reflect"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
 
 type JWT struct {
 	Secret string
+	KeyId  string
+
+	// Keys are retired signing keys by key id. Tokens signed with them are
+	// still accepted until they expire, which allows rotating Secret.
+	Keys map[string]string
+
+	AccessTTL  time.Duration
+	RefreshTTL time.Duration
 }
 
 func (cfg *JWT) LoadFromEnv(file ...string) error {
+	if err := cfg.lookupEnv(); err != nil {
+		return err
+	}
+
+	if cfg.Secret == "" {
+		if err := godotenv.Load(file...); err != nil {
+			return err
+		}
+
+		if err := cfg.lookupEnv(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Secret == "" {
+		return errors.New("jwt secret can't be empty")
+	}
+
+	if cfg.KeyId == "" {
+		cfg.KeyId = "default"
+	}
+
+	if cfg.AccessTTL == 0 {
+		cfg.AccessTTL = 15 * time.Minute
+	}
+
+	if cfg.RefreshTTL == 0 {
+		cfg.RefreshTTL = 30 * 24 * time.Hour
+	}
+
+	return nil
+}
+
+// lookupEnv reads JWT_PREVIOUS_KEYS as a comma separated list of kid:secret
+// pairs, e.g. "2023-11:oldsecret,2023-10:oldersecret".
+func (cfg *JWT) lookupEnv() error {
 	if secret, ok := os.LookupEnv("JWT_SECRET"); ok {
 		cfg.Secret = secret
 	}
 
-	if reflect.ValueOf(*cfg).IsZero() {
-		if err := godotenv.Load(file...); err != nil {
+	if keyId, ok := os.LookupEnv("JWT_KEY_ID"); ok {
+		cfg.KeyId = keyId
+	}
+
+	if keys, ok := os.LookupEnv("JWT_PREVIOUS_KEYS"); ok && keys != "" {
+		cfg.Keys = make(map[string]string)
+		for _, key := range strings.Split(keys, ",") {
+			keyId, secret, found := strings.Cut(strings.TrimSpace(key), ":")
+			if !found || keyId == "" || secret == "" {
+				return errors.New("invalid jwt previous key: " + key)
+			}
+
+			cfg.Keys[keyId] = secret
+		}
+	}
+
+	if ttl, ok := os.LookupEnv("JWT_ACCESS_TTL"); ok && ttl != "" {
+		if cnv, err := time.ParseDuration(ttl); err != nil {
 			return err
+		} else {
+			cfg.AccessTTL = cnv
 		}
+	}
 
-		return cfg.LoadFromEnv(file...)
+	if ttl, ok := os.LookupEnv("JWT_REFRESH_TTL"); ok && ttl != "" {
+		if cnv, err := time.ParseDuration(ttl); err != nil {
+			return err
+		} else {
+			cfg.RefreshTTL = cnv
+		}
 	}
 
 	return nil
@@ -0,0 +1,89 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"strconv"
+
+	"github.com/joho/godotenv"
+)
+
+type Mail struct {
+	Driver string
+	From   string
+
+	Host     string
+	Port     int
+	Username string
+	Password string
+
+	Dir string
+}
+
+func (cfg *Mail) LoadFromEnv(file ...string) error {
+	if err := cfg.lookupEnv(); err != nil {
+		return err
+	}
+
+	if reflect.ValueOf(*cfg).IsZero() {
+		if err := godotenv.Load(file...); err == nil {
+			if err := cfg.lookupEnv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.Driver == "" {
+		cfg.Driver = "file"
+	}
+
+	if cfg.From == "" {
+		cfg.From = "Wanderer <no-reply@wanderer.local>"
+	}
+
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+
+	if cfg.Dir == "" {
+		cfg.Dir = "mails"
+	}
+
+	return nil
+}
+
+func (cfg *Mail) lookupEnv() error {
+	if driver, ok := os.LookupEnv("MAIL_DRIVER"); ok {
+		cfg.Driver = driver
+	}
+
+	if from, ok := os.LookupEnv("MAIL_FROM"); ok {
+		cfg.From = from
+	}
+
+	if host, ok := os.LookupEnv("SMTP_HOST"); ok {
+		cfg.Host = host
+	}
+
+	if port, ok := os.LookupEnv("SMTP_PORT"); ok && port != "" {
+		if cnv, err := strconv.Atoi(port); err != nil {
+			return err
+		} else {
+			cfg.Port = cnv
+		}
+	}
+
+	if username, ok := os.LookupEnv("SMTP_USERNAME"); ok {
+		cfg.Username = username
+	}
+
+	if password, ok := os.LookupEnv("SMTP_PASSWORD"); ok {
+		cfg.Password = password
+	}
+
+	if dir, ok := os.LookupEnv("MAIL_DIR"); ok {
+		cfg.Dir = dir
+	}
+
+	return nil
+}
@@ -9,8 +9,9 @@ import (
 )
 
 type Midtrans struct {
-	ApiKey string
-	Env    midtrans.EnvironmentType
+	ApiKey      string
+	Env         midtrans.EnvironmentType
+	CheckStatus bool
 }
 
 func (cfg *Midtrans) LoadFromEnv(file ...string) error {
@@ -26,6 +27,10 @@ func (cfg *Midtrans) LoadFromEnv(file ...string) error {
 		}
 	}
 
+	if check, ok := os.LookupEnv("MIDTRANS_CHECK_STATUS"); ok {
+		cfg.CheckStatus = check == "1"
+	}
+
 	if reflect.ValueOf(*cfg).IsZero() {
 		if err := godotenv.Load(file...); err != nil {
 			return err
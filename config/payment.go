@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Payment struct {
+	Gateway     string
+	CallbackUrl string
+
+	FakeKey        string
+	FakeExpiry     time.Duration
+	FakeAutoSettle time.Duration
+}
+
+func (cfg *Payment) LoadFromEnv(file ...string) error {
+	if err := cfg.lookupEnv(); err != nil {
+		return err
+	}
+
+	if reflect.ValueOf(*cfg).IsZero() {
+		if err := godotenv.Load(file...); err == nil {
+			if err := cfg.lookupEnv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.Gateway == "" {
+		cfg.Gateway = "midtrans"
+	}
+
+	if cfg.FakeKey == "" {
+		cfg.FakeKey = "fake-server-key"
+	}
+
+	if cfg.FakeExpiry == 0 {
+		cfg.FakeExpiry = 24 * time.Hour
+	}
+
+	return nil
+}
+
+func (cfg *Payment) lookupEnv() error {
+	if gateway, ok := os.LookupEnv("PAYMENT_GATEWAY"); ok {
+		cfg.Gateway = gateway
+	}
+
+	if callbackUrl, ok := os.LookupEnv("PAYMENT_CALLBACK_URL"); ok {
+		cfg.CallbackUrl = callbackUrl
+	}
+
+	if key, ok := os.LookupEnv("PAYMENT_FAKE_KEY"); ok {
+		cfg.FakeKey = key
+	}
+
+	if expiry, ok := os.LookupEnv("PAYMENT_FAKE_EXPIRY"); ok && expiry != "" {
+		if cnv, err := time.ParseDuration(expiry); err != nil {
+			return err
+		} else {
+			cfg.FakeExpiry = cnv
+		}
+	}
+
+	if autoSettle, ok := os.LookupEnv("PAYMENT_FAKE_AUTO_SETTLE"); ok && autoSettle != "" {
+		if cnv, err := time.ParseDuration(autoSettle); err != nil {
+			return err
+		} else {
+			cfg.FakeAutoSettle = cnv
+		}
+	}
+
+	return nil
+}
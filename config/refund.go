@@ -0,0 +1,86 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/joho/godotenv"
+)
+
+// RefundTier refunds Percentage of the paid amount when a refund is requested
+// at least DaysBefore days before the tour starts.
+type RefundTier struct {
+	DaysBefore int
+	Percentage int
+}
+
+type Refund struct {
+	Policy []RefundTier
+}
+
+func (cfg *Refund) LoadFromEnv(file ...string) error {
+	if err := cfg.lookupEnv(); err != nil {
+		return err
+	}
+
+	if len(cfg.Policy) == 0 {
+		if err := godotenv.Load(file...); err == nil {
+			if err := cfg.lookupEnv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(cfg.Policy) == 0 {
+		cfg.Policy = []RefundTier{
+			{DaysBefore: 30, Percentage: 100},
+			{DaysBefore: 14, Percentage: 50},
+			{DaysBefore: 7, Percentage: 25},
+		}
+	}
+
+	return nil
+}
+
+// lookupEnv reads REFUND_POLICY as a comma separated list of days:percentage
+// pairs, e.g. "30:100,14:50,7:25".
+func (cfg *Refund) lookupEnv() error {
+	policy, ok := os.LookupEnv("REFUND_POLICY")
+	if !ok || policy == "" {
+		return nil
+	}
+
+	var tiers []RefundTier
+	for _, tier := range strings.Split(policy, ",") {
+		days, percentage, found := strings.Cut(strings.TrimSpace(tier), ":")
+		if !found {
+			return errors.New("invalid refund policy tier: " + tier)
+		}
+
+		daysBefore, err := strconv.Atoi(days)
+		if err != nil {
+			return err
+		}
+
+		pct, err := strconv.Atoi(percentage)
+		if err != nil {
+			return err
+		}
+
+		if daysBefore < 0 || pct < 0 || pct > 100 {
+			return errors.New("invalid refund policy tier: " + tier)
+		}
+
+		tiers = append(tiers, RefundTier{DaysBefore: daysBefore, Percentage: pct})
+	}
+
+	sort.Slice(tiers, func(i, j int) bool {
+		return tiers[i].DaysBefore > tiers[j].DaysBefore
+	})
+	cfg.Policy = tiers
+
+	return nil
+}
@@ -0,0 +1,71 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+type Scheduler struct {
+	BookingExpiryInterval time.Duration
+	NotificationInterval  time.Duration
+	ExportInterval        time.Duration
+}
+
+func (cfg *Scheduler) LoadFromEnv(file ...string) error {
+	if err := cfg.lookupEnv(); err != nil {
+		return err
+	}
+
+	if reflect.ValueOf(*cfg).IsZero() {
+		if err := godotenv.Load(file...); err == nil {
+			if err := cfg.lookupEnv(); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.BookingExpiryInterval == 0 {
+		cfg.BookingExpiryInterval = time.Minute
+	}
+
+	if cfg.NotificationInterval == 0 {
+		cfg.NotificationInterval = 10 * time.Second
+	}
+
+	if cfg.ExportInterval == 0 {
+		cfg.ExportInterval = time.Minute
+	}
+
+	return nil
+}
+
+func (cfg *Scheduler) lookupEnv() error {
+	if interval, ok := os.LookupEnv("SCHEDULER_BOOKING_EXPIRY_INTERVAL"); ok && interval != "" {
+		if cnv, err := time.ParseDuration(interval); err != nil {
+			return err
+		} else {
+			cfg.BookingExpiryInterval = cnv
+		}
+	}
+
+	if interval, ok := os.LookupEnv("SCHEDULER_NOTIFICATION_INTERVAL"); ok && interval != "" {
+		if cnv, err := time.ParseDuration(interval); err != nil {
+			return err
+		} else {
+			cfg.NotificationInterval = cnv
+		}
+	}
+
+	if interval, ok := os.LookupEnv("SCHEDULER_EXPORT_INTERVAL"); ok && interval != "" {
+		if cnv, err := time.ParseDuration(interval); err != nil {
+			return err
+		} else {
+			cfg.ExportInterval = cnv
+		}
+	}
+
+	return nil
+}
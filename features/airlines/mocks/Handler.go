@@ -76,6 +76,22 @@ func (_m *Handler) Import() echo.HandlerFunc {
 	return r0
 }
 
+// ImportTemplate provides a mock function with given fields:
+func (_m *Handler) ImportTemplate() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields:
 func (_m *Handler) Update() echo.HandlerFunc {
 	ret := _m.Called()
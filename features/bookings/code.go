@@ -0,0 +1,83 @@
+package bookings
+
+import (
+	"crypto/rand"
+	"strings"
+)
+
+const (
+	codeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	codeLength   = 10
+	codeMaxSize  = 20
+)
+
+var codeReplacer = strings.NewReplacer("O", "0", "I", "1", "L", "1")
+
+// NewCode generates a random booking code of Crockford base32 characters. The
+// last character is a Luhn mod 32 check character, so a mistyped code can be
+// rejected without looking it up.
+func NewCode() (string, error) {
+	var buf = make([]byte, codeLength-1)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	for i := range buf {
+		buf[i] = codeAlphabet[buf[i]%byte(len(codeAlphabet))]
+	}
+
+	return string(buf) + string(checkChar(string(buf))), nil
+}
+
+// NormalizeCode turns a booking code typed by a user into its canonical form.
+func NormalizeCode(code string) string {
+	return codeReplacer.Replace(strings.ToUpper(strings.TrimSpace(code)))
+}
+
+// ValidCode reports whether code is a well-formed booking code. Numeric codes
+// of bookings made before the current format are accepted as they are.
+func ValidCode(code string) bool {
+	if code == "" || len(code) > codeMaxSize {
+		return false
+	}
+
+	if isLegacyCode(code) {
+		return true
+	}
+
+	if len(code) != codeLength {
+		return false
+	}
+
+	for i := 0; i < len(code); i++ {
+		if strings.IndexByte(codeAlphabet, code[i]) < 0 {
+			return false
+		}
+	}
+
+	return checkChar(code[:codeLength-1]) == code[codeLength-1]
+}
+
+func isLegacyCode(code string) bool {
+	for i := 0; i < len(code); i++ {
+		if code[i] < '0' || code[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func checkChar(payload string) byte {
+	var base = len(codeAlphabet)
+	var factor, sum = 2, 0
+
+	for i := len(payload) - 1; i >= 0; i-- {
+		addend := factor * strings.IndexByte(codeAlphabet, payload[i])
+		sum += addend/base + addend%base
+
+		factor = 3 - factor
+	}
+
+	return codeAlphabet[(base-sum%base)%base]
+}
@@ -0,0 +1,50 @@
+package bookings_test
+
+import (
+	"testing"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewCode(t *testing.T) {
+	var seen = make(map[string]bool)
+
+	for i := 0; i < 1000; i++ {
+		code, err := bookings.NewCode()
+
+		assert.NoError(t, err)
+		assert.Len(t, code, 10)
+		assert.True(t, bookings.ValidCode(code), code)
+		assert.False(t, seen[code], code)
+
+		seen[code] = true
+	}
+}
+
+func TestValidCode(t *testing.T) {
+	var testCases = []struct {
+		code  string
+		valid bool
+	}{
+		{code: "WNDR4Q7K2W", valid: true},
+		{code: "WNDR4Q7K2X", valid: false},
+		{code: "WNDR4Q7K3W", valid: false},
+		{code: "WNDRQ47K2W", valid: false},
+		{code: "WNDR4Q7K2", valid: false},
+		{code: "wndr4q7k2w", valid: false},
+		{code: "WNDR4Q7KUW", valid: false},
+		{code: "1231700000000", valid: true},
+		{code: "123170000000000000000", valid: false},
+		{code: "", valid: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.valid, bookings.ValidCode(tc.code), tc.code)
+	}
+}
+
+func TestNormalizeCode(t *testing.T) {
+	assert.Equal(t, "WNDR4Q7K2W", bookings.NormalizeCode(" wndr4q7k2w "))
+	assert.Equal(t, "B00K1NG1", bookings.NormalizeCode("bOoKiNgl"))
+}
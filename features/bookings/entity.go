@@ -2,26 +2,38 @@ package bookings
 
 import (
 	"context"
+	"errors"
+	"io"
 	"time"
 	"wanderer/helpers/filters"
+	"wanderer/utils/exporter"
 
 	"github.com/labstack/echo/v4"
 )
 
+// Booking is a reservation of seats on a tour departure. Discount is what
+// its Voucher, if any, took off the Total.
 type Booking struct {
-	Code      int
+	Code      string
 	Total     float64
+	Discount  float64
 	Status    string
 	BookedAt  time.Time
 	DeletedAt time.Time
 
-	User User
-	Tour Tour
+	User      User
+	Tour      Tour
+	Departure Departure
+	Voucher   Voucher
 
 	Detail  []Detail
 	Payment Payment
+	Refunds []Refund
+	History []Transition
 }
 
+// Detail is a passenger of a booking. Category is set from their age when
+// the tour starts and Price is what their seat cost before the tour discount.
 type Detail struct {
 	Id             uint
 	DocumentNumber string
@@ -29,12 +41,14 @@ type Detail struct {
 	Name           string
 	Nationality    string
 	DOB            time.Time
+	Category       string
+	Price          float64
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt time.Time
 
-	BookingCode int
+	BookingCode string
 }
 
 type Payment struct {
@@ -46,14 +60,58 @@ type Payment struct {
 	BillCode      string
 	Status        string
 
-	BookingCode  int
+	BookingCode  string
 	BookingTotal float64
 
+	// CancelAttempts counts the failed attempts to cancel the expired
+	// payment at the gateway, the next one being due at CancelRetryAt.
+	CancelAttempts int
+	CancelRetryAt  time.Time
+
 	CreatedAt time.Time
 	ExpiredAt time.Time
 	PaidAt    time.Time
 }
 
+type Refund struct {
+	Id          uint
+	BookingCode string
+	Reason      string
+	Passengers  []uint
+	Percentage  int
+	Amount      float64
+	Status      string
+	Note        string
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	History []RefundHistory
+}
+
+type RefundHistory struct {
+	Id        uint
+	RefundId  uint
+	Status    string
+	Amount    float64
+	Note      string
+	CreatedAt time.Time
+}
+
+type PaymentNotification struct {
+	OrderId       string
+	TransactionId string
+	StatusCode    string
+	GrossAmount   string
+	Status        string
+	PaymentType   string
+	FraudStatus   string
+	SignatureKey  string
+
+	RejectReason string
+	CreatedAt    time.Time
+}
+
 type User struct {
 	Id    uint
 	Name  string
@@ -64,21 +122,30 @@ type User struct {
 	ImageUrl string
 }
 
+// Tour is the tour a booking is made for. Price is the adult price, while a
+// nil ChildPrice or InfantPrice means children or infants pay it too.
 type Tour struct {
 	Id          uint
 	Title       string
 	Description string
 	Price       float64
+	ChildPrice  *float64
+	InfantPrice *float64
+	AdminFee    float64
+	Discount    int
 	Start       time.Time
 	Finish      time.Time
 	Quota       int
 	Available   int
 	Rating      *float32
+	Status      string
 
 	Picture []File
 
 	Itinerary []Itinerary
 
+	Departures []Departure
+
 	FacilityInclude []Facility
 	FacilityExclude []Facility
 
@@ -89,6 +156,70 @@ type Tour struct {
 	Reviews []Review
 }
 
+// Departure is the run of a tour a booking is made for. A zero Price means
+// the tour price applies.
+type Departure struct {
+	Id        uint
+	TourId    uint
+	Start     time.Time
+	Finish    time.Time
+	Quota     int
+	Available int
+	Price     float64
+}
+
+// On is the tour as it runs on departure d, with the departure's dates, seats
+// and price override.
+func (t Tour) On(d Departure) Tour {
+	t.Start = d.Start
+	t.Finish = d.Finish
+	t.Quota = d.Quota
+	t.Available = d.Available
+
+	if d.Price != 0 {
+		t.Price = d.Price
+	}
+
+	return t
+}
+
+// Refunded is the set of passengers of b whose seats a paid out refund gave
+// back.
+func (b Booking) Refunded() map[uint]bool {
+	var result = make(map[uint]bool)
+	for _, refund := range b.Refunds {
+		if refund.Status != RefundRefunded {
+			continue
+		}
+
+		for _, passenger := range refund.Passengers {
+			result[passenger] = true
+		}
+	}
+
+	return result
+}
+
+// Departure picks the departure of t with id. A tour running only once
+// doesn't need its departure picked.
+func (t Tour) Departure(id uint) (*Departure, error) {
+	if id == 0 {
+		if len(t.Departures) != 1 {
+			return nil, errors.New("validate: departure can't be empty")
+		}
+
+		return &t.Departures[0], nil
+	}
+
+	for i := range t.Departures {
+		if t.Departures[i].Id == id {
+			return &t.Departures[i], nil
+		}
+	}
+
+	return nil, errors.New("not found: departure not found")
+}
+
 type File struct {
 	Id  int
 	Url string
@@ -133,29 +264,67 @@ type Handler interface {
 	Update() echo.HandlerFunc
 	PaymentNotification() echo.HandlerFunc
 	ExportReportTransaction() echo.HandlerFunc
+	CreateExportSchedule() echo.HandlerFunc
+	GetExportSchedules() echo.HandlerFunc
+	DeleteExportSchedule() echo.HandlerFunc
+	GetExportFiles() echo.HandlerFunc
+	DownloadExport() echo.HandlerFunc
+	Invoice() echo.HandlerFunc
+	Ticket() echo.HandlerFunc
 }
 
 type Service interface {
-	GetAll(ctx context.Context, flt filters.Filter) ([]Booking, int, error)
-	GetDetail(ctx context.Context, code int) (*Booking, error)
+	GetAll(ctx context.Context, userId uint, flt filters.Filter) ([]Booking, int, error)
+	GetDetail(ctx context.Context, userId uint, code string) (*Booking, error)
 	Create(ctx context.Context, data Booking) (*Booking, error)
-	UpdateBookingStatus(ctx context.Context, code int, status string) error
-	UpdatePaymentStatus(ctx context.Context, code int, paymentStatus string) error
-	ChangePaymentMethod(ctx context.Context, code int, data Payment) (*Payment, error)
-	Export(c echo.Context, typeFile string) error
+	UpdateBookingStatus(ctx context.Context, actor Actor, code string, status string) error
+	UpdatePaymentStatus(ctx context.Context, code string, paymentStatus string) error
+	PaymentNotification(ctx context.Context, data PaymentNotification) error
+	ChangePaymentMethod(ctx context.Context, actor Actor, code string, data Payment) (*Payment, error)
+	RequestRefund(ctx context.Context, userId uint, code string, data Refund) (*Refund, error)
+	ApproveRefund(ctx context.Context, actor Actor, code string, amount float64) (*Refund, error)
+	ExpirePendingBookings(ctx context.Context) (int, error)
+	SendNotifications(ctx context.Context) (int, error)
+	Invoice(ctx context.Context, userId uint, code string) ([]byte, error)
+	Ticket(ctx context.Context, userId uint, code string) ([]byte, error)
+	Export(ctx context.Context, w io.Writer, format exporter.Format, flt filters.Booking) error
+	ExportToCloud(ctx context.Context, format exporter.Format, flt filters.Booking) (string, error)
+	CreateExportSchedule(ctx context.Context, data ExportSchedule) (*ExportSchedule, error)
+	GetExportSchedules(ctx context.Context) ([]ExportSchedule, error)
+	DeleteExportSchedule(ctx context.Context, id uint) error
+	RunExportSchedules(ctx context.Context) (int, error)
+	GetExportFiles(ctx context.Context, scheduleId uint) ([]ExportFile, error)
+	GetExportFile(ctx context.Context, id uint) (*ExportFile, error)
 }
 
 type Repository interface {
 	GetAll(ctx context.Context, flt filters.Filter) ([]Booking, int, error)
-	GetDetail(ctx context.Context, code int) (*Booking, error)
+	GetDetail(ctx context.Context, code string) (*Booking, error)
 	GetTourById(ctx context.Context, tourId uint) (*Tour, error)
 	GetUserById(ctx context.Context, userId uint) (*User, error)
+	GetVoucher(ctx context.Context, code string, userId uint) (*Voucher, error)
 	Create(ctx context.Context, data Booking) (*Booking, error)
-	UpdateBookingStatus(ctx context.Context, code int, status string) error
-	UpdatePaymentStatus(ctx context.Context, code int, bookingStatus string, paymentStatus string) error
-	ChangePaymentMethod(ctx context.Context, code int, data Booking) (*Payment, error)
-	Export() ([]Booking, error)
-	ExportFileCsv(c echo.Context, data []Booking) error
-	ExportFileExcel(c echo.Context, data []Booking) error
-	ExportFilePDF(c echo.Context, data []Booking) error
+	UpdateBookingStatus(ctx context.Context, data Transition) error
+	UpdatePaymentStatus(ctx context.Context, data Transition) error
+	ChangePaymentMethod(ctx context.Context, code string, data Payment) error
+	CreatePaymentRejection(ctx context.Context, data PaymentNotification) error
+	CreateRefund(ctx context.Context, data Refund, transition Transition) (*Refund, error)
+	UpdateRefundStatus(ctx context.Context, refundId uint, status string, amount float64, note string) error
+	CompleteRefund(ctx context.Context, data Refund, transition Transition) error
+	GetExpiredPending(ctx context.Context, before time.Time, attempts int, limit int) ([]Booking, error)
+	ExpireBooking(ctx context.Context, code string, before time.Time) (bool, error)
+	PostponeExpiry(ctx context.Context, code string, attempts int, retryAt time.Time) error
+	GetDueNotifications(ctx context.Context, before time.Time, limit int) ([]Notification, error)
+	ClaimNotification(ctx context.Context, data Notification, until time.Time) (bool, error)
+	UpdateNotification(ctx context.Context, data Notification) error
+	Export(ctx context.Context, flt filters.Booking) ([]Booking, error)
+	UploadExport(ctx context.Context, file io.Reader) (string, error)
+	CreateExportSchedule(ctx context.Context, data ExportSchedule) (*ExportSchedule, error)
+	GetExportSchedules(ctx context.Context) ([]ExportSchedule, error)
+	DeleteExportSchedule(ctx context.Context, id uint) error
+	GetDueExportSchedules(ctx context.Context, before time.Time, limit int) ([]ExportSchedule, error)
+	ClaimExportSchedule(ctx context.Context, data ExportSchedule, next time.Time) (bool, error)
+	CreateExportFile(ctx context.Context, data ExportFile) (*ExportFile, error)
+	GetExportFiles(ctx context.Context, scheduleId uint) ([]ExportFile, error)
+	GetExportFile(ctx context.Context, id uint) (*ExportFile, error)
 }
@@ -0,0 +1,96 @@
+package bookings
+
+import (
+	"time"
+	"wanderer/helpers/filters"
+	"wanderer/utils/exporter"
+)
+
+const (
+	FrequencyDaily   = "daily"
+	FrequencyWeekly  = "weekly"
+	FrequencyMonthly = "monthly"
+)
+
+const (
+	ExportReady  = "ready"
+	ExportFailed = "failed"
+)
+
+// ExportSchedule is a recurring export of the bookings matching Filter. Every
+// run exports the bookings made during the period that just ended, so the
+// booked dates of Filter are not used.
+type ExportSchedule struct {
+	Id        uint
+	Frequency string
+	Format    exporter.Format
+	Filter    filters.Booking
+	CreatedBy uint
+
+	NextRunAt time.Time
+	CreatedAt time.Time
+}
+
+// ExportFile is an export made by a schedule, kept for download.
+type ExportFile struct {
+	Id         uint
+	ScheduleId uint
+	Format     exporter.Format
+	Status     string
+	Error      string
+	Content    []byte
+
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	CreatedAt   time.Time
+}
+
+// ValidFrequency reports whether frequency is one a schedule can run at.
+func ValidFrequency(frequency string) bool {
+	switch frequency {
+	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly:
+		return true
+	}
+
+	return false
+}
+
+// FirstRun is the start of the day, week or month following at, in at's
+// location. Runs are aligned on those boundaries, so periods never drift.
+func (s ExportSchedule) FirstRun(at time.Time) time.Time {
+	day := time.Date(at.Year(), at.Month(), at.Day(), 0, 0, 0, 0, at.Location())
+
+	switch s.Frequency {
+	case FrequencyWeekly:
+		return day.AddDate(0, 0, 7-(int(day.Weekday())+6)%7)
+	case FrequencyMonthly:
+		return time.Date(at.Year(), at.Month()+1, 1, 0, 0, 0, 0, at.Location())
+	default:
+		return day.AddDate(0, 0, 1)
+	}
+}
+
+// Next is the run that follows the one at run.
+func (s ExportSchedule) Next(run time.Time) time.Time {
+	switch s.Frequency {
+	case FrequencyWeekly:
+		return run.AddDate(0, 0, 7)
+	case FrequencyMonthly:
+		return run.AddDate(0, 1, 0)
+	default:
+		return run.AddDate(0, 0, 1)
+	}
+}
+
+// Period is the span of booking time exported by the run at run, from its
+// start up to but not including run.
+func (s ExportSchedule) Period(run time.Time) (time.Time, time.Time) {
+	switch s.Frequency {
+	case FrequencyWeekly:
+		return run.AddDate(0, 0, -7), run
+	case FrequencyMonthly:
+		return run.AddDate(0, -1, 0), run
+	default:
+		return run.AddDate(0, 0, -1), run
+	}
+}
@@ -0,0 +1,58 @@
+package bookings_test
+
+import (
+	"testing"
+	"time"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExportScheduleRuns(t *testing.T) {
+	// Wednesday, 31 January 2024.
+	at := time.Date(2024, 1, 31, 15, 4, 5, 0, time.UTC)
+
+	var testCases = []struct {
+		frequency string
+		first     time.Time
+		next      time.Time
+		start     time.Time
+	}{
+		{
+			frequency: bookings.FrequencyDaily,
+			first:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			next:      time.Date(2024, 2, 2, 0, 0, 0, 0, time.UTC),
+			start:     time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			frequency: bookings.FrequencyWeekly,
+			first:     time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC),
+			next:      time.Date(2024, 2, 12, 0, 0, 0, 0, time.UTC),
+			start:     time.Date(2024, 1, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			frequency: bookings.FrequencyMonthly,
+			first:     time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+			next:      time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC),
+			start:     time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		schedule := bookings.ExportSchedule{Frequency: tc.frequency}
+
+		first := schedule.FirstRun(at)
+		assert.Equal(t, tc.first, first, tc.frequency)
+		assert.Equal(t, tc.next, schedule.Next(first), tc.frequency)
+
+		start, end := schedule.Period(first)
+		assert.Equal(t, tc.start, start, tc.frequency)
+		assert.Equal(t, first, end, tc.frequency)
+	}
+
+	sunday := time.Date(2024, 2, 4, 23, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2024, 2, 5, 0, 0, 0, 0, time.UTC), bookings.ExportSchedule{Frequency: bookings.FrequencyWeekly}.FirstRun(sunday))
+
+	assert.True(t, bookings.ValidFrequency(bookings.FrequencyMonthly))
+	assert.False(t, bookings.ValidFrequency("yearly"))
+}
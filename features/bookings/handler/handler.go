@@ -4,13 +4,16 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"os"
+	"net/url"
 	"strconv"
 	"strings"
+	"time"
 	"wanderer/config"
 	"wanderer/features/bookings"
+	"wanderer/helpers/authorization"
 	"wanderer/helpers/filters"
 	"wanderer/helpers/tokens"
+	"wanderer/utils/exporter"
 
 	"github.com/golang-jwt/jwt/v5"
 	echo "github.com/labstack/echo/v4"
@@ -33,6 +36,20 @@ func (hdl *bookingHandler) GetAll() echo.HandlerFunc {
 		var response = make(map[string]any)
 		var baseUrl = c.Scheme() + "://" + c.Request().Host
 
+		token := c.Get("user")
+		if token == nil {
+			response["message"] = "unauthorized access"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		userId, err := tokens.ExtractToken(hdl.jwtConfig.Secret, token.(*jwt.Token))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "unauthorized"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
 		var pagination = new(filters.Pagination)
 		c.Bind(pagination)
 		if pagination.Start != 0 && pagination.Limit == 0 {
@@ -45,10 +62,28 @@ func (hdl *bookingHandler) GetAll() echo.HandlerFunc {
 		var sort = new(filters.Sort)
 		c.Bind(sort)
 
-		result, totalData, err := hdl.bookingService.GetAll(context.Background(), filters.Filter{Pagination: *pagination, Sort: *sort})
+		var booking = new(filters.Booking)
+		if err := c.Bind(booking); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		result, totalData, err := hdl.bookingService.GetAll(c.Request().Context(), userId, filters.Filter{Search: *search, Pagination: *pagination, Sort: *sort, Booking: *booking})
 		if err != nil {
 			c.Logger().Error(err)
 
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
 			response["message"] = "internal server error"
 			return c.JSON(http.StatusInternalServerError, response)
 		}
@@ -65,11 +100,49 @@ func (hdl *bookingHandler) GetAll() echo.HandlerFunc {
 		response["data"] = data
 
 		if pagination.Limit != 0 {
+			var query = url.Values{}
+			if search.Keyword != "" {
+				query.Set("keyword", search.Keyword)
+			}
+
+			if sort.Column != "" {
+				query.Set("sort", sort.Column)
+				query.Set("dir", strconv.FormatBool(sort.Direction))
+			}
+
+			if booking.UserId != 0 {
+				query.Set("user_id", strconv.Itoa(int(booking.UserId)))
+			}
+
+			if booking.TourId != 0 {
+				query.Set("tour_id", strconv.Itoa(int(booking.TourId)))
+			}
+
+			if booking.LocationId != 0 {
+				query.Set("location_id", strconv.Itoa(int(booking.LocationId)))
+			}
+
+			if booking.Status != "" {
+				query.Set("status", booking.Status)
+			}
+
+			if booking.PaymentMethod != "" {
+				query.Set("payment_method", booking.PaymentMethod)
+			}
+
+			if !booking.BookedStart.IsZero() {
+				query.Set("booked_start", booking.BookedStart.Format(time.RFC3339))
+			}
+
+			if !booking.BookedEnd.IsZero() {
+				query.Set("booked_end", booking.BookedEnd.Format(time.RFC3339))
+			}
+
 			var paginationResponse = make(map[string]any)
 			if pagination.Start >= (pagination.Limit) {
 				prev := fmt.Sprintf("%s%s?start=%d&limit=%d", baseUrl, c.Path(), pagination.Start-pagination.Limit, pagination.Limit)
-				if search.Keyword != "" {
-					prev += "&keyword=" + search.Keyword
+				if len(query) != 0 {
+					prev += "&" + query.Encode()
 				}
 				paginationResponse["prev"] = prev
 			} else {
@@ -78,8 +151,8 @@ func (hdl *bookingHandler) GetAll() echo.HandlerFunc {
 
 			if totalData > pagination.Start+pagination.Limit {
 				next := fmt.Sprintf("%s%s?start=%d&limit=%d", baseUrl, c.Path(), pagination.Start+pagination.Limit, pagination.Limit)
-				if search.Keyword != "" {
-					next += "&keyword=" + search.Keyword
+				if len(query) != 0 {
+					next += "&" + query.Encode()
 				}
 				paginationResponse["next"] = next
 			} else {
@@ -88,7 +161,7 @@ func (hdl *bookingHandler) GetAll() echo.HandlerFunc {
 			response["pagination"] = paginationResponse
 		}
 
-		response["message"] = "get all tour success"
+		response["message"] = "get all booking success"
 		return c.JSON(http.StatusOK, response)
 	}
 }
@@ -97,15 +170,27 @@ func (hdl *bookingHandler) GetDetail() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var response = make(map[string]any)
 
-		bookingCode, err := strconv.Atoi(c.Param("code"))
+		token := c.Get("user")
+		if token == nil {
+			response["message"] = "unauthorized access"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		userId, err := tokens.ExtractToken(hdl.jwtConfig.Secret, token.(*jwt.Token))
 		if err != nil {
 			c.Logger().Error(err)
 
+			response["message"] = "unauthorized"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		bookingCode := bookings.NormalizeCode(c.Param("code"))
+		if !bookings.ValidCode(bookingCode) {
 			response["message"] = "invalid booking code"
 			return c.JSON(http.StatusBadRequest, response)
 		}
 
-		result, err := hdl.bookingService.GetDetail(c.Request().Context(), bookingCode)
+		result, err := hdl.bookingService.GetDetail(c.Request().Context(), userId, bookingCode)
 		if err != nil {
 			c.Logger().Error(err)
 
@@ -193,10 +278,8 @@ func (hdl *bookingHandler) Update() echo.HandlerFunc {
 		var response = make(map[string]any)
 		var request = new(BookingUpdateRequest)
 
-		bookingCode, err := strconv.Atoi(c.Param("code"))
-		if err != nil {
-			c.Logger().Error(err)
-
+		bookingCode := bookings.NormalizeCode(c.Param("code"))
+		if !bookings.ValidCode(bookingCode) {
 			response["message"] = "invalid booking code"
 			return c.JSON(http.StatusBadRequest, response)
 		}
@@ -209,7 +292,7 @@ func (hdl *bookingHandler) Update() echo.HandlerFunc {
 		}
 
 		if request.Bank != "" {
-			result, err := hdl.bookingService.ChangePaymentMethod(c.Request().Context(), bookingCode, request.ToEntity().Payment)
+			result, err := hdl.bookingService.ChangePaymentMethod(c.Request().Context(), actor(c), bookingCode, request.ToEntity().Payment)
 			if err != nil {
 				c.Logger().Error(err)
 
@@ -237,8 +320,21 @@ func (hdl *bookingHandler) Update() echo.HandlerFunc {
 
 			response["message"] = "change payment method success"
 			response["data"] = data
-		} else if request.Status != "" {
-			if err := hdl.bookingService.UpdateBookingStatus(c.Request().Context(), bookingCode, request.Status); err != nil {
+		} else if request.Status == bookings.StatusRefund || request.Status == bookings.StatusRefunded {
+			var result *bookings.Refund
+			var err error
+			if request.Status == bookings.StatusRefunded {
+				if !authorization.IsAdmin(c) {
+					return authorization.Forbidden(c)
+				}
+
+				result, err = hdl.bookingService.ApproveRefund(c.Request().Context(), actor(c), bookingCode, request.RefundAmount)
+			} else {
+				userId, _ := authorization.Identity(c)
+				result, err = hdl.bookingService.RequestRefund(c.Request().Context(), userId, bookingCode, request.ToRefundEntity())
+			}
+
+			if err != nil {
 				c.Logger().Error(err)
 
 				if strings.Contains(err.Error(), "validate: ") {
@@ -260,19 +356,54 @@ func (hdl *bookingHandler) Update() echo.HandlerFunc {
 				return c.JSON(http.StatusInternalServerError, response)
 			}
 
-			if request.Status == "cancel" {
-				response["message"] = "cancel success"
-			} else if request.Status == "refund" {
+			var data = new(RefundResponse)
+			data.FromEntity(*result)
+
+			if request.Status == bookings.StatusRefund {
 				response["message"] = "refund requested"
-			} else if request.Status == "refunded" {
+			} else {
 				response["message"] = "approve refund success"
 			}
+			response["data"] = data
+		} else if request.Status != "" {
+			if err := hdl.bookingService.UpdateBookingStatus(c.Request().Context(), actor(c), bookingCode, request.Status); err != nil {
+				c.Logger().Error(err)
+
+				if strings.Contains(err.Error(), "validate: ") {
+					response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+					return c.JSON(http.StatusBadRequest, response)
+				}
+
+				if strings.Contains(err.Error(), "not found: ") {
+					response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+					return c.JSON(http.StatusNotFound, response)
+				}
+
+				if strings.Contains(err.Error(), "unprocessable: ") {
+					response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+					return c.JSON(http.StatusUnprocessableEntity, response)
+				}
+
+				response["message"] = "internal server error"
+				return c.JSON(http.StatusInternalServerError, response)
+			}
+
+			response["message"] = "cancel success"
 		}
 
 		return c.JSON(http.StatusOK, response)
 	}
 }
 
+func actor(c echo.Context) bookings.Actor {
+	userId, role := authorization.Identity(c)
+	if role == authorization.RoleAdmin {
+		return bookings.Actor{Id: userId, Source: bookings.SourceAdmin}
+	}
+
+	return bookings.Actor{Id: userId, Source: bookings.SourceUser}
+}
+
 func (hdl *bookingHandler) PaymentNotification() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var request = new(PaymentNotificationRequest)
@@ -283,14 +414,7 @@ func (hdl *bookingHandler) PaymentNotification() echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest, "bad request")
 		}
 
-		code, err := strconv.Atoi(request.Code)
-		if err != nil {
-			c.Logger().Error(err)
-
-			return c.JSON(http.StatusBadRequest, "bad request")
-		}
-
-		if err = hdl.bookingService.UpdatePaymentStatus(c.Request().Context(), code, request.Status); err != nil {
+		if err := hdl.bookingService.PaymentNotification(c.Request().Context(), request.ToEntity()); err != nil {
 			c.Logger().Error(err)
 
 			if strings.Contains(err.Error(), "validate: ") {
@@ -308,27 +432,285 @@ func (hdl *bookingHandler) PaymentNotification() echo.HandlerFunc {
 	}
 }
 
+// ExportReportTransaction streams the export as a download, or stores it in
+// the cloud and responds with its url when upload=true is asked for.
 func (hdl *bookingHandler) ExportReportTransaction() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var response = make(map[string]any)
-		typeFile := c.QueryParam("type")
 
-		err := hdl.bookingService.Export(c, typeFile)
+		format, err := exporter.ParseFormat(c.QueryParam("type"))
 		if err != nil {
+			response["message"] = err.Error()
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		var flt = new(filters.Booking)
+		if err := c.Bind(flt); err != nil {
 			c.Logger().Error(err)
 
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if upload, _ := strconv.ParseBool(c.QueryParam("upload")); upload {
+			url, err := hdl.bookingService.ExportToCloud(c.Request().Context(), format, *flt)
+			if err != nil {
+				c.Logger().Error(err)
+
+				if strings.Contains(err.Error(), "validate: ") {
+					response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+					return c.JSON(http.StatusBadRequest, response)
+				}
+
+				response["message"] = "internal server error"
+				return c.JSON(http.StatusInternalServerError, response)
+			}
+
+			response["message"] = "export transaction list success"
+			response["data"] = map[string]any{"url": url}
+			return c.JSON(http.StatusOK, response)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentType, format.ContentType())
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+format.Filename("transaction-list", time.Now()))
+
+		if err := hdl.bookingService.Export(c.Request().Context(), c.Response(), format, *flt); err != nil {
+			c.Logger().Error(err)
+
+			// Once the file has started going out, the response can't be
+			// turned into an error anymore.
+			if c.Response().Committed {
+				return nil
+			}
+
+			c.Response().Header().Del(echo.HeaderContentType)
+			c.Response().Header().Del(echo.HeaderContentDisposition)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
 			response["message"] = "internal server error"
 			return c.JSON(http.StatusInternalServerError, response)
 		}
 
-		filePath := fmt.Sprintf("transaction-list.%s", typeFile)
+		return nil
+	}
+}
+
+func (hdl *bookingHandler) CreateExportSchedule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(ExportScheduleRequest)
 
-		err = os.Remove(filePath)
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		userId, _ := authorization.Identity(c)
+		result, err := hdl.bookingService.CreateExportSchedule(c.Request().Context(), request.ToEntity(userId))
 		if err != nil {
-			return err
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		var data = new(ExportScheduleResponse)
+		data.FromEntity(*result)
+
+		response["message"] = "create export schedule success"
+		response["data"] = data
+		return c.JSON(http.StatusCreated, response)
+	}
+}
+
+func (hdl *bookingHandler) GetExportSchedules() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		result, err := hdl.bookingService.GetExportSchedules(c.Request().Context())
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		var data []ExportScheduleResponse
+		for _, schedule := range result {
+			var tmpSchedule = new(ExportScheduleResponse)
+			tmpSchedule.FromEntity(schedule)
+
+			data = append(data, *tmpSchedule)
+		}
+
+		response["message"] = "get all export schedule success"
+		response["data"] = data
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *bookingHandler) DeleteExportSchedule() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			response["message"] = "invalid export schedule id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.bookingService.DeleteExportSchedule(c.Request().Context(), uint(id)); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "delete export schedule success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *bookingHandler) GetExportFiles() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		var scheduleId int
+		if param := c.QueryParam("schedule_id"); param != "" {
+			id, err := strconv.Atoi(param)
+			if err != nil || id <= 0 {
+				response["message"] = "invalid export schedule id"
+				return c.JSON(http.StatusBadRequest, response)
+			}
+			scheduleId = id
+		}
+
+		result, err := hdl.bookingService.GetExportFiles(c.Request().Context(), uint(scheduleId))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		var data []ExportFileResponse
+		for _, file := range result {
+			var tmpFile = new(ExportFileResponse)
+			tmpFile.FromEntity(file)
+
+			data = append(data, *tmpFile)
 		}
 
-		response["message"] = "export transaction list success"
+		response["message"] = "get all export success"
+		response["data"] = data
 		return c.JSON(http.StatusOK, response)
 	}
 }
+
+// DownloadExport serves an export made by a schedule, named after the period
+// it covers.
+func (hdl *bookingHandler) DownloadExport() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			response["message"] = "invalid export id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		result, err := hdl.bookingService.GetExportFile(c.Request().Context(), uint(id))
+		if err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename="+result.Format.Filename("transaction-list", result.PeriodStart))
+		return c.Blob(http.StatusOK, result.Format.ContentType(), result.Content)
+	}
+}
+
+func (hdl *bookingHandler) Invoice() echo.HandlerFunc {
+	return hdl.document("invoice", hdl.bookingService.Invoice)
+}
+
+func (hdl *bookingHandler) Ticket() echo.HandlerFunc {
+	return hdl.document("ticket", hdl.bookingService.Ticket)
+}
+
+// document serves the PDF made by render as a download named after the
+// booking.
+func (hdl *bookingHandler) document(name string, render func(ctx context.Context, userId uint, code string) ([]byte, error)) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		token := c.Get("user")
+		if token == nil {
+			response["message"] = "unauthorized access"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		userId, err := tokens.ExtractToken(hdl.jwtConfig.Secret, token.(*jwt.Token))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "unauthorized"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		bookingCode := bookings.NormalizeCode(c.Param("code"))
+		if !bookings.ValidCode(bookingCode) {
+			response["message"] = "invalid booking code"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		result, err := render(c.Request().Context(), userId, bookingCode)
+		if err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		c.Response().Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%s-%s.pdf", name, bookingCode))
+		return c.Blob(http.StatusOK, "application/pdf", result)
+	}
+}
@@ -3,12 +3,16 @@ package handler
 import (
 	"time"
 	"wanderer/features/bookings"
+	"wanderer/helpers/filters"
+	"wanderer/utils/exporter"
 )
 
 type BookingCreateRequest struct {
-	TourId uint                         `json:"tour_id"`
-	Detail []BookingDetailCreateRequest `json:"detail"`
-	Bank   string                       `json:"payment_method"`
+	TourId      uint                         `json:"tour_id"`
+	DepartureId uint                         `json:"departure_id"`
+	Detail      []BookingDetailCreateRequest `json:"detail"`
+	Bank        string                       `json:"payment_method"`
+	VoucherCode string                       `json:"voucher_code"`
 }
 
 func (req *BookingCreateRequest) ToEntity(userId uint) bookings.Booking {
@@ -18,6 +22,10 @@ func (req *BookingCreateRequest) ToEntity(userId uint) bookings.Booking {
 		ent.Tour.Id = req.TourId
 	}
 
+	if req.DepartureId != 0 {
+		ent.Departure.Id = req.DepartureId
+	}
+
 	if userId != 0 {
 		ent.User.Id = userId
 	}
@@ -30,12 +38,20 @@ func (req *BookingCreateRequest) ToEntity(userId uint) bookings.Booking {
 		ent.Payment.Bank = req.Bank
 	}
 
+	if req.VoucherCode != "" {
+		ent.Voucher.Code = req.VoucherCode
+	}
+
 	return *ent
 }
 
 type BookingUpdateRequest struct {
 	Bank   string `json:"payment_method"`
 	Status string `json:"status"`
+
+	RefundReason     string  `json:"reason"`
+	RefundPassengers []uint  `json:"passengers"`
+	RefundAmount     float64 `json:"refund_amount"`
 }
 
 func (req *BookingUpdateRequest) ToEntity() bookings.Booking {
@@ -52,6 +68,20 @@ func (req *BookingUpdateRequest) ToEntity() bookings.Booking {
 	return *ent
 }
 
+func (req *BookingUpdateRequest) ToRefundEntity() bookings.Refund {
+	var ent = new(bookings.Refund)
+
+	if req.RefundReason != "" {
+		ent.Reason = req.RefundReason
+	}
+
+	if len(req.RefundPassengers) != 0 {
+		ent.Passengers = req.RefundPassengers
+	}
+
+	return *ent
+}
+
 type BookingDetailCreateRequest struct {
 	DocumentNumber string    `json:"document_number"`
 	Greeting       string    `json:"greeting"`
@@ -87,6 +117,54 @@ func (req *BookingDetailCreateRequest) ToEntity() bookings.Detail {
 }
 
 type PaymentNotificationRequest struct {
-	Code   string `json:"order_id"`
-	Status string `json:"transaction_status"`
+	Code          string `json:"order_id"`
+	TransactionId string `json:"transaction_id"`
+	StatusCode    string `json:"status_code"`
+	GrossAmount   string `json:"gross_amount"`
+	Status        string `json:"transaction_status"`
+	PaymentType   string `json:"payment_type"`
+	FraudStatus   string `json:"fraud_status"`
+	SignatureKey  string `json:"signature_key"`
+}
+
+func (req *PaymentNotificationRequest) ToEntity() bookings.PaymentNotification {
+	var ent = new(bookings.PaymentNotification)
+
+	ent.OrderId = req.Code
+	ent.TransactionId = req.TransactionId
+	ent.StatusCode = req.StatusCode
+	ent.GrossAmount = req.GrossAmount
+	ent.Status = req.Status
+	ent.PaymentType = req.PaymentType
+	ent.FraudStatus = req.FraudStatus
+	ent.SignatureKey = req.SignatureKey
+
+	return *ent
+}
+
+type ExportScheduleRequest struct {
+	Frequency     string `json:"frequency"`
+	Type          string `json:"type"`
+	UserId        uint   `json:"user_id"`
+	TourId        uint   `json:"tour_id"`
+	LocationId    uint   `json:"location_id"`
+	Status        string `json:"status"`
+	PaymentMethod string `json:"payment_method"`
+}
+
+func (req *ExportScheduleRequest) ToEntity(userId uint) bookings.ExportSchedule {
+	var ent = new(bookings.ExportSchedule)
+
+	ent.Frequency = req.Frequency
+	ent.Format = exporter.Format(req.Type)
+	ent.Filter = filters.Booking{
+		UserId:        req.UserId,
+		TourId:        req.TourId,
+		LocationId:    req.LocationId,
+		Status:        req.Status,
+		PaymentMethod: req.PaymentMethod,
+	}
+	ent.CreatedBy = userId
+
+	return *ent
 }
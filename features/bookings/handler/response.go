@@ -7,10 +7,12 @@ import (
 )
 
 type BookingResponse struct {
-	Code        int     `json:"booking_code,omitempty"`
+	Code        string  `json:"booking_code,omitempty"`
 	DetailCount int     `json:"detail_count,omitempty"`
 	Status      string  `json:"status,omitempty"`
 	Total       float64 `json:"total,omitempty"`
+	VoucherCode string  `json:"voucher_code,omitempty"`
+	Discount    float64 `json:"voucher_discount,omitempty"`
 
 	PaymentBank          string     `json:"payment_method,omitempty"`
 	PaymentVirtualNumber string     `json:"virtual_number,omitempty"`
@@ -18,13 +20,19 @@ type BookingResponse struct {
 	PaymentBillCode      string     `json:"code_bill,omitempty"`
 	PaymentExpiredAt     *time.Time `json:"payment_expired,omitempty"`
 
-	Tour *TourResponse `json:"tour,omitempty"`
+	Tour        *TourResponse `json:"tour,omitempty"`
+	DepartureId uint          `json:"departure_id,omitempty"`
 
 	User *UserResponse `json:"user,omitempty"`
+
+	Passengers     []PassengerResponse `json:"passengers,omitempty"`
+	PriceBreakdown []PriceLineResponse `json:"price_breakdown,omitempty"`
+	Refunds        []RefundResponse    `json:"refunds,omitempty"`
+	Timeline       []TimelineResponse  `json:"timeline,omitempty"`
 }
 
 func (res *BookingResponse) FromEntity(ent bookings.Booking) {
-	if ent.Code != 0 {
+	if ent.Code != "" {
 		res.Code = ent.Code
 	}
 
@@ -40,6 +48,11 @@ func (res *BookingResponse) FromEntity(ent bookings.Booking) {
 		res.Total = ent.Total
 	}
 
+	if ent.Voucher.Code != "" {
+		res.VoucherCode = ent.Voucher.Code
+		res.Discount = ent.Discount
+	}
+
 	if !reflect.ValueOf(ent.Payment).IsZero() {
 		if ent.Payment.Bank != "" {
 			res.PaymentBank = ent.Payment.Bank
@@ -68,11 +81,138 @@ func (res *BookingResponse) FromEntity(ent bookings.Booking) {
 		res.Tour = tmpTour
 	}
 
+	if ent.Departure.Id != 0 {
+		res.DepartureId = ent.Departure.Id
+	}
+
 	if !reflect.ValueOf(ent.User).IsZero() {
 		var tmpUser = new(UserResponse)
 		tmpUser.FromEntity(ent.User)
 		res.User = tmpUser
 	}
+
+	refunded := ent.Refunded()
+	for _, detail := range ent.Detail {
+		if detail.Id == 0 {
+			continue
+		}
+
+		var tmpPassenger = new(PassengerResponse)
+		tmpPassenger.FromEntity(detail)
+		tmpPassenger.Refunded = refunded[detail.Id]
+
+		res.Passengers = append(res.Passengers, *tmpPassenger)
+	}
+
+	for _, line := range ent.Breakdown() {
+		res.PriceBreakdown = append(res.PriceBreakdown, PriceLineResponse{
+			Category:   line.Category,
+			Passengers: line.Passengers,
+			Price:      line.Price,
+			Subtotal:   line.Subtotal,
+		})
+	}
+
+	for _, refund := range ent.Refunds {
+		var tmpRefund = new(RefundResponse)
+		tmpRefund.FromEntity(refund)
+
+		res.Refunds = append(res.Refunds, *tmpRefund)
+	}
+
+	for _, history := range ent.History {
+		var tmpTimeline = new(TimelineResponse)
+		tmpTimeline.FromEntity(history)
+
+		res.Timeline = append(res.Timeline, *tmpTimeline)
+	}
+}
+
+type TimelineResponse struct {
+	From        string    `json:"from,omitempty"`
+	To          string    `json:"to"`
+	PaymentFrom string    `json:"payment_from,omitempty"`
+	PaymentTo   string    `json:"payment_to,omitempty"`
+	ActorId     uint      `json:"actor_id,omitempty"`
+	Source      string    `json:"source"`
+	Note        string    `json:"note,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (res *TimelineResponse) FromEntity(ent bookings.Transition) {
+	res.From = ent.From
+	res.To = ent.To
+	res.PaymentFrom = ent.PaymentFrom
+	res.PaymentTo = ent.PaymentTo
+	res.ActorId = ent.Actor.Id
+	res.Source = ent.Actor.Source
+	res.Note = ent.Note
+	res.CreatedAt = ent.CreatedAt
+}
+
+type PassengerResponse struct {
+	Id       uint    `json:"passenger_id"`
+	Greeting string  `json:"greeting,omitempty"`
+	Name     string  `json:"name,omitempty"`
+	Category string  `json:"category,omitempty"`
+	Price    float64 `json:"price"`
+	Refunded bool    `json:"refunded,omitempty"`
+}
+
+func (res *PassengerResponse) FromEntity(ent bookings.Detail) {
+	res.Id = ent.Id
+	res.Greeting = ent.Greeting
+	res.Name = ent.Name
+	res.Category = ent.Category
+	res.Price = ent.Price
+}
+
+// PriceLineResponse is what the passengers of a category pay for their seats,
+// before the tour discount.
+type PriceLineResponse struct {
+	Category   string  `json:"category"`
+	Passengers int     `json:"passengers"`
+	Price      float64 `json:"price"`
+	Subtotal   float64 `json:"subtotal"`
+}
+
+type RefundResponse struct {
+	Id         uint                    `json:"refund_id"`
+	Reason     string                  `json:"reason,omitempty"`
+	Passengers []uint                  `json:"passengers,omitempty"`
+	Percentage int                     `json:"percentage"`
+	Amount     float64                 `json:"amount"`
+	Status     string                  `json:"status,omitempty"`
+	Note       string                  `json:"note,omitempty"`
+	CreatedAt  time.Time               `json:"created_at"`
+	History    []RefundHistoryResponse `json:"history,omitempty"`
+}
+
+func (res *RefundResponse) FromEntity(ent bookings.Refund) {
+	res.Id = ent.Id
+	res.Reason = ent.Reason
+	res.Passengers = ent.Passengers
+	res.Percentage = ent.Percentage
+	res.Amount = ent.Amount
+	res.Status = ent.Status
+	res.Note = ent.Note
+	res.CreatedAt = ent.CreatedAt
+
+	for _, history := range ent.History {
+		res.History = append(res.History, RefundHistoryResponse{
+			Status:    history.Status,
+			Amount:    history.Amount,
+			Note:      history.Note,
+			CreatedAt: history.CreatedAt,
+		})
+	}
+}
+
+type RefundHistoryResponse struct {
+	Status    string    `json:"status"`
+	Amount    float64   `json:"amount"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
 }
 
 type TourResponse struct {
@@ -216,3 +356,51 @@ func (res *UserResponse) FromEntity(ent bookings.User) {
 		res.Image = "default"
 	}
 }
+
+type ExportScheduleResponse struct {
+	Id            uint      `json:"schedule_id"`
+	Frequency     string    `json:"frequency"`
+	Type          string    `json:"type"`
+	UserId        uint      `json:"user_id,omitempty"`
+	TourId        uint      `json:"tour_id,omitempty"`
+	LocationId    uint      `json:"location_id,omitempty"`
+	Status        string    `json:"status,omitempty"`
+	PaymentMethod string    `json:"payment_method,omitempty"`
+	NextRunAt     time.Time `json:"next_run_at"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (res *ExportScheduleResponse) FromEntity(ent bookings.ExportSchedule) {
+	res.Id = ent.Id
+	res.Frequency = ent.Frequency
+	res.Type = string(ent.Format)
+	res.UserId = ent.Filter.UserId
+	res.TourId = ent.Filter.TourId
+	res.LocationId = ent.Filter.LocationId
+	res.Status = ent.Filter.Status
+	res.PaymentMethod = ent.Filter.PaymentMethod
+	res.NextRunAt = ent.NextRunAt
+	res.CreatedAt = ent.CreatedAt
+}
+
+type ExportFileResponse struct {
+	Id          uint      `json:"export_id"`
+	ScheduleId  uint      `json:"schedule_id"`
+	Type        string    `json:"type"`
+	Status      string    `json:"status"`
+	Error       string    `json:"error,omitempty"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (res *ExportFileResponse) FromEntity(ent bookings.ExportFile) {
+	res.Id = ent.Id
+	res.ScheduleId = ent.ScheduleId
+	res.Type = string(ent.Format)
+	res.Status = ent.Status
+	res.Error = ent.Error
+	res.PeriodStart = ent.PeriodStart
+	res.PeriodEnd = ent.PeriodEnd
+	res.CreatedAt = ent.CreatedAt
+}
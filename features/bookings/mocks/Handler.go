@@ -28,6 +28,54 @@ func (_m *Handler) Create() echo.HandlerFunc {
 	return r0
 }
 
+// CreateExportSchedule provides a mock function with given fields:
+func (_m *Handler) CreateExportSchedule() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// DeleteExportSchedule provides a mock function with given fields:
+func (_m *Handler) DeleteExportSchedule() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// DownloadExport provides a mock function with given fields:
+func (_m *Handler) DownloadExport() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // ExportReportTransaction provides a mock function with given fields:
 func (_m *Handler) ExportReportTransaction() echo.HandlerFunc {
 	ret := _m.Called()
@@ -76,6 +124,54 @@ func (_m *Handler) GetDetail() echo.HandlerFunc {
 	return r0
 }
 
+// GetExportFiles provides a mock function with given fields:
+func (_m *Handler) GetExportFiles() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// GetExportSchedules provides a mock function with given fields:
+func (_m *Handler) GetExportSchedules() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// Invoice provides a mock function with given fields:
+func (_m *Handler) Invoice() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // PaymentNotification provides a mock function with given fields:
 func (_m *Handler) PaymentNotification() echo.HandlerFunc {
 	ret := _m.Called()
@@ -92,6 +188,22 @@ func (_m *Handler) PaymentNotification() echo.HandlerFunc {
 	return r0
 }
 
+// Ticket provides a mock function with given fields:
+func (_m *Handler) Ticket() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields:
 func (_m *Handler) Update() echo.HandlerFunc {
 	ret := _m.Called()
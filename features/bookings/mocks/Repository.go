@@ -6,11 +6,13 @@ import (
 	context "context"
 	bookings "wanderer/features/bookings"
 
-	echo "github.com/labstack/echo/v4"
-
 	filters "wanderer/helpers/filters"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -19,24 +21,60 @@ type Repository struct {
 }
 
 // ChangePaymentMethod provides a mock function with given fields: ctx, code, data
-func (_m *Repository) ChangePaymentMethod(ctx context.Context, code int, data bookings.Booking) (*bookings.Payment, error) {
+func (_m *Repository) ChangePaymentMethod(ctx context.Context, code string, data bookings.Payment) error {
 	ret := _m.Called(ctx, code, data)
 
-	var r0 *bookings.Payment
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, bookings.Payment) error); ok {
+		r0 = rf(ctx, code, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ClaimExportSchedule provides a mock function with given fields: ctx, data, next
+func (_m *Repository) ClaimExportSchedule(ctx context.Context, data bookings.ExportSchedule, next time.Time) (bool, error) {
+	ret := _m.Called(ctx, data, next)
+
+	var r0 bool
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, bookings.Booking) (*bookings.Payment, error)); ok {
-		return rf(ctx, code, data)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportSchedule, time.Time) (bool, error)); ok {
+		return rf(ctx, data, next)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, bookings.Booking) *bookings.Payment); ok {
-		r0 = rf(ctx, code, data)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportSchedule, time.Time) bool); ok {
+		r0 = rf(ctx, data, next)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*bookings.Payment)
-		}
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.ExportSchedule, time.Time) error); ok {
+		r1 = rf(ctx, data, next)
+	} else {
+		r1 = ret.Error(1)
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, bookings.Booking) error); ok {
-		r1 = rf(ctx, code, data)
+	return r0, r1
+}
+
+// ClaimNotification provides a mock function with given fields: ctx, data, until
+func (_m *Repository) ClaimNotification(ctx context.Context, data bookings.Notification, until time.Time) (bool, error) {
+	ret := _m.Called(ctx, data, until)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Notification, time.Time) (bool, error)); ok {
+		return rf(ctx, data, until)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Notification, time.Time) bool); ok {
+		r0 = rf(ctx, data, until)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.Notification, time.Time) error); ok {
+		r1 = rf(ctx, data, until)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -44,6 +82,20 @@ func (_m *Repository) ChangePaymentMethod(ctx context.Context, code int, data bo
 	return r0, r1
 }
 
+// CompleteRefund provides a mock function with given fields: ctx, data, transition
+func (_m *Repository) CompleteRefund(ctx context.Context, data bookings.Refund, transition bookings.Transition) error {
+	ret := _m.Called(ctx, data, transition)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Refund, bookings.Transition) error); ok {
+		r0 = rf(ctx, data, transition)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Create provides a mock function with given fields: ctx, data
 func (_m *Repository) Create(ctx context.Context, data bookings.Booking) (*bookings.Booking, error) {
 	ret := _m.Called(ctx, data)
@@ -70,25 +122,25 @@ func (_m *Repository) Create(ctx context.Context, data bookings.Booking) (*booki
 	return r0, r1
 }
 
-// Export provides a mock function with given fields:
-func (_m *Repository) Export() ([]bookings.Booking, error) {
-	ret := _m.Called()
+// CreateExportFile provides a mock function with given fields: ctx, data
+func (_m *Repository) CreateExportFile(ctx context.Context, data bookings.ExportFile) (*bookings.ExportFile, error) {
+	ret := _m.Called(ctx, data)
 
-	var r0 []bookings.Booking
+	var r0 *bookings.ExportFile
 	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]bookings.Booking, error)); ok {
-		return rf()
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportFile) (*bookings.ExportFile, error)); ok {
+		return rf(ctx, data)
 	}
-	if rf, ok := ret.Get(0).(func() []bookings.Booking); ok {
-		r0 = rf()
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportFile) *bookings.ExportFile); ok {
+		r0 = rf(ctx, data)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]bookings.Booking)
+			r0 = ret.Get(0).(*bookings.ExportFile)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.ExportFile) error); ok {
+		r1 = rf(ctx, data)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -96,27 +148,39 @@ func (_m *Repository) Export() ([]bookings.Booking, error) {
 	return r0, r1
 }
 
-// ExportFileCsv provides a mock function with given fields: c, data
-func (_m *Repository) ExportFileCsv(c echo.Context, data []bookings.Booking) error {
-	ret := _m.Called(c, data)
+// CreateExportSchedule provides a mock function with given fields: ctx, data
+func (_m *Repository) CreateExportSchedule(ctx context.Context, data bookings.ExportSchedule) (*bookings.ExportSchedule, error) {
+	ret := _m.Called(ctx, data)
 
-	var r0 error
-	if rf, ok := ret.Get(0).(func(echo.Context, []bookings.Booking) error); ok {
-		r0 = rf(c, data)
+	var r0 *bookings.ExportSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportSchedule) (*bookings.ExportSchedule, error)); ok {
+		return rf(ctx, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportSchedule) *bookings.ExportSchedule); ok {
+		r0 = rf(ctx, data)
 	} else {
-		r0 = ret.Error(0)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.ExportSchedule)
+		}
 	}
 
-	return r0
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.ExportSchedule) error); ok {
+		r1 = rf(ctx, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
 }
 
-// ExportFileExcel provides a mock function with given fields: c, data
-func (_m *Repository) ExportFileExcel(c echo.Context, data []bookings.Booking) error {
-	ret := _m.Called(c, data)
+// CreatePaymentRejection provides a mock function with given fields: ctx, data
+func (_m *Repository) CreatePaymentRejection(ctx context.Context, data bookings.PaymentNotification) error {
+	ret := _m.Called(ctx, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(echo.Context, []bookings.Booking) error); ok {
-		r0 = rf(c, data)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.PaymentNotification) error); ok {
+		r0 = rf(ctx, data)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -124,13 +188,39 @@ func (_m *Repository) ExportFileExcel(c echo.Context, data []bookings.Booking) e
 	return r0
 }
 
-// ExportFilePDF provides a mock function with given fields: c, data
-func (_m *Repository) ExportFilePDF(c echo.Context, data []bookings.Booking) error {
-	ret := _m.Called(c, data)
+// CreateRefund provides a mock function with given fields: ctx, data, transition
+func (_m *Repository) CreateRefund(ctx context.Context, data bookings.Refund, transition bookings.Transition) (*bookings.Refund, error) {
+	ret := _m.Called(ctx, data, transition)
+
+	var r0 *bookings.Refund
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Refund, bookings.Transition) (*bookings.Refund, error)); ok {
+		return rf(ctx, data, transition)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Refund, bookings.Transition) *bookings.Refund); ok {
+		r0 = rf(ctx, data, transition)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.Refund)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.Refund, bookings.Transition) error); ok {
+		r1 = rf(ctx, data, transition)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExportSchedule provides a mock function with given fields: ctx, id
+func (_m *Repository) DeleteExportSchedule(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(echo.Context, []bookings.Booking) error); ok {
-		r0 = rf(c, data)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -138,6 +228,56 @@ func (_m *Repository) ExportFilePDF(c echo.Context, data []bookings.Booking) err
 	return r0
 }
 
+// ExpireBooking provides a mock function with given fields: ctx, code, before
+func (_m *Repository) ExpireBooking(ctx context.Context, code string, before time.Time) (bool, error) {
+	ret := _m.Called(ctx, code, before)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) (bool, error)); ok {
+		return rf(ctx, code, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) bool); ok {
+		r0 = rf(ctx, code, before)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, time.Time) error); ok {
+		r1 = rf(ctx, code, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Export provides a mock function with given fields: ctx, flt
+func (_m *Repository) Export(ctx context.Context, flt filters.Booking) ([]bookings.Booking, error) {
+	ret := _m.Called(ctx, flt)
+
+	var r0 []bookings.Booking
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Booking) ([]bookings.Booking, error)); ok {
+		return rf(ctx, flt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Booking) []bookings.Booking); ok {
+		r0 = rf(ctx, flt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.Booking)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, filters.Booking) error); ok {
+		r1 = rf(ctx, flt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetAll provides a mock function with given fields: ctx, flt
 func (_m *Repository) GetAll(ctx context.Context, flt filters.Filter) ([]bookings.Booking, int, error) {
 	ret := _m.Called(ctx, flt)
@@ -172,15 +312,15 @@ func (_m *Repository) GetAll(ctx context.Context, flt filters.Filter) ([]booking
 }
 
 // GetDetail provides a mock function with given fields: ctx, code
-func (_m *Repository) GetDetail(ctx context.Context, code int) (*bookings.Booking, error) {
+func (_m *Repository) GetDetail(ctx context.Context, code string) (*bookings.Booking, error) {
 	ret := _m.Called(ctx, code)
 
 	var r0 *bookings.Booking
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*bookings.Booking, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*bookings.Booking, error)); ok {
 		return rf(ctx, code)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *bookings.Booking); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string) *bookings.Booking); ok {
 		r0 = rf(ctx, code)
 	} else {
 		if ret.Get(0) != nil {
@@ -188,7 +328,7 @@ func (_m *Repository) GetDetail(ctx context.Context, code int) (*bookings.Bookin
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
 		r1 = rf(ctx, code)
 	} else {
 		r1 = ret.Error(1)
@@ -197,6 +337,162 @@ func (_m *Repository) GetDetail(ctx context.Context, code int) (*bookings.Bookin
 	return r0, r1
 }
 
+// GetDueExportSchedules provides a mock function with given fields: ctx, before, limit
+func (_m *Repository) GetDueExportSchedules(ctx context.Context, before time.Time, limit int) ([]bookings.ExportSchedule, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	var r0 []bookings.ExportSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]bookings.ExportSchedule, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []bookings.ExportSchedule); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.ExportSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetDueNotifications provides a mock function with given fields: ctx, before, limit
+func (_m *Repository) GetDueNotifications(ctx context.Context, before time.Time, limit int) ([]bookings.Notification, error) {
+	ret := _m.Called(ctx, before, limit)
+
+	var r0 []bookings.Notification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) ([]bookings.Notification, error)); ok {
+		return rf(ctx, before, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int) []bookings.Notification); ok {
+		r0 = rf(ctx, before, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.Notification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int) error); ok {
+		r1 = rf(ctx, before, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExpiredPending provides a mock function with given fields: ctx, before, attempts, limit
+func (_m *Repository) GetExpiredPending(ctx context.Context, before time.Time, attempts int, limit int) ([]bookings.Booking, error) {
+	ret := _m.Called(ctx, before, attempts, limit)
+
+	var r0 []bookings.Booking
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) ([]bookings.Booking, error)); ok {
+		return rf(ctx, before, attempts, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time, int, int) []bookings.Booking); ok {
+		r0 = rf(ctx, before, attempts, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.Booking)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time, int, int) error); ok {
+		r1 = rf(ctx, before, attempts, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportFile provides a mock function with given fields: ctx, id
+func (_m *Repository) GetExportFile(ctx context.Context, id uint) (*bookings.ExportFile, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *bookings.ExportFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*bookings.ExportFile, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *bookings.ExportFile); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.ExportFile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportFiles provides a mock function with given fields: ctx, scheduleId
+func (_m *Repository) GetExportFiles(ctx context.Context, scheduleId uint) ([]bookings.ExportFile, error) {
+	ret := _m.Called(ctx, scheduleId)
+
+	var r0 []bookings.ExportFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]bookings.ExportFile, error)); ok {
+		return rf(ctx, scheduleId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []bookings.ExportFile); ok {
+		r0 = rf(ctx, scheduleId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.ExportFile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, scheduleId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportSchedules provides a mock function with given fields: ctx
+func (_m *Repository) GetExportSchedules(ctx context.Context) ([]bookings.ExportSchedule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []bookings.ExportSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]bookings.ExportSchedule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []bookings.ExportSchedule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.ExportSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetTourById provides a mock function with given fields: ctx, tourId
 func (_m *Repository) GetTourById(ctx context.Context, tourId uint) (*bookings.Tour, error) {
 	ret := _m.Called(ctx, tourId)
@@ -249,13 +545,67 @@ func (_m *Repository) GetUserById(ctx context.Context, userId uint) (*bookings.U
 	return r0, r1
 }
 
-// UpdateBookingStatus provides a mock function with given fields: ctx, code, status
-func (_m *Repository) UpdateBookingStatus(ctx context.Context, code int, status string) error {
-	ret := _m.Called(ctx, code, status)
+// GetVoucher provides a mock function with given fields: ctx, code, userId
+func (_m *Repository) GetVoucher(ctx context.Context, code string, userId uint) (*bookings.Voucher, error) {
+	ret := _m.Called(ctx, code, userId)
+
+	var r0 *bookings.Voucher
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) (*bookings.Voucher, error)); ok {
+		return rf(ctx, code, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string, uint) *bookings.Voucher); ok {
+		r0 = rf(ctx, code, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.Voucher)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string, uint) error); ok {
+		r1 = rf(ctx, code, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PostponeExpiry provides a mock function with given fields: ctx, code, attempts, retryAt
+func (_m *Repository) PostponeExpiry(ctx context.Context, code string, attempts int, retryAt time.Time) error {
+	ret := _m.Called(ctx, code, attempts, retryAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, int, time.Time) error); ok {
+		r0 = rf(ctx, code, attempts, retryAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateBookingStatus provides a mock function with given fields: ctx, data
+func (_m *Repository) UpdateBookingStatus(ctx context.Context, data bookings.Transition) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Transition) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateNotification provides a mock function with given fields: ctx, data
+func (_m *Repository) UpdateNotification(ctx context.Context, data bookings.Notification) error {
+	ret := _m.Called(ctx, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
-		r0 = rf(ctx, code, status)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Notification) error); ok {
+		r0 = rf(ctx, data)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -263,13 +613,13 @@ func (_m *Repository) UpdateBookingStatus(ctx context.Context, code int, status
 	return r0
 }
 
-// UpdatePaymentStatus provides a mock function with given fields: ctx, code, bookingStatus, paymentStatus
-func (_m *Repository) UpdatePaymentStatus(ctx context.Context, code int, bookingStatus string, paymentStatus string) error {
-	ret := _m.Called(ctx, code, bookingStatus, paymentStatus)
+// UpdatePaymentStatus provides a mock function with given fields: ctx, data
+func (_m *Repository) UpdatePaymentStatus(ctx context.Context, data bookings.Transition) error {
+	ret := _m.Called(ctx, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string, string) error); ok {
-		r0 = rf(ctx, code, bookingStatus, paymentStatus)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Transition) error); ok {
+		r0 = rf(ctx, data)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -277,6 +627,44 @@ func (_m *Repository) UpdatePaymentStatus(ctx context.Context, code int, booking
 	return r0
 }
 
+// UpdateRefundStatus provides a mock function with given fields: ctx, refundId, status, amount, note
+func (_m *Repository) UpdateRefundStatus(ctx context.Context, refundId uint, status string, amount float64, note string) error {
+	ret := _m.Called(ctx, refundId, status, amount, note)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, float64, string) error); ok {
+		r0 = rf(ctx, refundId, status, amount, note)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UploadExport provides a mock function with given fields: ctx, file
+func (_m *Repository) UploadExport(ctx context.Context, file io.Reader) (string, error) {
+	ret := _m.Called(ctx, file)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) (string, error)); ok {
+		return rf(ctx, file)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, io.Reader) string); ok {
+		r0 = rf(ctx, file)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, io.Reader) error); ok {
+		r1 = rf(ctx, file)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
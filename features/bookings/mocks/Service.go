@@ -6,10 +6,12 @@ import (
 	context "context"
 	bookings "wanderer/features/bookings"
 
-	echo "github.com/labstack/echo/v4"
+	exporter "wanderer/utils/exporter"
 
 	filters "wanderer/helpers/filters"
 
+	io "io"
+
 	mock "github.com/stretchr/testify/mock"
 )
 
@@ -18,25 +20,51 @@ type Service struct {
 	mock.Mock
 }
 
-// ChangePaymentMethod provides a mock function with given fields: ctx, code, data
-func (_m *Service) ChangePaymentMethod(ctx context.Context, code int, data bookings.Payment) (*bookings.Payment, error) {
-	ret := _m.Called(ctx, code, data)
+// ApproveRefund provides a mock function with given fields: ctx, actor, code, amount
+func (_m *Service) ApproveRefund(ctx context.Context, actor bookings.Actor, code string, amount float64) (*bookings.Refund, error) {
+	ret := _m.Called(ctx, actor, code, amount)
+
+	var r0 *bookings.Refund
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Actor, string, float64) (*bookings.Refund, error)); ok {
+		return rf(ctx, actor, code, amount)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Actor, string, float64) *bookings.Refund); ok {
+		r0 = rf(ctx, actor, code, amount)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.Refund)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.Actor, string, float64) error); ok {
+		r1 = rf(ctx, actor, code, amount)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ChangePaymentMethod provides a mock function with given fields: ctx, actor, code, data
+func (_m *Service) ChangePaymentMethod(ctx context.Context, actor bookings.Actor, code string, data bookings.Payment) (*bookings.Payment, error) {
+	ret := _m.Called(ctx, actor, code, data)
 
 	var r0 *bookings.Payment
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, bookings.Payment) (*bookings.Payment, error)); ok {
-		return rf(ctx, code, data)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Actor, string, bookings.Payment) (*bookings.Payment, error)); ok {
+		return rf(ctx, actor, code, data)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int, bookings.Payment) *bookings.Payment); ok {
-		r0 = rf(ctx, code, data)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Actor, string, bookings.Payment) *bookings.Payment); ok {
+		r0 = rf(ctx, actor, code, data)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*bookings.Payment)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int, bookings.Payment) error); ok {
-		r1 = rf(ctx, code, data)
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.Actor, string, bookings.Payment) error); ok {
+		r1 = rf(ctx, actor, code, data)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -70,13 +98,39 @@ func (_m *Service) Create(ctx context.Context, data bookings.Booking) (*bookings
 	return r0, r1
 }
 
-// Export provides a mock function with given fields: c, typeFile
-func (_m *Service) Export(c echo.Context, typeFile string) error {
-	ret := _m.Called(c, typeFile)
+// CreateExportSchedule provides a mock function with given fields: ctx, data
+func (_m *Service) CreateExportSchedule(ctx context.Context, data bookings.ExportSchedule) (*bookings.ExportSchedule, error) {
+	ret := _m.Called(ctx, data)
+
+	var r0 *bookings.ExportSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportSchedule) (*bookings.ExportSchedule, error)); ok {
+		return rf(ctx, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.ExportSchedule) *bookings.ExportSchedule); ok {
+		r0 = rf(ctx, data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.ExportSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bookings.ExportSchedule) error); ok {
+		r1 = rf(ctx, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteExportSchedule provides a mock function with given fields: ctx, id
+func (_m *Service) DeleteExportSchedule(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(echo.Context, string) error); ok {
-		r0 = rf(c, typeFile)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -84,32 +138,94 @@ func (_m *Service) Export(c echo.Context, typeFile string) error {
 	return r0
 }
 
-// GetAll provides a mock function with given fields: ctx, flt
-func (_m *Service) GetAll(ctx context.Context, flt filters.Filter) ([]bookings.Booking, int, error) {
-	ret := _m.Called(ctx, flt)
+// ExpirePendingBookings provides a mock function with given fields: ctx
+func (_m *Service) ExpirePendingBookings(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Export provides a mock function with given fields: ctx, w, format, flt
+func (_m *Service) Export(ctx context.Context, w io.Writer, format exporter.Format, flt filters.Booking) error {
+	ret := _m.Called(ctx, w, format, flt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, io.Writer, exporter.Format, filters.Booking) error); ok {
+		r0 = rf(ctx, w, format, flt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ExportToCloud provides a mock function with given fields: ctx, format, flt
+func (_m *Service) ExportToCloud(ctx context.Context, format exporter.Format, flt filters.Booking) (string, error) {
+	ret := _m.Called(ctx, format, flt)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, exporter.Format, filters.Booking) (string, error)); ok {
+		return rf(ctx, format, flt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, exporter.Format, filters.Booking) string); ok {
+		r0 = rf(ctx, format, flt)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, exporter.Format, filters.Booking) error); ok {
+		r1 = rf(ctx, format, flt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetAll provides a mock function with given fields: ctx, userId, flt
+func (_m *Service) GetAll(ctx context.Context, userId uint, flt filters.Filter) ([]bookings.Booking, int, error) {
+	ret := _m.Called(ctx, userId, flt)
 
 	var r0 []bookings.Booking
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) ([]bookings.Booking, int, error)); ok {
-		return rf(ctx, flt)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, filters.Filter) ([]bookings.Booking, int, error)); ok {
+		return rf(ctx, userId, flt)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) []bookings.Booking); ok {
-		r0 = rf(ctx, flt)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, filters.Filter) []bookings.Booking); ok {
+		r0 = rf(ctx, userId, flt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]bookings.Booking)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, filters.Filter) int); ok {
-		r1 = rf(ctx, flt)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, filters.Filter) int); ok {
+		r1 = rf(ctx, userId, flt)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, filters.Filter) error); ok {
-		r2 = rf(ctx, flt)
+	if rf, ok := ret.Get(2).(func(context.Context, uint, filters.Filter) error); ok {
+		r2 = rf(ctx, userId, flt)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -117,25 +233,243 @@ func (_m *Service) GetAll(ctx context.Context, flt filters.Filter) ([]bookings.B
 	return r0, r1, r2
 }
 
-// GetDetail provides a mock function with given fields: ctx, code
-func (_m *Service) GetDetail(ctx context.Context, code int) (*bookings.Booking, error) {
-	ret := _m.Called(ctx, code)
+// GetDetail provides a mock function with given fields: ctx, userId, code
+func (_m *Service) GetDetail(ctx context.Context, userId uint, code string) (*bookings.Booking, error) {
+	ret := _m.Called(ctx, userId, code)
 
 	var r0 *bookings.Booking
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, int) (*bookings.Booking, error)); ok {
-		return rf(ctx, code)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) (*bookings.Booking, error)); ok {
+		return rf(ctx, userId, code)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, int) *bookings.Booking); ok {
-		r0 = rf(ctx, code)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) *bookings.Booking); ok {
+		r0 = rf(ctx, userId, code)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*bookings.Booking)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, int) error); ok {
-		r1 = rf(ctx, code)
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = rf(ctx, userId, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportFile provides a mock function with given fields: ctx, id
+func (_m *Service) GetExportFile(ctx context.Context, id uint) (*bookings.ExportFile, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *bookings.ExportFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*bookings.ExportFile, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *bookings.ExportFile); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.ExportFile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportFiles provides a mock function with given fields: ctx, scheduleId
+func (_m *Service) GetExportFiles(ctx context.Context, scheduleId uint) ([]bookings.ExportFile, error) {
+	ret := _m.Called(ctx, scheduleId)
+
+	var r0 []bookings.ExportFile
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]bookings.ExportFile, error)); ok {
+		return rf(ctx, scheduleId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []bookings.ExportFile); ok {
+		r0 = rf(ctx, scheduleId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.ExportFile)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, scheduleId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetExportSchedules provides a mock function with given fields: ctx
+func (_m *Service) GetExportSchedules(ctx context.Context) ([]bookings.ExportSchedule, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []bookings.ExportSchedule
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]bookings.ExportSchedule, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []bookings.ExportSchedule); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]bookings.ExportSchedule)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Invoice provides a mock function with given fields: ctx, userId, code
+func (_m *Service) Invoice(ctx context.Context, userId uint, code string) ([]byte, error) {
+	ret := _m.Called(ctx, userId, code)
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) ([]byte, error)); ok {
+		return rf(ctx, userId, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) []byte); ok {
+		r0 = rf(ctx, userId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = rf(ctx, userId, code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PaymentNotification provides a mock function with given fields: ctx, data
+func (_m *Service) PaymentNotification(ctx context.Context, data bookings.PaymentNotification) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.PaymentNotification) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RequestRefund provides a mock function with given fields: ctx, userId, code, data
+func (_m *Service) RequestRefund(ctx context.Context, userId uint, code string, data bookings.Refund) (*bookings.Refund, error) {
+	ret := _m.Called(ctx, userId, code, data)
+
+	var r0 *bookings.Refund
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, bookings.Refund) (*bookings.Refund, error)); ok {
+		return rf(ctx, userId, code, data)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string, bookings.Refund) *bookings.Refund); ok {
+		r0 = rf(ctx, userId, code, data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.Refund)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string, bookings.Refund) error); ok {
+		r1 = rf(ctx, userId, code, data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RunExportSchedules provides a mock function with given fields: ctx
+func (_m *Service) RunExportSchedules(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SendNotifications provides a mock function with given fields: ctx
+func (_m *Service) SendNotifications(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ticket provides a mock function with given fields: ctx, userId, code
+func (_m *Service) Ticket(ctx context.Context, userId uint, code string) ([]byte, error) {
+	ret := _m.Called(ctx, userId, code)
+
+	var r0 []byte
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) ([]byte, error)); ok {
+		return rf(ctx, userId, code)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) []byte); ok {
+		r0 = rf(ctx, userId, code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, string) error); ok {
+		r1 = rf(ctx, userId, code)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -143,13 +477,13 @@ func (_m *Service) GetDetail(ctx context.Context, code int) (*bookings.Booking,
 	return r0, r1
 }
 
-// UpdateBookingStatus provides a mock function with given fields: ctx, code, status
-func (_m *Service) UpdateBookingStatus(ctx context.Context, code int, status string) error {
-	ret := _m.Called(ctx, code, status)
+// UpdateBookingStatus provides a mock function with given fields: ctx, actor, code, status
+func (_m *Service) UpdateBookingStatus(ctx context.Context, actor bookings.Actor, code string, status string) error {
+	ret := _m.Called(ctx, actor, code, status)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
-		r0 = rf(ctx, code, status)
+	if rf, ok := ret.Get(0).(func(context.Context, bookings.Actor, string, string) error); ok {
+		r0 = rf(ctx, actor, code, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -158,11 +492,11 @@ func (_m *Service) UpdateBookingStatus(ctx context.Context, code int, status str
 }
 
 // UpdatePaymentStatus provides a mock function with given fields: ctx, code, paymentStatus
-func (_m *Service) UpdatePaymentStatus(ctx context.Context, code int, paymentStatus string) error {
+func (_m *Service) UpdatePaymentStatus(ctx context.Context, code string, paymentStatus string) error {
 	ret := _m.Called(ctx, code, paymentStatus)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, int, string) error); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
 		r0 = rf(ctx, code, paymentStatus)
 	} else {
 		r0 = ret.Error(0)
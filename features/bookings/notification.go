@@ -0,0 +1,63 @@
+package bookings
+
+import "time"
+
+const (
+	EventCreated   = "created"
+	EventPaid      = "paid"
+	EventExpired   = "expired"
+	EventCancelled = "cancelled"
+	EventRefunded  = "refunded"
+)
+
+const (
+	NotificationPending = "pending"
+	NotificationSent    = "sent"
+	NotificationFailed  = "failed"
+	NotificationSkipped = "skipped"
+)
+
+// Notification is an email about a booking event waiting in the outbox. It is
+// stored along with the transition that caused it, and sent afterwards, so an
+// event is never lost between the two.
+type Notification struct {
+	Id          uint
+	BookingCode string
+	Event       string
+	Status      string
+	Attempts    int
+	LastError   string
+
+	NextAttemptAt time.Time
+	SentAt        time.Time
+	CreatedAt     time.Time
+}
+
+// Event is the event customers are notified about when the transition is
+// applied, or empty when the transition isn't worth an email.
+func (t Transition) Event() string {
+	if t.From == t.To {
+		return ""
+	}
+
+	switch t.To {
+	case StatusPending:
+		if t.From == "" {
+			return EventCreated
+		}
+	case StatusApproved:
+		if t.From == StatusRefund {
+			return EventRefunded
+		}
+		return EventPaid
+	case StatusCancel:
+		if t.PaymentTo == PaymentExpire {
+			return EventExpired
+		}
+		return EventCancelled
+	case StatusRefunded:
+		return EventRefunded
+	}
+
+	return ""
+}
@@ -0,0 +1,30 @@
+package bookings_test
+
+import (
+	"testing"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTransitionEvent(t *testing.T) {
+	var testCases = []struct {
+		transition bookings.Transition
+		event      string
+	}{
+		{transition: bookings.Transition{To: "pending", PaymentTo: "pending"}, event: bookings.EventCreated},
+		{transition: bookings.Transition{From: "pending", To: "approved", PaymentFrom: "pending", PaymentTo: "settlement"}, event: bookings.EventPaid},
+		{transition: bookings.Transition{From: "cancel", To: "approved", PaymentFrom: "expire", PaymentTo: "settlement"}, event: bookings.EventPaid},
+		{transition: bookings.Transition{From: "pending", To: "cancel", PaymentFrom: "pending", PaymentTo: "expire"}, event: bookings.EventExpired},
+		{transition: bookings.Transition{From: "pending", To: "cancel", PaymentFrom: "pending", PaymentTo: "cancel"}, event: bookings.EventCancelled},
+		{transition: bookings.Transition{From: "pending", To: "cancel", PaymentFrom: "pending", PaymentTo: "pending"}, event: bookings.EventCancelled},
+		{transition: bookings.Transition{From: "approved", To: "refund", PaymentFrom: "settlement", PaymentTo: "settlement"}, event: ""},
+		{transition: bookings.Transition{From: "refund", To: "refunded", PaymentFrom: "settlement", PaymentTo: "settlement"}, event: bookings.EventRefunded},
+		{transition: bookings.Transition{From: "refund", To: "approved", PaymentFrom: "partial_refund", PaymentTo: "partial_refund"}, event: bookings.EventRefunded},
+		{transition: bookings.Transition{From: "pending", To: "pending", PaymentFrom: "pending", PaymentTo: "capture"}, event: ""},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.event, tc.transition.Event(), "%s -> %s", tc.transition.From, tc.transition.To)
+	}
+}
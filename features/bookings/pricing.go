@@ -0,0 +1,172 @@
+package bookings
+
+import (
+	"errors"
+	"math"
+	"time"
+)
+
+const (
+	PassengerAdult  = "adult"
+	PassengerChild  = "child"
+	PassengerInfant = "infant"
+)
+
+// A passenger younger than ChildAge when the tour starts travels as an
+// infant, one younger than AdultAge as a child.
+const (
+	ChildAge = 2
+	AdultAge = 12
+)
+
+// PassengerCategory is the category a passenger born on dob travels in on a
+// tour starting at start.
+func PassengerCategory(dob time.Time, start time.Time) string {
+	switch {
+	case start.Before(dob.AddDate(ChildAge, 0, 0)):
+		return PassengerInfant
+	case start.Before(dob.AddDate(AdultAge, 0, 0)):
+		return PassengerChild
+	default:
+		return PassengerAdult
+	}
+}
+
+// PriceOf is the price of a seat of t for a passenger of category, before the
+// tour discount. Children and infants pay the adult price unless t prices
+// them, and never more than it, so a departure priced lower stays fair.
+func (t Tour) PriceOf(category string) float64 {
+	var price *float64
+	switch category {
+	case PassengerChild:
+		price = t.ChildPrice
+	case PassengerInfant:
+		price = t.InfantPrice
+	}
+
+	if price == nil || *price > t.Price {
+		return t.Price
+	}
+
+	return *price
+}
+
+// Discounted is price after the tour discount of t.
+func (t Tour) Discounted(price float64) float64 {
+	return price - (float64(t.Discount) / 100 * price)
+}
+
+// PricePassengers sets the category and the price of every passenger of
+// details travelling on t, checking that the party can travel together:
+// children and infants need at least one adult along, and every infant an
+// adult's lap.
+func (t Tour) PricePassengers(details []Detail) ([]Detail, error) {
+	var result = make([]Detail, len(details))
+	var count = make(map[string]int)
+
+	for i, detail := range details {
+		if detail.DOB.After(t.Start) {
+			return nil, errors.New("validate: date of birth can't be after the tour starts")
+		}
+
+		detail.Category = PassengerCategory(detail.DOB, t.Start)
+		detail.Price = t.PriceOf(detail.Category)
+		count[detail.Category]++
+
+		result[i] = detail
+	}
+
+	if count[PassengerAdult] == 0 {
+		return nil, errors.New("unprocessable: a booking needs at least one adult passenger")
+	}
+
+	if count[PassengerInfant] > count[PassengerAdult] {
+		return nil, errors.New("unprocessable: every infant needs an adult passenger")
+	}
+
+	return result, nil
+}
+
+// TourTotal is the price of the seats of passengers on tour after its own
+// discount, before any voucher and the admin fee.
+func TourTotal(tour Tour, passengers []Detail) float64 {
+	var total float64
+	for _, passenger := range passengers {
+		total += tour.Discounted(passenger.Price)
+	}
+
+	return total
+}
+
+// PriceLine is what the passengers of a category pay for their seats.
+type PriceLine struct {
+	Category   string
+	Passengers int
+	Price      float64
+	Subtotal   float64
+}
+
+// Breakdown groups the seats of b by passenger category and price, adults
+// first, before the tour discount. Bookings made before seats were priced per
+// passenger split what their seats cost evenly.
+func (b Booking) Breakdown() []PriceLine {
+	var result []PriceLine
+	var priced = b.Priced()
+	var unpriced = b.unpricedSeat()
+
+	for _, category := range []string{PassengerAdult, PassengerChild, PassengerInfant} {
+		for _, detail := range b.Detail {
+			if detail.Category != category {
+				continue
+			}
+
+			var price = detail.Price
+			if !priced {
+				price = unpriced
+			}
+
+			var found bool
+			for i := range result {
+				if result[i].Category == category && result[i].Price == price {
+					result[i].Passengers++
+					result[i].Subtotal += price
+					found = true
+					break
+				}
+			}
+
+			if !found {
+				result = append(result, PriceLine{Category: category, Passengers: 1, Price: price, Subtotal: price})
+			}
+		}
+	}
+
+	return result
+}
+
+// Priced tells whether the seats of b were priced per passenger. An adult seat
+// is never free, so a booking whose seats are all zero priced predates it.
+func (b Booking) Priced() bool {
+	for _, detail := range b.Detail {
+		if detail.Price != 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// unpricedSeat is the price of a seat of b before the tour discount, worked
+// back from its total the way the total was made.
+func (b Booking) unpricedSeat() float64 {
+	if len(b.Detail) == 0 {
+		return 0
+	}
+
+	var seats = math.Max(b.Total+b.Discount-b.Tour.AdminFee, 0)
+	if b.Tour.Discount > 0 && b.Tour.Discount < 100 {
+		seats = seats / (1 - float64(b.Tour.Discount)/100)
+	}
+
+	return seats / float64(len(b.Detail))
+}
@@ -0,0 +1,122 @@
+package bookings_test
+
+import (
+	"testing"
+	"time"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPassengerCategory(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		name     string
+		dob      time.Time
+		category string
+	}{
+		{name: "newborn", dob: start.AddDate(0, -1, 0), category: bookings.PassengerInfant},
+		{name: "turns two on start", dob: start.AddDate(-2, 0, 0), category: bookings.PassengerChild},
+		{name: "turns two after start", dob: start.AddDate(-2, 0, 1), category: bookings.PassengerInfant},
+		{name: "turns twelve on start", dob: start.AddDate(-12, 0, 0), category: bookings.PassengerAdult},
+		{name: "turns twelve after start", dob: start.AddDate(-12, 0, 1), category: bookings.PassengerChild},
+		{name: "grown up", dob: start.AddDate(-40, 0, 0), category: bookings.PassengerAdult},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.category, bookings.PassengerCategory(tc.dob, start))
+		})
+	}
+}
+
+func TestTourPriceOf(t *testing.T) {
+	childPrice, infantPrice := float64(600000), float64(0)
+	tour := bookings.Tour{Price: 1000000, ChildPrice: &childPrice, InfantPrice: &infantPrice}
+
+	assert.Equal(t, float64(1000000), tour.PriceOf(bookings.PassengerAdult))
+	assert.Equal(t, float64(600000), tour.PriceOf(bookings.PassengerChild))
+	assert.Equal(t, float64(0), tour.PriceOf(bookings.PassengerInfant))
+
+	cheaper := tour.On(bookings.Departure{Price: 500000})
+	assert.Equal(t, float64(500000), cheaper.PriceOf(bookings.PassengerChild))
+
+	unpriced := bookings.Tour{Price: 1000000}
+	assert.Equal(t, float64(1000000), unpriced.PriceOf(bookings.PassengerChild))
+	assert.Equal(t, float64(1000000), unpriced.PriceOf(bookings.PassengerInfant))
+}
+
+func TestTourPricePassengers(t *testing.T) {
+	start := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	childPrice := float64(600000)
+	tour := bookings.Tour{Price: 1000000, ChildPrice: &childPrice, Discount: 10, Start: start}
+
+	adult := bookings.Detail{Name: "adult", DOB: start.AddDate(-30, 0, 0)}
+	child := bookings.Detail{Name: "child", DOB: start.AddDate(-6, 0, 0)}
+	infant := bookings.Detail{Name: "infant", DOB: start.AddDate(-1, 0, 0)}
+
+	t.Run("priced", func(t *testing.T) {
+		details := []bookings.Detail{adult, child, infant}
+
+		result, err := tour.PricePassengers(details)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{bookings.PassengerAdult, bookings.PassengerChild, bookings.PassengerInfant}, []string{result[0].Category, result[1].Category, result[2].Category})
+		assert.Equal(t, []float64{1000000, 600000, 1000000}, []float64{result[0].Price, result[1].Price, result[2].Price})
+		assert.Equal(t, float64(2340000), bookings.TourTotal(tour, result))
+		assert.Empty(t, details[0].Category)
+	})
+
+	t.Run("no adult", func(t *testing.T) {
+		_, err := tour.PricePassengers([]bookings.Detail{child, infant})
+
+		assert.ErrorContains(t, err, "adult")
+	})
+
+	t.Run("more infants than adults", func(t *testing.T) {
+		_, err := tour.PricePassengers([]bookings.Detail{adult, infant, infant})
+
+		assert.ErrorContains(t, err, "infant")
+	})
+
+	t.Run("born after start", func(t *testing.T) {
+		_, err := tour.PricePassengers([]bookings.Detail{adult, {DOB: start.AddDate(0, 0, 1)}})
+
+		assert.ErrorContains(t, err, "date of birth")
+	})
+}
+
+func TestBookingBreakdown(t *testing.T) {
+	booking := bookings.Booking{
+		Detail: []bookings.Detail{
+			{Category: bookings.PassengerChild, Price: 600000},
+			{Category: bookings.PassengerAdult, Price: 1000000},
+			{Category: bookings.PassengerInfant, Price: 0},
+			{Category: bookings.PassengerAdult, Price: 1000000},
+		},
+	}
+
+	assert.Equal(t, []bookings.PriceLine{
+		{Category: bookings.PassengerAdult, Passengers: 2, Price: 1000000, Subtotal: 2000000},
+		{Category: bookings.PassengerChild, Passengers: 1, Price: 600000, Subtotal: 600000},
+		{Category: bookings.PassengerInfant, Passengers: 1, Price: 0, Subtotal: 0},
+	}, booking.Breakdown())
+}
+
+func TestBookingBreakdownUnpriced(t *testing.T) {
+	booking := bookings.Booking{
+		Total:    1720000,
+		Discount: 100000,
+		Tour:     bookings.Tour{AdminFee: 20000, Discount: 10},
+		Detail: []bookings.Detail{
+			{Category: bookings.PassengerAdult},
+			{Category: bookings.PassengerAdult},
+		},
+	}
+
+	assert.False(t, booking.Priced())
+	assert.Equal(t, []bookings.PriceLine{
+		{Category: bookings.PassengerAdult, Passengers: 2, Price: 1000000, Subtotal: 2000000},
+	}, booking.Breakdown())
+}
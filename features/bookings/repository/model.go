@@ -1,17 +1,16 @@
 package repository
 
 import (
-	"fmt"
 	"reflect"
-	"strconv"
 	"time"
 	"wanderer/features/bookings"
+	"wanderer/utils/exporter"
 
 	"gorm.io/gorm"
 )
 
 type Booking struct {
-	Code      int            `gorm:"column:code; primaryKey;"`
+	Code      string         `gorm:"column:code; primaryKey; type:varchar(20);"`
 	Total     float64        `gorm:"column:total;"`
 	Status    string         `gorm:"column:status; type:enum('pending', 'cancel', 'approved', 'refund', 'refunded'); default:'pending'; index;"`
 	BookedAt  time.Time      `gorm:"autoCreateTime"`
@@ -23,24 +22,26 @@ type Booking struct {
 	TourId uint
 	Tour   Tour `gorm:"foreignKey:TourId"`
 
+	DepartureId uint      `gorm:"column:departure_id; index;"`
+	Departure   Departure `gorm:"foreignKey:DepartureId"`
+
+	VoucherId       *uint   `gorm:"column:voucher_id; index;"`
+	VoucherCode     string  `gorm:"column:voucher_code; type:varchar(50);"`
+	VoucherDiscount float64 `gorm:"column:voucher_discount; type:decimal(16,2); default:0;"`
+
 	Detail  []BookingDetail
 	Payment Payment `gorm:"embedded;embeddedPrefix:payment_"`
 }
 
-func (mod *Booking) GenerateCode() (err error) {
-	mod.Code, err = strconv.Atoi(fmt.Sprintf("%d%d%d", mod.UserId, mod.TourId, time.Now().Unix()))
-	if err != nil {
-		return err
+func (mod *Booking) FromEntity(ent bookings.Booking) {
+	if ent.Code != "" {
+		mod.Code = ent.Code
 	}
 
-	return
-}
-
-func (mod *Booking) CalcTotal(tour Tour) {
-	mod.Total = (float64(len(mod.Detail)) * tour.Price) - (float64(tour.Discount) / 100 * tour.Price * float64(len(mod.Detail))) + tour.AdminFee
-}
+	if ent.Total != 0 {
+		mod.Total = ent.Total
+	}
 
-func (mod *Booking) FromEntity(ent bookings.Booking) {
 	if ent.Tour.Id != 0 {
 		mod.TourId = ent.Tour.Id
 	}
@@ -49,6 +50,17 @@ func (mod *Booking) FromEntity(ent bookings.Booking) {
 		mod.UserId = ent.User.Id
 	}
 
+	if ent.Departure.Id != 0 {
+		mod.DepartureId = ent.Departure.Id
+	}
+
+	if ent.Voucher.Id != 0 {
+		var voucherId = ent.Voucher.Id
+		mod.VoucherId = &voucherId
+		mod.VoucherCode = ent.Voucher.Code
+		mod.VoucherDiscount = ent.Discount
+	}
+
 	for _, detail := range ent.Detail {
 		var tmpDetail = new(BookingDetail)
 		tmpDetail.FromEntity(detail)
@@ -68,7 +80,7 @@ func (mod *Booking) FromEntity(ent bookings.Booking) {
 func (mod *Booking) ToEntity() *bookings.Booking {
 	var ent = new(bookings.Booking)
 
-	if mod.Code != 0 {
+	if mod.Code != "" {
 		ent.Code = mod.Code
 	}
 
@@ -80,6 +92,12 @@ func (mod *Booking) ToEntity() *bookings.Booking {
 		ent.Status = mod.Status
 	}
 
+	if mod.VoucherId != nil {
+		ent.Voucher.Id = *mod.VoucherId
+		ent.Voucher.Code = mod.VoucherCode
+		ent.Discount = mod.VoucherDiscount
+	}
+
 	if !mod.BookedAt.IsZero() {
 		ent.BookedAt = mod.BookedAt
 	}
@@ -92,6 +110,11 @@ func (mod *Booking) ToEntity() *bookings.Booking {
 		ent.Tour = *mod.Tour.ToEntity(nil)
 	}
 
+	if mod.Departure.Id != 0 {
+		ent.Departure = mod.Departure.ToEntity()
+		ent.Tour = ent.Tour.On(ent.Departure)
+	}
+
 	if !reflect.ValueOf(mod.User).IsZero() {
 		ent.User = *mod.User.ToEntity()
 	}
@@ -116,12 +139,14 @@ type BookingDetail struct {
 	Name           string    `gorm:"column:name; type:varchar(200);"`
 	Nationality    string    `gorm:"column:nationality; type:varchar(100);"`
 	DOB            time.Time `gorm:"column:dob;"`
+	Category       string    `gorm:"column:category; type:enum('adult', 'child', 'infant'); default:'adult';"`
+	Price          float64   `gorm:"column:price; type:decimal(16,2); default:0;"`
 
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	DeletedAt gorm.DeletedAt `gorm:"index"`
 
-	BookingCode int
+	BookingCode string `gorm:"column:booking_code; type:varchar(20);"`
 }
 
 func (mod *BookingDetail) FromEntity(ent bookings.Detail) {
@@ -144,6 +169,12 @@ func (mod *BookingDetail) FromEntity(ent bookings.Detail) {
 	if !ent.DOB.IsZero() {
 		mod.DOB = ent.DOB
 	}
+
+	if ent.Category != "" {
+		mod.Category = ent.Category
+	}
+
+	mod.Price = ent.Price
 }
 
 func (mod *BookingDetail) ToEntity() bookings.Detail {
@@ -173,6 +204,9 @@ func (mod *BookingDetail) ToEntity() bookings.Detail {
 		ent.DOB = mod.DOB
 	}
 
+	ent.Category = mod.Category
+	ent.Price = mod.Price
+
 	if !mod.CreatedAt.IsZero() {
 		ent.CreatedAt = mod.CreatedAt
 	}
@@ -185,7 +219,7 @@ func (mod *BookingDetail) ToEntity() bookings.Detail {
 		ent.DeletedAt = mod.DeletedAt.Time
 	}
 
-	if mod.BookingCode != 0 {
+	if mod.BookingCode != "" {
 		ent.BookingCode = mod.BookingCode
 	}
 
@@ -200,6 +234,9 @@ type Payment struct {
 	BillCode      string `gorm:"column:bill_code; type:varchar(50);"`
 	Status        string `gorm:"column:status; type:varchar(20);"`
 
+	CancelAttempts int       `gorm:"column:cancel_attempts; default:0;"`
+	CancelRetryAt  time.Time `gorm:"column:cancel_retry_at; default:null;"`
+
 	CreatedAt time.Time `gorm:"index"`
 	ExpiredAt time.Time `gorm:"nullable"`
 	PaidAt    time.Time `gorm:"default:null;"`
@@ -278,9 +315,385 @@ func (mod *Payment) ToEntity() *bookings.Payment {
 		ent.PaidAt = mod.PaidAt
 	}
 
+	ent.CancelAttempts = mod.CancelAttempts
+	if !mod.CancelRetryAt.IsZero() {
+		ent.CancelRetryAt = mod.CancelRetryAt
+	}
+
 	return ent
 }
 
+type PaymentRejection struct {
+	Id            uint   `gorm:"column:id; primaryKey;"`
+	OrderId       string `gorm:"column:order_id; type:varchar(50); index;"`
+	TransactionId string `gorm:"column:transaction_id; type:varchar(50);"`
+	StatusCode    string `gorm:"column:status_code; type:varchar(10);"`
+	GrossAmount   string `gorm:"column:gross_amount; type:varchar(30);"`
+	Status        string `gorm:"column:transaction_status; type:varchar(20);"`
+	PaymentType   string `gorm:"column:payment_type; type:varchar(30);"`
+	FraudStatus   string `gorm:"column:fraud_status; type:varchar(20);"`
+	SignatureKey  string `gorm:"column:signature_key; type:varchar(255);"`
+	RejectReason  string `gorm:"column:reject_reason; type:varchar(255);"`
+
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (mod *PaymentRejection) FromEntity(ent bookings.PaymentNotification) {
+	mod.OrderId = ent.OrderId
+	mod.TransactionId = ent.TransactionId
+	mod.StatusCode = ent.StatusCode
+	mod.GrossAmount = ent.GrossAmount
+	mod.Status = ent.Status
+	mod.PaymentType = ent.PaymentType
+	mod.FraudStatus = ent.FraudStatus
+	mod.SignatureKey = ent.SignatureKey
+	mod.RejectReason = ent.RejectReason
+}
+
+type Refund struct {
+	Id          uint    `gorm:"column:id; primaryKey;"`
+	BookingCode string  `gorm:"column:booking_code; type:varchar(20); index;"`
+	Reason      string  `gorm:"column:reason; type:text;"`
+	Percentage  int     `gorm:"column:percentage;"`
+	Amount      float64 `gorm:"column:amount; type:decimal(16,2);"`
+	Status      string  `gorm:"column:status; type:enum('requested', 'approved', 'refunded', 'failed'); default:'requested'; index;"`
+	Note        string  `gorm:"column:note; type:text;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	Passengers []RefundPassenger `gorm:"foreignKey:RefundId"`
+	History    []RefundHistory   `gorm:"foreignKey:RefundId"`
+}
+
+func (mod *Refund) FromEntity(ent bookings.Refund) {
+	if ent.Id != 0 {
+		mod.Id = ent.Id
+	}
+
+	if ent.BookingCode != "" {
+		mod.BookingCode = ent.BookingCode
+	}
+
+	if ent.Reason != "" {
+		mod.Reason = ent.Reason
+	}
+
+	if ent.Percentage != 0 {
+		mod.Percentage = ent.Percentage
+	}
+
+	if ent.Amount != 0 {
+		mod.Amount = ent.Amount
+	}
+
+	if ent.Status != "" {
+		mod.Status = ent.Status
+	}
+
+	if ent.Note != "" {
+		mod.Note = ent.Note
+	}
+
+	for _, passenger := range ent.Passengers {
+		mod.Passengers = append(mod.Passengers, RefundPassenger{BookingDetailId: passenger})
+	}
+}
+
+func (mod *Refund) ToEntity() *bookings.Refund {
+	var ent = new(bookings.Refund)
+
+	if mod.Id != 0 {
+		ent.Id = mod.Id
+	}
+
+	if mod.BookingCode != "" {
+		ent.BookingCode = mod.BookingCode
+	}
+
+	if mod.Reason != "" {
+		ent.Reason = mod.Reason
+	}
+
+	if mod.Percentage != 0 {
+		ent.Percentage = mod.Percentage
+	}
+
+	if mod.Amount != 0 {
+		ent.Amount = mod.Amount
+	}
+
+	if mod.Status != "" {
+		ent.Status = mod.Status
+	}
+
+	if mod.Note != "" {
+		ent.Note = mod.Note
+	}
+
+	if !mod.CreatedAt.IsZero() {
+		ent.CreatedAt = mod.CreatedAt
+	}
+
+	if !mod.UpdatedAt.IsZero() {
+		ent.UpdatedAt = mod.UpdatedAt
+	}
+
+	for _, passenger := range mod.Passengers {
+		ent.Passengers = append(ent.Passengers, passenger.BookingDetailId)
+	}
+
+	for _, history := range mod.History {
+		ent.History = append(ent.History, *history.ToEntity())
+	}
+
+	return ent
+}
+
+type RefundPassenger struct {
+	Id              uint `gorm:"column:id; primaryKey;"`
+	RefundId        uint `gorm:"column:refund_id; index;"`
+	BookingDetailId uint `gorm:"column:booking_detail_id; index;"`
+}
+
+type RefundHistory struct {
+	Id       uint    `gorm:"column:id; primaryKey;"`
+	RefundId uint    `gorm:"column:refund_id; index;"`
+	Status   string  `gorm:"column:status; type:varchar(20);"`
+	Amount   float64 `gorm:"column:amount; type:decimal(16,2);"`
+	Note     string  `gorm:"column:note; type:text;"`
+
+	CreatedAt time.Time
+}
+
+func (mod *RefundHistory) ToEntity() *bookings.RefundHistory {
+	var ent = new(bookings.RefundHistory)
+
+	if mod.Id != 0 {
+		ent.Id = mod.Id
+	}
+
+	if mod.RefundId != 0 {
+		ent.RefundId = mod.RefundId
+	}
+
+	if mod.Status != "" {
+		ent.Status = mod.Status
+	}
+
+	if mod.Amount != 0 {
+		ent.Amount = mod.Amount
+	}
+
+	if mod.Note != "" {
+		ent.Note = mod.Note
+	}
+
+	if !mod.CreatedAt.IsZero() {
+		ent.CreatedAt = mod.CreatedAt
+	}
+
+	return ent
+}
+
+type BookingStatusHistory struct {
+	Id          uint   `gorm:"column:id; primaryKey;"`
+	BookingCode string `gorm:"column:booking_code; type:varchar(20); index;"`
+	FromStatus  string `gorm:"column:from_status; type:varchar(20);"`
+	ToStatus    string `gorm:"column:to_status; type:varchar(20);"`
+	PaymentFrom string `gorm:"column:payment_from; type:varchar(20);"`
+	PaymentTo   string `gorm:"column:payment_to; type:varchar(20);"`
+	ActorId     *uint  `gorm:"column:actor_id;"`
+	Source      string `gorm:"column:source; type:enum('user', 'admin', 'webhook', 'scheduler');"`
+	Note        string `gorm:"column:note; type:text;"`
+
+	CreatedAt time.Time `gorm:"index"`
+}
+
+func (mod *BookingStatusHistory) TableName() string {
+	return "booking_status_history"
+}
+
+func (mod *BookingStatusHistory) FromEntity(ent bookings.Transition) {
+	mod.BookingCode = ent.BookingCode
+	mod.FromStatus = ent.From
+	mod.ToStatus = ent.To
+	mod.PaymentFrom = ent.PaymentFrom
+	mod.PaymentTo = ent.PaymentTo
+	mod.Source = ent.Actor.Source
+	mod.Note = ent.Note
+
+	if ent.Actor.Id != 0 {
+		mod.ActorId = &ent.Actor.Id
+	}
+}
+
+func (mod *BookingStatusHistory) ToEntity() *bookings.Transition {
+	var ent = new(bookings.Transition)
+
+	ent.Id = mod.Id
+	ent.BookingCode = mod.BookingCode
+	ent.From = mod.FromStatus
+	ent.To = mod.ToStatus
+	ent.PaymentFrom = mod.PaymentFrom
+	ent.PaymentTo = mod.PaymentTo
+	ent.Actor.Source = mod.Source
+	ent.Note = mod.Note
+	ent.CreatedAt = mod.CreatedAt
+
+	if mod.ActorId != nil {
+		ent.Actor.Id = *mod.ActorId
+	}
+
+	return ent
+}
+
+type BookingNotification struct {
+	Id          uint       `gorm:"column:id; primaryKey;"`
+	BookingCode string     `gorm:"column:booking_code; type:varchar(20); index;"`
+	Event       string     `gorm:"column:event; type:varchar(20);"`
+	Status      string     `gorm:"column:status; type:enum('pending', 'sent', 'failed', 'skipped'); default:'pending'; index:idx_booking_notifications_due,priority:1;"`
+	Attempts    int        `gorm:"column:attempts;"`
+	LastError   string     `gorm:"column:last_error; type:text;"`
+	NextAttempt time.Time  `gorm:"column:next_attempt_at; index:idx_booking_notifications_due,priority:2;"`
+	SentAt      *time.Time `gorm:"column:sent_at;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (mod *BookingNotification) FromEntity(ent bookings.Notification) {
+	mod.Id = ent.Id
+	mod.BookingCode = ent.BookingCode
+	mod.Event = ent.Event
+	mod.Status = ent.Status
+	mod.Attempts = ent.Attempts
+	mod.LastError = ent.LastError
+	mod.NextAttempt = ent.NextAttemptAt
+
+	if !ent.SentAt.IsZero() {
+		mod.SentAt = &ent.SentAt
+	}
+}
+
+func (mod *BookingNotification) ToEntity() *bookings.Notification {
+	var ent = new(bookings.Notification)
+
+	ent.Id = mod.Id
+	ent.BookingCode = mod.BookingCode
+	ent.Event = mod.Event
+	ent.Status = mod.Status
+	ent.Attempts = mod.Attempts
+	ent.LastError = mod.LastError
+	ent.NextAttemptAt = mod.NextAttempt
+	ent.CreatedAt = mod.CreatedAt
+
+	if mod.SentAt != nil {
+		ent.SentAt = *mod.SentAt
+	}
+
+	return ent
+}
+
+type BookingExportSchedule struct {
+	Id            uint      `gorm:"column:id; primaryKey;"`
+	Frequency     string    `gorm:"column:frequency; type:enum('daily', 'weekly', 'monthly');"`
+	Format        string    `gorm:"column:format; type:varchar(10);"`
+	UserId        uint      `gorm:"column:user_id;"`
+	TourId        uint      `gorm:"column:tour_id;"`
+	LocationId    uint      `gorm:"column:location_id;"`
+	Status        string    `gorm:"column:status; type:varchar(20);"`
+	PaymentMethod string    `gorm:"column:payment_method; type:varchar(30);"`
+	CreatedBy     uint      `gorm:"column:created_by;"`
+	NextRunAt     time.Time `gorm:"column:next_run_at; index;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (mod *BookingExportSchedule) FromEntity(ent bookings.ExportSchedule) {
+	mod.Id = ent.Id
+	mod.Frequency = ent.Frequency
+	mod.Format = string(ent.Format)
+	mod.UserId = ent.Filter.UserId
+	mod.TourId = ent.Filter.TourId
+	mod.LocationId = ent.Filter.LocationId
+	mod.Status = ent.Filter.Status
+	mod.PaymentMethod = ent.Filter.PaymentMethod
+	mod.CreatedBy = ent.CreatedBy
+	mod.NextRunAt = ent.NextRunAt
+}
+
+func (mod *BookingExportSchedule) ToEntity() *bookings.ExportSchedule {
+	var ent = new(bookings.ExportSchedule)
+
+	ent.Id = mod.Id
+	ent.Frequency = mod.Frequency
+	ent.Format = exporter.Format(mod.Format)
+	ent.Filter.UserId = mod.UserId
+	ent.Filter.TourId = mod.TourId
+	ent.Filter.LocationId = mod.LocationId
+	ent.Filter.Status = mod.Status
+	ent.Filter.PaymentMethod = mod.PaymentMethod
+	ent.CreatedBy = mod.CreatedBy
+	ent.NextRunAt = mod.NextRunAt
+	ent.CreatedAt = mod.CreatedAt
+
+	return ent
+}
+
+type BookingExportFile struct {
+	Id          uint      `gorm:"column:id; primaryKey;"`
+	ScheduleId  uint      `gorm:"column:schedule_id; index;"`
+	Format      string    `gorm:"column:format; type:varchar(10);"`
+	Status      string    `gorm:"column:status; type:enum('ready', 'failed');"`
+	Error       string    `gorm:"column:error; type:text;"`
+	Content     []byte    `gorm:"column:content; type:longblob;"`
+	PeriodStart time.Time `gorm:"column:period_start;"`
+	PeriodEnd   time.Time `gorm:"column:period_end;"`
+
+	CreatedAt time.Time
+}
+
+func (mod *BookingExportFile) FromEntity(ent bookings.ExportFile) {
+	mod.Id = ent.Id
+	mod.ScheduleId = ent.ScheduleId
+	mod.Format = string(ent.Format)
+	mod.Status = ent.Status
+	mod.Error = ent.Error
+	mod.Content = ent.Content
+	mod.PeriodStart = ent.PeriodStart
+	mod.PeriodEnd = ent.PeriodEnd
+}
+
+func (mod *BookingExportFile) ToEntity() *bookings.ExportFile {
+	var ent = new(bookings.ExportFile)
+
+	ent.Id = mod.Id
+	ent.ScheduleId = mod.ScheduleId
+	ent.Format = exporter.Format(mod.Format)
+	ent.Status = mod.Status
+	ent.Error = mod.Error
+	ent.Content = mod.Content
+	ent.PeriodStart = mod.PeriodStart
+	ent.PeriodEnd = mod.PeriodEnd
+	ent.CreatedAt = mod.CreatedAt
+
+	return ent
+}
+
+type SeatHold struct {
+	Id          uint   `gorm:"column:id; primaryKey;"`
+	BookingCode string `gorm:"column:booking_code; type:varchar(20); uniqueIndex;"`
+	TourId      uint   `gorm:"column:tour_id; index;"`
+	DepartureId uint   `gorm:"column:departure_id; index;"`
+	Seats       int    `gorm:"column:seats;"`
+	Status      string `gorm:"column:status; type:enum('held', 'converted', 'released'); default:'held'; index;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type User struct {
 	Id    uint
 	Name  string `gorm:"column:fullname;"`
@@ -322,6 +735,8 @@ type Tour struct {
 	Title       string
 	Description string
 	Price       float64
+	ChildPrice  *float64
+	InfantPrice *float64
 	AdminFee    float64
 	Discount    int
 	Start       time.Time
@@ -329,10 +744,12 @@ type Tour struct {
 	Quota       int
 	Available   int
 	Rating      *float32
+	Status      string
 
-	Picture   []File `gorm:"many2many:tour_attachment;"`
-	Itinerary []Itinerary
-	Facility  []Facility `gorm:"many2many:tour_facility;"`
+	Picture    []File `gorm:"many2many:tour_attachment;"`
+	Itinerary  []Itinerary
+	Facility   []Facility `gorm:"many2many:tour_facility;"`
+	Departures []Departure
 
 	AirlineId uint
 	Airline   Airline
@@ -362,6 +779,17 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *bookings.Tour {
 		ent.Price = mod.Price
 	}
 
+	ent.ChildPrice = mod.ChildPrice
+	ent.InfantPrice = mod.InfantPrice
+
+	if mod.AdminFee != 0 {
+		ent.AdminFee = mod.AdminFee
+	}
+
+	if mod.Discount != 0 {
+		ent.Discount = mod.Discount
+	}
+
 	if !mod.Start.IsZero() {
 		ent.Start = mod.Start
 	}
@@ -382,10 +810,18 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *bookings.Tour {
 		ent.Rating = mod.Rating
 	}
 
+	if mod.Status != "" {
+		ent.Status = mod.Status
+	}
+
 	if !reflect.ValueOf(mod.Airline).IsZero() {
 		ent.Airline = *mod.Airline.ToEntity()
 	}
 
+	if mod.LocationId != 0 {
+		ent.Location.Id = mod.LocationId
+	}
+
 	if !reflect.ValueOf(mod.Location).IsZero() {
 		ent.Location = *mod.Location.ToEntity()
 	}
@@ -420,9 +856,39 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *bookings.Tour {
 		}
 	}
 
+	for _, departure := range mod.Departures {
+		ent.Departures = append(ent.Departures, departure.ToEntity())
+	}
+
 	return ent
 }
 
+type Departure struct {
+	Id        uint
+	TourId    uint
+	Start     time.Time `gorm:"column:start; type:timestamp;"`
+	Finish    time.Time `gorm:"column:finish; type:timestamp;"`
+	Quota     int
+	Available int
+	Price     float64 `gorm:"column:price; type:decimal(16,2);"`
+}
+
+func (mod *Departure) TableName() string {
+	return "tour_departures"
+}
+
+func (mod *Departure) ToEntity() bookings.Departure {
+	return bookings.Departure{
+		Id:        mod.Id,
+		TourId:    mod.TourId,
+		Start:     mod.Start,
+		Finish:    mod.Finish,
+		Quota:     mod.Quota,
+		Available: mod.Available,
+		Price:     mod.Price,
+	}
+}
+
 type File struct {
 	Id  int
 	Url string `gorm:"column:file;"`
@@ -534,6 +1000,7 @@ type Review struct {
 	Rating float32
 
 	CreatedAt time.Time
+	DeletedAt gorm.DeletedAt
 }
 
 func (mod *Review) ToEntity() *bookings.Review {
@@ -561,3 +1028,51 @@ func (mod *Review) ToEntity() *bookings.Review {
 
 	return ent
 }
+
+// Voucher is a promo code, as far as redeeming it goes. Vouchers are managed
+// by the vouchers feature.
+type Voucher struct {
+	Id          uint
+	Code        string
+	Type        string
+	Value       float64
+	MaxDiscount float64
+	MinSpend    float64
+	Quota       int
+	UserLimit   int
+	Stackable   bool
+	StartAt     time.Time
+	EndAt       *time.Time
+	DeletedAt   gorm.DeletedAt
+}
+
+func (mod *Voucher) ToEntity() *bookings.Voucher {
+	var ent = new(bookings.Voucher)
+
+	ent.Id = mod.Id
+	ent.Code = mod.Code
+	ent.Type = mod.Type
+	ent.Value = mod.Value
+	ent.MaxDiscount = mod.MaxDiscount
+	ent.MinSpend = mod.MinSpend
+	ent.Quota = mod.Quota
+	ent.UserLimit = mod.UserLimit
+	ent.Stackable = mod.Stackable
+	ent.StartAt = mod.StartAt
+
+	if mod.EndAt != nil {
+		ent.EndAt = *mod.EndAt
+	}
+
+	return ent
+}
+
+type VoucherTour struct {
+	VoucherId uint
+	TourId    uint
+}
+
+type VoucherLocation struct {
+	VoucherId  uint
+	LocationId uint
+}
@@ -2,35 +2,27 @@ package repository
 
 import (
 	"context"
-	"encoding/csv"
 	"errors"
-	"fmt"
 	"io"
-	"os"
-	"strconv"
+	"strings"
 	"time"
 	"wanderer/features/bookings"
 	"wanderer/helpers/filters"
 	"wanderer/utils/files"
-	"wanderer/utils/payments"
 
-	"github.com/jung-kurt/gofpdf"
-	"github.com/labstack/echo/v4"
-	"github.com/xuri/excelize/v2"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func NewBookingRepository(mysqlDB *gorm.DB, payment payments.Midtrans, cloud files.Cloud) bookings.Repository {
+func NewBookingRepository(mysqlDB *gorm.DB, cloud files.Cloud) bookings.Repository {
 	return &bookingRepository{
 		mysqlDB: mysqlDB,
-		payment: payment,
 		cloud:   cloud,
 	}
 }
 
 type bookingRepository struct {
 	mysqlDB *gorm.DB
-	payment payments.Midtrans
 	cloud   files.Cloud
 }
 
@@ -39,9 +31,34 @@ func (repo *bookingRepository) GetAll(ctx context.Context, flt filters.Filter) (
 	var totalData int64
 	var data []bookings.Booking
 
-	qry := repo.mysqlDB.WithContext(ctx).Model(&Booking{})
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).Joins("User").Joins("Tour", repo.mysqlDB.Select("title", "start", "finish").Model(&Tour{})).Joins("Departure", repo.mysqlDB.Select("id", "start", "finish", "price").Model(&Departure{}))
 
-	qry.Count(&totalData)
+	qry = filterBookings(qry, flt.Booking)
+
+	if flt.Search.Keyword != "" {
+		keyword := "%" + flt.Search.Keyword + "%"
+		qry = qry.Where("(CAST(bookings.code AS CHAR) like ? OR User.fullname like ? OR Tour.title like ?)", keyword, keyword, keyword)
+	}
+
+	if err := qry.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	dir := "asc"
+	if flt.Sort.Direction {
+		dir = "desc"
+	}
+
+	switch flt.Sort.Column {
+	case "code", "total", "status", "booked_at":
+		qry = qry.Order("bookings." + flt.Sort.Column + " " + dir)
+	case "tour":
+		qry = qry.Order("Tour.title " + dir)
+	case "user":
+		qry = qry.Order("User.fullname " + dir)
+	default:
+		qry = qry.Order("bookings.booked_at desc")
+	}
 
 	if flt.Pagination.Limit != 0 {
 		qry = qry.Limit(flt.Pagination.Limit)
@@ -51,7 +68,7 @@ func (repo *bookingRepository) GetAll(ctx context.Context, flt filters.Filter) (
 		qry = qry.Offset(flt.Pagination.Start)
 	}
 
-	if err := qry.Joins("User").Joins("Tour", repo.mysqlDB.Select("title", "start", "finish").Model(&Tour{})).Find(&mod).Error; err != nil {
+	if err := qry.Find(&mod).Error; err != nil {
 		return nil, int(totalData), err
 	}
 
@@ -63,9 +80,9 @@ func (repo *bookingRepository) GetAll(ctx context.Context, flt filters.Filter) (
 	return data, int(totalData), nil
 }
 
-func (repo *bookingRepository) GetDetail(ctx context.Context, code int) (*bookings.Booking, error) {
+func (repo *bookingRepository) GetDetail(ctx context.Context, code string) (*bookings.Booking, error) {
 	var mod = new(Booking)
-	if err := repo.mysqlDB.WithContext(ctx).Where(&Booking{Code: code}).First(mod).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Joins("User").Where(&Booking{Code: code}).First(mod).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("not found: booking not found")
 		}
@@ -116,20 +133,54 @@ func (repo *bookingRepository) GetDetail(ctx context.Context, code int) (*bookin
 	modTour.Itinerary = modItinerary
 
 	var modReviews []Review
-	if err := repo.mysqlDB.WithContext(ctx).Where("tour_id = ?", mod.TourId).Joins("User").Find(&modReviews).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Where("reviews.tour_id = ? AND reviews.status <> ?", mod.TourId, "hidden").Joins("User").Find(&modReviews).Error; err != nil {
 		return nil, err
 	}
 	modTour.Reviews = modReviews
 
 	data.Tour = *modTour.ToEntity(modFacilityExclude)
 
+	if mod.DepartureId != 0 {
+		var modDeparture = new(Departure)
+		if err := repo.mysqlDB.WithContext(ctx).Where(&Departure{Id: mod.DepartureId}).First(modDeparture).Error; err != nil {
+			return nil, err
+		}
+
+		data.Departure = modDeparture.ToEntity()
+		data.Tour = data.Tour.On(data.Departure)
+	}
+
+	var modRefunds []Refund
+	if err := repo.mysqlDB.WithContext(ctx).Where(&Refund{BookingCode: code}).Preload("Passengers").Preload("History", func(db *gorm.DB) *gorm.DB {
+		return db.Order("created_at asc, id asc")
+	}).Order("created_at asc").Find(&modRefunds).Error; err != nil {
+		return nil, err
+	}
+
+	for _, refund := range modRefunds {
+		data.Refunds = append(data.Refunds, *refund.ToEntity())
+	}
+
+	var modHistory []BookingStatusHistory
+	if err := repo.mysqlDB.WithContext(ctx).Where(&BookingStatusHistory{BookingCode: code}).Order("created_at asc, id asc").Find(&modHistory).Error; err != nil {
+		return nil, err
+	}
+
+	for _, history := range modHistory {
+		data.History = append(data.History, *history.ToEntity())
+	}
+
 	return data, nil
 }
 
 func (repo *bookingRepository) GetTourById(ctx context.Context, tourId uint) (*bookings.Tour, error) {
 	var mod = new(Tour)
 
-	if err := repo.mysqlDB.WithContext(ctx).Where(&Tour{Id: tourId}).First(mod).Error; err != nil {
+	qry := repo.mysqlDB.WithContext(ctx).Preload("Departures", func(db *gorm.DB) *gorm.DB {
+		return db.Where("deleted_at IS NULL").Order("start asc")
+	})
+
+	if err := qry.Where(&Tour{Id: tourId}).Where("deleted_at IS NULL").First(mod).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return nil, errors.New("not found: tour not found")
 		}
@@ -152,55 +203,137 @@ func (repo *bookingRepository) GetUserById(ctx context.Context, userId uint) (*b
 	return mod.ToEntity(), nil
 }
 
-func (repo *bookingRepository) Create(ctx context.Context, data bookings.Booking) (*bookings.Booking, error) {
-	var modBooking = new(Booking)
-	modBooking.FromEntity(data)
+// GetVoucher is the voucher code, with how often it has been redeemed in all
+// and by the user userId.
+func (repo *bookingRepository) GetVoucher(ctx context.Context, code string, userId uint) (*bookings.Voucher, error) {
+	var mod = new(Voucher)
 
-	var modTour = new(Tour)
-	if err := repo.mysqlDB.WithContext(ctx).Where(&Tour{Id: modBooking.TourId}).First(modTour).Error; err != nil {
+	db := repo.mysqlDB.WithContext(ctx)
+	if err := db.Where("code = ?", code).First(mod).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("not found: tour not found")
+			return nil, errors.New("not found: voucher not found")
 		}
 		return nil, err
 	}
-	modBooking.Tour = *modTour
 
-	var modUser = new(User)
-	if err := repo.mysqlDB.WithContext(ctx).Where(&User{Id: modBooking.UserId}).First(modUser).Error; err != nil {
-		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("not found: user not found")
-		}
+	var ent = mod.ToEntity()
+
+	if err := db.Model(&VoucherTour{}).Where("voucher_id = ?", mod.Id).Pluck("tour_id", &ent.TourIds).Error; err != nil {
 		return nil, err
 	}
-	modBooking.User = *modUser
 
-	modBooking.CalcTotal(*modTour)
-
-	if err := modBooking.GenerateCode(); err != nil {
+	if err := db.Model(&VoucherLocation{}).Where("voucher_id = ?", mod.Id).Pluck("location_id", &ent.LocationIds).Error; err != nil {
 		return nil, err
 	}
 
-	res, err := repo.payment.NewBookingPayment(*modBooking.ToEntity())
+	used, usedByUser, err := repo.voucherUsage(db, mod.Id, userId)
 	if err != nil {
 		return nil, err
 	}
-	var modPayment = new(Payment)
-	modPayment.FromEntity(*res)
-	modBooking.Payment = *modPayment
+	ent.Used = used
+	ent.UsedByUser = usedByUser
+
+	return ent, nil
+}
+
+// voucherUsage counts the bookings that redeemed the voucher voucherId, in all
+// and by the user userId, leaving the canceled ones out.
+func (repo *bookingRepository) voucherUsage(db *gorm.DB, voucherId uint, userId uint) (int, int, error) {
+	var used, usedByUser int64
+
+	qry := db.Model(&Booking{}).Where("voucher_id = ? AND status <> ?", voucherId, bookings.StatusCancel)
+
+	if err := qry.Session(&gorm.Session{}).Count(&used).Error; err != nil {
+		return 0, 0, err
+	}
+
+	if err := qry.Session(&gorm.Session{}).Where("user_id = ?", userId).Count(&usedByUser).Error; err != nil {
+		return 0, 0, err
+	}
+
+	return int(used), int(usedByUser), nil
+}
+
+// redeemVoucher checks again, holding the voucher's row lock, that the
+// voucher voucherId hasn't reached its usage limits. Concurrent bookings
+// redeeming the same voucher are serialized by the lock, so it is never
+// redeemed more often than allowed.
+func (repo *bookingRepository) redeemVoucher(tx *gorm.DB, voucherId uint, userId uint) error {
+	var mod = new(Voucher)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "quota", "user_limit").Where("id = ?", voucherId).First(mod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("not found: voucher not found")
+		}
+		return err
+	}
+
+	var ent = mod.ToEntity()
+
+	used, usedByUser, err := repo.voucherUsage(tx, voucherId, userId)
+	if err != nil {
+		return err
+	}
+	ent.Used = used
+	ent.UsedByUser = usedByUser
+
+	return ent.Available()
+}
+
+func (repo *bookingRepository) Create(ctx context.Context, data bookings.Booking) (*bookings.Booking, error) {
+	var modBooking = new(Booking)
+	modBooking.FromEntity(data)
+
+	err := repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		modTour, err := repo.lockTour(tx, modBooking.TourId)
+		if err != nil {
+			return err
+		}
+
+		// Checked again under the lock, the tour may have been unpublished since.
+		if modTour.Status != bookings.TourPublished {
+			return errors.New("unprocessable: tour is not open for booking")
+		}
+
+		if err := repo.holdSeats(tx, modBooking.TourId, modBooking.DepartureId, len(modBooking.Detail)); err != nil {
+			return err
+		}
+
+		if modBooking.VoucherId != nil {
+			if err := repo.redeemVoucher(tx, *modBooking.VoucherId, modBooking.UserId); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Omit("User", "Tour", "Departure").Create(modBooking).Error; err != nil {
+			if strings.Contains(err.Error(), "1062") {
+				return errors.New("used: booking code already exist")
+			}
+			return err
+		}
+
+		err = repo.recordTransition(tx, bookings.Transition{
+			BookingCode: modBooking.Code,
+			To:          bookings.StatusPending,
+			PaymentTo:   modBooking.Payment.Status,
+			Actor:       bookings.Actor{Id: modBooking.UserId, Source: bookings.SourceUser},
+		})
+		if err != nil {
+			return err
+		}
 
-	if err := repo.mysqlDB.WithContext(ctx).Omit("User", "Tour").Create(modBooking).Error; err != nil {
+		return tx.Create(&SeatHold{BookingCode: modBooking.Code, TourId: modBooking.TourId, DepartureId: modBooking.DepartureId, Seats: len(modBooking.Detail), Status: "held"}).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	modBooking.User = User{}
-	modBooking.Tour = Tour{}
 	modBooking.Status = ""
 	modBooking.Detail = nil
 
 	return modBooking.ToEntity(), nil
 }
 
-func (repo *bookingRepository) UpdateBookingStatus(ctx context.Context, code int, status string) error {
+func (repo *bookingRepository) UpdateBookingStatus(ctx context.Context, data bookings.Transition) error {
 	tx := repo.mysqlDB.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -208,31 +341,18 @@ func (repo *bookingRepository) UpdateBookingStatus(ctx context.Context, code int
 		}
 	}()
 
-	if status == "refunded" {
-		err := tx.WithContext(ctx).Transaction(func(txTour *gorm.DB) error {
-			return txTour.WithContext(ctx).
-				Model(&Tour{}).
-				Where("id = (SELECT tour_id FROM bookings where code = ? AND status = 'refund')", code).
-				Update("available", gorm.Expr("available + (SELECT COUNT(id) FROM booking_details where booking_code = ?)", code)).Error
-		})
-		if err != nil {
+	if err := repo.applyTransition(tx, data); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if data.From != data.To && data.To == bookings.StatusCancel {
+		if err := repo.releaseSeats(tx, data.BookingCode, "held", 0); err != nil {
 			tx.Rollback()
 			return err
 		}
 	}
 
-	if status == "cancel" {
-		repo.payment.CancelBookingPayment(code)
-	}
-
-	err := tx.WithContext(ctx).Transaction(func(txTour *gorm.DB) error {
-		return txTour.WithContext(ctx).Where("code = ?", code).Updates(&Booking{Status: status}).Error
-	})
-	if err != nil {
-		tx.Rollback()
-		return err
-	}
-
 	if err := tx.WithContext(ctx).Commit().Error; err != nil {
 		return err
 	}
@@ -240,7 +360,7 @@ func (repo *bookingRepository) UpdateBookingStatus(ctx context.Context, code int
 	return nil
 }
 
-func (repo *bookingRepository) UpdatePaymentStatus(ctx context.Context, code int, bookingStatus string, paymentStatus string) error {
+func (repo *bookingRepository) UpdatePaymentStatus(ctx context.Context, data bookings.Transition) error {
 	tx := repo.mysqlDB.WithContext(ctx).Begin()
 	defer func() {
 		if r := recover(); r != nil {
@@ -248,27 +368,26 @@ func (repo *bookingRepository) UpdatePaymentStatus(ctx context.Context, code int
 		}
 	}()
 
-	if bookingStatus == "approved" {
-		err := tx.WithContext(ctx).Transaction(func(txTour *gorm.DB) error {
-			return txTour.WithContext(ctx).
-				Model(&Tour{}).
-				Where("id = (SELECT tour_id FROM bookings where code = ? AND status = 'pending')", code).
-				Update("available", gorm.Expr("available - (SELECT COUNT(id) FROM booking_details where booking_code = ?)", code)).Error
-		})
-		if err != nil {
-			tx.Rollback()
-			return err
-		}
-	}
-
-	err := tx.WithContext(ctx).Transaction(func(txTour *gorm.DB) error {
-		return txTour.WithContext(ctx).Where("code = ?", code).Updates(&Booking{Status: bookingStatus, Payment: Payment{Status: paymentStatus}}).Error
-	})
-	if err != nil {
+	if err := repo.applyTransition(tx, data); err != nil {
 		tx.Rollback()
 		return err
 	}
 
+	if data.From != data.To {
+		switch data.To {
+		case bookings.StatusApproved:
+			if err := repo.convertSeats(tx, data.BookingCode); err != nil {
+				tx.Rollback()
+				return err
+			}
+		case bookings.StatusCancel:
+			if err := repo.releaseSeats(tx, data.BookingCode, "held", 0); err != nil {
+				tx.Rollback()
+				return err
+			}
+		}
+	}
+
 	if err := tx.WithContext(ctx).Commit().Error; err != nil {
 		return err
 	}
@@ -276,240 +395,584 @@ func (repo *bookingRepository) UpdatePaymentStatus(ctx context.Context, code int
 	return nil
 }
 
-func (repo *bookingRepository) ChangePaymentMethod(ctx context.Context, code int, data bookings.Booking) (*bookings.Payment, error) {
-	var newPayment = new(bookings.Payment)
-	var retries = 2
-	var complete = false
+// applyTransition moves a booking from the transition's statuses to its new
+// ones and records it in the status history. The update only matches a booking
+// still in the expected statuses, so a concurrent change makes it fail
+// instead of being overwritten.
+func (repo *bookingRepository) applyTransition(tx *gorm.DB, data bookings.Transition) error {
+	qry := tx.Model(&Booking{}).
+		Where("code = ? AND status = ? AND payment_status = ?", data.BookingCode, data.From, data.PaymentFrom).
+		Updates(map[string]any{"status": data.To, "payment_status": data.PaymentTo})
+	if err := qry.Error; err != nil {
+		return err
+	}
+
+	if qry.RowsAffected == 0 {
+		return errors.New("unprocessable: booking status has changed, please try again")
+	}
+
+	return repo.recordTransition(tx, data)
+}
+
+// recordTransition adds a transition to the status history and puts the
+// notification for its event, if any, in the outbox. A booking is only
+// reported cancelled to a customer who has been told it was made.
+func (repo *bookingRepository) recordTransition(tx *gorm.DB, data bookings.Transition) error {
+	var modHistory = new(BookingStatusHistory)
+	modHistory.FromEntity(data)
+
+	if err := tx.Create(modHistory).Error; err != nil {
+		return err
+	}
 
-	repo.payment.CancelBookingPayment(code)
-	for retries <= 2 || complete {
-		res, err := repo.payment.NewBookingPayment(data)
-		if err == nil {
-			newPayment = res
+	event := data.Event()
+	if event == "" {
+		return nil
+	}
 
-			break
-		} else if retries < 2 {
-			time.Sleep(50)
-			retries++
+	if event == bookings.EventCancelled {
+		var announced int64
+		qry := tx.Model(&BookingNotification{}).Where("booking_code = ? AND event = ? AND status = ?", data.BookingCode, bookings.EventCreated, bookings.NotificationSent)
+		if err := qry.Count(&announced).Error; err != nil {
+			return err
+		}
 
-			continue
+		if announced == 0 {
+			return nil
 		}
+	}
 
+	return tx.Create(&BookingNotification{
+		BookingCode: data.BookingCode,
+		Event:       event,
+		Status:      bookings.NotificationPending,
+		NextAttempt: time.Now(),
+	}).Error
+}
+
+func (repo *bookingRepository) GetDueNotifications(ctx context.Context, before time.Time, limit int) ([]bookings.Notification, error) {
+	var mod []BookingNotification
+
+	qry := repo.mysqlDB.WithContext(ctx).
+		Where("status = ? AND next_attempt_at <= ?", bookings.NotificationPending, before).
+		Order("next_attempt_at asc, id asc").
+		Limit(limit)
+
+	if err := qry.Find(&mod).Error; err != nil {
 		return nil, err
 	}
 
-	var modPayment = new(Payment)
-	modPayment.FromEntity(*newPayment)
-	if err := repo.mysqlDB.WithContext(ctx).Where(Booking{Code: code}).Updates(&Booking{Payment: *modPayment}).Error; err != nil {
+	var result []bookings.Notification
+	for _, notification := range mod {
+		result = append(result, *notification.ToEntity())
+	}
+
+	return result, nil
+}
+
+// ClaimNotification moves a due notification's next attempt to until, so no
+// other instance picks it up while it is being sent. It reports false when
+// someone else claimed it first. A claim left by a process that died is simply
+// retried once until has passed.
+func (repo *bookingRepository) ClaimNotification(ctx context.Context, data bookings.Notification, until time.Time) (bool, error) {
+	qry := repo.mysqlDB.WithContext(ctx).Model(&BookingNotification{}).
+		Where("id = ? AND status = ? AND next_attempt_at = ?", data.Id, bookings.NotificationPending, data.NextAttemptAt).
+		Update("next_attempt_at", until)
+	if qry.Error != nil {
+		return false, qry.Error
+	}
+
+	return qry.RowsAffected != 0, nil
+}
+
+func (repo *bookingRepository) UpdateNotification(ctx context.Context, data bookings.Notification) error {
+	var mod = new(BookingNotification)
+	mod.FromEntity(data)
+
+	return repo.mysqlDB.WithContext(ctx).Model(&BookingNotification{}).Where("id = ?", data.Id).Updates(map[string]any{
+		"status":          mod.Status,
+		"attempts":        mod.Attempts,
+		"last_error":      mod.LastError,
+		"next_attempt_at": mod.NextAttempt,
+		"sent_at":         mod.SentAt,
+	}).Error
+}
+
+func (repo *bookingRepository) CreateRefund(ctx context.Context, data bookings.Refund, transition bookings.Transition) (*bookings.Refund, error) {
+	var modRefund = new(Refund)
+	modRefund.FromEntity(data)
+	modRefund.Status = bookings.RefundRequested
+	modRefund.History = []RefundHistory{{Status: bookings.RefundRequested, Amount: modRefund.Amount, Note: modRefund.Reason}}
+
+	err := repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := repo.applyTransition(tx, transition); err != nil {
+			return err
+		}
+
+		return tx.Create(modRefund).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return newPayment, nil
+	return modRefund.ToEntity(), nil
 }
 
-func (repo *bookingRepository) Export() ([]bookings.Booking, error) {
+func (repo *bookingRepository) UpdateRefundStatus(ctx context.Context, refundId uint, status string, amount float64, note string) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&Refund{Id: refundId}).Updates(map[string]any{"status": status, "amount": amount, "note": note}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&RefundHistory{RefundId: refundId, Status: status, Amount: amount, Note: note}).Error
+	})
+}
+
+// CompleteRefund records a refund the gateway has paid out, applies the
+// booking's move out of refund and gives the refunded passengers' seats back
+// to the tour. Only an approved refund is completed, and completing one that
+// already is changes nothing, so a payout can safely be completed again. The
+// payment status is kept as it is now, since the gateway may have reported
+// the refund in the meantime.
+func (repo *bookingRepository) CompleteRefund(ctx context.Context, data bookings.Refund, transition bookings.Transition) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var modBooking = new(Booking)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("payment_status").Where("code = ?", data.BookingCode).First(modBooking).Error; err != nil {
+			return err
+		}
+
+		qry := tx.Model(&Refund{}).
+			Where("id = ? AND status = ?", data.Id, bookings.RefundApproved).
+			Updates(map[string]any{"status": bookings.RefundRefunded, "amount": data.Amount, "note": data.Note})
+		if err := qry.Error; err != nil {
+			return err
+		}
+
+		if qry.RowsAffected == 0 {
+			var modRefund = new(Refund)
+			if err := tx.Select("status").Where("id = ?", data.Id).First(modRefund).Error; err != nil {
+				return err
+			}
+
+			if modRefund.Status == bookings.RefundRefunded {
+				return nil
+			}
+
+			return errors.New("unprocessable: refund status has changed, please try again")
+		}
+
+		transition.PaymentFrom = modBooking.Payment.Status
+		transition.PaymentTo = modBooking.Payment.Status
+		if err := repo.applyTransition(tx, transition); err != nil {
+			return err
+		}
+
+		if err := tx.Create(&RefundHistory{RefundId: data.Id, Status: bookings.RefundRefunded, Amount: data.Amount, Note: data.Note}).Error; err != nil {
+			return err
+		}
+
+		return repo.releaseSeats(tx, data.BookingCode, "converted", len(data.Passengers))
+	})
+}
+
+// GetExpiredPending lists the pending bookings whose payment expired before
+// the given time. Bookings whose gateway cancel failed are left out until
+// their retry is due, and for good after the given number of attempts, so
+// they can't hold up the rest.
+func (repo *bookingRepository) GetExpiredPending(ctx context.Context, before time.Time, attempts int, limit int) ([]bookings.Booking, error) {
 	var mod []Booking
-	var data []bookings.Booking
 
-	qry := repo.mysqlDB.Model(&Booking{})
+	qry := repo.mysqlDB.WithContext(ctx).
+		Select("code", "status", "payment_status", "payment_expired_at", "payment_cancel_attempts", "payment_cancel_retry_at").
+		Where("status = ? AND payment_status = ? AND payment_expired_at < ?", bookings.StatusPending, bookings.PaymentPending, before).
+		Where("payment_cancel_attempts < ?", attempts).
+		Where("payment_cancel_retry_at IS NULL OR payment_cancel_retry_at < ?", before).
+		Order("payment_expired_at asc").
+		Limit(limit)
 
-	if err := qry.Joins("User").Joins("Tour", repo.mysqlDB.Select("title", "start", "finish").Model(&Tour{})).Find(&mod).Error; err != nil {
+	if err := qry.Find(&mod).Error; err != nil {
 		return nil, err
 	}
 
+	var result []bookings.Booking
 	for _, booking := range mod {
-		booking.Payment = Payment{}
-		data = append(data, *booking.ToEntity())
+		result = append(result, *booking.ToEntity())
 	}
 
-	return data, nil
+	return result, nil
 }
 
-func (repo *bookingRepository) ExportFileCsv(c echo.Context, data []bookings.Booking) error {
-	path := "transaction-list.csv"
+// ExpireBooking cancels a pending booking whose payment expired before the
+// given time. The booking row is locked with SKIP LOCKED, so when several
+// instances race on the same booking only one of them moves it and the
+// others report false.
+func (repo *bookingRepository) ExpireBooking(ctx context.Context, code string, before time.Time) (bool, error) {
+	var expired bool
+
+	err := repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var modBooking = new(Booking)
+		qry := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Select("code").
+			Where("code = ? AND status = ? AND payment_status = ? AND payment_expired_at < ?", code, bookings.StatusPending, bookings.PaymentPending, before).
+			Limit(1).
+			Find(modBooking)
+		if err := qry.Error; err != nil {
+			return err
+		}
 
-	file, err := os.Create(path)
+		if qry.RowsAffected == 0 {
+			return nil
+		}
+
+		if err := repo.releaseSeats(tx, code, "held", 0); err != nil {
+			return err
+		}
+
+		err := repo.applyTransition(tx, bookings.Transition{
+			BookingCode: code,
+			From:        bookings.StatusPending,
+			To:          bookings.StatusCancel,
+			PaymentFrom: bookings.PaymentPending,
+			PaymentTo:   bookings.PaymentExpire,
+			Actor:       bookings.Actor{Source: bookings.SourceScheduler},
+			Note:        "payment expired",
+		})
+		if err != nil {
+			return err
+		}
+
+		expired = true
+		return nil
+	})
 	if err != nil {
+		return false, err
+	}
+
+	return expired, nil
+}
+
+// PostponeExpiry records a failed attempt to cancel the expired payment of a
+// pending booking and when to try again.
+func (repo *bookingRepository) PostponeExpiry(ctx context.Context, code string, attempts int, retryAt time.Time) error {
+	return repo.mysqlDB.WithContext(ctx).Model(&Booking{}).
+		Where("code = ? AND status = ?", code, bookings.StatusPending).
+		Updates(map[string]any{"payment_cancel_attempts": attempts, "payment_cancel_retry_at": retryAt}).Error
+}
+
+// lockTour holds the row lock of a tour. Seats and departures of the tour
+// only change under this lock, so concurrent bookings are serialized.
+func (repo *bookingRepository) lockTour(tx *gorm.DB, tourId uint) (*Tour, error) {
+	var modTour = new(Tour)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "status").Where(&Tour{Id: tourId}).Where("deleted_at IS NULL").First(modTour).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found: tour not found")
+		}
+		return nil, err
+	}
+
+	return modTour, nil
+}
+
+// holdSeats takes seats off a departure and its tour while holding the tour's
+// row lock, so available never drops below zero.
+func (repo *bookingRepository) holdSeats(tx *gorm.DB, tourId uint, departureId uint, seats int) error {
+	if _, err := repo.lockTour(tx, tourId); err != nil {
+		return err
+	}
+
+	var modDeparture = new(Departure)
+	if err := tx.Select("id", "available").Where(&Departure{Id: departureId, TourId: tourId}).Where("deleted_at IS NULL").First(modDeparture).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("not found: departure not found")
+		}
 		return err
 	}
-	defer file.Close()
 
-	writer := csv.NewWriter(file)
-	defer writer.Flush()
+	if modDeparture.Available < seats {
+		return errors.New("unprocessable: not enough seats available")
+	}
 
-	headers := []string{"Booking Code", "Name", "Tour Package", "Duration", "Price", "Status"}
-	err = writer.Write(headers)
-	if err != nil {
+	qry := tx.Model(&Departure{}).Where("id = ? AND available >= ?", departureId, seats).Update("available", gorm.Expr("available - ?", seats))
+	if err := qry.Error; err != nil {
 		return err
 	}
 
-	for _, booking := range data {
-		duration := booking.Tour.Finish.Sub(booking.Tour.Start).Hours() / 24
+	if qry.RowsAffected == 0 {
+		return errors.New("unprocessable: not enough seats available")
+	}
 
-		row := []string{
-			strconv.FormatInt(int64(booking.Code), 10),
-			booking.User.Name,
-			booking.Tour.Title,
-			strconv.FormatInt(int64(duration), 10),
-			strconv.FormatInt(int64(booking.Total), 10),
-			booking.Status,
-		}
-		err := writer.Write(row)
-		if err != nil {
-			return err
+	return tx.Model(&Tour{}).Where("id = ?", tourId).Update("available", gorm.Expr("available - ?", seats)).Error
+}
+
+// releaseSeats gives seats of a hold back to its departure, all of them when
+// seats is zero. Releasing a hold that isn't in the expected status is a no-op,
+// so repeated cancel or expire events are safe.
+func (repo *bookingRepository) releaseSeats(tx *gorm.DB, code string, status string, seats int) error {
+	var modHold = new(SeatHold)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&SeatHold{BookingCode: code, Status: status}).First(modHold).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
 		}
+		return err
+	}
+
+	if seats <= 0 || seats > modHold.Seats {
+		seats = modHold.Seats
 	}
 
-	writer.Flush()
-	if err := writer.Error(); err != nil {
+	// The tour row goes first, it is the lock holdSeats takes.
+	if err := tx.Model(&Tour{}).Where("id = ?", modHold.TourId).Update("available", gorm.Expr("available + ?", seats)).Error; err != nil {
 		return err
 	}
 
-	_, err = file.Seek(0, 0)
-	if err != nil {
+	if err := tx.Model(&Departure{}).Where("id = ?", modHold.DepartureId).Update("available", gorm.Expr("available + ?", seats)).Error; err != nil {
 		return err
 	}
 
-	_, err = repo.cloud.Upload(context.Background(), "csv-folder", file)
-	if err != nil {
+	if seats < modHold.Seats {
+		return tx.Model(modHold).Update("seats", modHold.Seats-seats).Error
+	}
+
+	return tx.Model(modHold).Update("status", "released").Error
+}
+
+// convertSeats turns a held reservation into a sold one. A settlement that
+// arrives after its hold was released has to win the seats back first.
+func (repo *bookingRepository) convertSeats(tx *gorm.DB, code string) error {
+	var modHold = new(SeatHold)
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&SeatHold{BookingCode: code}).First(modHold).Error
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
 		return err
 	}
 
-	c.Response().Header().Set(echo.HeaderContentType, "application/csv")
-	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=transaction-list.csv")
+	switch {
+	case modHold.Status == "converted":
+		return nil
+	case modHold.Status == "held":
+		return tx.Model(modHold).Update("status", "converted").Error
+	case modHold.Id == 0:
+		var modBooking = new(Booking)
+		if err := tx.Preload("Detail").Where(&Booking{Code: code}).First(modBooking).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("not found: booking not found")
+			}
+			return err
+		}
 
-	file, err = os.Open(path)
-	if err != nil {
+		modHold.BookingCode = code
+		modHold.TourId = modBooking.TourId
+		modHold.DepartureId = modBooking.DepartureId
+		modHold.Seats = len(modBooking.Detail)
+	}
+
+	if err := repo.holdSeats(tx, modHold.TourId, modHold.DepartureId, modHold.Seats); err != nil {
 		return err
 	}
-	defer file.Close()
 
-	_, err = io.Copy(c.Response().Writer, file)
-	if err != nil {
+	modHold.Status = "converted"
+	return tx.Save(modHold).Error
+}
+
+func (repo *bookingRepository) ChangePaymentMethod(ctx context.Context, code string, data bookings.Payment) error {
+	var modPayment = new(Payment)
+	modPayment.FromEntity(data)
+
+	if err := repo.mysqlDB.WithContext(ctx).Where(Booking{Code: code}).Updates(&Booking{Payment: *modPayment}).Error; err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (repo *bookingRepository) ExportFileExcel(c echo.Context, data []bookings.Booking) error {
-	path := "transaction-list.xlsx"
+func (repo *bookingRepository) CreatePaymentRejection(ctx context.Context, data bookings.PaymentNotification) error {
+	var mod = new(PaymentRejection)
+	mod.FromEntity(data)
 
-	file, err := os.Create(path)
-	if err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Create(mod).Error; err != nil {
 		return err
 	}
-	defer file.Close()
 
-	xlsx := excelize.NewFile()
+	return nil
+}
 
-	sheetName := "Sheet1"
-	xlsx.NewSheet(sheetName)
+// filterBookings narrows qry, which has to join Tour, down to the bookings
+// matching flt. The payment method matches either the payment type or the
+// bank paid through.
+func filterBookings(qry *gorm.DB, flt filters.Booking) *gorm.DB {
+	if flt.UserId != 0 {
+		qry = qry.Where("bookings.user_id = ?", flt.UserId)
+	}
 
-	style, err := xlsx.NewStyle(&excelize.Style{
-		Fill: excelize.Fill{Type: "pattern", Color: []string{"#ffc430"}, Pattern: 1},
-	})
-	err = xlsx.SetCellStyle("Sheet1", "A1", "F1", style)
+	if flt.TourId != 0 {
+		qry = qry.Where("bookings.tour_id = ?", flt.TourId)
+	}
 
-	headers := []string{"Booking Code", "Name", "Tour Package", "Duration", "Price", "Status"}
-	for col, header := range headers {
-		cell := fmt.Sprintf("%c1", 'A'+col)
-		xlsx.SetCellValue(sheetName, cell, header)
+	if flt.LocationId != 0 {
+		qry = qry.Where("Tour.location_id = ?", flt.LocationId)
 	}
 
-	for row, booking := range data {
-		duration := booking.Tour.Finish.Sub(booking.Tour.Start).Hours() / 24
+	if flt.Status != "" {
+		qry = qry.Where("bookings.status = ?", flt.Status)
+	}
 
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("A%d", row+2), strconv.FormatInt(int64(booking.Code), 10))
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("B%d", row+2), booking.User.Name)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("C%d", row+2), booking.Tour.Title)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("D%d", row+2), strconv.FormatInt(int64(duration), 10))
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("E%d", row+2), booking.Total)
-		xlsx.SetCellValue(sheetName, fmt.Sprintf("F%d", row+2), booking.Status)
+	if flt.PaymentMethod != "" {
+		qry = qry.Where("(bookings.payment_method = ? OR bookings.payment_bank = ?)", flt.PaymentMethod, flt.PaymentMethod)
 	}
 
-	err = xlsx.SaveAs(path)
-	if err != nil {
-		panic(err)
+	if !flt.BookedStart.IsZero() {
+		qry = qry.Where("bookings.booked_at >= ?", flt.BookedStart)
 	}
 
-	_, err = repo.cloud.Upload(context.Background(), "excel-folder", file)
-	if err != nil {
-		return err
+	if !flt.BookedEnd.IsZero() {
+		qry = qry.Where("bookings.booked_at <= ?", flt.BookedEnd)
 	}
 
-	c.Response().Header().Set(echo.HeaderContentType, "application/xlsx")
-	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=transaction-list.xlsx")
+	return qry
+}
 
-	file, err = os.Open(path)
-	if err != nil {
-		return err
+func (repo *bookingRepository) Export(ctx context.Context, flt filters.Booking) ([]bookings.Booking, error) {
+	var mod []Booking
+	var data []bookings.Booking
+
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).
+		Joins("User").
+		Joins("Tour", repo.mysqlDB.Select("title", "price", "admin_fee", "discount", "start", "finish", "location_id").Model(&Tour{})).
+		Joins("Tour.Location").
+		Joins("Departure", repo.mysqlDB.Select("id", "start", "finish", "price").Model(&Departure{})).
+		Preload("Detail")
+
+	if err := filterBookings(qry, flt).Order("bookings.booked_at asc").Find(&mod).Error; err != nil {
+		return nil, err
 	}
-	defer file.Close()
 
-	_, err = io.Copy(c.Response().Writer, file)
+	for _, booking := range mod {
+		data = append(data, *booking.ToEntity())
+	}
+
+	return data, nil
+}
+
+func (repo *bookingRepository) UploadExport(ctx context.Context, file io.Reader) (string, error) {
+	url, err := repo.cloud.Upload(ctx, "exports", file)
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return nil
+	return *url, nil
 }
 
-func (repo *bookingRepository) ExportFilePDF(c echo.Context, data []bookings.Booking) error {
-	path := "transaction-list.pdf"
+func (repo *bookingRepository) CreateExportSchedule(ctx context.Context, data bookings.ExportSchedule) (*bookings.ExportSchedule, error) {
+	var mod = new(BookingExportSchedule)
+	mod.FromEntity(data)
 
-	file, err := os.Create(path)
-	if err != nil {
-		return err
+	if err := repo.mysqlDB.WithContext(ctx).Create(mod).Error; err != nil {
+		return nil, err
 	}
-	defer file.Close()
 
-	pdf := gofpdf.New("P", "mm", "A4", "")
-	pdf.AddPage()
-	pdf.SetFont("Arial", "", 14)
-	pdf.SetFontSize(11)
-	pdf.SetFillColor(255, 196, 48)
+	return mod.ToEntity(), nil
+}
 
-	headers := []string{"Booking Code", "Name", "Tour Package", "Duration", "Price", "Status"}
-	for _, header := range headers {
-		pdf.CellFormat(30, 10, header, "1", 0, "C", true, 0, "")
+func (repo *bookingRepository) GetExportSchedules(ctx context.Context) ([]bookings.ExportSchedule, error) {
+	var mod []BookingExportSchedule
+	if err := repo.mysqlDB.WithContext(ctx).Order("id asc").Find(&mod).Error; err != nil {
+		return nil, err
 	}
 
-	for _, booking := range data {
-		duration := booking.Tour.Finish.Sub(booking.Tour.Start).Hours() / 24
+	var result []bookings.ExportSchedule
+	for _, schedule := range mod {
+		result = append(result, *schedule.ToEntity())
+	}
 
-		pdf.Ln(-1)
-		pdf.CellFormat(30, 10, strconv.FormatInt(int64(booking.Code), 10), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(30, 10, booking.User.Name, "1", 0, "C", false, 0, "")
-		pdf.CellFormat(30, 10, booking.Tour.Title, "1", 0, "C", false, 0, "")
-		pdf.CellFormat(30, 10, strconv.FormatInt(int64(duration), 10), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(30, 10, strconv.FormatInt(int64(booking.Total), 10), "1", 0, "C", false, 0, "")
-		pdf.CellFormat(30, 10, booking.Status, "1", 0, "C", false, 0, "")
+	return result, nil
+}
+
+func (repo *bookingRepository) DeleteExportSchedule(ctx context.Context, id uint) error {
+	qry := repo.mysqlDB.WithContext(ctx).Delete(&BookingExportSchedule{}, id)
+	if qry.Error != nil {
+		return qry.Error
 	}
 
-	err = pdf.OutputFileAndClose(path)
-	if err != nil {
-		panic(err)
+	if qry.RowsAffected == 0 {
+		return errors.New("not found: export schedule not found")
 	}
 
-	_, err = repo.cloud.Upload(context.Background(), "pdf-folder", file)
-	if err != nil {
-		return err
+	return nil
+}
+
+func (repo *bookingRepository) GetDueExportSchedules(ctx context.Context, before time.Time, limit int) ([]bookings.ExportSchedule, error) {
+	var mod []BookingExportSchedule
+
+	qry := repo.mysqlDB.WithContext(ctx).
+		Where("next_run_at <= ?", before).
+		Order("next_run_at asc, id asc").
+		Limit(limit)
+
+	if err := qry.Find(&mod).Error; err != nil {
+		return nil, err
 	}
 
-	c.Response().Header().Set(echo.HeaderContentType, "application/pdf")
-	c.Response().Header().Set(echo.HeaderContentDisposition, "attachment; filename=transaction-list.pdf")
+	var result []bookings.ExportSchedule
+	for _, schedule := range mod {
+		result = append(result, *schedule.ToEntity())
+	}
 
-	file, err = os.Open(path)
-	if err != nil {
-		return err
+	return result, nil
+}
+
+// ClaimExportSchedule moves a due schedule on to its next run, and reports
+// false when another instance already did, so every run is exported once.
+func (repo *bookingRepository) ClaimExportSchedule(ctx context.Context, data bookings.ExportSchedule, next time.Time) (bool, error) {
+	qry := repo.mysqlDB.WithContext(ctx).Model(&BookingExportSchedule{}).
+		Where("id = ? AND next_run_at = ?", data.Id, data.NextRunAt).
+		Update("next_run_at", next)
+	if qry.Error != nil {
+		return false, qry.Error
 	}
-	defer file.Close()
 
-	_, err = io.Copy(c.Response().Writer, file)
-	if err != nil {
-		return err
+	return qry.RowsAffected != 0, nil
+}
+
+func (repo *bookingRepository) CreateExportFile(ctx context.Context, data bookings.ExportFile) (*bookings.ExportFile, error) {
+	var mod = new(BookingExportFile)
+	mod.FromEntity(data)
+
+	if err := repo.mysqlDB.WithContext(ctx).Create(mod).Error; err != nil {
+		return nil, err
 	}
 
-	return nil
+	return mod.ToEntity(), nil
+}
+
+// GetExportFiles lists the exports of a schedule, or of every schedule when
+// scheduleId is zero, newest first and without their content.
+func (repo *bookingRepository) GetExportFiles(ctx context.Context, scheduleId uint) ([]bookings.ExportFile, error) {
+	var mod []BookingExportFile
+
+	qry := repo.mysqlDB.WithContext(ctx).Omit("content")
+	if scheduleId != 0 {
+		qry = qry.Where("schedule_id = ?", scheduleId)
+	}
+
+	if err := qry.Order("id desc").Find(&mod).Error; err != nil {
+		return nil, err
+	}
+
+	var result []bookings.ExportFile
+	for _, file := range mod {
+		result = append(result, *file.ToEntity())
+	}
+
+	return result, nil
+}
+
+func (repo *bookingRepository) GetExportFile(ctx context.Context, id uint) (*bookings.ExportFile, error) {
+	var mod = new(BookingExportFile)
+	if err := repo.mysqlDB.WithContext(ctx).First(mod, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found: export not found")
+		}
+		return nil, err
+	}
+
+	return mod.ToEntity(), nil
 }
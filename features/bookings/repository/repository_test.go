@@ -0,0 +1,470 @@
+package repository_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"wanderer/features/bookings"
+	"wanderer/helpers/filters"
+	"wanderer/utils/database"
+
+	ar "wanderer/features/airlines/repository"
+	br "wanderer/features/bookings/repository"
+	lr "wanderer/features/locations/repository"
+	tr "wanderer/features/tours/repository"
+	ur "wanderer/features/users/repository"
+
+	"github.com/stretchr/testify/assert"
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// newTestDB connects to the database in MYSQL_TEST_DSN. Seat reservation
+// relies on row locks, so it can only be exercised against a real MySQL.
+func newTestDB(t *testing.T) *gorm.DB {
+	dsn := os.Getenv("MYSQL_TEST_DSN")
+	if dsn == "" {
+		t.Skip("MYSQL_TEST_DSN is not set")
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := database.MysqlMigrate(db); err != nil {
+		t.Fatal(err)
+	}
+
+	return db
+}
+
+func newCode(t *testing.T) string {
+	code, err := bookings.NewCode()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return code
+}
+
+func TestBookingRepositorySeatReservation(t *testing.T) {
+	db := newTestDB(t)
+	repo := br.NewBookingRepository(db, nil)
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	user := &ur.User{Name: "maman", Email: fmt.Sprintf("maman%d@example.com", suffix), Password: "secret", Role: "user"}
+	airline := &ar.Airline{Name: fmt.Sprintf("airline %d", suffix)}
+	location := &lr.Location{Name: fmt.Sprintf("location %d", suffix)}
+	for _, mod := range []any{user, airline, location} {
+		if err := db.Create(mod).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	const quota = 5
+	tour := &tr.Tour{
+		Title:      "limited tour",
+		Price:      10000,
+		Start:      time.Now().Add(24 * time.Hour),
+		Finish:     time.Now().Add(48 * time.Hour),
+		Quota:      quota,
+		Available:  quota,
+		AirlineId:  airline.Id,
+		LocationId: location.Id,
+		Departures: []tr.Departure{
+			{Start: time.Now().Add(24 * time.Hour), Finish: time.Now().Add(48 * time.Hour), Quota: quota, Available: quota},
+		},
+	}
+	if err := db.Create(tour).Error; err != nil {
+		t.Fatal(err)
+	}
+	departure := bookings.Departure{Id: tour.Departures[0].Id}
+
+	available := func() int {
+		var mod = new(tr.Tour)
+		if err := db.Select("available").Where("id = ?", tour.Id).First(mod).Error; err != nil {
+			t.Fatal(err)
+		}
+
+		var modDeparture = new(tr.Departure)
+		if err := db.Select("available").Where("id = ?", departure.Id).First(modDeparture).Error; err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, mod.Available, modDeparture.Available)
+
+		return mod.Available
+	}
+
+	const attempts = 30
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		created []string
+		errs    []error
+	)
+
+	for i := 0; i < attempts; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			booking := bookings.Booking{
+				Code:      newCode(t),
+				Total:     10000,
+				User:      bookings.User{Id: user.Id},
+				Tour:      bookings.Tour{Id: tour.Id},
+				Departure: departure,
+				Detail: []bookings.Detail{
+					{DocumentNumber: fmt.Sprint(i), Greeting: "mr", Name: "maman", Nationality: "indonesia", DOB: time.Now()},
+				},
+			}
+
+			_, err := repo.Create(ctx, booking)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				errs = append(errs, err)
+				return
+			}
+			created = append(created, booking.Code)
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Len(t, created, quota)
+	assert.Len(t, errs, attempts-quota)
+	for _, err := range errs {
+		assert.True(t, strings.Contains(err.Error(), "not enough seats"), err.Error())
+	}
+	assert.Equal(t, 0, available())
+
+	transit := func(code string, from, to, paymentFrom, paymentTo string) bookings.Transition {
+		return bookings.Transition{
+			BookingCode: code,
+			From:        from,
+			To:          to,
+			PaymentFrom: paymentFrom,
+			PaymentTo:   paymentTo,
+			Actor:       bookings.Actor{Source: bookings.SourceWebhook},
+		}
+	}
+
+	t.Run("release on cancel is idempotent", func(t *testing.T) {
+		assert.NoError(t, repo.UpdateBookingStatus(ctx, transit(created[0], "pending", "cancel", "", "")))
+		assert.NoError(t, repo.UpdatePaymentStatus(ctx, transit(created[0], "cancel", "cancel", "", "expire")))
+		assert.Equal(t, 1, available())
+	})
+
+	t.Run("settlement converts the hold", func(t *testing.T) {
+		assert.NoError(t, repo.UpdatePaymentStatus(ctx, transit(created[1], "pending", "approved", "", "settlement")))
+		assert.ErrorContains(t, repo.UpdatePaymentStatus(ctx, transit(created[1], "pending", "approved", "", "settlement")), "status has changed")
+		assert.Equal(t, 1, available())
+	})
+
+	t.Run("late settlement takes the seat back", func(t *testing.T) {
+		assert.NoError(t, repo.UpdatePaymentStatus(ctx, transit(created[0], "cancel", "approved", "expire", "settlement")))
+		assert.Equal(t, 0, available())
+	})
+
+	t.Run("late settlement without seats", func(t *testing.T) {
+		assert.NoError(t, repo.UpdatePaymentStatus(ctx, transit(created[2], "pending", "cancel", "", "expire")))
+		assert.NoError(t, repo.UpdatePaymentStatus(ctx, transit(created[3], "pending", "cancel", "", "cancel")))
+
+		_, err := repo.Create(ctx, bookings.Booking{
+			Code:      newCode(t),
+			Total:     20000,
+			User:      bookings.User{Id: user.Id},
+			Tour:      bookings.Tour{Id: tour.Id},
+			Departure: departure,
+			Detail:    []bookings.Detail{{DocumentNumber: "a"}, {DocumentNumber: "b"}},
+		})
+		assert.NoError(t, err)
+
+		err = repo.UpdatePaymentStatus(ctx, transit(created[2], "cancel", "approved", "expire", "settlement"))
+		assert.ErrorContains(t, err, "not enough seats")
+		assert.Equal(t, 0, available())
+	})
+
+	t.Run("transitions are recorded", func(t *testing.T) {
+		booking, err := repo.GetDetail(ctx, created[0])
+		assert.NoError(t, err)
+		if assert.Len(t, booking.History, 4) {
+			assert.Equal(t, "", booking.History[0].From)
+			assert.Equal(t, "pending", booking.History[0].To)
+			assert.Equal(t, "approved", booking.History[3].To)
+			assert.Equal(t, bookings.SourceWebhook, booking.History[3].Actor.Source)
+		}
+	})
+}
+
+func TestBookingRepositoryExpireBooking(t *testing.T) {
+	db := newTestDB(t)
+	repo := br.NewBookingRepository(db, nil)
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	user := &ur.User{Name: "maman", Email: fmt.Sprintf("maman%d@example.com", suffix), Password: "secret", Role: "user"}
+	airline := &ar.Airline{Name: fmt.Sprintf("airline %d", suffix)}
+	location := &lr.Location{Name: fmt.Sprintf("location %d", suffix)}
+	for _, mod := range []any{user, airline, location} {
+		if err := db.Create(mod).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tour := &tr.Tour{
+		Title:      "expiring tour",
+		Start:      time.Now().Add(24 * time.Hour),
+		Quota:      2,
+		Available:  2,
+		AirlineId:  airline.Id,
+		LocationId: location.Id,
+		Departures: []tr.Departure{
+			{Start: time.Now().Add(24 * time.Hour), Finish: time.Now().Add(48 * time.Hour), Quota: 2, Available: 2},
+		},
+	}
+	if err := db.Create(tour).Error; err != nil {
+		t.Fatal(err)
+	}
+	departure := bookings.Departure{Id: tour.Departures[0].Id}
+
+	code := newCode(t)
+	_, err := repo.Create(ctx, bookings.Booking{
+		Code:      code,
+		Total:     10000,
+		User:      bookings.User{Id: user.Id},
+		Tour:      bookings.Tour{Id: tour.Id},
+		Departure: departure,
+		Detail:    []bookings.Detail{{DocumentNumber: "a"}},
+		Payment:   bookings.Payment{Bank: "bca", Status: "pending", ExpiredAt: time.Now().Add(-time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = repo.Create(ctx, bookings.Booking{
+		Code:      code,
+		Total:     10000,
+		User:      bookings.User{Id: user.Id},
+		Tour:      bookings.Tour{Id: tour.Id},
+		Departure: departure,
+		Detail:    []bookings.Detail{{DocumentNumber: "b"}},
+	})
+	assert.ErrorContains(t, err, "used: booking code")
+
+	expired, err := repo.GetExpiredPending(ctx, time.Now(), 8, 100)
+	assert.NoError(t, err)
+	var found bool
+	for _, booking := range expired {
+		found = found || booking.Code == code
+	}
+	assert.True(t, found)
+
+	ok, err := repo.ExpireBooking(ctx, code, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = repo.ExpireBooking(ctx, code, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	var mod = new(tr.Tour)
+	assert.NoError(t, db.Select("available").Where("id = ?", tour.Id).First(mod).Error)
+	assert.Equal(t, 2, mod.Available)
+
+	var notifications []br.BookingNotification
+	assert.NoError(t, db.Where("booking_code = ?", code).Order("id asc").Find(&notifications).Error)
+	if assert.Len(t, notifications, 2) {
+		assert.Equal(t, bookings.EventCreated, notifications[0].Event)
+		assert.Equal(t, bookings.EventExpired, notifications[1].Event)
+	}
+
+	due, err := repo.GetDueNotifications(ctx, time.Now(), 1000)
+	assert.NoError(t, err)
+	for _, notification := range due {
+		if notification.BookingCode != code {
+			continue
+		}
+
+		ok, err := repo.ClaimNotification(ctx, notification, time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.True(t, ok)
+
+		ok, err = repo.ClaimNotification(ctx, notification, time.Now().Add(time.Minute))
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	}
+}
+
+func TestBookingRepositoryDepartureListing(t *testing.T) {
+	db := newTestDB(t)
+	repo := br.NewBookingRepository(db, nil)
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	user := &ur.User{Name: "maman", Email: fmt.Sprintf("maman%d@example.com", suffix), Password: "secret", Role: "user"}
+	airline := &ar.Airline{Name: fmt.Sprintf("airline %d", suffix)}
+	location := &lr.Location{Name: fmt.Sprintf("location %d", suffix)}
+	for _, mod := range []any{user, airline, location} {
+		if err := db.Create(mod).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	tour := &tr.Tour{
+		Title:      "two departures tour",
+		Price:      10000,
+		Start:      start,
+		Finish:     start.Add(10 * 24 * time.Hour),
+		Quota:      4,
+		Available:  4,
+		AirlineId:  airline.Id,
+		LocationId: location.Id,
+		Departures: []tr.Departure{
+			{Start: start, Finish: start.Add(2 * 24 * time.Hour), Quota: 2, Available: 2, Price: 12000},
+			{Start: start.Add(7 * 24 * time.Hour), Finish: start.Add(10 * 24 * time.Hour), Quota: 2, Available: 2, Price: 15000},
+		},
+	}
+	if err := db.Create(tour).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	var codes = make(map[string]tr.Departure)
+	for _, departure := range tour.Departures {
+		code := newCode(t)
+		_, err := repo.Create(ctx, bookings.Booking{
+			Code:      code,
+			Total:     departure.Price,
+			User:      bookings.User{Id: user.Id},
+			Tour:      bookings.Tour{Id: tour.Id},
+			Departure: bookings.Departure{Id: departure.Id},
+			Detail:    []bookings.Detail{{DocumentNumber: "a"}},
+			Payment:   bookings.Payment{Bank: "bca", Status: "pending", ExpiredAt: time.Now().Add(time.Hour)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		codes[code] = departure
+	}
+
+	assertDepartures := func(t *testing.T, result []bookings.Booking) {
+		if !assert.Len(t, result, 2) {
+			return
+		}
+
+		for _, booking := range result {
+			departure := codes[booking.Code]
+			assert.Equal(t, departure.Id, booking.Departure.Id)
+			assert.Equal(t, departure.Price, booking.Tour.Price)
+			assert.True(t, departure.Start.Equal(booking.Tour.Start))
+			assert.True(t, departure.Finish.Equal(booking.Tour.Finish))
+		}
+	}
+
+	t.Run("get all", func(t *testing.T) {
+		result, _, err := repo.GetAll(ctx, filters.Filter{Booking: filters.Booking{TourId: tour.Id}})
+
+		assert.NoError(t, err)
+		assertDepartures(t, result)
+	})
+
+	t.Run("export", func(t *testing.T) {
+		result, err := repo.Export(ctx, filters.Booking{TourId: tour.Id})
+
+		assert.NoError(t, err)
+		assertDepartures(t, result)
+	})
+}
+
+func TestBookingRepositoryCancelNotification(t *testing.T) {
+	db := newTestDB(t)
+	repo := br.NewBookingRepository(db, nil)
+	ctx := context.Background()
+
+	suffix := time.Now().UnixNano()
+	user := &ur.User{Name: "maman", Email: fmt.Sprintf("maman%d@example.com", suffix), Password: "secret", Role: "user"}
+	airline := &ar.Airline{Name: fmt.Sprintf("airline %d", suffix)}
+	location := &lr.Location{Name: fmt.Sprintf("location %d", suffix)}
+	for _, mod := range []any{user, airline, location} {
+		if err := db.Create(mod).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tour := &tr.Tour{
+		Title:      "cancelled tour",
+		Start:      time.Now().Add(24 * time.Hour),
+		Quota:      2,
+		Available:  2,
+		AirlineId:  airline.Id,
+		LocationId: location.Id,
+		Departures: []tr.Departure{
+			{Start: time.Now().Add(24 * time.Hour), Finish: time.Now().Add(48 * time.Hour), Quota: 2, Available: 2},
+		},
+	}
+	if err := db.Create(tour).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	cancel := func(t *testing.T, announced bool) []br.BookingNotification {
+		code := newCode(t)
+		_, err := repo.Create(ctx, bookings.Booking{
+			Code:      code,
+			Total:     10000,
+			User:      bookings.User{Id: user.Id},
+			Tour:      bookings.Tour{Id: tour.Id},
+			Departure: bookings.Departure{Id: tour.Departures[0].Id},
+			Detail:    []bookings.Detail{{DocumentNumber: "a"}},
+			Payment:   bookings.Payment{Bank: "bca", Status: "pending", ExpiredAt: time.Now().Add(time.Hour)},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if announced {
+			assert.NoError(t, db.Model(&br.BookingNotification{}).Where("booking_code = ?", code).Update("status", bookings.NotificationSent).Error)
+		}
+
+		err = repo.UpdateBookingStatus(ctx, bookings.Transition{
+			BookingCode: code,
+			From:        bookings.StatusPending,
+			To:          bookings.StatusCancel,
+			PaymentFrom: bookings.PaymentPending,
+			PaymentTo:   bookings.PaymentFailure,
+			Actor:       bookings.Actor{Id: user.Id, Source: bookings.SourceUser},
+		})
+		assert.NoError(t, err)
+
+		var notifications []br.BookingNotification
+		assert.NoError(t, db.Where("booking_code = ?", code).Order("id asc").Find(&notifications).Error)
+
+		return notifications
+	}
+
+	t.Run("customer never told about the booking", func(t *testing.T) {
+		notifications := cancel(t, false)
+
+		if assert.Len(t, notifications, 1) {
+			assert.Equal(t, bookings.EventCreated, notifications[0].Event)
+		}
+	})
+
+	t.Run("customer told about the booking", func(t *testing.T) {
+		notifications := cancel(t, true)
+
+		if assert.Len(t, notifications, 2) {
+			assert.Equal(t, bookings.EventCreated, notifications[0].Event)
+			assert.Equal(t, bookings.EventCancelled, notifications[1].Event)
+		}
+	})
+}
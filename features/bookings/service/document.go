@@ -0,0 +1,173 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"wanderer/features/bookings"
+
+	"github.com/jung-kurt/gofpdf"
+	"github.com/skip2/go-qrcode"
+)
+
+// Invoice is the invoice of a paid booking as a PDF.
+func (srv *bookingService) Invoice(ctx context.Context, userId uint, code string) ([]byte, error) {
+	booking, err := srv.GetDetail(ctx, userId, code)
+	if err != nil {
+		return nil, err
+	}
+
+	switch booking.Status {
+	case bookings.StatusApproved, bookings.StatusRefund, bookings.StatusRefunded:
+	default:
+		return nil, errors.New("unprocessable: invoice is only available for paid bookings")
+	}
+
+	return renderDocument("Invoice", *booking, true)
+}
+
+// Ticket is the e-ticket of an approved booking as a PDF.
+func (srv *bookingService) Ticket(ctx context.Context, userId uint, code string) ([]byte, error) {
+	booking, err := srv.GetDetail(ctx, userId, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != bookings.StatusApproved {
+		return nil, errors.New("unprocessable: e-ticket is only available for approved bookings")
+	}
+
+	return renderDocument("E-Ticket", *booking, false)
+}
+
+// renderDocument lays out the booking reference with its QR code, the tour,
+// the itinerary and the passengers, followed by the price breakdown on
+// invoices.
+func renderDocument(title string, booking bookings.Booking, invoice bool) ([]byte, error) {
+	qr, err := qrcode.Encode(booking.Code, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.SetTitle(title+" "+booking.Code, true)
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 20)
+	pdf.CellFormat(140, 10, "Wanderer "+title, "", 1, "L", false, 0, "")
+
+	pdf.SetFont("Arial", "", 11)
+	pdf.CellFormat(140, 7, "Booking code: "+booking.Code, "", 1, "L", false, 0, "")
+	pdf.CellFormat(140, 7, "Booked at: "+booking.BookedAt.Format("02 Jan 2006 15:04"), "", 1, "L", false, 0, "")
+	pdf.CellFormat(140, 7, tr("Customer: "+booking.User.Name+" ("+booking.User.Email+")"), "", 1, "L", false, 0, "")
+	if invoice {
+		pdf.CellFormat(140, 7, "Status: "+booking.Status, "", 1, "L", false, 0, "")
+	}
+
+	pdf.RegisterImageOptionsReader("qr", gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(qr))
+	pdf.ImageOptions("qr", 160, 10, 40, 40, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+	pdf.SetY(55)
+
+	section(pdf, "Tour")
+	row(pdf, tr, "Package", booking.Tour.Title)
+	row(pdf, tr, "Destination", booking.Tour.Location.Name)
+	row(pdf, tr, "Airline", booking.Tour.Airline.Name)
+	row(pdf, tr, "Departure", booking.Tour.Start.Format("02 Jan 2006"))
+	row(pdf, tr, "Return", booking.Tour.Finish.Format("02 Jan 2006"))
+
+	if len(booking.Tour.Itinerary) != 0 {
+		section(pdf, "Itinerary")
+		for i, itinerary := range booking.Tour.Itinerary {
+			pdf.SetFont("Arial", "B", 10)
+			pdf.CellFormat(0, 6, tr(fmt.Sprintf("Day %d - %s", i+1, itinerary.Location)), "", 1, "L", false, 0, "")
+			pdf.SetFont("Arial", "", 10)
+			pdf.MultiCell(0, 5, tr(itinerary.Description), "", "L", false)
+		}
+	}
+
+	section(pdf, "Passengers")
+	pdf.SetFont("Arial", "B", 10)
+	for i, header := range []string{"No", "Name", "Nationality", "Date of birth", "Document number"} {
+		pdf.CellFormat(passengerWidths[i], 7, header, "1", 0, "L", false, 0, "")
+	}
+	pdf.Ln(-1)
+
+	pdf.SetFont("Arial", "", 10)
+	for i, detail := range booking.Detail {
+		for j, value := range []string{strconv.Itoa(i + 1), detail.Greeting + " " + detail.Name, detail.Nationality, detail.DOB.Format("02 Jan 2006"), detail.DocumentNumber} {
+			pdf.CellFormat(passengerWidths[j], 7, tr(value), "1", 0, "L", false, 0, "")
+		}
+		pdf.Ln(-1)
+	}
+
+	if invoice {
+		var subtotal float64
+
+		section(pdf, "Price")
+		for _, line := range booking.Breakdown() {
+			subtotal += line.Subtotal
+			amount(pdf, fmt.Sprintf("%s%s (%d x %s)", strings.ToUpper(line.Category[:1]), line.Category[1:], line.Passengers, formatMoney(line.Price)), formatMoney(line.Subtotal))
+		}
+
+		var discount = float64(booking.Tour.Discount) / 100 * subtotal
+		amount(pdf, fmt.Sprintf("Discount (%d%%)", booking.Tour.Discount), "- "+formatMoney(discount))
+		if booking.Voucher.Code != "" {
+			amount(pdf, tr("Voucher "+booking.Voucher.Code), "- "+formatMoney(booking.Discount))
+		}
+		amount(pdf, "Admin fee", formatMoney(booking.Tour.AdminFee))
+
+		pdf.SetFont("Arial", "B", 11)
+		amount(pdf, "Total", formatMoney(booking.Total))
+
+		pdf.SetFont("Arial", "", 10)
+		pdf.Ln(4)
+		if !booking.Payment.PaidAt.IsZero() {
+			pdf.CellFormat(0, 6, "Paid at "+booking.Payment.PaidAt.Format("02 Jan 2006 15:04"), "", 1, "L", false, 0, "")
+		}
+		pdf.CellFormat(0, 6, "Payment method: "+booking.Payment.Method+" "+booking.Payment.Bank, "", 1, "L", false, 0, "")
+	} else {
+		pdf.Ln(6)
+		pdf.SetFont("Arial", "", 10)
+		pdf.MultiCell(0, 5, "Please show this e-ticket and the document of every passenger at check-in. The QR code holds your booking code.", "", "L", false)
+	}
+
+	pdf.SetY(-20)
+	pdf.SetFont("Arial", "I", 8)
+	pdf.CellFormat(0, 5, "Generated at "+time.Now().Format("02 Jan 2006 15:04"), "", 0, "C", false, 0, "")
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+var passengerWidths = []float64{10, 65, 35, 35, 45}
+
+func section(pdf *gofpdf.Fpdf, title string) {
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "B", 13)
+	pdf.CellFormat(0, 9, title, "B", 1, "L", false, 0, "")
+	pdf.Ln(2)
+	pdf.SetFont("Arial", "", 10)
+}
+
+func row(pdf *gofpdf.Fpdf, tr func(string) string, label string, value string) {
+	if value == "" {
+		return
+	}
+
+	pdf.CellFormat(40, 6, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(0, 6, tr(value), "", 1, "L", false, 0, "")
+}
+
+func amount(pdf *gofpdf.Fpdf, label string, value string) {
+	pdf.CellFormat(140, 7, label, "", 0, "L", false, 0, "")
+	pdf.CellFormat(50, 7, value, "", 1, "R", false, 0, "")
+}
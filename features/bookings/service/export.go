@@ -0,0 +1,196 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+	"wanderer/features/bookings"
+	"wanderer/helpers/filters"
+	"wanderer/utils/exporter"
+)
+
+const exportBatchSize = 20
+
+var exportHeader = []string{
+	"Booking Code", "Name", "Tour Package", "Location", "Duration", "Passengers", "Price", "Discount",
+	"Voucher Code", "Voucher Discount", "Admin Fee", "Total", "Status", "Payment Method", "Bank", "Booked At", "Paid At",
+}
+
+// Export writes the bookings matching flt to w in format.
+func (srv *bookingService) Export(ctx context.Context, w io.Writer, format exporter.Format, flt filters.Booking) error {
+	if err := validateFilter(flt); err != nil {
+		return err
+	}
+
+	result, err := srv.repo.Export(ctx, flt)
+	if err != nil {
+		return err
+	}
+
+	exp, err := exporter.NewWriter(format, w, "Transaction List", exportHeader)
+	if err != nil {
+		return err
+	}
+
+	for _, booking := range result {
+		if err := exp.Write(exportRow(booking)); err != nil {
+			return err
+		}
+	}
+
+	return exp.Close()
+}
+
+func exportRow(booking bookings.Booking) []any {
+	var duration = booking.Tour.Finish.Sub(booking.Tour.Start).Hours() / 24
+	var passengers = len(booking.Detail)
+	var discount float64
+	for _, line := range booking.Breakdown() {
+		discount += line.Subtotal - booking.Tour.Discounted(line.Subtotal)
+	}
+
+	var paidAt string
+	if !booking.Payment.PaidAt.IsZero() {
+		paidAt = booking.Payment.PaidAt.Format("2006-01-02 15:04:05")
+	}
+
+	return []any{
+		booking.Code, booking.User.Name, booking.Tour.Title, booking.Tour.Location.Name, int64(duration), passengers,
+		booking.Tour.Price, discount, booking.Voucher.Code, booking.Discount, booking.Tour.AdminFee, booking.Total, booking.Status,
+		booking.Payment.Method, booking.Payment.Bank, booking.BookedAt.Format("2006-01-02 15:04:05"), paidAt,
+	}
+}
+
+// ExportToCloud stores an export in the cloud instead of handing it over, and
+// returns where it can be downloaded from.
+func (srv *bookingService) ExportToCloud(ctx context.Context, format exporter.Format, flt filters.Booking) (string, error) {
+	var buf bytes.Buffer
+	if err := srv.Export(ctx, &buf, format, flt); err != nil {
+		return "", err
+	}
+
+	return srv.repo.UploadExport(ctx, &buf)
+}
+
+// CreateExportSchedule starts a recurring export. Its first run is at the
+// start of the next day, week or month.
+func (srv *bookingService) CreateExportSchedule(ctx context.Context, data bookings.ExportSchedule) (*bookings.ExportSchedule, error) {
+	if !bookings.ValidFrequency(data.Frequency) {
+		return nil, errors.New("validate: frequency must be daily, weekly or monthly")
+	}
+
+	format, err := exporter.ParseFormat(string(data.Format))
+	if err != nil {
+		return nil, errors.New("validate: " + err.Error())
+	}
+	data.Format = format
+
+	data.Filter.BookedStart = time.Time{}
+	data.Filter.BookedEnd = time.Time{}
+	if err := validateFilter(data.Filter); err != nil {
+		return nil, err
+	}
+
+	data.NextRunAt = data.FirstRun(time.Now())
+
+	return srv.repo.CreateExportSchedule(ctx, data)
+}
+
+func (srv *bookingService) GetExportSchedules(ctx context.Context) ([]bookings.ExportSchedule, error) {
+	return srv.repo.GetExportSchedules(ctx)
+}
+
+// DeleteExportSchedule stops a recurring export. The exports it already made
+// can still be downloaded.
+func (srv *bookingService) DeleteExportSchedule(ctx context.Context, id uint) error {
+	return srv.repo.DeleteExportSchedule(ctx, id)
+}
+
+// RunExportSchedules makes the exports of the schedules that are due. A
+// schedule that fell behind makes one export per call until it catches up.
+func (srv *bookingService) RunExportSchedules(ctx context.Context) (int, error) {
+	due, err := srv.repo.GetDueExportSchedules(ctx, time.Now(), exportBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	var errs []error
+	for _, schedule := range due {
+		ok, err := srv.repo.ClaimExportSchedule(ctx, schedule, schedule.Next(schedule.NextRunAt))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("export schedule %d: %w", schedule.Id, err))
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		file := srv.runExportSchedule(ctx, schedule)
+		if _, err := srv.repo.CreateExportFile(ctx, file); err != nil {
+			errs = append(errs, fmt.Errorf("export schedule %d: %w", schedule.Id, err))
+			continue
+		}
+
+		if file.Status == bookings.ExportFailed {
+			errs = append(errs, fmt.Errorf("export schedule %d: %s", schedule.Id, file.Error))
+			continue
+		}
+
+		total++
+	}
+
+	return total, errors.Join(errs...)
+}
+
+// runExportSchedule exports the period ending at the schedule's current run.
+// A failed export is kept as well, so it shows up next to the others.
+func (srv *bookingService) runExportSchedule(ctx context.Context, schedule bookings.ExportSchedule) bookings.ExportFile {
+	start, end := schedule.Period(schedule.NextRunAt)
+
+	var file = bookings.ExportFile{
+		ScheduleId:  schedule.Id,
+		Format:      schedule.Format,
+		PeriodStart: start,
+		PeriodEnd:   end,
+	}
+
+	// The booked end of a filter is inclusive, and booking times are stored
+	// to the millisecond.
+	var flt = schedule.Filter
+	flt.BookedStart = start
+	flt.BookedEnd = end.Add(-time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := srv.Export(ctx, &buf, schedule.Format, flt); err != nil {
+		file.Status = bookings.ExportFailed
+		file.Error = err.Error()
+		return file
+	}
+
+	file.Status = bookings.ExportReady
+	file.Content = buf.Bytes()
+	return file
+}
+
+func (srv *bookingService) GetExportFiles(ctx context.Context, scheduleId uint) ([]bookings.ExportFile, error) {
+	return srv.repo.GetExportFiles(ctx, scheduleId)
+}
+
+// GetExportFile is a scheduled export with its content, ready to download.
+func (srv *bookingService) GetExportFile(ctx context.Context, id uint) (*bookings.ExportFile, error) {
+	file, err := srv.repo.GetExportFile(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if file.Status != bookings.ExportReady {
+		return nil, errors.New("unprocessable: export has failed")
+	}
+
+	return file, nil
+}
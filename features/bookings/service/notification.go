@@ -0,0 +1,189 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"wanderer/features/bookings"
+	"wanderer/utils/mail"
+)
+
+const (
+	notificationBatchSize = 50
+	notificationAttempts  = 8
+)
+
+var (
+	// notificationLease is how long a claimed notification is left alone by
+	// other instances while it is being sent.
+	notificationLease = 5 * time.Minute
+
+	// notificationBackoff is the delay before the first retry, doubled on
+	// every retry after it.
+	notificationBackoff = time.Minute
+)
+
+//go:embed templates/*.tmpl
+var templateFiles embed.FS
+
+var notificationTemplates = parseNotificationTemplates(bookings.EventCreated, bookings.EventPaid, bookings.EventExpired, bookings.EventCancelled, bookings.EventRefunded)
+
+func parseNotificationTemplates(events ...string) map[string]*template.Template {
+	var funcs = template.FuncMap{
+		"date":     func(t time.Time) string { return t.Format("02 Jan 2006") },
+		"datetime": func(t time.Time) string { return t.Format("02 Jan 2006 15:04 MST") },
+		"money":    formatMoney,
+		"upper":    strings.ToUpper,
+		"inc":      func(i int) int { return i + 1 },
+	}
+
+	var result = make(map[string]*template.Template)
+	for _, event := range events {
+		result[event] = template.Must(template.New(event).Funcs(funcs).ParseFS(templateFiles, "templates/layout.tmpl", "templates/"+event+".tmpl"))
+	}
+
+	return result
+}
+
+type notificationData struct {
+	Booking bookings.Booking
+	Refund  *bookings.Refund
+}
+
+// renderNotification writes the email about event for booking.
+func renderNotification(event string, booking bookings.Booking) (*mail.Message, error) {
+	tmpl, ok := notificationTemplates[event]
+	if !ok {
+		return nil, fmt.Errorf("no template for event %s", event)
+	}
+
+	var data = notificationData{Booking: booking}
+	if len(booking.Refunds) != 0 {
+		data.Refund = &booking.Refunds[len(booking.Refunds)-1]
+	}
+
+	var subject, body bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.ExecuteTemplate(&body, "body", data); err != nil {
+		return nil, err
+	}
+
+	return &mail.Message{
+		To:      booking.User.Email,
+		Subject: strings.TrimSpace(subject.String()),
+		Body:    body.String(),
+	}, nil
+}
+
+// formatMoney writes an amount in rupiah, e.g. Rp 1.250.000.
+func formatMoney(amount float64) string {
+	digits := strconv.FormatInt(int64(amount), 10)
+
+	var sign string
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+
+	var buf strings.Builder
+	for i, digit := range digits {
+		if i != 0 && (len(digits)-i)%3 == 0 {
+			buf.WriteByte('.')
+		}
+		buf.WriteRune(digit)
+	}
+
+	return sign + "Rp " + buf.String()
+}
+
+// SendNotifications sends the due emails of the outbox. A failed email is
+// retried with a growing delay and given up after notificationAttempts.
+func (srv *bookingService) SendNotifications(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	due, err := srv.repo.GetDueNotifications(ctx, now, notificationBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	var errs []error
+	for _, notification := range due {
+		ok, err := srv.repo.ClaimNotification(ctx, notification, now.Add(notificationLease))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("notification %d: %w", notification.Id, err))
+			continue
+		}
+
+		if !ok {
+			continue
+		}
+
+		notification.Attempts++
+		if err := srv.sendNotification(ctx, &notification); err != nil {
+			notification.LastError = err.Error()
+			notification.NextAttemptAt = time.Now().Add(notificationBackoff << (notification.Attempts - 1))
+			if notification.Attempts >= notificationAttempts {
+				notification.Status = bookings.NotificationFailed
+			}
+
+			errs = append(errs, fmt.Errorf("notification %d: %w", notification.Id, err))
+		} else if notification.Status == bookings.NotificationSent {
+			total++
+		}
+
+		if err := srv.repo.UpdateNotification(ctx, notification); err != nil {
+			errs = append(errs, fmt.Errorf("notification %d: %w", notification.Id, err))
+		}
+	}
+
+	return total, errors.Join(errs...)
+}
+
+// sendNotification emails the booking's current details. A payment reminder
+// for a booking that has been paid or cancelled meanwhile is skipped, since
+// it would only be confusing by now, and one for a booking that hasn't been
+// charged yet is retried later.
+func (srv *bookingService) sendNotification(ctx context.Context, notification *bookings.Notification) error {
+	booking, err := srv.repo.GetDetail(ctx, notification.BookingCode)
+	if err != nil {
+		return err
+	}
+
+	if notification.Event == bookings.EventCreated && booking.Status != bookings.StatusPending {
+		notification.Status = bookings.NotificationSkipped
+		notification.LastError = ""
+		return nil
+	}
+
+	// A booking is stored before it is charged, its payment details follow.
+	if notification.Event == bookings.EventCreated && booking.Payment.Method == "" {
+		return errors.New("booking hasn't been charged yet")
+	}
+
+	if booking.User.Email == "" {
+		return errors.New("booking has no email address to notify")
+	}
+
+	msg, err := renderNotification(notification.Event, *booking)
+	if err != nil {
+		return err
+	}
+
+	if err := srv.mailer.Send(ctx, *msg); err != nil {
+		return err
+	}
+
+	notification.Status = bookings.NotificationSent
+	notification.LastError = ""
+	notification.SentAt = time.Now()
+	return nil
+}
@@ -3,24 +3,68 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
 	"time"
+	"wanderer/config"
 	"wanderer/features/bookings"
 	"wanderer/helpers/filters"
+	"wanderer/utils/mail"
+	"wanderer/utils/payments"
+)
 
-	"github.com/labstack/echo/v4"
+const (
+	codeAttempts    = 3
+	paymentAttempts = 3
+	expireBatchSize = 100
+	expireAttempts  = 8
 )
 
-func NewBookingService(repo bookings.Repository) bookings.Service {
+var paymentRetryDelay = 50 * time.Millisecond
+
+// reserveWindow is how long a booking being made is given to get charged.
+var reserveWindow = 10 * time.Minute
+
+// expireBackoff is the delay before retrying a failed gateway cancel of an
+// expired booking, doubled on every retry after it.
+var expireBackoff = time.Minute
+
+func NewBookingService(repo bookings.Repository, payment payments.Gateway, refund config.Refund, mailer mail.Mailer) bookings.Service {
 	return &bookingService{
-		repo: repo,
+		repo:    repo,
+		payment: payment,
+		refund:  refund,
+		mailer:  mailer,
 	}
 }
 
 type bookingService struct {
-	repo bookings.Repository
+	repo    bookings.Repository
+	payment payments.Gateway
+	refund  config.Refund
+	mailer  mail.Mailer
 }
 
-func (srv *bookingService) GetAll(ctx context.Context, flt filters.Filter) ([]bookings.Booking, int, error) {
+func (srv *bookingService) GetAll(ctx context.Context, userId uint, flt filters.Filter) ([]bookings.Booking, int, error) {
+	if userId == 0 {
+		return nil, 0, errors.New("validate: user id can't be empty")
+	}
+
+	if err := validateFilter(flt.Booking); err != nil {
+		return nil, 0, err
+	}
+
+	user, err := srv.repo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if user.Role != "admin" {
+		flt.Booking.UserId = userId
+	}
+
 	result, totalData, err := srv.repo.GetAll(ctx, flt)
 	if err != nil {
 		return nil, 0, err
@@ -29,16 +73,43 @@ func (srv *bookingService) GetAll(ctx context.Context, flt filters.Filter) ([]bo
 	return result, totalData, nil
 }
 
-func (srv *bookingService) GetDetail(ctx context.Context, code int) (*bookings.Booking, error) {
-	if code == 0 {
+func validateFilter(flt filters.Booking) error {
+	switch flt.Status {
+	case "", bookings.StatusPending, bookings.StatusCancel, bookings.StatusApproved, bookings.StatusRefund, bookings.StatusRefunded:
+	default:
+		return errors.New("validate: invalid booking status")
+	}
+
+	if !flt.BookedStart.IsZero() && !flt.BookedEnd.IsZero() && flt.BookedStart.After(flt.BookedEnd) {
+		return errors.New("validate: booked start can't be after booked end")
+	}
+
+	return nil
+}
+
+func (srv *bookingService) GetDetail(ctx context.Context, userId uint, code string) (*bookings.Booking, error) {
+	if userId == 0 {
+		return nil, errors.New("validate: user id can't be empty")
+	}
+
+	if !bookings.ValidCode(code) {
 		return nil, errors.New("validate: invalid booking code")
 	}
 
+	user, err := srv.repo.GetUserById(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
 	result, err := srv.repo.GetDetail(ctx, code)
 	if err != nil {
 		return nil, err
 	}
 
+	if user.Role != "admin" && result.User.Id != userId {
+		return nil, errors.New("not found: booking not found")
+	}
+
 	return result, nil
 }
 
@@ -102,82 +173,234 @@ func (srv *bookingService) Create(ctx context.Context, data bookings.Booking) (*
 		return nil, err
 	}
 
-	if tour.Start.Before(time.Now()) {
+	if tour.Status != bookings.TourPublished {
+		return nil, errors.New("unprocessable: tour is not open for booking")
+	}
+
+	departure, err := tour.Departure(data.Departure.Id)
+	if err != nil {
+		return nil, err
+	}
+
+	if departure.Start.Before(time.Now()) {
 		return nil, errors.New("unprocessable: tour has been started")
 	}
 
+	if departure.Available < len(data.Detail) {
+		return nil, errors.New("unprocessable: not enough seats available")
+	}
+
+	data.User = *user
+	data.Departure = *departure
+	data.Tour = tour.On(*departure)
+	data.Tour.Departures = nil
+
+	data.Detail, err = data.Tour.PricePassengers(data.Detail)
+	if err != nil {
+		return nil, err
+	}
+
+	if data.Voucher.Code != "" {
+		voucher, err := srv.repo.GetVoucher(ctx, bookings.NormalizeVoucherCode(data.Voucher.Code), data.User.Id)
+		if err != nil {
+			return nil, err
+		}
+
+		discount, err := voucher.Apply(data.Tour, data.Detail, time.Now())
+		if err != nil {
+			return nil, err
+		}
+
+		data.Voucher = *voucher
+		data.Discount = discount
+	}
+
+	data.Total = calcTotal(data.Tour, data.Detail, data.Discount)
+
+	for attempt := 1; ; attempt++ {
+		result, err := srv.reserve(ctx, data)
+		if err != nil && strings.Contains(err.Error(), "used: booking code") && attempt < codeAttempts {
+			continue
+		}
+
+		return result, err
+	}
+}
+
+// reserve stores data under a fresh booking code and charges it afterwards,
+// so a code that turns out to be taken never gets charged. A booking that
+// can't be charged is canceled again. Until it is charged it expires after
+// reserveWindow, so one left behind by a failure midway doesn't hold its
+// seats for good.
+func (srv *bookingService) reserve(ctx context.Context, data bookings.Booking) (*bookings.Booking, error) {
+	code, err := bookings.NewCode()
+	if err != nil {
+		return nil, err
+	}
+	data.Code = code
+	data.Payment.Status = bookings.PaymentPending
+	data.Payment.ExpiredAt = time.Now().Add(reserveWindow)
+
 	result, err := srv.repo.Create(ctx, data)
 	if err != nil {
 		return nil, err
 	}
 
+	payment, err := srv.payment.NewBookingPayment(data)
+	if err != nil {
+		return nil, srv.cancelReservation(ctx, data, err)
+	}
+
+	if err := srv.repo.ChangePaymentMethod(ctx, code, *payment); err != nil {
+		if cancelErr := srv.payment.CancelBookingPayment(code); cancelErr != nil {
+			return nil, errors.Join(err, cancelErr)
+		}
+
+		return nil, srv.cancelReservation(ctx, data, err)
+	}
+
+	result.Payment = *payment
 	return result, nil
 }
 
-func (srv *bookingService) UpdateBookingStatus(ctx context.Context, code int, status string) error {
-	if code == 0 {
+// cancelReservation cancels a booking reserve couldn't charge, giving its
+// seats back, and returns the cause along with anything going wrong on the
+// way. The customer never heard of the booking, so isn't emailed about it.
+func (srv *bookingService) cancelReservation(ctx context.Context, data bookings.Booking, cause error) error {
+	err := srv.repo.UpdateBookingStatus(ctx, bookings.Transition{
+		BookingCode: data.Code,
+		From:        bookings.StatusPending,
+		To:          bookings.StatusCancel,
+		PaymentFrom: bookings.PaymentPending,
+		PaymentTo:   bookings.PaymentFailure,
+		Actor:       bookings.Actor{Id: data.User.Id, Source: bookings.SourceUser},
+		Note:        "payment failed: " + cause.Error(),
+	})
+	if err != nil {
+		return errors.Join(cause, err)
+	}
+
+	return cause
+}
+
+// calcTotal is what a booking of the priced passengers on tour costs, with
+// discount taken off by a voucher.
+func calcTotal(tour bookings.Tour, passengers []bookings.Detail, discount float64) float64 {
+	return bookings.TourTotal(tour, passengers) - discount + tour.AdminFee
+}
+
+func (srv *bookingService) UpdateBookingStatus(ctx context.Context, actor bookings.Actor, code string, status string) error {
+	if !bookings.ValidCode(code) {
 		return errors.New("validate: invalid booking code")
 	}
 
+	if status != bookings.StatusCancel {
+		return errors.New("validate: invalid booking status")
+	}
+
 	oldData, err := srv.repo.GetDetail(ctx, code)
 	if err != nil {
 		return err
 	}
 
+	if actor.Source == bookings.SourceUser && oldData.User.Id != actor.Id {
+		return errors.New("not found: booking not found")
+	}
+
 	if oldData.Tour.Start.Before(time.Now()) {
 		return errors.New("unprocessable: can't update booking after tour started")
 	}
 
-	switch status {
-	case "cancel":
-		if oldData.Status != "pending" {
-			return errors.New("unprocessable: booking can't canceled")
-		}
-	case "refund":
-		if oldData.Status != "approved" {
-			return errors.New("unprocessable: refund request denied")
-		}
-	case "refunded":
-		if oldData.Status != "refund" {
-			return errors.New("unprocessable: can't approve refund without refund request")
-		}
-	default:
-		return errors.New("validate: invalid booking status")
+	transition, err := bookings.BookingTransition(*oldData, status, actor)
+	if err != nil {
+		return err
+	}
+
+	if err := srv.payment.CancelBookingPayment(code); err != nil {
+		return err
 	}
 
-	if err := srv.repo.UpdateBookingStatus(ctx, code, status); err != nil {
+	if err := srv.repo.UpdateBookingStatus(ctx, *transition); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (srv *bookingService) UpdatePaymentStatus(ctx context.Context, code int, paymentStatus string) error {
-	if code == 0 {
+func (srv *bookingService) UpdatePaymentStatus(ctx context.Context, code string, paymentStatus string) error {
+	if !bookings.ValidCode(code) {
 		return errors.New("validate: invalid booking code")
 	}
 
-	var bookingStatus = "pending"
-	switch paymentStatus {
-	case "settlement":
-		bookingStatus = "approved"
-	case "cancel", "expire":
-		bookingStatus = "cancel"
-	case "capture", "deny", "pending":
-		bookingStatus = "pending"
-	default:
-		return errors.New("validate: invalid payment status")
+	booking, err := srv.repo.GetDetail(ctx, code)
+	if err != nil {
+		return err
 	}
 
-	if err := srv.repo.UpdatePaymentStatus(ctx, code, bookingStatus, paymentStatus); err != nil {
+	return srv.applyPaymentStatus(ctx, *booking, paymentStatus)
+}
+
+// applyPaymentStatus moves a booking along with a payment status reported by
+// the gateway. Repeated notifications for the same status are ignored.
+func (srv *bookingService) applyPaymentStatus(ctx context.Context, booking bookings.Booking, paymentStatus string) error {
+	transition, err := bookings.PaymentTransition(booking, paymentStatus, bookings.Actor{Source: bookings.SourceWebhook})
+	if err != nil {
+		return err
+	}
+
+	if !transition.Changed() {
+		return nil
+	}
+
+	if err := srv.repo.UpdatePaymentStatus(ctx, *transition); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (srv *bookingService) ChangePaymentMethod(ctx context.Context, code int, data bookings.Payment) (*bookings.Payment, error) {
-	if code == 0 {
+func (srv *bookingService) PaymentNotification(ctx context.Context, data bookings.PaymentNotification) error {
+	result, err := srv.payment.VerifyNotification(data)
+	if err != nil {
+		return srv.rejectPaymentNotification(ctx, data, err.Error())
+	}
+
+	if !bookings.ValidCode(result.OrderId) {
+		return srv.rejectPaymentNotification(ctx, *result, "invalid order id")
+	}
+
+	booking, err := srv.repo.GetDetail(ctx, result.OrderId)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found: ") {
+			return srv.rejectPaymentNotification(ctx, *result, "booking not found")
+		}
+
+		return err
+	}
+
+	grossAmount, err := strconv.ParseFloat(result.GrossAmount, 64)
+	if err != nil || int64(grossAmount) != int64(booking.Total) {
+		return srv.rejectPaymentNotification(ctx, *result, "gross amount mismatch")
+	}
+
+	return srv.applyPaymentStatus(ctx, *booking, result.Status)
+}
+
+func (srv *bookingService) rejectPaymentNotification(ctx context.Context, data bookings.PaymentNotification, reason string) error {
+	data.RejectReason = reason
+	if err := srv.repo.CreatePaymentRejection(ctx, data); err != nil {
+		return err
+	}
+
+	return errors.New("unprocessable: payment notification rejected: " + reason)
+}
+
+// ChangePaymentMethod replaces the pending payment of a booking with one
+// through another bank. When the new payment can't be charged, the old method
+// is charged again so the booking stays payable. A user can only change the
+// payment of their own bookings.
+func (srv *bookingService) ChangePaymentMethod(ctx context.Context, actor bookings.Actor, code string, data bookings.Payment) (*bookings.Payment, error) {
+	if !bookings.ValidCode(code) {
 		return nil, errors.New("validate: invalid booking code")
 	}
 
@@ -190,7 +413,11 @@ func (srv *bookingService) ChangePaymentMethod(ctx context.Context, code int, da
 		return nil, err
 	}
 
-	if oldData.Status != "pending" || oldData.Payment.Status != "pending" {
+	if actor.Source == bookings.SourceUser && oldData.User.Id != actor.Id {
+		return nil, errors.New("not found: booking not found")
+	}
+
+	if oldData.Status != bookings.StatusPending || oldData.Payment.Status != bookings.PaymentPending {
 		return nil, errors.New("unprocessable: can't change payment method")
 	}
 
@@ -198,36 +425,308 @@ func (srv *bookingService) ChangePaymentMethod(ctx context.Context, code int, da
 		return &oldData.Payment, nil
 	}
 
+	// The gateway knows a booking by its code, so the old payment has to go
+	// before the new one can be charged.
+	if err := srv.payment.CancelBookingPayment(code); err != nil {
+		return nil, err
+	}
+
+	oldMethod := bookings.Payment{Bank: oldData.Payment.Bank}
 	oldData.Payment = data
-	result, err := srv.repo.ChangePaymentMethod(ctx, code, *oldData)
+
+	result, err := srv.charge(*oldData)
 	if err != nil {
+		// Charge the old method again, so the booking can still be paid the
+		// way it could before.
+		oldData.Payment = oldMethod
+		restored, restoreErr := srv.charge(*oldData)
+		if restoreErr == nil {
+			restoreErr = srv.repo.ChangePaymentMethod(ctx, code, *restored)
+		}
+
+		if restoreErr != nil {
+			return nil, errors.Join(err, restoreErr)
+		}
+
+		return nil, err
+	}
+
+	if err := srv.repo.ChangePaymentMethod(ctx, code, *result); err != nil {
+		if cancelErr := srv.payment.CancelBookingPayment(code); cancelErr != nil {
+			return nil, errors.Join(err, cancelErr)
+		}
+
 		return nil, err
 	}
 
 	return result, nil
 }
 
-func (srv *bookingService) Export(c echo.Context, typeFile string) error {
-	result, err := srv.repo.Export()
+// charge asks the gateway for a payment of booking, retrying a few times
+// before giving up.
+func (srv *bookingService) charge(booking bookings.Booking) (*bookings.Payment, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := srv.payment.NewBookingPayment(booking)
+		if err == nil {
+			return result, nil
+		}
+
+		if attempt == paymentAttempts {
+			return nil, err
+		}
+
+		time.Sleep(paymentRetryDelay)
+	}
+}
+
+func (srv *bookingService) RequestRefund(ctx context.Context, userId uint, code string, data bookings.Refund) (*bookings.Refund, error) {
+	if userId == 0 {
+		return nil, errors.New("validate: user id can't be empty")
+	}
+
+	if !bookings.ValidCode(code) {
+		return nil, errors.New("validate: invalid booking code")
+	}
+
+	if strings.TrimSpace(data.Reason) == "" {
+		return nil, errors.New("validate: refund reason can't be empty")
+	}
+
+	booking, err := srv.repo.GetDetail(ctx, code)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	if booking.User.Id != userId {
+		return nil, errors.New("not found: booking not found")
+	}
+
+	transition, err := bookings.BookingTransition(*booking, bookings.StatusRefund, bookings.Actor{Id: userId, Source: bookings.SourceUser})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if booking.Tour.Start.Before(now) {
+		return nil, errors.New("unprocessable: can't update booking after tour started")
+	}
+
+	refunded := booking.Refunded()
+	if len(data.Passengers) == 0 {
+		for _, detail := range booking.Detail {
+			if !refunded[detail.Id] {
+				data.Passengers = append(data.Passengers, detail.Id)
+			}
+		}
+	}
+
+	var passengers = make(map[uint]bool)
+	for _, detail := range booking.Detail {
+		passengers[detail.Id] = true
+	}
+
+	for _, passenger := range data.Passengers {
+		if refunded[passenger] {
+			return nil, errors.New("validate: passenger has already been refunded")
+		}
+
+		if !passengers[passenger] {
+			return nil, errors.New("validate: invalid refund passenger")
+		}
+
+		passengers[passenger] = false
+	}
+
+	data.Percentage = refundPercentage(srv.refund.Policy, booking.Tour.Start, now)
+	if data.Percentage == 0 {
+		return nil, errors.New("unprocessable: booking isn't eligible for refund")
+	}
+
+	data.BookingCode = code
+	data.Amount = refundAmount(booking.Total, booking.Detail, data.Passengers, data.Percentage)
+	transition.Note = data.Reason
+
+	result, err := srv.repo.CreateRefund(ctx, data, *transition)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ApproveRefund pays out the latest refund request of a booking. The refund
+// is marked approved before the gateway is called and failed if the gateway
+// rejects it. Both can be approved again: the gateway pays a refund out only
+// once, so retrying an approved refund just completes it when the booking
+// couldn't be updated after the payout. An amount of zero refunds what the
+// cancellation policy allows; a smaller amount makes a partial refund. The
+// booking is refunded once every passenger is, and approved again otherwise.
+func (srv *bookingService) ApproveRefund(ctx context.Context, actor bookings.Actor, code string, amount float64) (*bookings.Refund, error) {
+	if !bookings.ValidCode(code) {
+		return nil, errors.New("validate: invalid booking code")
+	}
+
+	if amount < 0 {
+		return nil, errors.New("validate: invalid refund amount")
+	}
+
+	booking, err := srv.repo.GetDetail(ctx, code)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(booking.Refunds) == 0 {
+		return nil, errors.New("unprocessable: can't approve refund without refund request")
+	}
+
+	refund := &booking.Refunds[len(booking.Refunds)-1]
+
+	refunded := booking.Refunded()
+	for _, passenger := range refund.Passengers {
+		refunded[passenger] = true
 	}
 
-	var data []bookings.Booking
-	for _, export := range result {
-		data = append(data, export)
+	to := bookings.StatusRefunded
+	for _, detail := range booking.Detail {
+		if !refunded[detail.Id] {
+			to = bookings.StatusApproved
+			break
+		}
+	}
+
+	transition, err := bookings.BookingTransition(*booking, to, actor)
+	if err != nil {
+		return nil, err
 	}
 
-	switch typeFile {
-	case "pdf":
-		err = srv.repo.ExportFilePDF(c, data)
-	case "csv":
-		err = srv.repo.ExportFileCsv(c, data)
-	case "xlsx":
-		err = srv.repo.ExportFileExcel(c, data)
+	switch refund.Status {
+	case bookings.RefundRequested, bookings.RefundFailed:
+		if amount == 0 {
+			amount = refund.Amount
+		}
+
+		if amount > refund.Amount {
+			return nil, errors.New("validate: refund amount exceeds cancellation policy")
+		}
+
+		refund.Amount = amount
+		if err := srv.repo.UpdateRefundStatus(ctx, refund.Id, bookings.RefundApproved, amount, ""); err != nil {
+			return nil, err
+		}
+	case bookings.RefundApproved:
+		if amount != 0 && amount != refund.Amount {
+			return nil, errors.New("unprocessable: refund is already being processed with another amount")
+		}
+
+		amount = refund.Amount
 	default:
-		err = errors.New("unsupported file type")
+		return nil, errors.New("unprocessable: can't approve refund without refund request")
+	}
+
+	if err := srv.payment.RefundBookingPayment(code, refund.Id, amount, refund.Reason); err != nil {
+		if failErr := srv.repo.UpdateRefundStatus(ctx, refund.Id, bookings.RefundFailed, amount, err.Error()); failErr != nil {
+			return nil, errors.Join(err, failErr)
+		}
+
+		return nil, err
+	}
+
+	refund.Status = bookings.RefundRefunded
+	refund.Note = ""
+	if err := srv.repo.CompleteRefund(ctx, *refund, *transition); err != nil {
+		return nil, err
+	}
+
+	return refund, nil
+}
+
+// refundPercentage picks the first tier of the policy the request still
+// qualifies for. Tiers are ordered from the most days before the tour.
+func refundPercentage(policy []config.RefundTier, start time.Time, now time.Time) int {
+	daysBefore := int(start.Sub(now).Hours() / 24)
+
+	for _, tier := range policy {
+		if daysBefore >= tier.DaysBefore {
+			return tier.Percentage
+		}
+	}
+
+	return 0
+}
+
+// refundAmount is the share of total the refunded passengers paid for, by the
+// price of their seats, cut down to percentage. Bookings made before seats
+// were priced per passenger split total evenly.
+func refundAmount(total float64, passengers []bookings.Detail, refunded []uint, percentage int) float64 {
+	var isRefunded = make(map[uint]bool)
+	for _, id := range refunded {
+		isRefunded[id] = true
+	}
+
+	var priced bool
+	for _, passenger := range passengers {
+		if passenger.Price != 0 {
+			priced = true
+		}
+	}
+
+	var seats, refundedSeats float64
+	for _, passenger := range passengers {
+		var seat float64 = 1
+		if priced {
+			seat = passenger.Price
+		}
+
+		seats += seat
+		if isRefunded[passenger.Id] {
+			refundedSeats += seat
+		}
+	}
+
+	if seats == 0 {
+		return 0
+	}
+
+	return math.Floor(total * refundedSeats / seats * float64(percentage) / 100)
+}
+
+// ExpirePendingBookings cancels pending bookings whose payment window has
+// passed. A booking whose gateway cancel fails is left pending, since it may
+// have been paid at the last moment; its notification or a later run settles
+// it. Later runs retry it with a growing delay and give up after
+// expireAttempts. A payment the gateway doesn't know has nothing to cancel.
+func (srv *bookingService) ExpirePendingBookings(ctx context.Context) (int, error) {
+	now := time.Now()
+
+	expired, err := srv.repo.GetExpiredPending(ctx, now, expireAttempts, expireBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var total int
+	var errs []error
+	for _, booking := range expired {
+		if err := srv.payment.CancelBookingPayment(booking.Code); err != nil && !strings.Contains(err.Error(), "not found: ") {
+			errs = append(errs, fmt.Errorf("booking %s: %w", booking.Code, err))
+
+			attempts := booking.Payment.CancelAttempts + 1
+			if err := srv.repo.PostponeExpiry(ctx, booking.Code, attempts, now.Add(expireBackoff<<(attempts-1))); err != nil {
+				errs = append(errs, fmt.Errorf("booking %s: %w", booking.Code, err))
+			}
+
+			continue
+		}
+
+		ok, err := srv.repo.ExpireBooking(ctx, booking.Code, now)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("booking %s: %w", booking.Code, err))
+			continue
+		}
+
+		if ok {
+			total++
+		}
 	}
 
-	return err
+	return total, errors.Join(errs...)
 }
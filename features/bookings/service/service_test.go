@@ -1,28 +1,43 @@
 package service
 
 import (
+	"bytes"
 	"context"
 	"errors"
-	"net/http"
-	"net/http/httptest"
+	"io"
+	"strings"
 	"testing"
 	"time"
+	"wanderer/config"
 	"wanderer/features/bookings"
 	"wanderer/features/bookings/mocks"
 	"wanderer/helpers/filters"
+	"wanderer/utils/exporter"
+	"wanderer/utils/mail"
+	paymentMocks "wanderer/utils/payments/mocks"
 
-	"github.com/labstack/echo/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
+const bookingCode = "WNDR4Q7K2W"
+
+var refundConfig = config.Refund{
+	Policy: []config.RefundTier{
+		{DaysBefore: 30, Percentage: 100},
+		{DaysBefore: 14, Percentage: 50},
+	},
+}
+
 func TestBookingServiceGetAll(t *testing.T) {
 	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
 	ctx := context.Background()
 
 	data := []bookings.Booking{
 		{
-			Code:      123,
+			Code:      bookingCode,
 			Total:     10000,
 			Status:    "pending",
 			BookedAt:  time.Now(),
@@ -39,7 +54,7 @@ func TestBookingServiceGetAll(t *testing.T) {
 					Greeting:       "mr",
 					Name:           "maman",
 					Nationality:    "indonesia",
-					DOB:            time.Now(),
+					DOB:            time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC),
 				},
 			},
 			Payment: bookings.Payment{
@@ -47,7 +62,7 @@ func TestBookingServiceGetAll(t *testing.T) {
 			},
 		},
 		{
-			Code:      234,
+			Code:      "WNDR4Q7K3T",
 			Total:     10000,
 			Status:    "pending",
 			BookedAt:  time.Now(),
@@ -64,7 +79,7 @@ func TestBookingServiceGetAll(t *testing.T) {
 					Greeting:       "mr",
 					Name:           "maman",
 					Nationality:    "indonesia",
-					DOB:            time.Now(),
+					DOB:            time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC),
 				},
 			},
 			Payment: bookings.Payment{
@@ -73,10 +88,50 @@ func TestBookingServiceGetAll(t *testing.T) {
 		},
 	}
 
+	t.Run("invalid user id", func(t *testing.T) {
+		result, totalData, err := srv.GetAll(ctx, 0, filters.Filter{})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "user id")
+		assert.Equal(t, 0, totalData)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid status filter", func(t *testing.T) {
+		result, totalData, err := srv.GetAll(ctx, 1, filters.Filter{Booking: filters.Booking{Status: "paid"}})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "status")
+		assert.Equal(t, 0, totalData)
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid booked range filter", func(t *testing.T) {
+		result, totalData, err := srv.GetAll(ctx, 1, filters.Filter{Booking: filters.Booking{BookedStart: time.Now(), BookedEnd: time.Now().Add(-24 * time.Hour)}})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "booked")
+		assert.Equal(t, 0, totalData)
+		assert.Nil(t, result)
+	})
+
+	t.Run("user not found", func(t *testing.T) {
+		repo.On("GetUserById", ctx, uint(1)).Return(nil, errors.New("not found: user not found")).Once()
+
+		result, totalData, err := srv.GetAll(ctx, 1, filters.Filter{})
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Equal(t, 0, totalData)
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("error from repository", func(t *testing.T) {
-		repo.On("GetAll", ctx, filters.Filter{}).Return(nil, 0, errors.New("some error from repository")).Once()
+		repo.On("GetUserById", ctx, uint(1)).Return(&bookings.User{Id: 1, Role: "user"}, nil).Once()
+		repo.On("GetAll", ctx, filters.Filter{Booking: filters.Booking{UserId: 1}}).Return(nil, 0, errors.New("some error from repository")).Once()
 
-		result, totalData, err := srv.GetAll(ctx, filters.Filter{})
+		result, totalData, err := srv.GetAll(ctx, 1, filters.Filter{})
 
 		assert.ErrorContains(t, err, "some error from repository")
 		assert.Equal(t, 0, totalData)
@@ -85,11 +140,40 @@ func TestBookingServiceGetAll(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("user only see own bookings", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(1)).Return(&bookings.User{Id: 1, Role: "user"}, nil).Once()
+		repo.On("GetAll", ctx, filters.Filter{Booking: filters.Booking{UserId: 1, Status: "pending"}}).Return(caseData, 2, nil).Once()
+
+		result, totalData, err := srv.GetAll(ctx, 1, filters.Filter{Booking: filters.Booking{UserId: 2, Status: "pending"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, totalData)
+		assert.Equal(t, caseData, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin filter by user", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(9)).Return(&bookings.User{Id: 9, Role: "admin"}, nil).Once()
+		repo.On("GetAll", ctx, filters.Filter{Booking: filters.Booking{UserId: 1, TourId: 1}}).Return(caseData, 2, nil).Once()
+
+		result, totalData, err := srv.GetAll(ctx, 9, filters.Filter{Booking: filters.Booking{UserId: 1, TourId: 1}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, totalData)
+		assert.Equal(t, caseData, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin see all bookings", func(t *testing.T) {
 		caseData := data
+		repo.On("GetUserById", ctx, uint(9)).Return(&bookings.User{Id: 9, Role: "admin"}, nil).Once()
 		repo.On("GetAll", ctx, filters.Filter{}).Return(caseData, 2, nil).Once()
 
-		result, totalData, err := srv.GetAll(ctx, filters.Filter{})
+		result, totalData, err := srv.GetAll(ctx, 9, filters.Filter{})
 
 		assert.NoError(t, err)
 		assert.Equal(t, 2, totalData)
@@ -101,7 +185,8 @@ func TestBookingServiceGetAll(t *testing.T) {
 
 func TestBookingServiceGetDetail(t *testing.T) {
 	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
 	ctx := context.Background()
 
 	data := bookings.Booking{
@@ -121,7 +206,7 @@ func TestBookingServiceGetDetail(t *testing.T) {
 				Greeting:       "mr",
 				Name:           "maman",
 				Nationality:    "indonesia",
-				DOB:            time.Now(),
+				DOB:            time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC),
 			},
 		},
 		Payment: bookings.Payment{
@@ -129,18 +214,38 @@ func TestBookingServiceGetDetail(t *testing.T) {
 		},
 	}
 
+	t.Run("invalid user id", func(t *testing.T) {
+		result, err := srv.GetDetail(ctx, 0, bookingCode)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "user id")
+		assert.Nil(t, result)
+	})
+
 	t.Run("invalid booking code", func(t *testing.T) {
-		result, err := srv.GetDetail(ctx, 0)
+		result, err := srv.GetDetail(ctx, 1, "WNDR4Q7K2X")
 
 		assert.ErrorContains(t, err, "validate")
 		assert.ErrorContains(t, err, "booking code")
 		assert.Nil(t, result)
 	})
 
+	t.Run("user not found", func(t *testing.T) {
+		repo.On("GetUserById", ctx, uint(1)).Return(nil, errors.New("not found: user not found")).Once()
+
+		result, err := srv.GetDetail(ctx, 1, bookingCode)
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("error from repository", func(t *testing.T) {
-		repo.On("GetDetail", ctx, 123).Return(nil, errors.New("some error from repository")).Once()
+		repo.On("GetUserById", ctx, uint(1)).Return(&bookings.User{Id: 1, Role: "user"}, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(nil, errors.New("some error from repository")).Once()
 
-		result, err := srv.GetDetail(ctx, 123)
+		result, err := srv.GetDetail(ctx, 1, bookingCode)
 
 		assert.ErrorContains(t, err, "some error from repository")
 		assert.Nil(t, result)
@@ -148,11 +253,39 @@ func TestBookingServiceGetDetail(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("booking owned by other user", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(2)).Return(&bookings.User{Id: 2, Role: "user"}, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&caseData, nil).Once()
+
+		result, err := srv.GetDetail(ctx, 2, bookingCode)
+
+		assert.ErrorContains(t, err, "not found")
+		assert.ErrorContains(t, err, "booking")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("success", func(t *testing.T) {
 		caseData := data
-		repo.On("GetDetail", ctx, 123).Return(&caseData, nil).Once()
+		repo.On("GetUserById", ctx, uint(1)).Return(&bookings.User{Id: 1, Role: "user"}, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&caseData, nil).Once()
 
-		result, err := srv.GetDetail(ctx, 123)
+		result, err := srv.GetDetail(ctx, 1, bookingCode)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin see other user booking", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(9)).Return(&bookings.User{Id: 9, Role: "admin"}, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&caseData, nil).Once()
+
+		result, err := srv.GetDetail(ctx, 9, bookingCode)
 
 		assert.NoError(t, err)
 		assert.Equal(t, &caseData, result)
@@ -163,7 +296,8 @@ func TestBookingServiceGetDetail(t *testing.T) {
 
 func TestBookingServiceCreate(t *testing.T) {
 	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
 	ctx := context.Background()
 
 	data := bookings.Booking{
@@ -183,7 +317,7 @@ func TestBookingServiceCreate(t *testing.T) {
 				Greeting:       "mr",
 				Name:           "maman",
 				Nationality:    "indonesia",
-				DOB:            time.Now(),
+				DOB:            time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC),
 			},
 		},
 		Payment: bookings.Payment{
@@ -316,7 +450,7 @@ func TestBookingServiceCreate(t *testing.T) {
 			Greeting:       "mr",
 			Name:           "maman",
 			Nationality:    "indonesia",
-			DOB:            time.Now(),
+			DOB:            time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC),
 		})
 		result, err := srv.Create(ctx, caseData)
 
@@ -367,542 +501,2099 @@ func TestBookingServiceCreate(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("tour started", func(t *testing.T) {
+	t.Run("tour not published", func(t *testing.T) {
 		caseData := data
 		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(&bookings.User{Role: "User"}, nil).Once()
-		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Start: time.Now().Add(-1 * time.Hour)}, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Status: "archived", Start: time.Now().Add(time.Hour)}, nil).Once()
 
 		result, err := srv.Create(ctx, caseData)
 
 		assert.ErrorContains(t, err, "unprocessable")
-		assert.ErrorContains(t, err, "tour")
-		assert.ErrorContains(t, err, "started")
+		assert.ErrorContains(t, err, "not open for booking")
 		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("error from repository", func(t *testing.T) {
+	t.Run("tour started", func(t *testing.T) {
 		caseData := data
 		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(&bookings.User{Role: "User"}, nil).Once()
-		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Start: time.Now().Add(time.Hour)}, nil).Once()
-		repo.On("Create", ctx, caseData).Return(nil, errors.New("some error from repository")).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Status: "published", Departures: []bookings.Departure{{Id: 2, Start: time.Now().Add(-1 * time.Hour), Available: 1}}}, nil).Once()
 
 		result, err := srv.Create(ctx, caseData)
 
-		assert.ErrorContains(t, err, "some error from repository")
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "tour")
+		assert.ErrorContains(t, err, "started")
 		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("departure not picked", func(t *testing.T) {
 		caseData := data
 		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(&bookings.User{Role: "User"}, nil).Once()
-		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Start: time.Now().Add(time.Hour)}, nil).Once()
-		repo.On("Create", ctx, caseData).Return(&caseData, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Status: "published", Departures: []bookings.Departure{
+			{Id: 2, Start: time.Now().Add(time.Hour), Available: 1},
+			{Id: 3, Start: time.Now().Add(24 * time.Hour), Available: 1},
+		}}, nil).Once()
 
 		result, err := srv.Create(ctx, caseData)
 
-		assert.NoError(t, err)
-		assert.Equal(t, &caseData, result)
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "departure")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
-}
-
-func TestBookingServiceUpdateBookingStatus(t *testing.T) {
-	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
-	ctx := context.Background()
-
-	t.Run("invalid booking code", func(t *testing.T) {
-		err := srv.UpdateBookingStatus(ctx, 0, "cancel")
-
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "invalid booking code")
-	})
 
-	t.Run("booking not found", func(t *testing.T) {
-		repo.On("GetDetail", ctx, 123).Return(nil, errors.New("not found: booking not found")).Once()
+	t.Run("departure not found", func(t *testing.T) {
+		caseData := data
+		caseData.Departure.Id = 9
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(&bookings.User{Role: "User"}, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Status: "published", Departures: []bookings.Departure{{Id: 2, Start: time.Now().Add(time.Hour), Available: 1}}}, nil).Once()
 
-		err := srv.UpdateBookingStatus(ctx, 123, "cancel")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.ErrorContains(t, err, "not found")
-		assert.ErrorContains(t, err, "booking")
+		assert.ErrorContains(t, err, "departure")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("after tour ends", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Tour: bookings.Tour{Start: time.Now().Add(-24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+	repoGetUser := &bookings.User{Id: 1, Name: "maman", Role: "user"}
+	repoGetTour := &bookings.Tour{Id: 1, Price: 10000, AdminFee: 2500, Discount: 10, Status: "published", Departures: []bookings.Departure{{Id: 2, Start: time.Now().Add(time.Hour), Available: 1}}}
+	gatewayPayment := &bookings.Payment{Method: "bank_transfer", Bank: "bri", VirtualNumber: "8808123", Status: "pending"}
 
-		err := srv.UpdateBookingStatus(ctx, 123, "cancel")
-
-		assert.ErrorContains(t, err, "unprocessable")
-		assert.ErrorContains(t, err, "tour started")
+	isNewBooking := mock.MatchedBy(func(booking bookings.Booking) bool {
+		if booking.Payment.Bank != "bri" || booking.Payment.Status != "pending" || !booking.Payment.ExpiredAt.After(time.Now()) {
+			return false
+		}
 
-		repo.AssertExpectations(t)
+		return bookings.ValidCode(booking.Code) && booking.Total == 11500 && booking.User.Name == "maman" && booking.Tour.Id == 1 && booking.Departure.Id == 2
 	})
-
-	t.Run("invalid booking status", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Tour: bookings.Tour{Start: time.Now().Add(24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
-
-		err := srv.UpdateBookingStatus(ctx, 123, "pending")
-
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "invalid booking status")
-
-		repo.AssertExpectations(t)
+	isFailedCharge := mock.MatchedBy(func(transition bookings.Transition) bool {
+		return bookings.ValidCode(transition.BookingCode) && transition.From == "pending" && transition.To == "cancel" && transition.PaymentTo == "failure"
 	})
+	code := mock.AnythingOfType("string")
 
-	t.Run("cancel booking while before status isn't pending", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Status: "approved", Tour: bookings.Tour{Start: time.Now().Add(24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+	t.Run("not enough seats", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&bookings.Tour{Id: 1, Status: "published", Departures: []bookings.Departure{{Id: 2, Start: time.Now().Add(time.Hour), Available: 0}}}, nil).Once()
 
-		err := srv.UpdateBookingStatus(ctx, 123, "cancel")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.ErrorContains(t, err, "unprocessable")
-		assert.ErrorContains(t, err, "cancel")
+		assert.ErrorContains(t, err, "seats")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("request refund while booking status isn't approved", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Status: "pending", Tour: bookings.Tour{Start: time.Now().Add(24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+	t.Run("error from payment gateway", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isNewBooking).Return(nil, errors.New("some error from payment gateway")).Once()
+		repo.On("UpdateBookingStatus", ctx, isFailedCharge).Return(nil).Once()
 
-		err := srv.UpdateBookingStatus(ctx, 123, "refund")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.ErrorContains(t, err, "unprocessable")
-		assert.ErrorContains(t, err, "refund")
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("approve refund while refund not requested", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Status: "approved", Tour: bookings.Tour{Start: time.Now().Add(24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+	t.Run("error from payment gateway and repository on cancel", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isNewBooking).Return(nil, errors.New("some error from payment gateway")).Once()
+		repo.On("UpdateBookingStatus", ctx, isFailedCharge).Return(errors.New("some error from repository")).Once()
 
-		err := srv.UpdateBookingStatus(ctx, 123, "refunded")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.ErrorContains(t, err, "unprocessable")
-		assert.ErrorContains(t, err, "approve refund")
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
 	t.Run("error from repository", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Status: "approved", Tour: bookings.Tour{Start: time.Now().Add(24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
-		repo.On("UpdateBookingStatus", ctx, 123, "refund").Return(errors.New("some error from repository")).Once()
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(nil, errors.New("some error from repository")).Once()
 
-		err := srv.UpdateBookingStatus(ctx, 123, "refund")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("success", func(t *testing.T) {
-		repoGetDetail := &bookings.Booking{Status: "approved", Tour: bookings.Tour{Start: time.Now().Add(24 * time.Hour)}}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
-		repo.On("UpdateBookingStatus", ctx, 123, "refund").Return(nil).Once()
+	t.Run("error from repository on payment", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isNewBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(errors.New("some error from repository")).Once()
+		payment.On("CancelBookingPayment", code).Return(nil).Once()
+		repo.On("UpdateBookingStatus", ctx, isFailedCharge).Return(nil).Once()
 
-		err := srv.UpdateBookingStatus(ctx, 123, "refund")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.NoError(t, err)
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
-}
 
-func TestBookingServiceUpdatePaymentStatus(t *testing.T) {
-	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
-	ctx := context.Background()
+	t.Run("error from repository on payment and compensating cancel", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isNewBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(errors.New("some error from repository")).Once()
+		payment.On("CancelBookingPayment", code).Return(errors.New("some error from payment gateway")).Once()
 
-	t.Run("invalid booking code", func(t *testing.T) {
-		err := srv.UpdatePaymentStatus(ctx, 0, "cancel")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "invalid booking code")
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("error from repository", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "approved", "settlement").Return(errors.New("some error from repository")).Once()
+	t.Run("booking code taken on every attempt", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(nil, errors.New("used: booking code already exist")).Times(3)
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "settlement")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.ErrorContains(t, err, "some error from repository")
+		assert.ErrorContains(t, err, "used: booking code")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("payment settlement", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "approved", "settlement").Return(nil).Once()
+	t.Run("retry with new booking code", func(t *testing.T) {
+		caseData := data
+		var codes []string
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Run(func(args mock.Arguments) {
+			codes = append(codes, args.Get(1).(bookings.Booking).Code)
+		}).Return(nil, errors.New("used: booking code already exist")).Once()
+		repo.On("Create", ctx, isNewBooking).Run(func(args mock.Arguments) {
+			codes = append(codes, args.Get(1).(bookings.Booking).Code)
+		}).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", mock.MatchedBy(func(booking bookings.Booking) bool {
+			return len(codes) == 2 && booking.Code == codes[1]
+		})).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(nil).Once()
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "settlement")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.NoError(t, err)
+		assert.NotNil(t, result)
+		if assert.Len(t, codes, 2) {
+			assert.NotEqual(t, codes[0], codes[1])
+		}
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("payment cancel", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "cancel", "cancel").Return(nil).Once()
+	t.Run("success", func(t *testing.T) {
+		caseData := data
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("Create", ctx, isNewBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isNewBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(nil).Once()
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "cancel")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+		assert.Equal(t, *gatewayPayment, result.Payment)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("payment expire", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "cancel", "expire").Return(nil).Once()
+	t.Run("success with departure price", func(t *testing.T) {
+		caseData := data
+		caseData.Departure.Id = 3
+		repoTour := *repoGetTour
+		repoTour.Departures = []bookings.Departure{
+			{Id: 2, Start: time.Now().Add(time.Hour), Available: 1},
+			{Id: 3, Start: time.Now().Add(48 * time.Hour), Finish: time.Now().Add(96 * time.Hour), Available: 5, Price: 20000},
+		}
+
+		isDepartureBooking := mock.MatchedBy(func(booking bookings.Booking) bool {
+			return booking.Total == 20500 && booking.Departure.Id == 3 && booking.Tour.Price == 20000 && booking.Tour.Start.Equal(repoTour.Departures[1].Start)
+		})
+
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&repoTour, nil).Once()
+		repo.On("Create", ctx, isDepartureBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isDepartureBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(nil).Once()
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "expire")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("payment capture", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "pending", "capture").Return(nil).Once()
+	t.Run("voucher not found", func(t *testing.T) {
+		caseData := data
+		caseData.Voucher.Code = "nope"
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("GetVoucher", ctx, "NOPE", uint(caseData.User.Id)).Return(nil, errors.New("not found: voucher not found")).Once()
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "capture")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.NoError(t, err)
+		assert.ErrorContains(t, err, "not found: voucher not found")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("payment deny", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "pending", "deny").Return(nil).Once()
+	t.Run("voucher not stackable with tour discount", func(t *testing.T) {
+		caseData := data
+		caseData.Voucher.Code = "HOLIDAY"
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("GetVoucher", ctx, "HOLIDAY", uint(caseData.User.Id)).Return(&bookings.Voucher{Id: 4, Code: "HOLIDAY", Type: bookings.VoucherFixed, Value: 1000, StartAt: time.Now().Add(-time.Hour)}, nil).Once()
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "deny")
+		result, err := srv.Create(ctx, caseData)
 
-		assert.NoError(t, err)
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "combined")
+		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("payment pending", func(t *testing.T) {
-		repo.On("UpdatePaymentStatus", ctx, 123, "pending", "pending").Return(nil).Once()
+	t.Run("success with voucher", func(t *testing.T) {
+		caseData := data
+		caseData.Voucher.Code = "holiday"
+
+		isVoucherBooking := mock.MatchedBy(func(booking bookings.Booking) bool {
+			return booking.Total == 10500 && booking.Discount == 1000 && booking.Voucher.Id == 4 && booking.Voucher.Code == "HOLIDAY"
+		})
+
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("GetVoucher", ctx, "HOLIDAY", uint(caseData.User.Id)).Return(&bookings.Voucher{Id: 4, Code: "HOLIDAY", Type: bookings.VoucherFixed, Value: 1000, Stackable: true, StartAt: time.Now().Add(-time.Hour)}, nil).Once()
+		repo.On("Create", ctx, isVoucherBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isVoucherBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(nil).Once()
 
-		err := srv.UpdatePaymentStatus(ctx, 123, "pending")
+		result, err := srv.Create(ctx, caseData)
 
 		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("invalid payment status", func(t *testing.T) {
-		err := srv.UpdatePaymentStatus(ctx, 123, "tes")
+	t.Run("voucher covering the whole booking", func(t *testing.T) {
+		caseData := data
+		caseData.Voucher.Code = "FREE"
 
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "invalid payment status")
-	})
-}
+		freeTour := *repoGetTour
+		freeTour.AdminFee = 0
+		freeTour.Discount = 0
 
-func TestBookingServiceChangePaymentMethod(t *testing.T) {
-	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
-	ctx := context.Background()
+		isFreeBooking := mock.MatchedBy(func(booking bookings.Booking) bool {
+			return booking.Total == 1 && booking.Discount == 9999
+		})
 
-	t.Run("invalid booking code", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: "bri"}
-		result, err := srv.ChangePaymentMethod(ctx, 0, caseData)
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&freeTour, nil).Once()
+		repo.On("GetVoucher", ctx, "FREE", uint(caseData.User.Id)).Return(&bookings.Voucher{Id: 5, Code: "FREE", Type: bookings.VoucherFixed, Value: 50000, StartAt: time.Now().Add(-time.Hour)}, nil).Once()
+		repo.On("Create", ctx, isFreeBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isFreeBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(nil).Once()
 
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "invalid booking code")
-		assert.Nil(t, result)
-	})
+		result, err := srv.Create(ctx, caseData)
 
-	t.Run("empty payment method", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: ""}
-		result, err := srv.ChangePaymentMethod(ctx, 123, caseData)
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
 
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "payment method")
-		assert.ErrorContains(t, err, "empty")
-		assert.Nil(t, result)
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("booking not found", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: "bri"}
+	t.Run("voucher fully redeemed meanwhile", func(t *testing.T) {
+		caseData := data
+		caseData.Voucher.Code = "HOLIDAY"
 
-		repo.On("GetDetail", ctx, 123).Return(nil, errors.New("not found: booking not found")).Once()
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(repoGetTour, nil).Once()
+		repo.On("GetVoucher", ctx, "HOLIDAY", uint(caseData.User.Id)).Return(&bookings.Voucher{Id: 4, Code: "HOLIDAY", Type: bookings.VoucherFixed, Value: 1000, Stackable: true, Quota: 1, StartAt: time.Now().Add(-time.Hour)}, nil).Once()
+		repo.On("Create", ctx, mock.Anything).Return(nil, errors.New("unprocessable: voucher has been fully redeemed")).Once()
 
-		result, err := srv.ChangePaymentMethod(ctx, 123, caseData)
+		result, err := srv.Create(ctx, caseData)
 
-		assert.ErrorContains(t, err, "not found")
-		assert.ErrorContains(t, err, "booking")
+		assert.ErrorContains(t, err, "fully redeemed")
 		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("change payment method while booking wasn't pending", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: "bri"}
+	childPrice, infantPrice := float64(6000), float64(0)
+	familyTour := *repoGetTour
+	familyTour.ChildPrice = &childPrice
+	familyTour.InfantPrice = &infantPrice
+	familyTour.Departures = []bookings.Departure{{Id: 2, Start: time.Now().Add(48 * time.Hour), Available: 5}}
 
-		repoGetDetail := &bookings.Booking{Status: "approved"}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+	child := bookings.Detail{DocumentNumber: "456", Greeting: "miss", Name: "euis", Nationality: "indonesia", DOB: time.Now().AddDate(-5, 0, 0)}
+	infant := bookings.Detail{DocumentNumber: "789", Greeting: "mstr", Name: "asep", Nationality: "indonesia", DOB: time.Now().AddDate(-1, 0, 0)}
 
-		result, err := srv.ChangePaymentMethod(ctx, 123, caseData)
+	t.Run("children without adult", func(t *testing.T) {
+		caseData := data
+		caseData.Detail = []bookings.Detail{child}
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&familyTour, nil).Once()
+
+		result, err := srv.Create(ctx, caseData)
 
 		assert.ErrorContains(t, err, "unprocessable")
-		assert.ErrorContains(t, err, "payment method")
+		assert.ErrorContains(t, err, "adult")
 		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("change payment method while same method and payment isn't expired", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: "bri"}
+	t.Run("more infants than adults", func(t *testing.T) {
+		caseData := data
+		secondInfant := infant
+		secondInfant.DocumentNumber = "790"
+		caseData.Detail = []bookings.Detail{data.Detail[0], infant, secondInfant}
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&familyTour, nil).Once()
 
-		repoGetDetail := &bookings.Booking{
-			Status: "pending",
-			Payment: bookings.Payment{
-				Status:    "pending",
-				Bank:      "bri",
-				ExpiredAt: time.Now().Add(time.Hour),
-			},
-		}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+		result, err := srv.Create(ctx, caseData)
 
-		result, err := srv.ChangePaymentMethod(ctx, 123, caseData)
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "infant")
+		assert.Nil(t, result)
 
-		assert.NoError(t, err)
-		assert.Equal(t, &repoGetDetail.Payment, result)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("born after tour starts", func(t *testing.T) {
+		caseData := data
+		unborn := infant
+		unborn.DOB = time.Now().Add(72 * time.Hour)
+		caseData.Detail = []bookings.Detail{data.Detail[0], unborn}
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&familyTour, nil).Once()
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "date of birth")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success with passenger categories", func(t *testing.T) {
+		caseData := data
+		caseData.Detail = []bookings.Detail{data.Detail[0], child, infant}
+
+		isFamilyBooking := mock.MatchedBy(func(booking bookings.Booking) bool {
+			if len(booking.Detail) != 3 {
+				return false
+			}
+
+			adult, child, infant := booking.Detail[0], booking.Detail[1], booking.Detail[2]
+			return booking.Total == 16900 &&
+				adult.Category == bookings.PassengerAdult && adult.Price == 10000 &&
+				child.Category == bookings.PassengerChild && child.Price == 6000 &&
+				infant.Category == bookings.PassengerInfant && infant.Price == 0
+		})
+
+		repo.On("GetUserById", ctx, uint(caseData.User.Id)).Return(repoGetUser, nil).Once()
+		repo.On("GetTourById", ctx, uint(caseData.Tour.Id)).Return(&familyTour, nil).Once()
+		repo.On("Create", ctx, isFamilyBooking).Return(&caseData, nil).Once()
+		payment.On("NewBookingPayment", isFamilyBooking).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, code, *gatewayPayment).Return(nil).Once()
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+		assert.Empty(t, caseData.Detail[1].Category)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceUpdateBookingStatus(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	user := bookings.Actor{Id: 1, Source: bookings.SourceUser}
+	admin := bookings.Actor{Id: 2, Source: bookings.SourceAdmin}
+
+	repoGetDetail := func(status string, start time.Time) *bookings.Booking {
+		return &bookings.Booking{
+			Code:    bookingCode,
+			Status:  status,
+			User:    bookings.User{Id: 1},
+			Tour:    bookings.Tour{Start: start},
+			Payment: bookings.Payment{Status: "pending"},
+		}
+	}
+
+	t.Run("invalid booking code", func(t *testing.T) {
+		err := srv.UpdateBookingStatus(ctx, user, "WNDR4Q7K2X", "cancel")
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "invalid booking code")
+	})
+
+	t.Run("invalid booking status", func(t *testing.T) {
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "refund")
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "invalid booking status")
+	})
+
+	t.Run("booking not found", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(nil, errors.New("not found: booking not found")).Once()
+
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "not found")
+		assert.ErrorContains(t, err, "booking")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("booking of another user", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", time.Now().Add(24*time.Hour)), nil).Once()
+
+		err := srv.UpdateBookingStatus(ctx, bookings.Actor{Id: 3, Source: bookings.SourceUser}, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("after tour starts", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", time.Now().Add(-24*time.Hour)), nil).Once()
+
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "tour started")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("cancel booking while before status isn't pending", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", time.Now().Add(24*time.Hour)), nil).Once()
+
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "from approved to cancel")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("cancel canceled booking", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("cancel", time.Now().Add(24*time.Hour)), nil).Once()
+
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "already cancel")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from payment gateway on cancel", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", time.Now().Add(24*time.Hour)), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(errors.New("some error from payment gateway")).Once()
+
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "some error from payment gateway")
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		transition := bookings.Transition{BookingCode: bookingCode, From: "pending", To: "cancel", PaymentFrom: "pending", PaymentTo: "pending", Actor: user}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", time.Now().Add(24*time.Hour)), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Once()
+		repo.On("UpdateBookingStatus", ctx, transition).Return(errors.New("some error from repository")).Once()
+
+		err := srv.UpdateBookingStatus(ctx, user, bookingCode, "cancel")
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("admin cancel booking", func(t *testing.T) {
+		transition := bookings.Transition{BookingCode: bookingCode, From: "pending", To: "cancel", PaymentFrom: "pending", PaymentTo: "pending", Actor: admin}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", time.Now().Add(24*time.Hour)), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Once()
+		repo.On("UpdateBookingStatus", ctx, transition).Return(nil).Once()
+
+		err := srv.UpdateBookingStatus(ctx, admin, bookingCode, "cancel")
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceRequestRefund(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	transition := bookings.Transition{BookingCode: bookingCode, From: "approved", To: "refund", Actor: bookings.Actor{Id: 1, Source: bookings.SourceUser}, Note: "sick"}
+
+	repoGetDetail := func(status string, start time.Time) *bookings.Booking {
+		return &bookings.Booking{
+			Code:   bookingCode,
+			Total:  300000,
+			Status: status,
+			User:   bookings.User{Id: 1},
+			Tour:   bookings.Tour{Start: start},
+			Detail: []bookings.Detail{{Id: 1}, {Id: 2}, {Id: 3}},
+		}
+	}
+
+	t.Run("invalid booking code", func(t *testing.T) {
+		result, err := srv.RequestRefund(ctx, 1, "WNDR4Q7K2X", bookings.Refund{Reason: "sick"})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "invalid booking code")
+		assert.Nil(t, result)
+	})
+
+	t.Run("empty reason", func(t *testing.T) {
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: " "})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "reason")
+		assert.Nil(t, result)
+	})
+
+	t.Run("booking of another user", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", time.Now().Add(60*24*time.Hour)), nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 2, bookingCode, bookings.Refund{Reason: "sick"})
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("booking isn't approved", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", time.Now().Add(60*24*time.Hour)), nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick"})
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "from pending to refund")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid passenger", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", time.Now().Add(60*24*time.Hour)), nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick", Passengers: []uint{1, 1}})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "passenger")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("passenger already refunded", func(t *testing.T) {
+		booking := repoGetDetail("approved", time.Now().Add(60*24*time.Hour))
+		booking.Refunds = []bookings.Refund{{Id: 7, Passengers: []uint{1}, Status: "refunded"}}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(booking, nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick", Passengers: []uint{1}})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "already been refunded")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("remaining passengers", func(t *testing.T) {
+		caseData := bookings.Refund{BookingCode: bookingCode, Reason: "sick", Passengers: []uint{2, 3}, Percentage: 100, Amount: 200000}
+
+		booking := repoGetDetail("approved", time.Now().Add(60*24*time.Hour))
+		booking.Refunds = []bookings.Refund{{Id: 7, Passengers: []uint{1}, Status: "refunded"}}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(booking, nil).Once()
+		repo.On("CreateRefund", ctx, caseData, transition).Return(&caseData, nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("not eligible by policy", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", time.Now().Add(7*24*time.Hour)), nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick"})
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "eligible")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", time.Now().Add(60*24*time.Hour)), nil).Once()
+		repo.On("CreateRefund", ctx, bookings.Refund{BookingCode: bookingCode, Reason: "sick", Passengers: []uint{1, 2, 3}, Percentage: 100, Amount: 300000}, transition).Return(nil, errors.New("some error from repository")).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick"})
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("partial refund", func(t *testing.T) {
+		caseData := bookings.Refund{BookingCode: bookingCode, Reason: "sick", Passengers: []uint{2}, Percentage: 50, Amount: 50000}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", time.Now().Add(20*24*time.Hour)), nil).Once()
+		repo.On("CreateRefund", ctx, caseData, transition).Return(&caseData, nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick", Passengers: []uint{2}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("partial refund by seat price", func(t *testing.T) {
+		caseData := bookings.Refund{BookingCode: bookingCode, Reason: "sick", Passengers: []uint{2}, Percentage: 50, Amount: 50833}
+
+		booking := repoGetDetail("approved", time.Now().Add(20*24*time.Hour))
+		booking.Total = 305000
+		booking.Detail = []bookings.Detail{
+			{Id: 1, Category: bookings.PassengerAdult, Price: 200000},
+			{Id: 2, Category: bookings.PassengerChild, Price: 100000},
+			{Id: 3, Category: bookings.PassengerInfant, Price: 0},
+		}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(booking, nil).Once()
+		repo.On("CreateRefund", ctx, caseData, transition).Return(&caseData, nil).Once()
+
+		result, err := srv.RequestRefund(ctx, 1, bookingCode, bookings.Refund{Reason: "sick", Passengers: []uint{2}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceApproveRefund(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	admin := bookings.Actor{Id: 2, Source: bookings.SourceAdmin}
+	transition := bookings.Transition{BookingCode: bookingCode, From: "refund", To: "refunded", PaymentFrom: "settlement", PaymentTo: "settlement", Actor: admin}
+
+	repoGetDetail := func(status string, refundStatus string) *bookings.Booking {
+		return &bookings.Booking{
+			Code:    bookingCode,
+			Total:   300000,
+			Status:  status,
+			Payment: bookings.Payment{Status: "settlement"},
+			Detail:  []bookings.Detail{{Id: 1}},
+			Refunds: []bookings.Refund{{Id: 7, BookingCode: bookingCode, Reason: "sick", Passengers: []uint{1}, Amount: 100000, Status: refundStatus}},
+		}
+	}
+
+	t.Run("invalid amount", func(t *testing.T) {
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, -1)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "amount")
+		assert.Nil(t, result)
+	})
+
+	t.Run("refund not requested", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", "refunded"), nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 0)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "from approved to refunded")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("refund already being processed with another amount", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("refund", "approved"), nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 40000)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "being processed")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("retry refund already paid out", func(t *testing.T) {
+		caseData := bookings.Refund{Id: 7, BookingCode: bookingCode, Reason: "sick", Passengers: []uint{1}, Amount: 100000, Status: "refunded"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("refund", "approved"), nil).Once()
+		payment.On("RefundBookingPayment", bookingCode, uint(7), float64(100000), "sick").Return(nil).Once()
+		repo.On("CompleteRefund", ctx, caseData, transition).Return(nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("amount exceeds policy", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("refund", "requested"), nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 100001)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "exceeds")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from payment gateway", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("refund", "requested"), nil).Once()
+		repo.On("UpdateRefundStatus", ctx, uint(7), "approved", float64(100000), "").Return(nil).Once()
+		payment.On("RefundBookingPayment", bookingCode, uint(7), float64(100000), "sick").Return(errors.New("some error from payment gateway")).Once()
+		repo.On("UpdateRefundStatus", ctx, uint(7), "failed", float64(100000), "some error from payment gateway").Return(nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 0)
+
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("error from repository after refund", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("refund", "failed"), nil).Once()
+		repo.On("UpdateRefundStatus", ctx, uint(7), "approved", float64(100000), "").Return(nil).Once()
+		payment.On("RefundBookingPayment", bookingCode, uint(7), float64(100000), "sick").Return(nil).Once()
+		repo.On("CompleteRefund", ctx, mock.AnythingOfType("bookings.Refund"), transition).Return(errors.New("some error from repository")).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 0)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("partial amount", func(t *testing.T) {
+		caseData := bookings.Refund{Id: 7, BookingCode: bookingCode, Reason: "sick", Passengers: []uint{1}, Amount: 40000, Status: "refunded"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("refund", "requested"), nil).Once()
+		repo.On("UpdateRefundStatus", ctx, uint(7), "approved", float64(40000), "").Return(nil).Once()
+		payment.On("RefundBookingPayment", bookingCode, uint(7), float64(40000), "sick").Return(nil).Once()
+		repo.On("CompleteRefund", ctx, caseData, transition).Return(nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 40000)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("some passengers stay on the tour", func(t *testing.T) {
+		caseData := bookings.Refund{Id: 7, BookingCode: bookingCode, Reason: "sick", Passengers: []uint{1}, Amount: 100000, Status: "refunded"}
+		caseTransition := transition
+		caseTransition.To = "approved"
+
+		booking := repoGetDetail("refund", "requested")
+		booking.Detail = []bookings.Detail{{Id: 1}, {Id: 2}}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(booking, nil).Once()
+		repo.On("UpdateRefundStatus", ctx, uint(7), "approved", float64(100000), "").Return(nil).Once()
+		payment.On("RefundBookingPayment", bookingCode, uint(7), float64(100000), "sick").Return(nil).Once()
+		repo.On("CompleteRefund", ctx, caseData, caseTransition).Return(nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("last passengers refunded", func(t *testing.T) {
+		caseData := bookings.Refund{Id: 8, BookingCode: bookingCode, Reason: "sick", Passengers: []uint{2}, Amount: 100000, Status: "refunded"}
+
+		booking := repoGetDetail("refund", "refunded")
+		booking.Detail = []bookings.Detail{{Id: 1}, {Id: 2}}
+		booking.Refunds = append(booking.Refunds, bookings.Refund{Id: 8, BookingCode: bookingCode, Reason: "sick", Passengers: []uint{2}, Amount: 100000, Status: "requested"})
+
+		repo.On("GetDetail", ctx, bookingCode).Return(booking, nil).Once()
+		repo.On("UpdateRefundStatus", ctx, uint(8), "approved", float64(100000), "").Return(nil).Once()
+		payment.On("RefundBookingPayment", bookingCode, uint(8), float64(100000), "sick").Return(nil).Once()
+		repo.On("CompleteRefund", ctx, caseData, transition).Return(nil).Once()
+
+		result, err := srv.ApproveRefund(ctx, admin, bookingCode, 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseData, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceUpdatePaymentStatus(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	webhook := bookings.Actor{Source: bookings.SourceWebhook}
+	repoGetDetail := func(status string, paymentStatus string) *bookings.Booking {
+		return &bookings.Booking{Code: bookingCode, Status: status, Payment: bookings.Payment{Status: paymentStatus}}
+	}
+
+	t.Run("invalid booking code", func(t *testing.T) {
+		err := srv.UpdatePaymentStatus(ctx, "WNDR4Q7K2X", "cancel")
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "invalid booking code")
+	})
+
+	t.Run("booking not found", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(nil, errors.New("not found: booking not found")).Once()
+
+		err := srv.UpdatePaymentStatus(ctx, bookingCode, "settlement")
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid payment status", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", "pending"), nil).Once()
+
+		err := srv.UpdatePaymentStatus(ctx, bookingCode, "tes")
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "invalid payment status")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("transition not allowed", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", "settlement"), nil).Once()
+
+		err := srv.UpdatePaymentStatus(ctx, bookingCode, "expire")
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "from approved to cancel")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("repeated payment status", func(t *testing.T) {
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("approved", "settlement"), nil).Once()
+
+		err := srv.UpdatePaymentStatus(ctx, bookingCode, "settlement")
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		transition := bookings.Transition{BookingCode: bookingCode, From: "pending", To: "approved", PaymentFrom: "pending", PaymentTo: "settlement", Actor: webhook}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail("pending", "pending"), nil).Once()
+		repo.On("UpdatePaymentStatus", ctx, transition).Return(errors.New("some error from repository")).Once()
+
+		err := srv.UpdatePaymentStatus(ctx, bookingCode, "settlement")
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+	})
+
+	var cases = []struct {
+		name          string
+		from          string
+		paymentFrom   string
+		paymentStatus string
+		to            string
+	}{
+		{"payment settlement", "pending", "pending", "settlement", "approved"},
+		{"payment cancel", "pending", "pending", "cancel", "cancel"},
+		{"payment expire", "pending", "pending", "expire", "cancel"},
+		{"payment capture", "pending", "pending", "capture", "pending"},
+		{"payment deny", "pending", "capture", "deny", "pending"},
+		{"payment cancel after booking canceled", "cancel", "pending", "cancel", "cancel"},
+		{"payment settlement after booking canceled", "cancel", "cancel", "settlement", "approved"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			transition := bookings.Transition{BookingCode: bookingCode, From: tc.from, To: tc.to, PaymentFrom: tc.paymentFrom, PaymentTo: tc.paymentStatus, Actor: webhook}
+
+			repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(tc.from, tc.paymentFrom), nil).Once()
+			repo.On("UpdatePaymentStatus", ctx, transition).Return(nil).Once()
+
+			err := srv.UpdatePaymentStatus(ctx, bookingCode, tc.paymentStatus)
+
+			assert.NoError(t, err)
+
+			repo.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBookingServicePaymentNotification(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	data := bookings.PaymentNotification{
+		OrderId:      bookingCode,
+		StatusCode:   "200",
+		GrossAmount:  "10000.00",
+		Status:       "settlement",
+		SignatureKey: "signature",
+	}
+
+	t.Run("invalid signature", func(t *testing.T) {
+		caseData := data
+		rejected := caseData
+		rejected.RejectReason = "invalid signature key"
+
+		payment.On("VerifyNotification", caseData).Return(nil, errors.New("invalid signature key")).Once()
+		repo.On("CreatePaymentRejection", ctx, rejected).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "signature")
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error store rejection", func(t *testing.T) {
+		caseData := data
+		rejected := caseData
+		rejected.RejectReason = "invalid signature key"
+
+		payment.On("VerifyNotification", caseData).Return(nil, errors.New("invalid signature key")).Once()
+		repo.On("CreatePaymentRejection", ctx, rejected).Return(errors.New("some error from repository")).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid order id", func(t *testing.T) {
+		caseData := data
+		caseData.OrderId = "abc"
+		rejected := caseData
+		rejected.RejectReason = "invalid order id"
+
+		payment.On("VerifyNotification", caseData).Return(&caseData, nil).Once()
+		repo.On("CreatePaymentRejection", ctx, rejected).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "order id")
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("booking not found", func(t *testing.T) {
+		caseData := data
+		rejected := caseData
+		rejected.RejectReason = "booking not found"
+
+		payment.On("VerifyNotification", caseData).Return(&caseData, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(nil, errors.New("not found: booking not found")).Once()
+		repo.On("CreatePaymentRejection", ctx, rejected).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "booking not found")
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("gross amount mismatch", func(t *testing.T) {
+		caseData := data
+		canonical := caseData
+		canonical.GrossAmount = "1.00"
+		rejected := canonical
+		rejected.RejectReason = "gross amount mismatch"
+
+		payment.On("VerifyNotification", caseData).Return(&canonical, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&bookings.Booking{Code: bookingCode, Total: 10000}, nil).Once()
+		repo.On("CreatePaymentRejection", ctx, rejected).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "gross amount")
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("use canonical status", func(t *testing.T) {
+		caseData := data
+		canonical := caseData
+		canonical.Status = "capture"
+		transition := bookings.Transition{BookingCode: bookingCode, From: "pending", To: "pending", PaymentFrom: "pending", PaymentTo: "capture", Actor: bookings.Actor{Source: bookings.SourceWebhook}}
+
+		payment.On("VerifyNotification", caseData).Return(&canonical, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&bookings.Booking{Code: bookingCode, Total: 10000, Status: "pending", Payment: bookings.Payment{Status: "pending"}}, nil).Once()
+		repo.On("UpdatePaymentStatus", ctx, transition).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		caseData := data
+
+		payment.On("VerifyNotification", caseData).Return(&caseData, nil).Once()
+		transition := bookings.Transition{BookingCode: bookingCode, From: "pending", To: "approved", PaymentFrom: "pending", PaymentTo: "settlement", Actor: bookings.Actor{Source: bookings.SourceWebhook}}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(&bookings.Booking{Code: bookingCode, Total: 10000, Status: "pending", Payment: bookings.Payment{Status: "pending"}}, nil).Once()
+		repo.On("UpdatePaymentStatus", ctx, transition).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("refund confirmed", func(t *testing.T) {
+		caseData := data
+		caseData.Status = "refund"
+
+		payment.On("VerifyNotification", caseData).Return(&caseData, nil).Once()
+		transition := bookings.Transition{BookingCode: bookingCode, From: "refunded", To: "refunded", PaymentFrom: "settlement", PaymentTo: "refund", Actor: bookings.Actor{Source: bookings.SourceWebhook}}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(&bookings.Booking{Code: bookingCode, Total: 10000, Status: "refunded", Payment: bookings.Payment{Status: "settlement"}}, nil).Once()
+		repo.On("UpdatePaymentStatus", ctx, transition).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("partial refund confirmed again", func(t *testing.T) {
+		caseData := data
+		caseData.Status = "partial_refund"
+
+		payment.On("VerifyNotification", caseData).Return(&caseData, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&bookings.Booking{Code: bookingCode, Total: 10000, Status: "approved", Payment: bookings.Payment{Status: "partial_refund"}}, nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("payment failure", func(t *testing.T) {
+		caseData := data
+		caseData.Status = "failure"
+
+		payment.On("VerifyNotification", caseData).Return(&caseData, nil).Once()
+		transition := bookings.Transition{BookingCode: bookingCode, From: "pending", To: "cancel", PaymentFrom: "pending", PaymentTo: "failure", Actor: bookings.Actor{Source: bookings.SourceWebhook}}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(&bookings.Booking{Code: bookingCode, Total: 10000, Status: "pending", Payment: bookings.Payment{Status: "pending"}}, nil).Once()
+		repo.On("UpdatePaymentStatus", ctx, transition).Return(nil).Once()
+
+		err := srv.PaymentNotification(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		payment.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceChangePaymentMethod(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+	owner := bookings.Actor{Id: 1, Source: bookings.SourceUser}
+
+	t.Run("invalid booking code", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bri"}
+		result, err := srv.ChangePaymentMethod(ctx, owner, "WNDR4Q7K2X", caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "invalid booking code")
+		assert.Nil(t, result)
+	})
+
+	t.Run("empty payment method", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: ""}
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "payment method")
+		assert.ErrorContains(t, err, "empty")
+		assert.Nil(t, result)
+	})
+
+	t.Run("booking not found", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bri"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(nil, errors.New("not found: booking not found")).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "not found")
+		assert.ErrorContains(t, err, "booking")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("booking of another user", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bri"}
+
+		repoGetDetail := &bookings.Booking{Status: "pending", User: bookings.User{Id: 2}, Payment: bookings.Payment{Status: "pending", Bank: "bca"}}
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail, nil).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "not found: booking not found")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("change payment method while booking wasn't pending", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bri"}
+
+		repoGetDetail := &bookings.Booking{Status: "approved", User: bookings.User{Id: 1}}
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail, nil).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "unprocessable")
+		assert.ErrorContains(t, err, "payment method")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("change payment method while same method and payment isn't expired", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bri"}
+
+		repoGetDetail := &bookings.Booking{
+			Status: "pending",
+			User:   bookings.User{Id: 1},
+			Payment: bookings.Payment{
+				Status:    "pending",
+				Bank:      "bri",
+				ExpiredAt: time.Now().Add(time.Hour),
+			},
+		}
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail, nil).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &repoGetDetail.Payment, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin changing the payment of any booking", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bri"}
+
+		repoGetDetail := &bookings.Booking{
+			Status: "pending",
+			User:   bookings.User{Id: 2},
+			Payment: bookings.Payment{
+				Status:    "pending",
+				Bank:      "bri",
+				ExpiredAt: time.Now().Add(time.Hour),
+			},
+		}
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail, nil).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, bookings.Actor{Id: 1, Source: bookings.SourceAdmin}, bookingCode, caseData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &repoGetDetail.Payment, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	repoGetDetail := func() *bookings.Booking {
+		return &bookings.Booking{
+			Code:   bookingCode,
+			Status: "pending",
+			User:   bookings.User{Id: 1},
+			Payment: bookings.Payment{
+				Status:    "pending",
+				Bank:      "bri",
+				ExpiredAt: time.Now(),
+			},
+		}
+	}
+	gatewayPayment := &bookings.Payment{Method: "bank_transfer", Bank: "bni", VirtualNumber: "8808123", Status: "pending"}
+	isNewPayment := mock.MatchedBy(func(booking bookings.Booking) bool {
+		return booking.Code == bookingCode && booking.Payment.Bank == "bni"
+	})
+	isOldPayment := mock.MatchedBy(func(booking bookings.Booking) bool {
+		return booking.Code == bookingCode && booking.Payment == bookings.Payment{Bank: "bri"}
+	})
+	restoredPayment := &bookings.Payment{Method: "bank_transfer", Bank: "bri", VirtualNumber: "26215123", Status: "pending"}
+	paymentRetryDelay = 0
+
+	t.Run("error from payment gateway on cancel", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bni"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(errors.New("some error from payment gateway")).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("error from payment gateway on charge", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bni"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Once()
+		payment.On("NewBookingPayment", isNewPayment).Return(nil, errors.New("some error from payment gateway")).Times(3)
+		payment.On("NewBookingPayment", isOldPayment).Return(restoredPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, bookingCode, *restoredPayment).Return(nil).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("error from payment gateway on charge and restore", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bni"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Once()
+		payment.On("NewBookingPayment", isNewPayment).Return(nil, errors.New("some error from payment gateway")).Times(3)
+		payment.On("NewBookingPayment", isOldPayment).Return(nil, errors.New("bank is offline")).Times(3)
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.ErrorContains(t, err, "bank is offline")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bni"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Twice()
+		payment.On("NewBookingPayment", isNewPayment).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, bookingCode, *gatewayPayment).Return(errors.New("some error from repository")).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("error from repository and compensating cancel", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bni"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Once()
+		payment.On("NewBookingPayment", isNewPayment).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, bookingCode, *gatewayPayment).Return(errors.New("some error from repository")).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(errors.New("some error from payment gateway")).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("success after retry", func(t *testing.T) {
+		caseData := bookings.Payment{Bank: "bni"}
+
+		repo.On("GetDetail", ctx, bookingCode).Return(repoGetDetail(), nil).Once()
+		payment.On("CancelBookingPayment", bookingCode).Return(nil).Once()
+		payment.On("NewBookingPayment", isNewPayment).Return(nil, errors.New("some error from payment gateway")).Once()
+		payment.On("NewBookingPayment", isNewPayment).Return(gatewayPayment, nil).Once()
+		repo.On("ChangePaymentMethod", ctx, bookingCode, *gatewayPayment).Return(nil).Once()
+
+		result, err := srv.ChangePaymentMethod(ctx, owner, bookingCode, caseData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, gatewayPayment, result)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceExpirePendingBookings(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	before := mock.AnythingOfType("time.Time")
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetExpiredPending", ctx, before, 8, 100).Return(nil, errors.New("some error from repository")).Once()
+
+		total, err := srv.ExpirePendingBookings(ctx)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("nothing to expire", func(t *testing.T) {
+		repo.On("GetExpiredPending", ctx, before, 8, 100).Return(nil, nil).Once()
+
+		total, err := srv.ExpirePendingBookings(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("skip booking when gateway cancel fails", func(t *testing.T) {
+		repo.On("GetExpiredPending", ctx, before, 8, 100).Return([]bookings.Booking{{Code: "1", Payment: bookings.Payment{CancelAttempts: 2}}, {Code: "2"}}, nil).Once()
+		payment.On("CancelBookingPayment", "1").Return(errors.New("some error from payment gateway")).Once()
+		repo.On("PostponeExpiry", ctx, "1", 3, mock.MatchedBy(func(retryAt time.Time) bool {
+			return retryAt.After(time.Now().Add(3 * time.Minute))
+		})).Return(nil).Once()
+		payment.On("CancelBookingPayment", "2").Return(nil).Once()
+		repo.On("ExpireBooking", ctx, "2", before).Return(true, nil).Once()
+
+		total, err := srv.ExpirePendingBookings(ctx)
+
+		assert.ErrorContains(t, err, "booking 1")
+		assert.ErrorContains(t, err, "some error from payment gateway")
+		assert.Equal(t, 1, total)
 
 		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
 	})
 
-	t.Run("error from repository", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: "bri"}
+	t.Run("payment unknown to gateway", func(t *testing.T) {
+		repo.On("GetExpiredPending", ctx, before, 8, 100).Return([]bookings.Booking{{Code: "1"}}, nil).Once()
+		payment.On("CancelBookingPayment", "1").Return(errors.New("not found: transaction doesn't exist")).Once()
+		repo.On("ExpireBooking", ctx, "1", before).Return(true, nil).Once()
 
-		repoGetDetail := &bookings.Booking{
-			Status: "pending",
-			Payment: bookings.Payment{
-				Status:    "pending",
-				Bank:      "bri",
-				ExpiredAt: time.Now(),
-			},
-		}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+		total, err := srv.ExpirePendingBookings(ctx)
 
-		repoChangePaymentMethod := bookings.Booking{
-			Status:  "pending",
-			Payment: caseData,
-		}
-		repo.On("ChangePaymentMethod", ctx, 123, repoChangePaymentMethod).Return(nil, errors.New("some error from repository")).Once()
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
 
-		result, err := srv.ChangePaymentMethod(ctx, 123, caseData)
+	t.Run("error from repository on expire", func(t *testing.T) {
+		repo.On("GetExpiredPending", ctx, before, 8, 100).Return([]bookings.Booking{{Code: "1"}}, nil).Once()
+		payment.On("CancelBookingPayment", "1").Return(nil).Once()
+		repo.On("ExpireBooking", ctx, "1", before).Return(false, errors.New("some error from repository")).Once()
+
+		total, err := srv.ExpirePendingBookings(ctx)
 
 		assert.ErrorContains(t, err, "some error from repository")
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+
+	t.Run("booking expired by another instance", func(t *testing.T) {
+		repo.On("GetExpiredPending", ctx, before, 8, 100).Return([]bookings.Booking{{Code: "1"}, {Code: "2"}}, nil).Once()
+		payment.On("CancelBookingPayment", "1").Return(nil).Once()
+		payment.On("CancelBookingPayment", "2").Return(nil).Once()
+		repo.On("ExpireBooking", ctx, "1", before).Return(false, nil).Once()
+		repo.On("ExpireBooking", ctx, "2", before).Return(true, nil).Once()
+
+		total, err := srv.ExpirePendingBookings(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+
+		repo.AssertExpectations(t)
+		payment.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceDocuments(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	booking := bookings.Booking{
+		Code:     bookingCode,
+		Total:    190000,
+		Status:   bookings.StatusApproved,
+		BookedAt: time.Now(),
+		User:     bookings.User{Id: 2, Name: "Maman", Email: "maman@example.com"},
+		Tour: bookings.Tour{
+			Title:     "Bali Trip",
+			Price:     100000,
+			Discount:  10,
+			AdminFee:  10000,
+			Start:     time.Now().Add(24 * time.Hour),
+			Finish:    time.Now().Add(72 * time.Hour),
+			Airline:   bookings.Airline{Name: "Garuda"},
+			Itinerary: []bookings.Itinerary{{Location: "Kuta", Description: "Beach day"}},
+		},
+		Detail:  []bookings.Detail{{Greeting: "Mr", Name: "Maman", Nationality: "Indonesia", DocumentNumber: "123", DOB: time.Date(1995, 5, 17, 0, 0, 0, 0, time.UTC)}},
+		Payment: bookings.Payment{Method: "bank_transfer", Bank: "bca", Status: bookings.PaymentSettlement, PaidAt: time.Now()},
+	}
+
+	t.Run("booking of another user", func(t *testing.T) {
+		repo.On("GetUserById", ctx, uint(3)).Return(&bookings.User{Id: 3, Role: "user"}, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&booking, nil).Once()
+
+		result, err := srv.Invoice(ctx, 3, bookingCode)
+
+		assert.ErrorContains(t, err, "not found: ")
 		assert.Nil(t, result)
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("success", func(t *testing.T) {
-		caseData := bookings.Payment{Bank: "bri"}
+	t.Run("unpaid booking", func(t *testing.T) {
+		var pending = booking
+		pending.Status = bookings.StatusPending
 
-		repoGetDetail := &bookings.Booking{
-			Status: "pending",
-			Payment: bookings.Payment{
-				Status:    "pending",
-				Bank:      "bri",
-				ExpiredAt: time.Now(),
-			},
-		}
-		repo.On("GetDetail", ctx, 123).Return(repoGetDetail, nil).Once()
+		repo.On("GetUserById", ctx, uint(2)).Return(&bookings.User{Id: 2, Role: "user"}, nil).Twice()
+		repo.On("GetDetail", ctx, bookingCode).Return(&pending, nil).Twice()
 
-		repoChangePaymentMethod := bookings.Booking{
-			Status:  "pending",
-			Payment: caseData,
-		}
-		repo.On("ChangePaymentMethod", ctx, 123, repoChangePaymentMethod).Return(&caseData, nil).Once()
+		_, err := srv.Invoice(ctx, 2, bookingCode)
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		_, err = srv.Ticket(ctx, 2, bookingCode)
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("no ticket for refunded booking", func(t *testing.T) {
+		var refunded = booking
+		refunded.Status = bookings.StatusRefunded
+
+		repo.On("GetUserById", ctx, uint(2)).Return(&bookings.User{Id: 2, Role: "user"}, nil).Twice()
+		repo.On("GetDetail", ctx, bookingCode).Return(&refunded, nil).Twice()
+
+		result, err := srv.Invoice(ctx, 2, bookingCode)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, result)
+
+		_, err = srv.Ticket(ctx, 2, bookingCode)
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin", func(t *testing.T) {
+		repo.On("GetUserById", ctx, uint(1)).Return(&bookings.User{Id: 1, Role: "admin"}, nil).Twice()
+		repo.On("GetDetail", ctx, bookingCode).Return(&booking, nil).Twice()
 
-		result, err := srv.ChangePaymentMethod(ctx, 123, caseData)
+		invoice, err := srv.Invoice(ctx, 1, bookingCode)
+		assert.NoError(t, err)
+		assert.True(t, strings.HasPrefix(string(invoice), "%PDF-"))
 
+		ticket, err := srv.Ticket(ctx, 1, bookingCode)
 		assert.NoError(t, err)
-		assert.Equal(t, caseData.Bank, result.Bank)
+		assert.True(t, strings.HasPrefix(string(ticket), "%PDF-"))
 
 		repo.AssertExpectations(t)
 	})
 }
 
-func TestBookingServiceExport(t *testing.T) {
+type failingMailer struct{}
+
+func (failingMailer) Send(ctx context.Context, msg mail.Message) error {
+	return errors.New("some error from mailer")
+}
+
+func TestBookingServiceSendNotifications(t *testing.T) {
 	repo := mocks.NewRepository(t)
-	srv := NewBookingService(repo)
-	// ctx := context.Background()
+	payment := paymentMocks.NewGateway(t)
+	mailer := mail.NewMemory()
+	srv := NewBookingService(repo, payment, refundConfig, mailer)
+	ctx := context.Background()
 
-	e := echo.New()
-	req := httptest.NewRequest(http.MethodGet, "/", nil)
-	rec := httptest.NewRecorder()
-	c := e.NewContext(req, rec)
+	before := mock.AnythingOfType("time.Time")
+	booking := bookings.Booking{
+		Code:   bookingCode,
+		Total:  1250000,
+		Status: bookings.StatusPending,
+		User:   bookings.User{Name: "Maman", Email: "maman@example.com"},
+		Tour: bookings.Tour{
+			Title:     "Bali Trip",
+			Start:     time.Date(2030, 1, 10, 0, 0, 0, 0, time.UTC),
+			Finish:    time.Date(2030, 1, 12, 0, 0, 0, 0, time.UTC),
+			Itinerary: []bookings.Itinerary{{Location: "Kuta", Description: "Beach day"}},
+		},
+		Detail:  []bookings.Detail{{Greeting: "Mr", Name: "Maman", Nationality: "Indonesia", DocumentNumber: "123"}},
+		Payment: bookings.Payment{Method: "bank_transfer", Bank: "bca", VirtualNumber: "8800123", Status: bookings.PaymentPending, ExpiredAt: time.Date(2029, 12, 1, 10, 0, 0, 0, time.UTC)},
+	}
 
-	t.Run("Error from repository", func(t *testing.T) {
-		repo.On("Export").Return(nil, errors.New("some error from repository")).Once()
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetDueNotifications", ctx, before, 50).Return(nil, errors.New("some error from repository")).Once()
 
-		err := srv.Export(c, "pdf")
+		total, err := srv.SendNotifications(ctx)
 
 		assert.ErrorContains(t, err, "some error from repository")
+		assert.Equal(t, 0, total)
 
 		repo.AssertExpectations(t)
 	})
-	t.Run("Export PDF Succes Case", func(t *testing.T) {
-		data := []bookings.Booking{
-			{
-				Code:   123,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
-			{
-				Code:   234,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
-		}
 
-		repo.On("Export").Return(data, nil).Once()
+	t.Run("claimed by another instance", func(t *testing.T) {
+		var notification = bookings.Notification{Id: 1, BookingCode: bookingCode, Event: bookings.EventCreated, Status: bookings.NotificationPending}
 
-		repo.On("ExportFilePDF", c, data).Return(nil).Once()
+		repo.On("GetDueNotifications", ctx, before, 50).Return([]bookings.Notification{notification}, nil).Once()
+		repo.On("ClaimNotification", ctx, notification, before).Return(false, nil).Once()
 
-		err := srv.Export(c, "pdf")
+		total, err := srv.SendNotifications(ctx)
 
 		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Empty(t, mailer.Messages())
 
 		repo.AssertExpectations(t)
 	})
-	t.Run("Export Excel Succes Case", func(t *testing.T) {
-		data := []bookings.Booking{
-			{
-				Code:   123,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
-			{
-				Code:   234,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
+
+	t.Run("payment reminder", func(t *testing.T) {
+		var notification = bookings.Notification{Id: 1, BookingCode: bookingCode, Event: bookings.EventCreated, Status: bookings.NotificationPending}
+
+		repo.On("GetDueNotifications", ctx, before, 50).Return([]bookings.Notification{notification}, nil).Once()
+		repo.On("ClaimNotification", ctx, notification, before).Return(true, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&booking, nil).Once()
+		repo.On("UpdateNotification", ctx, mock.MatchedBy(func(data bookings.Notification) bool {
+			return data.Id == 1 && data.Status == bookings.NotificationSent && data.Attempts == 1 && !data.SentAt.IsZero()
+		})).Return(nil).Once()
+
+		total, err := srv.SendNotifications(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+
+		messages := mailer.Messages()
+		if assert.Len(t, messages, 1) {
+			assert.Equal(t, "maman@example.com", messages[0].To)
+			assert.Equal(t, "Complete the payment for booking "+bookingCode, messages[0].Subject)
+			assert.Contains(t, messages[0].Body, "VA number    : 8800123")
+			assert.Contains(t, messages[0].Body, "Pay before   : 01 Dec 2029 10:00 UTC")
+			assert.Contains(t, messages[0].Body, "Rp 1.250.000")
+			assert.Contains(t, messages[0].Body, "1. Mr Maman (Indonesia, 123)")
+			assert.Contains(t, messages[0].Body, "Day 1 - Kuta: Beach day")
 		}
 
-		repo.On("Export").Return(data, nil).Once()
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("stale payment reminder is skipped", func(t *testing.T) {
+		var notification = bookings.Notification{Id: 2, BookingCode: bookingCode, Event: bookings.EventCreated, Status: bookings.NotificationPending}
+		var paid = booking
+		paid.Status = bookings.StatusApproved
 
-		repo.On("ExportFileExcel", c, data).Return(nil).Once()
+		repo.On("GetDueNotifications", ctx, before, 50).Return([]bookings.Notification{notification}, nil).Once()
+		repo.On("ClaimNotification", ctx, notification, before).Return(true, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&paid, nil).Once()
+		repo.On("UpdateNotification", ctx, mock.MatchedBy(func(data bookings.Notification) bool {
+			return data.Id == 2 && data.Status == bookings.NotificationSkipped
+		})).Return(nil).Once()
 
-		err := srv.Export(c, "xlsx")
+		total, err := srv.SendNotifications(ctx)
 
 		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+		assert.Len(t, mailer.Messages(), 1)
 
 		repo.AssertExpectations(t)
 	})
-	t.Run("Export CSV Succes Case", func(t *testing.T) {
-		data := []bookings.Booking{
-			{
-				Code:   123,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
-			{
-				Code:   234,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
+
+	t.Run("payment reminder before the booking is charged", func(t *testing.T) {
+		var notification = bookings.Notification{Id: 5, BookingCode: bookingCode, Event: bookings.EventCreated, Status: bookings.NotificationPending}
+		var uncharged = booking
+		uncharged.Payment = bookings.Payment{Bank: "bca", Status: bookings.PaymentPending}
+
+		repo.On("GetDueNotifications", ctx, before, 50).Return([]bookings.Notification{notification}, nil).Once()
+		repo.On("ClaimNotification", ctx, notification, before).Return(true, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&uncharged, nil).Once()
+		repo.On("UpdateNotification", ctx, mock.MatchedBy(func(data bookings.Notification) bool {
+			return data.Id == 5 && data.Status == bookings.NotificationPending && data.Attempts == 1 && data.LastError == "booking hasn't been charged yet"
+		})).Return(nil).Once()
+
+		total, err := srv.SendNotifications(ctx)
+
+		assert.ErrorContains(t, err, "notification 5")
+		assert.Equal(t, 0, total)
+		assert.Len(t, mailer.Messages(), 1)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed email is retried", func(t *testing.T) {
+		var srv = NewBookingService(repo, payment, refundConfig, failingMailer{})
+		var notification = bookings.Notification{Id: 3, BookingCode: bookingCode, Event: bookings.EventPaid, Status: bookings.NotificationPending, Attempts: 1}
+
+		repo.On("GetDueNotifications", ctx, before, 50).Return([]bookings.Notification{notification}, nil).Once()
+		repo.On("ClaimNotification", ctx, notification, before).Return(true, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&booking, nil).Once()
+		repo.On("UpdateNotification", ctx, mock.MatchedBy(func(data bookings.Notification) bool {
+			return data.Status == bookings.NotificationPending && data.Attempts == 2 && data.LastError == "some error from mailer" && data.NextAttemptAt.After(time.Now().Add(time.Minute))
+		})).Return(nil).Once()
+
+		total, err := srv.SendNotifications(ctx)
+
+		assert.ErrorContains(t, err, "notification 3")
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed email is given up", func(t *testing.T) {
+		var srv = NewBookingService(repo, payment, refundConfig, failingMailer{})
+		var notification = bookings.Notification{Id: 4, BookingCode: bookingCode, Event: bookings.EventRefunded, Status: bookings.NotificationPending, Attempts: notificationAttempts - 1}
+
+		repo.On("GetDueNotifications", ctx, before, 50).Return([]bookings.Notification{notification}, nil).Once()
+		repo.On("ClaimNotification", ctx, notification, before).Return(true, nil).Once()
+		repo.On("GetDetail", ctx, bookingCode).Return(&booking, nil).Once()
+		repo.On("UpdateNotification", ctx, mock.MatchedBy(func(data bookings.Notification) bool {
+			return data.Status == bookings.NotificationFailed && data.Attempts == notificationAttempts
+		})).Return(nil).Once()
+
+		_, err := srv.SendNotifications(ctx)
+
+		assert.ErrorContains(t, err, "some error from mailer")
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestRenderNotification(t *testing.T) {
+	var booking = bookings.Booking{
+		Code:    bookingCode,
+		User:    bookings.User{Email: "maman@example.com"},
+		Tour:    bookings.Tour{Title: "Bali Trip"},
+		Refunds: []bookings.Refund{{Amount: 500000, Passengers: []uint{1, 2}}},
+	}
+
+	for _, event := range []string{bookings.EventCreated, bookings.EventPaid, bookings.EventExpired, bookings.EventCancelled, bookings.EventRefunded} {
+		msg, err := renderNotification(event, booking)
+
+		assert.NoError(t, err, event)
+		assert.Contains(t, msg.Subject, bookingCode, event)
+		assert.Contains(t, msg.Body, "Bali Trip", event)
+	}
+
+	msg, err := renderNotification(bookings.EventRefunded, booking)
+	assert.NoError(t, err)
+	assert.Contains(t, msg.Body, "Refund amount : Rp 500.000")
+
+	_, err = renderNotification("unknown", booking)
+	assert.Error(t, err)
+}
+
+func TestBookingServiceExport(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	data := []bookings.Booking{
+		{
+			Code:     bookingCode,
+			Total:    18000,
+			Discount: 1000,
+			Status:   "approved",
+			BookedAt: time.Date(2023, 12, 1, 8, 30, 0, 0, time.UTC),
+			User:     bookings.User{Id: 1, Name: "maman"},
+			Tour: bookings.Tour{
+				Id:       1,
+				Title:    "bali",
+				Price:    10000,
+				AdminFee: 1000,
+				Discount: 10,
+				Start:    time.Date(2023, 12, 10, 0, 0, 0, 0, time.UTC),
+				Finish:   time.Date(2023, 12, 13, 0, 0, 0, 0, time.UTC),
+				Location: bookings.Location{Id: 1, Name: "indonesia"},
 			},
-		}
+			Voucher: bookings.Voucher{Id: 4, Code: "HOLIDAY"},
+			Detail:  []bookings.Detail{{Name: "maman", Category: "adult", Price: 10000}, {Name: "ujang", Category: "adult", Price: 10000}},
+			Payment: bookings.Payment{Method: "bank_transfer", Bank: "bca", PaidAt: time.Date(2023, 12, 1, 9, 0, 0, 0, time.UTC)},
+		},
+		{
+			Code:   "WNDR4Q7K3T",
+			Total:  10000,
+			Status: "pending",
+			User:   bookings.User{Id: 1, Name: "maman"},
+			Tour:   bookings.Tour{Id: 1, Title: "bali"},
+		},
+	}
+
+	t.Run("invalid filter", func(t *testing.T) {
+		err := srv.Export(ctx, io.Discard, exporter.CSV, filters.Booking{Status: "paid"})
+
+		assert.ErrorContains(t, err, "validate: ")
+
+		err = srv.Export(ctx, io.Discard, exporter.CSV, filters.Booking{BookedStart: time.Now(), BookedEnd: time.Now().Add(-time.Hour)})
+
+		assert.ErrorContains(t, err, "validate: ")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("Export", ctx, filters.Booking{}).Return(nil, errors.New("some error from repository")).Once()
+
+		var buf bytes.Buffer
+		err := srv.Export(ctx, &buf, exporter.PDF, filters.Booking{})
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Zero(t, buf.Len())
 
-		repo.On("Export").Return(data, nil).Once()
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unsupported file type", func(t *testing.T) {
+		repo.On("Export", ctx, filters.Booking{}).Return(data, nil).Once()
+
+		err := srv.Export(ctx, io.Discard, exporter.Format("doc"), filters.Booking{})
+
+		assert.ErrorContains(t, err, "unsupported file type")
+
+		repo.AssertExpectations(t)
+	})
 
-		repo.On("ExportFileCsv", c, data).Return(nil).Once()
+	t.Run("csv", func(t *testing.T) {
+		var flt = filters.Booking{Status: "approved", LocationId: 1, PaymentMethod: "bca"}
+		repo.On("Export", ctx, flt).Return(data[:1], nil).Once()
 
-		err := srv.Export(c, "csv")
+		var buf bytes.Buffer
+		err := srv.Export(ctx, &buf, exporter.CSV, flt)
 
 		assert.NoError(t, err)
 
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if assert.Len(t, lines, 2) {
+			assert.Equal(t, "Booking Code,Name,Tour Package,Location,Duration,Passengers,Price,Discount,Voucher Code,Voucher Discount,Admin Fee,Total,Status,Payment Method,Bank,Booked At,Paid At", lines[0])
+			assert.Equal(t, bookingCode+",maman,bali,indonesia,3,2,10000,2000,HOLIDAY,1000,1000,18000,approved,bank_transfer,bca,2023-12-01 08:30:00,2023-12-01 09:00:00", lines[1])
+		}
+
 		repo.AssertExpectations(t)
 	})
-	t.Run("Error export file Case", func(t *testing.T) {
-		data := []bookings.Booking{
-			{
-				Code:   123,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
-			{
-				Code:   234,
-				Total:  10000,
-				Status: "pending",
-				User: bookings.User{
-					Id: 1,
-				},
-				Tour: bookings.Tour{
-					Id: 1,
-				},
-			},
+
+	t.Run("excel and pdf", func(t *testing.T) {
+		for _, format := range []exporter.Format{exporter.Excel, exporter.PDF} {
+			repo.On("Export", ctx, filters.Booking{}).Return(data, nil).Once()
+
+			var buf bytes.Buffer
+			err := srv.Export(ctx, &buf, format, filters.Booking{})
+
+			assert.NoError(t, err)
+			assert.NotZero(t, buf.Len())
 		}
 
-		repo.On("Export").Return(data, nil).Once()
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("upload to cloud", func(t *testing.T) {
+		repo.On("Export", ctx, filters.Booking{}).Return(data, nil).Once()
+		repo.On("UploadExport", ctx, mock.AnythingOfType("*bytes.Buffer")).Return("https://cloud/exports/transactions.csv", nil).Once()
 
-		err := srv.Export(c, "doc")
+		url, err := srv.ExportToCloud(ctx, exporter.CSV, filters.Booking{})
 
-		assert.ErrorContains(t, err, "unsupported file type")
+		assert.NoError(t, err)
+		assert.Equal(t, "https://cloud/exports/transactions.csv", url)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from cloud", func(t *testing.T) {
+		repo.On("Export", ctx, filters.Booking{}).Return(data, nil).Once()
+		repo.On("UploadExport", ctx, mock.AnythingOfType("*bytes.Buffer")).Return("", errors.New("some error from cloud")).Once()
+
+		_, err := srv.ExportToCloud(ctx, exporter.CSV, filters.Booking{})
+
+		assert.ErrorContains(t, err, "some error from cloud")
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestBookingServiceExportSchedule(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	payment := paymentMocks.NewGateway(t)
+	srv := NewBookingService(repo, payment, refundConfig, mail.NewMemory())
+	ctx := context.Background()
+
+	t.Run("invalid frequency", func(t *testing.T) {
+		_, err := srv.CreateExportSchedule(ctx, bookings.ExportSchedule{Frequency: "yearly", Format: exporter.CSV})
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		_, err := srv.CreateExportSchedule(ctx, bookings.ExportSchedule{Frequency: bookings.FrequencyDaily, Format: "doc"})
+
+		assert.ErrorContains(t, err, "validate: unsupported file type")
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		_, err := srv.CreateExportSchedule(ctx, bookings.ExportSchedule{Frequency: bookings.FrequencyDaily, Format: exporter.CSV, Filter: filters.Booking{Status: "paid"}})
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("create", func(t *testing.T) {
+		repo.On("CreateExportSchedule", ctx, mock.MatchedBy(func(data bookings.ExportSchedule) bool {
+			return data.Frequency == bookings.FrequencyWeekly &&
+				data.NextRunAt.Weekday() == time.Monday && data.NextRunAt.After(time.Now()) &&
+				data.Filter.BookedStart.IsZero() && data.Filter.TourId == 1
+		})).Return(&bookings.ExportSchedule{Id: 1, Frequency: bookings.FrequencyWeekly}, nil).Once()
+
+		result, err := srv.CreateExportSchedule(ctx, bookings.ExportSchedule{
+			Frequency: bookings.FrequencyWeekly,
+			Format:    exporter.Excel,
+			Filter:    filters.Booking{TourId: 1, BookedStart: time.Now()},
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, uint(1), result.Id)
+
+		repo.AssertExpectations(t)
+	})
+
+	run := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	schedule := bookings.ExportSchedule{
+		Id:        1,
+		Frequency: bookings.FrequencyDaily,
+		Format:    exporter.CSV,
+		Filter:    filters.Booking{Status: "approved"},
+		NextRunAt: run,
+	}
+	period := filters.Booking{
+		Status:      "approved",
+		BookedStart: time.Date(2024, 1, 31, 0, 0, 0, 0, time.UTC),
+		BookedEnd:   run.Add(-time.Millisecond),
+	}
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetDueExportSchedules", ctx, mock.AnythingOfType("time.Time"), exportBatchSize).Return(nil, errors.New("some error from repository")).Once()
+
+		total, err := srv.RunExportSchedules(ctx)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("claimed by another instance", func(t *testing.T) {
+		repo.On("GetDueExportSchedules", ctx, mock.AnythingOfType("time.Time"), exportBatchSize).Return([]bookings.ExportSchedule{schedule}, nil).Once()
+		repo.On("ClaimExportSchedule", ctx, schedule, run.AddDate(0, 0, 1)).Return(false, nil).Once()
+
+		total, err := srv.RunExportSchedules(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("run", func(t *testing.T) {
+		repo.On("GetDueExportSchedules", ctx, mock.AnythingOfType("time.Time"), exportBatchSize).Return([]bookings.ExportSchedule{schedule}, nil).Once()
+		repo.On("ClaimExportSchedule", ctx, schedule, run.AddDate(0, 0, 1)).Return(true, nil).Once()
+		repo.On("Export", ctx, period).Return([]bookings.Booking{{Code: bookingCode, Status: "approved"}}, nil).Once()
+		repo.On("CreateExportFile", ctx, mock.MatchedBy(func(data bookings.ExportFile) bool {
+			return data.ScheduleId == 1 && data.Status == bookings.ExportReady &&
+				data.PeriodStart.Equal(period.BookedStart) && data.PeriodEnd.Equal(run) &&
+				strings.Contains(string(data.Content), bookingCode)
+		})).Return(&bookings.ExportFile{Id: 1}, nil).Once()
+
+		total, err := srv.RunExportSchedules(ctx)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("failed export is kept", func(t *testing.T) {
+		repo.On("GetDueExportSchedules", ctx, mock.AnythingOfType("time.Time"), exportBatchSize).Return([]bookings.ExportSchedule{schedule}, nil).Once()
+		repo.On("ClaimExportSchedule", ctx, schedule, run.AddDate(0, 0, 1)).Return(true, nil).Once()
+		repo.On("Export", ctx, period).Return(nil, errors.New("some error from repository")).Once()
+		repo.On("CreateExportFile", ctx, mock.MatchedBy(func(data bookings.ExportFile) bool {
+			return data.Status == bookings.ExportFailed && data.Error == "some error from repository" && data.Content == nil
+		})).Return(&bookings.ExportFile{Id: 2}, nil).Once()
+
+		total, err := srv.RunExportSchedules(ctx)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Equal(t, 0, total)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("download", func(t *testing.T) {
+		repo.On("GetExportFile", ctx, uint(1)).Return(&bookings.ExportFile{Id: 1, Status: bookings.ExportReady, Content: []byte("data")}, nil).Once()
+		repo.On("GetExportFile", ctx, uint(2)).Return(&bookings.ExportFile{Id: 2, Status: bookings.ExportFailed}, nil).Once()
+
+		result, err := srv.GetExportFile(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, []byte("data"), result.Content)
+
+		_, err = srv.GetExportFile(ctx, 2)
+
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		repo.AssertExpectations(t)
 	})
 }
 
 func Test_bookingService_UpdateBookingStatus(t *testing.T) {
 	type args struct {
 		ctx    context.Context
-		code   int
+		actor  bookings.Actor
+		code   string
 		status string
 	}
 	tests := []struct {
@@ -915,7 +2606,7 @@ func Test_bookingService_UpdateBookingStatus(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if err := tt.srv.UpdateBookingStatus(tt.args.ctx, tt.args.code, tt.args.status); (err != nil) != tt.wantErr {
+			if err := tt.srv.UpdateBookingStatus(tt.args.ctx, tt.args.actor, tt.args.code, tt.args.status); (err != nil) != tt.wantErr {
 				t.Errorf("bookingService.UpdateBookingStatus() error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
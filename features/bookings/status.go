@@ -0,0 +1,183 @@
+package bookings
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	StatusPending  = "pending"
+	StatusCancel   = "cancel"
+	StatusApproved = "approved"
+	StatusRefund   = "refund"
+	StatusRefunded = "refunded"
+)
+
+const (
+	PaymentPending    = "pending"
+	PaymentCapture    = "capture"
+	PaymentSettlement = "settlement"
+	PaymentDeny       = "deny"
+	PaymentCancel     = "cancel"
+	PaymentExpire     = "expire"
+	PaymentFailure    = "failure"
+	PaymentRefund     = "refund"
+	PaymentPartial    = "partial_refund"
+)
+
+const (
+	RefundRequested = "requested"
+	RefundApproved  = "approved"
+	RefundRefunded  = "refunded"
+	RefundFailed    = "failed"
+)
+
+// TourPublished is the status of the tours that can be booked.
+const TourPublished = "published"
+
+const (
+	SourceUser      = "user"
+	SourceAdmin     = "admin"
+	SourceWebhook   = "webhook"
+	SourceScheduler = "scheduler"
+)
+
+// bookingTransitions lists the booking statuses reachable from each status.
+// A canceled booking can still be approved when the gateway settles a
+// payment that was made just before the cancel went through, and a booking
+// under refund goes back to approved when the refund leaves some passengers
+// on the tour.
+var bookingTransitions = map[string][]string{
+	"":             {StatusPending},
+	StatusPending:  {StatusCancel, StatusApproved},
+	StatusCancel:   {StatusApproved},
+	StatusApproved: {StatusRefund},
+	StatusRefund:   {StatusRefunded, StatusApproved},
+	StatusRefunded: {},
+}
+
+// paymentTransitions lists the payment statuses reachable from each status,
+// following the transaction lifecycle of the payment gateway.
+var paymentTransitions = map[string][]string{
+	"":                {PaymentPending},
+	PaymentPending:    {PaymentCapture, PaymentSettlement, PaymentDeny, PaymentCancel, PaymentExpire, PaymentFailure},
+	PaymentCapture:    {PaymentSettlement, PaymentDeny, PaymentCancel, PaymentRefund, PaymentPartial},
+	PaymentDeny:       {PaymentPending, PaymentCancel, PaymentExpire},
+	PaymentCancel:     {PaymentSettlement},
+	PaymentExpire:     {PaymentSettlement},
+	PaymentFailure:    {},
+	PaymentSettlement: {PaymentRefund, PaymentPartial},
+	PaymentPartial:    {PaymentRefund},
+	PaymentRefund:     {},
+}
+
+// paymentBookingStatus is the booking status a payment status leads to. The
+// refund statuses only confirm refunds the booking went through on its own,
+// so they leave the booking status as it is.
+var paymentBookingStatus = map[string]string{
+	PaymentPending:    StatusPending,
+	PaymentCapture:    StatusPending,
+	PaymentDeny:       StatusPending,
+	PaymentSettlement: StatusApproved,
+	PaymentCancel:     StatusCancel,
+	PaymentExpire:     StatusCancel,
+	PaymentFailure:    StatusCancel,
+	PaymentRefund:     "",
+	PaymentPartial:    "",
+}
+
+type Actor struct {
+	Id     uint
+	Source string
+}
+
+// Transition is a single change of a booking's status and payment status.
+// Statuses left equal on both sides are unchanged by the transition.
+type Transition struct {
+	Id          uint
+	BookingCode string
+	From        string
+	To          string
+	PaymentFrom string
+	PaymentTo   string
+	Actor       Actor
+	Note        string
+	CreatedAt   time.Time
+}
+
+func (t Transition) Changed() bool {
+	return t.From != t.To || t.PaymentFrom != t.PaymentTo
+}
+
+// NewTransition moves booking to the given booking and payment status. An
+// empty status keeps the current one. It fails when any of the two moves isn't
+// allowed by the state machine.
+func NewTransition(booking Booking, to string, paymentTo string, actor Actor) (*Transition, error) {
+	var transition = &Transition{
+		BookingCode: booking.Code,
+		From:        booking.Status,
+		To:          booking.Status,
+		PaymentFrom: booking.Payment.Status,
+		PaymentTo:   booking.Payment.Status,
+		Actor:       actor,
+	}
+
+	if to != "" {
+		transition.To = to
+	}
+
+	if paymentTo != "" {
+		transition.PaymentTo = paymentTo
+	}
+
+	if !canTransit(bookingTransitions, transition.From, transition.To) {
+		return nil, fmt.Errorf("unprocessable: can't change booking status from %s to %s", transition.From, transition.To)
+	}
+
+	if !canTransit(paymentTransitions, transition.PaymentFrom, transition.PaymentTo) {
+		return nil, fmt.Errorf("unprocessable: can't change payment status from %s to %s", transition.PaymentFrom, transition.PaymentTo)
+	}
+
+	return transition, nil
+}
+
+// BookingTransition moves booking to another booking status on request of a
+// user or an admin. Unlike gateway notifications, such a request must change
+// the status.
+func BookingTransition(booking Booking, to string, actor Actor) (*Transition, error) {
+	if booking.Status == to {
+		return nil, fmt.Errorf("unprocessable: booking is already %s", to)
+	}
+
+	return NewTransition(booking, to, "", actor)
+}
+
+// PaymentTransition moves booking to paymentStatus and to the booking status
+// that payment status leads to. A repeated payment status changes nothing.
+func PaymentTransition(booking Booking, paymentStatus string, actor Actor) (*Transition, error) {
+	status, ok := paymentBookingStatus[paymentStatus]
+	if !ok {
+		return nil, errors.New("validate: invalid payment status")
+	}
+
+	if paymentStatus == booking.Payment.Status {
+		return NewTransition(booking, "", "", actor)
+	}
+
+	return NewTransition(booking, status, paymentStatus, actor)
+}
+
+func canTransit(transitions map[string][]string, from string, to string) bool {
+	if from == to {
+		return true
+	}
+
+	for _, next := range transitions[from] {
+		if next == to {
+			return true
+		}
+	}
+
+	return false
+}
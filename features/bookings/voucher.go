@@ -0,0 +1,127 @@
+package bookings
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+)
+
+const (
+	VoucherPercentage = "percentage"
+	VoucherFixed      = "fixed"
+)
+
+// Voucher is a promo code taking money off a booking. It applies to the tours
+// in TourIds and to every tour of the locations in LocationIds, or to any tour
+// when both are empty. A voucher that isn't Stackable can't be used on a tour
+// that already has a discount of its own.
+//
+// Used counts the bookings that redeemed the voucher and UsedByUser those of
+// the user booking, leaving the canceled ones out. A zero Quota, UserLimit,
+// MaxDiscount or EndAt means no limit.
+type Voucher struct {
+	Id          uint
+	Code        string
+	Type        string
+	Value       float64
+	MaxDiscount float64
+	MinSpend    float64
+	Quota       int
+	UserLimit   int
+	Stackable   bool
+	StartAt     time.Time
+	EndAt       time.Time
+	TourIds     []uint
+	LocationIds []uint
+
+	Used       int
+	UsedByUser int
+}
+
+// NormalizeVoucherCode turns a voucher code typed by a user into the form it is
+// stored in.
+func NormalizeVoucherCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// Available reports why v can't be redeemed once more, if it has reached one
+// of its usage limits.
+func (v Voucher) Available() error {
+	if v.Quota != 0 && v.Used >= v.Quota {
+		return errors.New("unprocessable: voucher has been fully redeemed")
+	}
+
+	if v.UserLimit != 0 && v.UsedByUser >= v.UserLimit {
+		return errors.New("unprocessable: you have reached the usage limit of this voucher")
+	}
+
+	return nil
+}
+
+// Apply checks that v can be redeemed at at on a booking of tour for the
+// priced passengers and works out how much it takes off the price of the booking.
+func (v Voucher) Apply(tour Tour, passengers []Detail, at time.Time) (float64, error) {
+	if at.Before(v.StartAt) || (!v.EndAt.IsZero() && !at.Before(v.EndAt)) {
+		return 0, errors.New("unprocessable: voucher is not valid at this time")
+	}
+
+	if err := v.Available(); err != nil {
+		return 0, err
+	}
+
+	if !v.Covers(tour) {
+		return 0, errors.New("unprocessable: voucher doesn't apply to this tour")
+	}
+
+	if !v.Stackable && tour.Discount != 0 {
+		return 0, errors.New("unprocessable: voucher can't be combined with the tour discount")
+	}
+
+	spend := TourTotal(tour, passengers)
+	if spend < v.MinSpend {
+		return 0, fmt.Errorf("unprocessable: voucher needs a minimum spend of %.0f", v.MinSpend)
+	}
+
+	return v.Discount(spend), nil
+}
+
+// Covers reports whether v applies to tour.
+func (v Voucher) Covers(tour Tour) bool {
+	if len(v.TourIds) == 0 && len(v.LocationIds) == 0 {
+		return true
+	}
+
+	for _, id := range v.TourIds {
+		if id == tour.Id {
+			return true
+		}
+	}
+
+	for _, id := range v.LocationIds {
+		if id == tour.Location.Id {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MinPayable is what a voucher leaves of the spend at least, since the
+// payment gateway can't charge a booking nothing.
+const MinPayable = 1
+
+// Discount is how much v takes off spend, rounded down to a whole amount so it
+// can be charged as is. It leaves at least MinPayable of spend to pay.
+func (v Voucher) Discount(spend float64) float64 {
+	var discount = v.Value
+	if v.Type == VoucherPercentage {
+		discount = v.Value / 100 * spend
+		if v.MaxDiscount != 0 && discount > v.MaxDiscount {
+			discount = v.MaxDiscount
+		}
+	}
+
+	return math.Max(math.Floor(math.Min(discount, spend-MinPayable)), 0)
+}
@@ -0,0 +1,77 @@
+package bookings_test
+
+import (
+	"testing"
+	"time"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVoucherApply(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	tour := bookings.Tour{Id: 1, Price: 1000000, Location: bookings.Location{Id: 7}}
+
+	var base = bookings.Voucher{
+		Code:      "SUMMER",
+		Type:      bookings.VoucherPercentage,
+		Value:     10,
+		Stackable: true,
+		StartAt:   now.Add(-time.Hour),
+		EndAt:     now.Add(time.Hour),
+	}
+
+	var testCases = []struct {
+		name     string
+		voucher  func(v *bookings.Voucher)
+		tour     func(t *bookings.Tour)
+		discount float64
+		err      string
+	}{
+		{name: "percentage", discount: 200000},
+		{name: "percentage capped", voucher: func(v *bookings.Voucher) { v.MaxDiscount = 150000 }, discount: 150000},
+		{name: "fixed", voucher: func(v *bookings.Voucher) { v.Type, v.Value = bookings.VoucherFixed, 250000 }, discount: 250000},
+		{name: "fixed above spend", voucher: func(v *bookings.Voucher) { v.Type, v.Value = bookings.VoucherFixed, 5000000 }, discount: 1999999},
+		{name: "fixed equal to spend", voucher: func(v *bookings.Voucher) { v.Type, v.Value = bookings.VoucherFixed, 2000000 }, discount: 1999999},
+		{name: "percentage of whole spend", voucher: func(v *bookings.Voucher) { v.Value = 100 }, discount: 1999999},
+		{name: "on top of tour discount", tour: func(t *bookings.Tour) { t.Discount = 50 }, discount: 100000},
+		{name: "not started", voucher: func(v *bookings.Voucher) { v.StartAt = now.Add(time.Minute) }, err: "not valid"},
+		{name: "expired", voucher: func(v *bookings.Voucher) { v.EndAt = now }, err: "not valid"},
+		{name: "no end", voucher: func(v *bookings.Voucher) { v.EndAt = time.Time{} }, discount: 200000},
+		{name: "fully redeemed", voucher: func(v *bookings.Voucher) { v.Quota, v.Used = 5, 5 }, err: "fully redeemed"},
+		{name: "user limit", voucher: func(v *bookings.Voucher) { v.UserLimit, v.UsedByUser = 1, 1 }, err: "usage limit"},
+		{name: "other tour", voucher: func(v *bookings.Voucher) { v.TourIds = []uint{2} }, err: "doesn't apply"},
+		{name: "scoped tour", voucher: func(v *bookings.Voucher) { v.TourIds = []uint{2, 1} }, discount: 200000},
+		{name: "scoped location", voucher: func(v *bookings.Voucher) { v.TourIds, v.LocationIds = []uint{2}, []uint{7} }, discount: 200000},
+		{name: "not stackable", voucher: func(v *bookings.Voucher) { v.Stackable = false }, tour: func(t *bookings.Tour) { t.Discount = 5 }, err: "combined"},
+		{name: "not stackable without tour discount", voucher: func(v *bookings.Voucher) { v.Stackable = false }, discount: 200000},
+		{name: "min spend", voucher: func(v *bookings.Voucher) { v.MinSpend = 3000000 }, err: "minimum spend"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			voucher, caseTour := base, tour
+			if tc.voucher != nil {
+				tc.voucher(&voucher)
+			}
+			if tc.tour != nil {
+				tc.tour(&caseTour)
+			}
+
+			passengers := []bookings.Detail{{Price: caseTour.Price}, {Price: caseTour.Price}}
+			discount, err := voucher.Apply(caseTour, passengers, now)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, "unprocessable")
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tc.discount, discount)
+		})
+	}
+}
+
+func TestNormalizeVoucherCode(t *testing.T) {
+	assert.Equal(t, "SUMMER24", bookings.NormalizeVoucherCode("  summer24 "))
+}
@@ -8,7 +8,7 @@ import (
 
 type Location struct {
 	Id   uint   `gorm:"column:id; primaryKey;"`
-	Name string `gorm:"column:name; type:varchar(200); unique;"`
+	Name string `gorm:"column:name; type:varchar(200); unique; index:idx_locations_search,class:FULLTEXT;"`
 
 	ImageUrl string    `gorm:"column:image; type:text;"`
 	ImageRaw io.Reader `gorm:"-"`
@@ -17,6 +17,23 @@ type Report struct {
 	RecentBooking []Booking
 
 	TopTours []Tour
+
+	PackageSales   []Sales
+	DepartureSales []Sales
+}
+
+// Sales sums up the bookings of a tour package, or of a single departure of it
+// when DepartureId is set. Bookings and Revenue only count paid bookings,
+// while the seats taken by pending ones already show in Available.
+type Sales struct {
+	TourId      uint
+	Title       string
+	DepartureId uint
+	Start       time.Time
+	Quota       int
+	Available   int
+	Bookings    int
+	Revenue     float64
 }
 
 type GraphBooking struct {
@@ -25,7 +42,7 @@ type GraphBooking struct {
 }
 
 type Booking struct {
-	Code     int
+	Code     string
 	Location string
 	Price    float64
 }
@@ -64,4 +81,6 @@ type Repository interface {
 	GetBookingCurrentYear(ctx context.Context) ([]GraphBooking, error)
 	GetRecentBooking(ctx context.Context) ([]Booking, error)
 	GetTopTour(ctx context.Context) ([]Tour, error)
+	GetPackageSales(ctx context.Context) ([]Sales, error)
+	GetDepartureSales(ctx context.Context) ([]Sales, error)
 }
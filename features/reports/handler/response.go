@@ -16,6 +16,9 @@ type ReportResponse struct {
 	RecentBooking []BookingResponse      `json:"recent_booking"`
 
 	TopTours []TourResponse `json:"top_tours"`
+
+	PackageSales   []SalesResponse `json:"package_sales"`
+	DepartureSales []SalesResponse `json:"departure_sales"`
 }
 
 func (res *ReportResponse) FromEntity(ent reports.Report) {
@@ -52,6 +55,18 @@ func (res *ReportResponse) FromEntity(ent reports.Report) {
 		tmpTour.FromEntity(topTour)
 		res.TopTours = append(res.TopTours, *tmpTour)
 	}
+
+	for _, sales := range ent.PackageSales {
+		var tmpSales = new(SalesResponse)
+		tmpSales.FromEntity(sales)
+		res.PackageSales = append(res.PackageSales, *tmpSales)
+	}
+
+	for _, sales := range ent.DepartureSales {
+		var tmpSales = new(SalesResponse)
+		tmpSales.FromEntity(sales)
+		res.DepartureSales = append(res.DepartureSales, *tmpSales)
+	}
 }
 
 type GraphBookingResponse struct {
@@ -70,13 +85,13 @@ func (res *GraphBookingResponse) FromEntity(ent reports.GraphBooking) {
 }
 
 type BookingResponse struct {
-	Code     int     `json:"booking_code"`
+	Code     string  `json:"booking_code"`
 	Location string  `json:"location"`
 	Price    float64 `json:"price"`
 }
 
 func (res *BookingResponse) FromEntity(ent reports.Booking) {
-	if ent.Code != 0 {
+	if ent.Code != "" {
 		res.Code = ent.Code
 	}
 
@@ -153,3 +168,25 @@ func (res *LocationResponse) FromEntity(ent reports.Location) {
 		res.Name = ent.Name
 	}
 }
+
+type SalesResponse struct {
+	TourId      uint      `json:"tour_id"`
+	Title       string    `json:"title"`
+	DepartureId uint      `json:"departure_id,omitempty"`
+	Start       time.Time `json:"start"`
+	Quota       int       `json:"quota"`
+	Booked      int       `json:"booked"`
+	Bookings    int       `json:"bookings"`
+	Revenue     float64   `json:"revenue"`
+}
+
+func (res *SalesResponse) FromEntity(ent reports.Sales) {
+	res.TourId = ent.TourId
+	res.Title = ent.Title
+	res.DepartureId = ent.DepartureId
+	res.Start = ent.Start
+	res.Quota = ent.Quota
+	res.Booked = ent.Quota - ent.Available
+	res.Bookings = ent.Bookings
+	res.Revenue = ent.Revenue
+}
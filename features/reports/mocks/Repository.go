@@ -40,6 +40,58 @@ func (_m *Repository) GetBookingCurrentYear(ctx context.Context) ([]reports.Grap
 	return r0, r1
 }
 
+// GetDepartureSales provides a mock function with given fields: ctx
+func (_m *Repository) GetDepartureSales(ctx context.Context) ([]reports.Sales, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []reports.Sales
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]reports.Sales, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []reports.Sales); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]reports.Sales)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetPackageSales provides a mock function with given fields: ctx
+func (_m *Repository) GetPackageSales(ctx context.Context) ([]reports.Sales, error) {
+	ret := _m.Called(ctx)
+
+	var r0 []reports.Sales
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) ([]reports.Sales, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) []reports.Sales); ok {
+		r0 = rf(ctx)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]reports.Sales)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // GetRecentBooking provides a mock function with given fields: ctx
 func (_m *Repository) GetRecentBooking(ctx context.Context) ([]reports.Booking, error) {
 	ret := _m.Called(ctx)
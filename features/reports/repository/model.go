@@ -8,17 +8,20 @@ import (
 )
 
 type Booking struct {
-	Code   int
+	Code   string
 	Status string
 
 	TourId uint
 	Tour   Tour
+
+	DepartureId uint
+	Departure   Departure
 }
 
 func (mod *Booking) ToEntity() *reports.Booking {
 	var ent = new(reports.Booking)
 
-	if mod.Code != 0 {
+	if mod.Code != "" {
 		ent.Code = mod.Code
 	}
 
@@ -32,9 +35,22 @@ func (mod *Booking) ToEntity() *reports.Booking {
 		}
 	}
 
+	if mod.Departure.Price != 0 {
+		ent.Price = mod.Departure.Price
+	}
+
 	return ent
 }
 
+type Departure struct {
+	Id    uint
+	Price float64
+}
+
+func (mod *Departure) TableName() string {
+	return "tour_departures"
+}
+
 type User struct {
 	Id   uint
 	Role string
@@ -135,3 +151,27 @@ func (mod *GraphBooking) ToEntity() (*reports.GraphBooking, error) {
 
 	return ent, nil
 }
+
+type Sales struct {
+	TourId      uint
+	Title       string
+	DepartureId uint
+	Start       time.Time
+	Quota       int
+	Available   int
+	Bookings    int
+	Revenue     float64
+}
+
+func (mod *Sales) ToEntity() *reports.Sales {
+	return &reports.Sales{
+		TourId:      mod.TourId,
+		Title:       mod.Title,
+		DepartureId: mod.DepartureId,
+		Start:       mod.Start,
+		Quota:       mod.Quota,
+		Available:   mod.Available,
+		Bookings:    mod.Bookings,
+		Revenue:     mod.Revenue,
+	}
+}
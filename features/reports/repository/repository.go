@@ -78,7 +78,7 @@ func (repo *reportRepository) GetBookingCurrentYear(ctx context.Context) ([]repo
 
 func (repo *reportRepository) GetRecentBooking(ctx context.Context) ([]reports.Booking, error) {
 	var modBooking []Booking
-	if err := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).Joins("Tour.Location").Order("booked_at desc").Limit(5).Find(&modBooking).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).Joins("Tour.Location").Joins("Departure").Order("booked_at desc").Limit(5).Find(&modBooking).Error; err != nil {
 		return nil, err
 	}
 
@@ -103,3 +103,57 @@ func (repo *reportRepository) GetTopTour(ctx context.Context) ([]reports.Tour, e
 
 	return data, nil
 }
+
+// paidBookings counts the paid bookings and their revenue per column.
+func (repo *reportRepository) paidBookings(column string) *gorm.DB {
+	return repo.mysqlDB.Table("bookings").
+		Select(column+", COUNT(*) AS bookings, SUM(total) AS revenue").
+		Where("status = ? AND deleted_at IS NULL", "approved").
+		Group(column)
+}
+
+// GetPackageSales is the sales of the tour packages earning the most.
+func (repo *reportRepository) GetPackageSales(ctx context.Context) ([]reports.Sales, error) {
+	var modSales []Sales
+	qry := repo.mysqlDB.WithContext(ctx).Table("tours").
+		Select("tours.id AS tour_id, tours.title, tours.start, tours.quota, tours.available, COALESCE(paid.bookings, 0) AS bookings, COALESCE(paid.revenue, 0) AS revenue").
+		Joins("LEFT JOIN (?) paid ON paid.tour_id = tours.id", repo.paidBookings("tour_id")).
+		Where("tours.deleted_at IS NULL").
+		Order("revenue desc, tours.id desc").
+		Limit(10)
+
+	if err := qry.Scan(&modSales).Error; err != nil {
+		return nil, err
+	}
+
+	var data []reports.Sales
+	for _, sales := range modSales {
+		data = append(data, *sales.ToEntity())
+	}
+
+	return data, nil
+}
+
+// GetDepartureSales is the sales of the next departures, showing how full
+// they are getting.
+func (repo *reportRepository) GetDepartureSales(ctx context.Context) ([]reports.Sales, error) {
+	var modSales []Sales
+	qry := repo.mysqlDB.WithContext(ctx).Table("tour_departures").
+		Select("tour_departures.tour_id, tours.title, tour_departures.id AS departure_id, tour_departures.start, tour_departures.quota, tour_departures.available, COALESCE(paid.bookings, 0) AS bookings, COALESCE(paid.revenue, 0) AS revenue").
+		Joins("JOIN tours ON tours.id = tour_departures.tour_id").
+		Joins("LEFT JOIN (?) paid ON paid.departure_id = tour_departures.id", repo.paidBookings("departure_id")).
+		Where("tour_departures.deleted_at IS NULL AND tours.deleted_at IS NULL AND tour_departures.start > ?", time.Now()).
+		Order("tour_departures.start asc").
+		Limit(10)
+
+	if err := qry.Scan(&modSales).Error; err != nil {
+		return nil, err
+	}
+
+	var data []reports.Sales
+	for _, sales := range modSales {
+		data = append(data, *sales.ToEntity())
+	}
+
+	return data, nil
+}
@@ -51,13 +51,25 @@ func (srv *reportService) Dashboard(ctx context.Context) (*reports.Report, error
 		return nil, err
 	}
 
+	packageSales, err := srv.repo.GetPackageSales(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	departureSales, err := srv.repo.GetDepartureSales(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	return &reports.Report{
-		TotalUser:     totalUser,
-		TotalBooking:  totalBooking,
-		TotalLocation: totalLocation,
-		TotalTour:     totalTour,
-		GraphBooking:  graphBooking,
-		RecentBooking: recentBooking,
-		TopTours:      topTour,
+		TotalUser:      totalUser,
+		TotalBooking:   totalBooking,
+		TotalLocation:  totalLocation,
+		TotalTour:      totalTour,
+		GraphBooking:   graphBooking,
+		RecentBooking:  recentBooking,
+		TopTours:       topTour,
+		PackageSales:   packageSales,
+		DepartureSales: departureSales,
 	}, nil
 }
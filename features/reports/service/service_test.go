@@ -29,7 +29,7 @@ func TestReportServiceDashboard(t *testing.T) {
 		},
 		RecentBooking: []reports.Booking{
 			{
-				Code:     1,
+				Code:     "1",
 				Location: "test",
 				Price:    10000,
 			},
@@ -49,6 +49,29 @@ func TestReportServiceDashboard(t *testing.T) {
 				},
 			},
 		},
+		PackageSales: []reports.Sales{
+			{
+				TourId:    1,
+				Title:     "test",
+				Start:     time.Now(),
+				Quota:     30,
+				Available: 12,
+				Bookings:  6,
+				Revenue:   60000,
+			},
+		},
+		DepartureSales: []reports.Sales{
+			{
+				TourId:      1,
+				Title:       "test",
+				DepartureId: 2,
+				Start:       time.Now().Add(time.Hour * 24),
+				Quota:       10,
+				Available:   4,
+				Bookings:    2,
+				Revenue:     20000,
+			},
+		},
 	}
 
 	t.Run("error get total user from repository", func(t *testing.T) {
@@ -149,6 +172,43 @@ func TestReportServiceDashboard(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
+	t.Run("error get package sales from repository", func(t *testing.T) {
+		repo.On("GetTotalUser", ctx).Return(data.TotalUser, nil).Once()
+		repo.On("GetTotalBooking", ctx).Return(data.TotalBooking, nil).Once()
+		repo.On("GetTotalLocation", ctx).Return(data.TotalLocation, nil).Once()
+		repo.On("GetTotalTour", ctx).Return(data.TotalTour, nil).Once()
+		repo.On("GetBookingCurrentYear", ctx).Return(data.GraphBooking, nil).Once()
+		repo.On("GetRecentBooking", ctx).Return(data.RecentBooking, nil).Once()
+		repo.On("GetTopTour", ctx).Return(data.TopTours, nil).Once()
+		repo.On("GetPackageSales", ctx).Return(nil, errors.New("some error from repository")).Once()
+
+		result, err := srv.Dashboard(ctx)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error get departure sales from repository", func(t *testing.T) {
+		repo.On("GetTotalUser", ctx).Return(data.TotalUser, nil).Once()
+		repo.On("GetTotalBooking", ctx).Return(data.TotalBooking, nil).Once()
+		repo.On("GetTotalLocation", ctx).Return(data.TotalLocation, nil).Once()
+		repo.On("GetTotalTour", ctx).Return(data.TotalTour, nil).Once()
+		repo.On("GetBookingCurrentYear", ctx).Return(data.GraphBooking, nil).Once()
+		repo.On("GetRecentBooking", ctx).Return(data.RecentBooking, nil).Once()
+		repo.On("GetTopTour", ctx).Return(data.TopTours, nil).Once()
+		repo.On("GetPackageSales", ctx).Return(data.PackageSales, nil).Once()
+		repo.On("GetDepartureSales", ctx).Return(nil, errors.New("some error from repository")).Once()
+
+		result, err := srv.Dashboard(ctx)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("success", func(t *testing.T) {
 		repo.On("GetTotalUser", ctx).Return(data.TotalUser, nil).Once()
 		repo.On("GetTotalBooking", ctx).Return(data.TotalBooking, nil).Once()
@@ -157,6 +217,8 @@ func TestReportServiceDashboard(t *testing.T) {
 		repo.On("GetBookingCurrentYear", ctx).Return(data.GraphBooking, nil).Once()
 		repo.On("GetRecentBooking", ctx).Return(data.RecentBooking, nil).Once()
 		repo.On("GetTopTour", ctx).Return(data.TopTours, nil).Once()
+		repo.On("GetPackageSales", ctx).Return(data.PackageSales, nil).Once()
+		repo.On("GetDepartureSales", ctx).Return(data.DepartureSales, nil).Once()
 
 		result, err := srv.Dashboard(ctx)
 
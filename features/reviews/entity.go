@@ -1,20 +1,57 @@
 package reviews
 
 import (
+	"context"
+	"io"
 	"time"
+	"wanderer/helpers/filters"
 
 	"github.com/labstack/echo/v4"
 )
 
+// A review holds at most MaxPhotos photos of at most MaxPhotoSize bytes each.
+const (
+	MaxPhotos    = 5
+	MaxPhotoSize = 5 << 20
+)
+
+const (
+	StatusVisible = "visible"
+	StatusFlagged = "flagged"
+	StatusHidden  = "hidden"
+)
+
+// Review is the opinion of a user on a tour they went on. A flagged review
+// waits in the moderation queue but stays visible until an admin hides it.
+// Hidden reviews are only shown to admins and don't count toward the rating
+// of the tour.
 type Review struct {
 	Id     uint
 	TourId uint
 	Text   string
 	Rating float32
+	Status string
+	Flags  int
 
-	User User
+	// Helpful is the number of users who found the review helpful.
+	Helpful int
+
+	User   User
+	Photos []Photo
 
 	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Photo is a picture attached to a review. Raw, Size and Type describe a new
+// upload, while stored photos only have their Url.
+type Photo struct {
+	Id  uint
+	Url string
+
+	Raw  io.Reader
+	Size int64
+	Type string
 }
 
 type User struct {
@@ -29,24 +66,83 @@ type Tour struct {
 	Start  time.Time
 }
 
+// Booking is a booking of a user on a tour, along with the dates of the
+// departure it is on.
 type Booking struct {
-	Code   int
-	UserId uint
-	TourId uint
-	Status string
+	Code        string
+	UserId      uint
+	TourId      uint
+	DepartureId uint
+	Status      string
+	Start       time.Time
+	Finish      time.Time
+}
+
+// Flag is a report of a review by a user, asking the admins to moderate it.
+type Flag struct {
+	ReviewId uint
+	UserId   uint
+	Reason   string
+}
+
+// Vote marks a review as helpful to a user. A user votes at most once per
+// review.
+type Vote struct {
+	ReviewId uint
+	UserId   uint
+}
+
+// ValidStatus reports whether status is a status a review can be in.
+func ValidStatus(status string) bool {
+	switch status {
+	case StatusVisible, StatusFlagged, StatusHidden:
+		return true
+	}
+
+	return false
 }
 
 type Handler interface {
 	Create() echo.HandlerFunc
+	GetAll() echo.HandlerFunc
+	GetByTour() echo.HandlerFunc
+	Update() echo.HandlerFunc
+	Delete() echo.HandlerFunc
+	Flag() echo.HandlerFunc
+	Moderate() echo.HandlerFunc
+	AddPhotos() echo.HandlerFunc
+	DeletePhoto() echo.HandlerFunc
+	Vote() echo.HandlerFunc
+	Unvote() echo.HandlerFunc
 }
 
 type Repository interface {
-	Create(userId uint, newReview Review) error
-	GetTourById(tourId uint) (*Tour, error)
-	IsBooking(tourId uint, userId uint) bool
-	IsApproved(tourId uint, userId uint) bool
+	Create(ctx context.Context, userId uint, newReview Review) error
+	GetAll(ctx context.Context, flt filters.Filter) ([]Review, int, error)
+	GetById(ctx context.Context, id uint) (*Review, error)
+	Update(ctx context.Context, id uint, data Review) error
+	Delete(ctx context.Context, id uint) ([]Photo, error)
+	Flag(ctx context.Context, data Flag) error
+	UpdateStatus(ctx context.Context, id uint, status string) error
+	AddPhotos(ctx context.Context, id uint, photos []Photo) error
+	DeletePhoto(ctx context.Context, id uint, photoId uint) error
+	DiscardPhotos(ctx context.Context, photos []Photo) error
+	Vote(ctx context.Context, data Vote) error
+	Unvote(ctx context.Context, data Vote) error
+	GetTourById(ctx context.Context, tourId uint) (*Tour, error)
+	IsBooking(ctx context.Context, tourId uint, userId uint) bool
+	GetApprovedBookings(ctx context.Context, tourId uint, userId uint) ([]Booking, error)
 }
 
 type Service interface {
-	Create(userId uint, newReview Review) error
+	Create(ctx context.Context, userId uint, newReview Review) error
+	GetAll(ctx context.Context, admin bool, flt filters.Filter) ([]Review, int, error)
+	Update(ctx context.Context, userId uint, id uint, data Review) error
+	Delete(ctx context.Context, userId uint, admin bool, id uint) error
+	Flag(ctx context.Context, data Flag) error
+	Moderate(ctx context.Context, id uint, status string) error
+	AddPhotos(ctx context.Context, userId uint, id uint, photos []Photo) error
+	DeletePhoto(ctx context.Context, userId uint, admin bool, id uint, photoId uint) error
+	Vote(ctx context.Context, data Vote) error
+	Unvote(ctx context.Context, data Vote) error
 }
@@ -2,9 +2,13 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"wanderer/config"
 	"wanderer/features/reviews"
+	"wanderer/helpers/authorization"
+	"wanderer/helpers/filters"
+	"wanderer/helpers/paginate"
 	"wanderer/helpers/tokens"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -51,7 +55,17 @@ func (hdl *reviewHandler) Create() echo.HandlerFunc {
 
 		var data = request.ToEntity()
 
-		if err := hdl.reviewService.Create(userId, *data); err != nil {
+		photos, err := openPhotos(c)
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+		defer closePhotos(photos)
+		data.Photos = photos
+
+		if err := hdl.reviewService.Create(c.Request().Context(), userId, *data); err != nil {
 			c.Logger().Error(err)
 
 			if strings.Contains(err.Error(), "validate: ") {
@@ -92,3 +106,420 @@ func (hdl *reviewHandler) Create() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, response)
 	}
 }
+
+// GetAll is the moderation queue, every review matching the status filter.
+func (hdl *reviewHandler) GetAll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var filter = new(filters.Filter)
+
+		c.Bind(&filter.Review)
+
+		return hdl.list(c, *filter)
+	}
+}
+
+// GetByTour lists the reviews of the tour in the path.
+func (hdl *reviewHandler) GetByTour() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var filter = new(filters.Filter)
+
+		tourId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid tour id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		c.Bind(&filter.Review)
+		filter.Review.TourId = uint(tourId)
+
+		return hdl.list(c, *filter)
+	}
+}
+
+func (hdl *reviewHandler) list(c echo.Context, filter filters.Filter) error {
+	var response = make(map[string]any)
+
+	c.Bind(&filter.Pagination)
+	if filter.Pagination.Start != 0 && filter.Pagination.Limit == 0 {
+		filter.Pagination.Limit = 5
+	}
+	c.Bind(&filter.Search)
+	c.Bind(&filter.Sort)
+
+	admin := authorization.IsAdmin(c)
+
+	result, totalData, err := hdl.reviewService.GetAll(c.Request().Context(), admin, filter)
+	if err != nil {
+		c.Logger().Error(err)
+
+		if strings.Contains(err.Error(), "validate: ") {
+			response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		response["message"] = "internal server error"
+		return c.JSON(http.StatusInternalServerError, response)
+	}
+
+	var data = make([]ReviewResponse, 0, len(result))
+	for _, review := range result {
+		var tmpReview = new(ReviewResponse)
+		tmpReview.FromEntity(review, admin)
+
+		data = append(data, *tmpReview)
+	}
+	response["data"] = data
+
+	if pagination := paginate.Links(c, filter.Pagination, totalData); pagination != nil {
+		response["pagination"] = pagination
+	}
+
+	response["message"] = "get all review success"
+	return c.JSON(http.StatusOK, response)
+}
+
+func (hdl *reviewHandler) Update() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(UpdateRequest)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.reviewService.Update(c.Request().Context(), userId, uint(reviewId), *request.ToEntity()); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "update review success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *reviewHandler) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.reviewService.Delete(c.Request().Context(), userId, authorization.IsAdmin(c), uint(reviewId)); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "delete review success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *reviewHandler) Flag() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(FlagRequest)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		var data = reviews.Flag{ReviewId: uint(reviewId), UserId: userId, Reason: request.Reason}
+		if err := hdl.reviewService.Flag(c.Request().Context(), data); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "used: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "used: ", "")
+				return c.JSON(http.StatusConflict, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "flag review success"
+		return c.JSON(http.StatusCreated, response)
+	}
+}
+
+func (hdl *reviewHandler) Moderate() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(ModerateRequest)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.reviewService.Moderate(c.Request().Context(), uint(reviewId), request.Status); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "moderate review success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *reviewHandler) AddPhotos() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		photos, err := openPhotos(c)
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+		defer closePhotos(photos)
+
+		if err := hdl.reviewService.AddPhotos(c.Request().Context(), userId, uint(reviewId), photos); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "add review photos success"
+		return c.JSON(http.StatusCreated, response)
+	}
+}
+
+func (hdl *reviewHandler) DeletePhoto() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		photoId, err := strconv.Atoi(c.Param("photoId"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid photo id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.reviewService.DeletePhoto(c.Request().Context(), userId, authorization.IsAdmin(c), uint(reviewId), uint(photoId)); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "delete review photo success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *reviewHandler) Vote() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.reviewService.Vote(c.Request().Context(), reviews.Vote{ReviewId: uint(reviewId), UserId: userId}); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "used: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "used: ", "")
+				return c.JSON(http.StatusConflict, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "vote review success"
+		return c.JSON(http.StatusCreated, response)
+	}
+}
+
+func (hdl *reviewHandler) Unvote() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		userId, _ := authorization.Identity(c)
+
+		reviewId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid review id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.reviewService.Unvote(c.Request().Context(), reviews.Vote{ReviewId: uint(reviewId), UserId: userId}); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "unvote review success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
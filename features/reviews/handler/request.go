@@ -1,11 +1,18 @@
 package handler
 
-import "wanderer/features/reviews"
+import (
+	"errors"
+	"io"
+	"net/http"
+	"wanderer/features/reviews"
+
+	"github.com/labstack/echo/v4"
+)
 
 type CreateRequest struct {
-	TourId uint    `json:"tour_id,omitempty"`
-	Text   string  `json:"text,omitempty"`
-	Rating float32 `json:"rating,omitempty"`
+	TourId uint    `json:"tour_id,omitempty" form:"tour_id"`
+	Text   string  `json:"text,omitempty" form:"text"`
+	Rating float32 `json:"rating,omitempty" form:"rating"`
 }
 
 func (req *CreateRequest) ToEntity() *reviews.Review {
@@ -25,3 +32,65 @@ func (req *CreateRequest) ToEntity() *reviews.Review {
 
 	return ent
 }
+
+type UpdateRequest struct {
+	Text   string  `json:"text,omitempty"`
+	Rating float32 `json:"rating,omitempty"`
+}
+
+func (req *UpdateRequest) ToEntity() *reviews.Review {
+	var ent = new(reviews.Review)
+
+	if req.Text != "" {
+		ent.Text = req.Text
+	}
+
+	if req.Rating != 0 {
+		ent.Rating = req.Rating
+	}
+
+	return ent
+}
+
+type FlagRequest struct {
+	Reason string `json:"reason"`
+}
+
+type ModerateRequest struct {
+	Status string `json:"status"`
+}
+
+// openPhotos opens the photos uploaded in a multipart request. Requests of
+// any other kind have none, while a malformed multipart request fails. The
+// photos have to be closed with closePhotos.
+func openPhotos(c echo.Context) ([]reviews.Photo, error) {
+	form, err := c.MultipartForm()
+	if err != nil {
+		if errors.Is(err, http.ErrNotMultipart) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	var result []reviews.Photo
+	for _, file := range form.File["photos"] {
+		src, err := file.Open()
+		if err != nil {
+			closePhotos(result)
+			return nil, err
+		}
+
+		result = append(result, reviews.Photo{Raw: src, Size: file.Size, Type: file.Header.Get("Content-Type")})
+	}
+
+	return result, nil
+}
+
+func closePhotos(photos []reviews.Photo) {
+	for _, photo := range photos {
+		if closer, ok := photo.Raw.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
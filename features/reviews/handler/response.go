@@ -1 +1,60 @@
 package handler
+
+import (
+	"time"
+	"wanderer/features/reviews"
+)
+
+type ReviewResponse struct {
+	Id     uint    `json:"review_id"`
+	TourId uint    `json:"tour_id"`
+	Text   string  `json:"text"`
+	Rating float32 `json:"rating"`
+	Status string  `json:"status,omitempty"`
+	Flags  *int    `json:"flags,omitempty"`
+
+	Helpful int             `json:"helpful"`
+	Photos  []PhotoResponse `json:"photos"`
+
+	User UserResponse `json:"user"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// FromEntity fills res from ent. The moderation state of a review is only
+// shown to admins.
+func (res *ReviewResponse) FromEntity(ent reviews.Review, admin bool) {
+	res.Id = ent.Id
+	res.TourId = ent.TourId
+	res.Text = ent.Text
+	res.Rating = ent.Rating
+
+	if admin {
+		res.Status = ent.Status
+		res.Flags = &ent.Flags
+	}
+
+	res.Helpful = ent.Helpful
+
+	res.Photos = make([]PhotoResponse, 0, len(ent.Photos))
+	for _, photo := range ent.Photos {
+		res.Photos = append(res.Photos, PhotoResponse{Id: photo.Id, Url: photo.Url})
+	}
+
+	res.User = UserResponse{Id: ent.User.Id, Name: ent.User.Name, Image: ent.User.Image}
+
+	res.CreatedAt = ent.CreatedAt
+	res.UpdatedAt = ent.UpdatedAt
+}
+
+type UserResponse struct {
+	Id    uint   `json:"user_id"`
+	Name  string `json:"name"`
+	Image string `json:"image"`
+}
+
+type PhotoResponse struct {
+	Id  uint   `json:"photo_id"`
+	Url string `json:"url"`
+}
@@ -3,9 +3,12 @@
 package mocks
 
 import (
-	reviews "wanderer/features/reviews"
+	context "context"
+	filters "wanderer/helpers/filters"
 
 	mock "github.com/stretchr/testify/mock"
+
+	reviews "wanderer/features/reviews"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -13,13 +16,13 @@ type Repository struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: userId, newReview
-func (_m *Repository) Create(userId uint, newReview reviews.Review) error {
-	ret := _m.Called(userId, newReview)
+// AddPhotos provides a mock function with given fields: ctx, id, photos
+func (_m *Repository) AddPhotos(ctx context.Context, id uint, photos []reviews.Photo) error {
+	ret := _m.Called(ctx, id, photos)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uint, reviews.Review) error); ok {
-		r0 = rf(userId, newReview)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, []reviews.Photo) error); ok {
+		r0 = rf(ctx, id, photos)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -27,25 +30,192 @@ func (_m *Repository) Create(userId uint, newReview reviews.Review) error {
 	return r0
 }
 
-// GetTourById provides a mock function with given fields: tourId
-func (_m *Repository) GetTourById(tourId uint) (*reviews.Tour, error) {
-	ret := _m.Called(tourId)
+// Create provides a mock function with given fields: ctx, userId, newReview
+func (_m *Repository) Create(ctx context.Context, userId uint, newReview reviews.Review) error {
+	ret := _m.Called(ctx, userId, newReview)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, reviews.Review) error); ok {
+		r0 = rf(ctx, userId, newReview)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *Repository) Delete(ctx context.Context, id uint) ([]reviews.Photo, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 []reviews.Photo
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]reviews.Photo, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) []reviews.Photo); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]reviews.Photo)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeletePhoto provides a mock function with given fields: ctx, id, photoId
+func (_m *Repository) DeletePhoto(ctx context.Context, id uint, photoId uint) error {
+	ret := _m.Called(ctx, id, photoId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, id, photoId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DiscardPhotos provides a mock function with given fields: ctx, photos
+func (_m *Repository) DiscardPhotos(ctx context.Context, photos []reviews.Photo) error {
+	ret := _m.Called(ctx, photos)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, []reviews.Photo) error); ok {
+		r0 = rf(ctx, photos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Flag provides a mock function with given fields: ctx, data
+func (_m *Repository) Flag(ctx context.Context, data reviews.Flag) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, reviews.Flag) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, flt
+func (_m *Repository) GetAll(ctx context.Context, flt filters.Filter) ([]reviews.Review, int, error) {
+	ret := _m.Called(ctx, flt)
+
+	var r0 []reviews.Review
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) ([]reviews.Review, int, error)); ok {
+		return rf(ctx, flt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) []reviews.Review); ok {
+		r0 = rf(ctx, flt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]reviews.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, filters.Filter) int); ok {
+		r1 = rf(ctx, flt)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, filters.Filter) error); ok {
+		r2 = rf(ctx, flt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GetApprovedBookings provides a mock function with given fields: ctx, tourId, userId
+func (_m *Repository) GetApprovedBookings(ctx context.Context, tourId uint, userId uint) ([]reviews.Booking, error) {
+	ret := _m.Called(ctx, tourId, userId)
+
+	var r0 []reviews.Booking
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) ([]reviews.Booking, error)); ok {
+		return rf(ctx, tourId, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) []reviews.Booking); ok {
+		r0 = rf(ctx, tourId, userId)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]reviews.Booking)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, tourId, userId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetById provides a mock function with given fields: ctx, id
+func (_m *Repository) GetById(ctx context.Context, id uint) (*reviews.Review, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 *reviews.Review
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*reviews.Review, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *reviews.Review); ok {
+		r0 = rf(ctx, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*reviews.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetTourById provides a mock function with given fields: ctx, tourId
+func (_m *Repository) GetTourById(ctx context.Context, tourId uint) (*reviews.Tour, error) {
+	ret := _m.Called(ctx, tourId)
 
 	var r0 *reviews.Tour
 	var r1 error
-	if rf, ok := ret.Get(0).(func(uint) (*reviews.Tour, error)); ok {
-		return rf(tourId)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*reviews.Tour, error)); ok {
+		return rf(ctx, tourId)
 	}
-	if rf, ok := ret.Get(0).(func(uint) *reviews.Tour); ok {
-		r0 = rf(tourId)
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *reviews.Tour); ok {
+		r0 = rf(ctx, tourId)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).(*reviews.Tour)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(uint) error); ok {
-		r1 = rf(tourId)
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, tourId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -53,13 +223,13 @@ func (_m *Repository) GetTourById(tourId uint) (*reviews.Tour, error) {
 	return r0, r1
 }
 
-// IsApproved provides a mock function with given fields: tourId, userId
-func (_m *Repository) IsApproved(tourId uint, userId uint) bool {
-	ret := _m.Called(tourId, userId)
+// IsBooking provides a mock function with given fields: ctx, tourId, userId
+func (_m *Repository) IsBooking(ctx context.Context, tourId uint, userId uint) bool {
+	ret := _m.Called(ctx, tourId, userId)
 
 	var r0 bool
-	if rf, ok := ret.Get(0).(func(uint, uint) bool); ok {
-		r0 = rf(tourId, userId)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) bool); ok {
+		r0 = rf(ctx, tourId, userId)
 	} else {
 		r0 = ret.Get(0).(bool)
 	}
@@ -67,15 +237,57 @@ func (_m *Repository) IsApproved(tourId uint, userId uint) bool {
 	return r0
 }
 
-// IsBooking provides a mock function with given fields: tourId, userId
-func (_m *Repository) IsBooking(tourId uint, userId uint) bool {
-	ret := _m.Called(tourId, userId)
+// Unvote provides a mock function with given fields: ctx, data
+func (_m *Repository) Unvote(ctx context.Context, data reviews.Vote) error {
+	ret := _m.Called(ctx, data)
 
-	var r0 bool
-	if rf, ok := ret.Get(0).(func(uint, uint) bool); ok {
-		r0 = rf(tourId, userId)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, reviews.Vote) error); ok {
+		r0 = rf(ctx, data)
 	} else {
-		r0 = ret.Get(0).(bool)
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, id, data
+func (_m *Repository) Update(ctx context.Context, id uint, data reviews.Review) error {
+	ret := _m.Called(ctx, id, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, reviews.Review) error); ok {
+		r0 = rf(ctx, id, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, id, status
+func (_m *Repository) UpdateStatus(ctx context.Context, id uint, status string) error {
+	ret := _m.Called(ctx, id, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Vote provides a mock function with given fields: ctx, data
+func (_m *Repository) Vote(ctx context.Context, data reviews.Vote) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, reviews.Vote) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
 	}
 
 	return r0
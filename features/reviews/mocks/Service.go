@@ -3,9 +3,12 @@
 package mocks
 
 import (
-	reviews "wanderer/features/reviews"
+	context "context"
+	filters "wanderer/helpers/filters"
 
 	mock "github.com/stretchr/testify/mock"
+
+	reviews "wanderer/features/reviews"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -13,13 +16,158 @@ type Service struct {
 	mock.Mock
 }
 
-// Create provides a mock function with given fields: userId, newReview
-func (_m *Service) Create(userId uint, newReview reviews.Review) error {
-	ret := _m.Called(userId, newReview)
+// AddPhotos provides a mock function with given fields: ctx, userId, id, photos
+func (_m *Service) AddPhotos(ctx context.Context, userId uint, id uint, photos []reviews.Photo) error {
+	ret := _m.Called(ctx, userId, id, photos)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, []reviews.Photo) error); ok {
+		r0 = rf(ctx, userId, id, photos)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Create provides a mock function with given fields: ctx, userId, newReview
+func (_m *Service) Create(ctx context.Context, userId uint, newReview reviews.Review) error {
+	ret := _m.Called(ctx, userId, newReview)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, reviews.Review) error); ok {
+		r0 = rf(ctx, userId, newReview)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, userId, admin, id
+func (_m *Service) Delete(ctx context.Context, userId uint, admin bool, id uint) error {
+	ret := _m.Called(ctx, userId, admin, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, bool, uint) error); ok {
+		r0 = rf(ctx, userId, admin, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeletePhoto provides a mock function with given fields: ctx, userId, admin, id, photoId
+func (_m *Service) DeletePhoto(ctx context.Context, userId uint, admin bool, id uint, photoId uint) error {
+	ret := _m.Called(ctx, userId, admin, id, photoId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, bool, uint, uint) error); ok {
+		r0 = rf(ctx, userId, admin, id, photoId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Flag provides a mock function with given fields: ctx, data
+func (_m *Service) Flag(ctx context.Context, data reviews.Flag) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, reviews.Flag) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, admin, flt
+func (_m *Service) GetAll(ctx context.Context, admin bool, flt filters.Filter) ([]reviews.Review, int, error) {
+	ret := _m.Called(ctx, admin, flt)
+
+	var r0 []reviews.Review
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, bool, filters.Filter) ([]reviews.Review, int, error)); ok {
+		return rf(ctx, admin, flt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, bool, filters.Filter) []reviews.Review); ok {
+		r0 = rf(ctx, admin, flt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]reviews.Review)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, bool, filters.Filter) int); ok {
+		r1 = rf(ctx, admin, flt)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, bool, filters.Filter) error); ok {
+		r2 = rf(ctx, admin, flt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// Moderate provides a mock function with given fields: ctx, id, status
+func (_m *Service) Moderate(ctx context.Context, id uint, status string) error {
+	ret := _m.Called(ctx, id, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, id, status)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Unvote provides a mock function with given fields: ctx, data
+func (_m *Service) Unvote(ctx context.Context, data reviews.Vote) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, reviews.Vote) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Update provides a mock function with given fields: ctx, userId, id, data
+func (_m *Service) Update(ctx context.Context, userId uint, id uint, data reviews.Review) error {
+	ret := _m.Called(ctx, userId, id, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, reviews.Review) error); ok {
+		r0 = rf(ctx, userId, id, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Vote provides a mock function with given fields: ctx, data
+func (_m *Service) Vote(ctx context.Context, data reviews.Vote) error {
+	ret := _m.Called(ctx, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(uint, reviews.Review) error); ok {
-		r0 = rf(userId, newReview)
+	if rf, ok := ret.Get(0).(func(context.Context, reviews.Vote) error); ok {
+		r0 = rf(ctx, data)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -0,0 +1,82 @@
+package reviews
+
+import "math"
+
+// The score of a tour weighs its average rating against PriorWeight imaginary
+// reviews rated PriorRating, the middle of the scale, so a tour needs plenty
+// of reviews before its score gets close to its average.
+const (
+	PriorRating = 3
+	PriorWeight = 10
+)
+
+// Ratings sums up the reviews of a tour that count toward its rating, i.e.
+// the ones that aren't hidden. Stars holds how many of them round to each of
+// 1 to 5 stars.
+type Ratings struct {
+	Count int
+	Total float64
+	Stars [5]int
+}
+
+// Star is the number of stars, 1 to 5, rating rounds to.
+func Star(rating float32) int {
+	star := int(math.Floor(float64(rating) + 0.5))
+	if star < 1 {
+		return 1
+	}
+	if star > 5 {
+		return 5
+	}
+
+	return star
+}
+
+// RoundRating rounds rating to the 2 decimals a review rating is stored with.
+func RoundRating(rating float32) float32 {
+	return float32(roundTotal(float64(rating)))
+}
+
+// roundTotal rounds a sum of ratings to the decimals of its terms, so adding
+// and removing them doesn't drift away from what the stored ratings add up to.
+func roundTotal(total float64) float64 {
+	return math.Round(total*100) / 100
+}
+
+// Add counts a review rated rating in.
+func (r *Ratings) Add(rating float32) {
+	r.Count++
+	r.Total = roundTotal(r.Total + float64(RoundRating(rating)))
+	r.Stars[Star(rating)-1]++
+}
+
+// Remove takes a review rated rating, counted in by Add, back out.
+func (r *Ratings) Remove(rating float32) {
+	if r.Count == 0 {
+		return
+	}
+
+	r.Count--
+	r.Total = roundTotal(r.Total - float64(RoundRating(rating)))
+	if r.Count == 0 {
+		r.Total = 0
+	}
+
+	if star := Star(rating) - 1; r.Stars[star] > 0 {
+		r.Stars[star]--
+	}
+}
+
+// Average is the mean rating, or 0 without any review.
+func (r Ratings) Average() float32 {
+	if r.Count == 0 {
+		return 0
+	}
+
+	return float32(r.Total / float64(r.Count))
+}
+
+// Score is the Bayesian average of the ratings, the one tours are ranked by.
+func (r Ratings) Score() float32 {
+	return float32((PriorRating*PriorWeight + r.Total) / float64(PriorWeight+r.Count))
+}
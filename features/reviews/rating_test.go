@@ -0,0 +1,76 @@
+package reviews_test
+
+import (
+	"testing"
+	"wanderer/features/reviews"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStar(t *testing.T) {
+	assert.Equal(t, 1, reviews.Star(1))
+	assert.Equal(t, 1, reviews.Star(1.4))
+	assert.Equal(t, 3, reviews.Star(2.5))
+	assert.Equal(t, 5, reviews.Star(4.8))
+	assert.Equal(t, 5, reviews.Star(5))
+}
+
+func TestRatings(t *testing.T) {
+	var ratings reviews.Ratings
+
+	assert.Equal(t, float32(0), ratings.Average())
+	assert.Equal(t, float32(reviews.PriorRating), ratings.Score())
+
+	ratings.Add(5)
+	ratings.Add(4)
+	ratings.Add(3.6)
+
+	assert.Equal(t, 3, ratings.Count)
+	assert.Equal(t, [5]int{0, 0, 0, 2, 1}, ratings.Stars)
+	assert.InDelta(t, 4.2, ratings.Average(), 0.0001)
+
+	ratings.Remove(4)
+
+	assert.Equal(t, 2, ratings.Count)
+	assert.Equal(t, [5]int{0, 0, 0, 1, 1}, ratings.Stars)
+	assert.InDelta(t, 4.3, ratings.Average(), 0.0001)
+
+	ratings.Remove(5)
+	ratings.Remove(3.6)
+	ratings.Remove(3.6)
+
+	assert.Equal(t, reviews.Ratings{}, ratings)
+}
+
+func TestRatingsRounding(t *testing.T) {
+	var ratings reviews.Ratings
+
+	assert.Equal(t, float32(4.33), reviews.RoundRating(4.3333))
+
+	for i := 0; i < 1000; i++ {
+		ratings.Add(4.3333)
+		ratings.Add(3.17)
+	}
+
+	assert.Equal(t, 7500.0, ratings.Total)
+
+	for i := 0; i < 1000; i++ {
+		ratings.Remove(4.3333)
+		ratings.Remove(3.17)
+	}
+
+	assert.Equal(t, reviews.Ratings{}, ratings)
+}
+
+func TestRatingsScore(t *testing.T) {
+	var single, many reviews.Ratings
+
+	single.Add(5)
+	for i := 0; i < 200; i++ {
+		many.Add(4.8)
+	}
+
+	assert.Greater(t, single.Average(), many.Average())
+	assert.Greater(t, many.Score(), single.Score())
+	assert.InDelta(t, 4.71, many.Score(), 0.01)
+}
@@ -3,6 +3,8 @@ package repository
 import (
 	"time"
 	"wanderer/features/reviews"
+
+	"gorm.io/gorm"
 )
 
 type Review struct {
@@ -12,8 +14,15 @@ type Review struct {
 	TourId uint    `gorm:"column:tour_id;"`
 	Text   string  `gorm:"column:text; type:text;"`
 	Rating float32 `gorm:"column:rating; type:float(8,2);"`
+	Status string  `gorm:"column:status; type:enum('visible', 'flagged', 'hidden'); default:'visible'; index;"`
+
+	Helpful int `gorm:"column:helpful; default:0; index;"`
+
+	Photos []ReviewPhoto `gorm:"foreignKey:ReviewId;"`
 
 	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
 }
 
 func (mod *Review) FromEntity(ent reviews.Review) {
@@ -32,6 +41,10 @@ func (mod *Review) FromEntity(ent reviews.Review) {
 	if ent.Rating != 0 {
 		mod.Rating = ent.Rating
 	}
+
+	if ent.Status != "" {
+		mod.Status = ent.Status
+	}
 }
 
 func (mod *Review) ToEntity() *reviews.Review {
@@ -45,6 +58,14 @@ func (mod *Review) ToEntity() *reviews.Review {
 		ent.User.Id = mod.UserId
 	}
 
+	if mod.User.Name != "" {
+		ent.User.Name = mod.User.Name
+	}
+
+	if mod.User.Image != "" {
+		ent.User.Image = mod.User.Image
+	}
+
 	if mod.TourId != 0 {
 		ent.TourId = mod.TourId
 	}
@@ -57,25 +78,108 @@ func (mod *Review) ToEntity() *reviews.Review {
 		ent.Rating = mod.Rating
 	}
 
+	if mod.Status != "" {
+		ent.Status = mod.Status
+	}
+
+	ent.Helpful = mod.Helpful
+
+	for _, photo := range mod.Photos {
+		ent.Photos = append(ent.Photos, photo.ToEntity())
+	}
+
 	if !mod.CreatedAt.IsZero() {
 		ent.CreatedAt = mod.CreatedAt
 	}
 
+	if !mod.UpdatedAt.IsZero() {
+		ent.UpdatedAt = mod.UpdatedAt
+	}
+
 	return ent
 }
 
+type ReviewPhoto struct {
+	Id       uint   `gorm:"column:id; primaryKey;"`
+	ReviewId uint   `gorm:"column:review_id; index;"`
+	Url      string `gorm:"column:url; type:text;"`
+
+	CreatedAt time.Time
+}
+
+func (mod *ReviewPhoto) ToEntity() reviews.Photo {
+	return reviews.Photo{
+		Id:  mod.Id,
+		Url: mod.Url,
+	}
+}
+
+// ReviewVote marks a review as helpful to a user. A user can only vote once
+// per review.
+type ReviewVote struct {
+	Id       uint   `gorm:"column:id; primaryKey;"`
+	ReviewId uint   `gorm:"column:review_id; uniqueIndex:idx_review_votes_user;"`
+	Review   Review `gorm:"foreignKey:ReviewId;"`
+	UserId   uint   `gorm:"column:user_id; uniqueIndex:idx_review_votes_user;"`
+
+	CreatedAt time.Time
+}
+
+// ReviewFlag is a report of a review. A user can only flag a review once.
+type ReviewFlag struct {
+	Id       uint   `gorm:"column:id; primaryKey;"`
+	ReviewId uint   `gorm:"column:review_id; uniqueIndex:idx_review_flags_user;"`
+	Review   Review `gorm:"foreignKey:ReviewId;"`
+	UserId   uint   `gorm:"column:user_id; uniqueIndex:idx_review_flags_user;"`
+	Reason   string `gorm:"column:reason; type:text;"`
+
+	CreatedAt time.Time
+}
+
+func (mod *ReviewFlag) FromEntity(ent reviews.Flag) {
+	if ent.ReviewId != 0 {
+		mod.ReviewId = ent.ReviewId
+	}
+
+	if ent.UserId != 0 {
+		mod.UserId = ent.UserId
+	}
+
+	if ent.Reason != "" {
+		mod.Reason = ent.Reason
+	}
+}
+
 type User struct {
-	Id uint
+	Id    uint
+	Name  string `gorm:"column:fullname;"`
+	Image string
 }
 
 type Tour struct {
 	Id     uint
 	Finish time.Time
 	Start  time.Time
+
+	ReviewCount int
+	RatingTotal float64
+	Rating1     int `gorm:"column:rating_1;"`
+	Rating2     int `gorm:"column:rating_2;"`
+	Rating3     int `gorm:"column:rating_3;"`
+	Rating4     int `gorm:"column:rating_4;"`
+	Rating5     int `gorm:"column:rating_5;"`
+}
+
+func (mod *Tour) Ratings() reviews.Ratings {
+	return reviews.Ratings{
+		Count: mod.ReviewCount,
+		Total: mod.RatingTotal,
+		Stars: [5]int{mod.Rating1, mod.Rating2, mod.Rating3, mod.Rating4, mod.Rating5},
+	}
 }
 
 type Booking struct {
-	Code   int
+	Code   string
 	UserId uint
 	TourId uint
 	Status string
@@ -1,30 +1,41 @@
 package repository
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"wanderer/features/reviews"
+	"wanderer/helpers/filters"
+	"wanderer/utils/files"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-func NewReviewRepository(mysqlDB *gorm.DB) reviews.Repository {
+func NewReviewRepository(mysqlDB *gorm.DB, cloud files.Cloud) reviews.Repository {
 	return &reviewRepository{
 		mysqlDB: mysqlDB,
+		cloud:   cloud,
 	}
 }
 
 type reviewRepository struct {
 	mysqlDB *gorm.DB
+	cloud   files.Cloud
 }
 
-func (repo *reviewRepository) Create(userId uint, newReview reviews.Review) error {
+func (repo *reviewRepository) Create(ctx context.Context, userId uint, newReview reviews.Review) error {
 	var model = new(Review)
 	model.FromEntity(newReview)
 	model.UserId = userId
+	model.Status = reviews.StatusVisible
+	model.Rating = reviews.RoundRating(model.Rating)
 
+	// Checked again once the tour is locked, this only saves uploading the
+	// photos of a review that can't be made.
 	var exist int64
-	if err := repo.mysqlDB.Model(&Review{}).Where(&Review{TourId: model.TourId, UserId: userId}).Count(&exist).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Model(&Review{}).Where(&Review{TourId: model.TourId, UserId: userId}).Count(&exist).Error; err != nil {
 		return err
 	}
 
@@ -32,60 +43,500 @@ func (repo *reviewRepository) Create(userId uint, newReview reviews.Review) erro
 		return errors.New("used: review already exist")
 	}
 
-	if err := repo.mysqlDB.Create(model).Error; err != nil {
-		if strings.Contains(err.Error(), "1452") && strings.Contains(err.Error(), "tour") {
-			return errors.New("not found: tour not found")
+	photos, err := repo.upload(ctx, newReview.Photos)
+	if err != nil {
+		return err
+	}
+	model.Photos = photos
+
+	err = repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		ratings, err := lockTour(tx, model.TourId)
+		if err != nil {
+			return err
+		}
+
+		var exist int64
+		if err := tx.Model(&Review{}).Where(&Review{TourId: model.TourId, UserId: userId}).Count(&exist).Error; err != nil {
+			return err
+		}
+
+		if exist != 0 {
+			return errors.New("used: review already exist")
+		}
+
+		if err := tx.Omit("User").Create(model).Error; err != nil {
+			if strings.Contains(err.Error(), "1452") && strings.Contains(err.Error(), "tour") {
+				return errors.New("not found: tour not found")
+			}
+
+			return err
+		}
+
+		ratings.Add(model.Rating)
+
+		return saveRatings(tx, model.TourId, ratings)
+	})
+	if err != nil {
+		return errors.Join(err, repo.discard(ctx, photos))
+	}
+
+	return nil
+}
+
+func (repo *reviewRepository) GetAll(ctx context.Context, flt filters.Filter) ([]reviews.Review, int, error) {
+	var totalData int64
+
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Review{}).Joins("User").Preload("Photos")
+
+	if flt.Review.TourId != 0 {
+		qry = qry.Where("reviews.tour_id = ?", flt.Review.TourId)
+	}
+
+	if flt.Review.Status != "" {
+		qry = qry.Where("reviews.status = ?", flt.Review.Status)
+	}
+
+	if flt.Review.Visible {
+		qry = qry.Where("reviews.status <> ?", reviews.StatusHidden)
+	}
+
+	if flt.Search.Keyword != "" {
+		qry = qry.Where("reviews.text like ?", "%"+flt.Search.Keyword+"%")
+	}
+
+	if err := qry.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	dir := "asc"
+	if flt.Sort.Direction {
+		dir = "desc"
+	}
+
+	switch flt.Sort.Column {
+	case "rating", "helpful", "created_at":
+		qry = qry.Order("reviews." + flt.Sort.Column + " " + dir)
+	default:
+		qry = qry.Order("reviews.created_at desc")
+	}
+
+	if flt.Pagination.Limit != 0 {
+		qry = qry.Limit(flt.Pagination.Limit)
+	}
+
+	if flt.Pagination.Start != 0 {
+		qry = qry.Offset(flt.Pagination.Start)
+	}
+
+	var mods []Review
+	if err := qry.Find(&mods).Error; err != nil {
+		return nil, 0, err
+	}
+
+	flags, err := repo.countFlags(ctx, mods)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result []reviews.Review
+	for _, mod := range mods {
+		ent := mod.ToEntity()
+		ent.Flags = flags[mod.Id]
+
+		result = append(result, *ent)
+	}
+
+	return result, int(totalData), nil
+}
+
+// countFlags is the number of times each of mods has been flagged.
+func (repo *reviewRepository) countFlags(ctx context.Context, mods []Review) (map[uint]int, error) {
+	var result = make(map[uint]int)
+	if len(mods) == 0 {
+		return result, nil
+	}
+
+	var ids []uint
+	for _, mod := range mods {
+		ids = append(ids, mod.Id)
+	}
+
+	var counts []struct {
+		ReviewId uint
+		Total    int
+	}
+	if err := repo.mysqlDB.WithContext(ctx).Model(&ReviewFlag{}).Select("review_id, COUNT(*) AS total").Where("review_id IN ?", ids).Group("review_id").Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+
+	for _, count := range counts {
+		result[count.ReviewId] = count.Total
+	}
+
+	return result, nil
+}
+
+func (repo *reviewRepository) GetById(ctx context.Context, id uint) (*reviews.Review, error) {
+	var mod = new(Review)
+	if err := repo.mysqlDB.WithContext(ctx).Preload("Photos").Where(&Review{Id: id}).First(mod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found: review not found")
 		}
 
+		return nil, err
+	}
+
+	return mod.ToEntity(), nil
+}
+
+func (repo *reviewRepository) Update(ctx context.Context, id uint, data reviews.Review) error {
+	rating := reviews.RoundRating(data.Rating)
+
+	return repo.change(ctx, id, func(tx *gorm.DB, mod *Review, ratings *reviews.Ratings) error {
+		if counted(mod.Status) {
+			ratings.Remove(mod.Rating)
+			ratings.Add(rating)
+		}
+
+		return tx.Model(mod).Updates(map[string]any{"text": data.Text, "rating": rating}).Error
+	})
+}
+
+// Delete removes the review id along with its photos, returning the photos so
+// their files can be discarded once the review is gone.
+func (repo *reviewRepository) Delete(ctx context.Context, id uint) ([]reviews.Photo, error) {
+	var photos []reviews.Photo
+
+	err := repo.change(ctx, id, func(tx *gorm.DB, mod *Review, ratings *reviews.Ratings) error {
+		if counted(mod.Status) {
+			ratings.Remove(mod.Rating)
+		}
+
+		var modPhotos []ReviewPhoto
+		if err := tx.Where(&ReviewPhoto{ReviewId: mod.Id}).Find(&modPhotos).Error; err != nil {
+			return err
+		}
+
+		if len(modPhotos) != 0 {
+			if err := tx.Delete(&modPhotos).Error; err != nil {
+				return err
+			}
+		}
+
+		qry := tx.Delete(mod)
+		if err := qry.Error; err != nil {
+			return err
+		}
+
+		if qry.RowsAffected == 0 {
+			return errors.New("not found: review not found")
+		}
+
+		for _, photo := range modPhotos {
+			photos = append(photos, photo.ToEntity())
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return photos, nil
+}
+
+func (repo *reviewRepository) UpdateStatus(ctx context.Context, id uint, status string) error {
+	return repo.change(ctx, id, func(tx *gorm.DB, mod *Review, ratings *reviews.Ratings) error {
+		if counted(mod.Status) && !counted(status) {
+			ratings.Remove(mod.Rating)
+		}
+
+		if !counted(mod.Status) && counted(status) {
+			ratings.Add(mod.Rating)
+		}
+
+		return tx.Model(mod).Update("status", status).Error
+	})
+}
+
+// change applies fn to the review id, letting it adjust the ratings of its
+// tour to match, and saves them in the same transaction. It holds the row
+// locks of the review and its tour so concurrent changes to its reviews can't
+// leave stale ratings behind.
+func (repo *reviewRepository) change(ctx context.Context, id uint, fn func(tx *gorm.DB, mod *Review, ratings *reviews.Ratings) error) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var mod = new(Review)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "tour_id", "rating", "status").Where(&Review{Id: id}).First(mod).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("not found: review not found")
+			}
+
+			return err
+		}
+
+		ratings, err := lockTour(tx, mod.TourId)
+		if err != nil {
+			return err
+		}
+
+		if err := fn(tx, mod, &ratings); err != nil {
+			return err
+		}
+
+		return saveRatings(tx, mod.TourId, ratings)
+	})
+}
+
+// Flag records the report and queues the review for moderation, unless an
+// admin already hid it.
+func (repo *reviewRepository) Flag(ctx context.Context, data reviews.Flag) error {
+	var mod = new(ReviewFlag)
+	mod.FromEntity(data)
+
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Review").Create(mod).Error; err != nil {
+			if strings.Contains(err.Error(), "1062") {
+				return errors.New("used: review already flagged")
+			}
+
+			if strings.Contains(err.Error(), "1452") {
+				return errors.New("not found: review not found")
+			}
+
+			return err
+		}
+
+		return tx.Model(&Review{}).Where(&Review{Id: data.ReviewId, Status: reviews.StatusVisible}).Update("status", reviews.StatusFlagged).Error
+	})
+}
+
+// AddPhotos uploads photos and attaches them to the review id, as long as it
+// stays within reviews.MaxPhotos.
+func (repo *reviewRepository) AddPhotos(ctx context.Context, id uint, photos []reviews.Photo) error {
+	// Counted again once the review is locked, this only saves uploading
+	// photos that wouldn't fit.
+	if err := countPhotos(repo.mysqlDB.WithContext(ctx), id, len(photos)); err != nil {
 		return err
 	}
 
-	var averageRating float32
-	if err := repo.mysqlDB.Model(&Review{}).Where("tour_id = ?", model.TourId).Select("AVG(rating)").Scan(&averageRating).Error; err != nil {
+	mods, err := repo.upload(ctx, photos)
+	if err != nil {
 		return err
 	}
 
-	if err := repo.mysqlDB.Model(&Tour{}).Where(&Tour{Id: model.TourId}).Update("rating", averageRating).Error; err != nil {
+	err = repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := countPhotos(tx.Clauses(clause.Locking{Strength: "UPDATE"}), id, len(mods)); err != nil {
+			return err
+		}
+
+		for i := range mods {
+			mods[i].ReviewId = id
+		}
+
+		return tx.Create(&mods).Error
+	})
+	if err != nil {
+		return errors.Join(err, repo.discard(ctx, mods))
+	}
+
+	return nil
+}
+
+// countPhotos checks that the review id exists and has room for adding more
+// photos. The review is read through tx, so it can be locked.
+func countPhotos(tx *gorm.DB, id uint, adding int) error {
+	var modReview = new(Review)
+	if err := tx.Select("id").Where(&Review{Id: id}).First(modReview).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("not found: review not found")
+		}
+
 		return err
 	}
 
+	var total int64
+	if err := tx.Session(&gorm.Session{NewDB: true}).Model(&ReviewPhoto{}).Where(&ReviewPhoto{ReviewId: id}).Count(&total).Error; err != nil {
+		return err
+	}
+
+	if int(total)+adding > reviews.MaxPhotos {
+		return fmt.Errorf("unprocessable: a review can't have more than %d photos", reviews.MaxPhotos)
+	}
+
 	return nil
 }
 
-func (repo *reviewRepository) GetTourById(tourId uint) (*reviews.Tour, error) {
-	var model = new(Review)
-	model.TourId = tourId
+// DeletePhoto removes the photo photoId of the review id along with its file.
+// The photo stays when its file can't be removed, so it can be tried again.
+func (repo *reviewRepository) DeletePhoto(ctx context.Context, id uint, photoId uint) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var mod = new(ReviewPhoto)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&ReviewPhoto{Id: photoId, ReviewId: id}).First(mod).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("not found: photo not found")
+			}
+
+			return err
+		}
+
+		if err := tx.Delete(mod).Error; err != nil {
+			return err
+		}
+
+		return repo.cloud.Delete(ctx, mod.Url)
+	})
+}
+
+// upload stores photos in the cloud, ready to be saved.
+func (repo *reviewRepository) upload(ctx context.Context, photos []reviews.Photo) ([]ReviewPhoto, error) {
+	var result []ReviewPhoto
+	for _, photo := range photos {
+		url, err := repo.cloud.Upload(ctx, "reviews", photo.Raw)
+		if err != nil {
+			return nil, errors.Join(err, repo.discard(ctx, result))
+		}
 
+		result = append(result, ReviewPhoto{Url: *url})
+	}
+
+	return result, nil
+}
+
+// DiscardPhotos removes the files of photos no review holds anymore.
+func (repo *reviewRepository) DiscardPhotos(ctx context.Context, photos []reviews.Photo) error {
+	var mods = make([]ReviewPhoto, 0, len(photos))
+	for _, photo := range photos {
+		mods = append(mods, ReviewPhoto{Url: photo.Url})
+	}
+
+	return repo.discard(ctx, mods)
+}
+
+// discard removes the files of photos uploaded for a change that didn't go
+// through.
+func (repo *reviewRepository) discard(ctx context.Context, photos []ReviewPhoto) error {
+	var errs []error
+	for _, photo := range photos {
+		if err := repo.cloud.Delete(ctx, photo.Url); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Vote marks the review as helpful to the user and counts the vote in.
+func (repo *reviewRepository) Vote(ctx context.Context, data reviews.Vote) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var mod = &ReviewVote{ReviewId: data.ReviewId, UserId: data.UserId}
+		if err := tx.Omit("Review").Create(mod).Error; err != nil {
+			if strings.Contains(err.Error(), "1062") {
+				return errors.New("used: review already marked as helpful")
+			}
+
+			if strings.Contains(err.Error(), "1452") {
+				return errors.New("not found: review not found")
+			}
+
+			return err
+		}
+
+		return tx.Model(&Review{}).Where(&Review{Id: data.ReviewId}).Update("helpful", gorm.Expr("helpful + 1")).Error
+	})
+}
+
+// Unvote takes the vote of the user back.
+func (repo *reviewRepository) Unvote(ctx context.Context, data reviews.Vote) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		qry := tx.Where(&ReviewVote{ReviewId: data.ReviewId, UserId: data.UserId}).Delete(&ReviewVote{})
+		if qry.Error != nil {
+			return qry.Error
+		}
+
+		if qry.RowsAffected == 0 {
+			return errors.New("not found: vote not found")
+		}
+
+		return tx.Model(&Review{}).Where(&Review{Id: data.ReviewId}).Update("helpful", gorm.Expr("GREATEST(helpful - 1, 0)")).Error
+	})
+}
+
+func (repo *reviewRepository) GetTourById(ctx context.Context, tourId uint) (*reviews.Tour, error) {
 	var tour = new(reviews.Tour)
-	if err := repo.mysqlDB.Model(&Tour{}).Where(&Tour{Id: model.TourId}).First(&tour).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Model(&Tour{}).Where(&Tour{Id: tourId}).First(&tour).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found: tour not found")
+		}
+
 		return nil, err
 	}
 
 	return tour, nil
 }
 
-func (repo *reviewRepository) IsBooking(tourId uint, userId uint) bool {
+func (repo *reviewRepository) IsBooking(ctx context.Context, tourId uint, userId uint) bool {
 	var model = new(Review)
 	model.TourId = tourId
 	model.UserId = userId
 
 	var booking = new(reviews.Booking)
-	if err := repo.mysqlDB.Model(&Booking{}).Where(&Booking{TourId: model.TourId}, &Booking{UserId: model.UserId}).First(&booking).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).Where(&Booking{TourId: model.TourId}, &Booking{UserId: model.UserId}).First(&booking).Error; err != nil {
 		return false
 	}
 
 	return true
 }
 
-func (repo *reviewRepository) IsApproved(tourId uint, userId uint) bool {
-	var model = new(Review)
-	model.TourId = tourId
-	model.UserId = userId
+// GetApprovedBookings lists the approved bookings of the user on the tour,
+// each with the dates of the departure it was booked on.
+func (repo *reviewRepository) GetApprovedBookings(ctx context.Context, tourId uint, userId uint) ([]reviews.Booking, error) {
+	var result []reviews.Booking
 
-	var booking = new(reviews.Booking)
-	if err := repo.mysqlDB.Model(&Booking{}).Where(&Booking{TourId: model.TourId}, &Booking{UserId: model.UserId}, &Booking{Status: "approved"}).First(&booking).Error; err != nil {
-		return false
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).
+		Select("bookings.code", "bookings.user_id", "bookings.tour_id", "bookings.departure_id", "bookings.status", "tour_departures.start", "tour_departures.finish").
+		Joins("JOIN tour_departures ON tour_departures.id = bookings.departure_id").
+		Where("bookings.tour_id = ? AND bookings.user_id = ? AND bookings.status = ?", tourId, userId, "approved").
+		Where("bookings.deleted_at IS NULL")
+
+	if err := qry.Scan(&result).Error; err != nil {
+		return nil, err
 	}
 
-	return true
+	return result, nil
+}
+
+// lockTour locks the row of the tour tourId until tx ends and loads its
+// ratings.
+func lockTour(tx *gorm.DB, tourId uint) (reviews.Ratings, error) {
+	var mod = new(Tour)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "review_count", "rating_total", "rating_1", "rating_2", "rating_3", "rating_4", "rating_5").Where(&Tour{Id: tourId}).First(mod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return reviews.Ratings{}, errors.New("not found: tour not found")
+		}
+		return reviews.Ratings{}, err
+	}
+
+	return mod.Ratings(), nil
+}
+
+// saveRatings stores ratings as the ratings of the tour tourId, along with
+// the average and score they make up.
+func saveRatings(tx *gorm.DB, tourId uint, ratings reviews.Ratings) error {
+	return tx.Model(&Tour{}).Where(&Tour{Id: tourId}).Updates(map[string]any{
+		"review_count": ratings.Count,
+		"rating_total": ratings.Total,
+		"rating_1":     ratings.Stars[0],
+		"rating_2":     ratings.Stars[1],
+		"rating_3":     ratings.Stars[2],
+		"rating_4":     ratings.Stars[3],
+		"rating_5":     ratings.Stars[4],
+		"rating":       ratings.Average(),
+		"rating_score": ratings.Score(),
+	}).Error
+}
+
+// counted reports whether a review in status counts toward the ratings of its
+// tour.
+func counted(status string) bool {
+	return status != reviews.StatusHidden
 }
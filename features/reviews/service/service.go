@@ -1,9 +1,13 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 	"wanderer/features/reviews"
+	"wanderer/helpers/filters"
 )
 
 func NewReviewService(repo reviews.Repository) reviews.Service {
@@ -16,41 +20,304 @@ type reviewService struct {
 	repo reviews.Repository
 }
 
-func (srv *reviewService) Create(userId uint, newReview reviews.Review) error {
-	if newReview.Text == "" {
-		return errors.New("validate: review field can't be empty")
+func (srv *reviewService) Create(ctx context.Context, userId uint, newReview reviews.Review) error {
+	if err := validateReview(newReview); err != nil {
+		return err
 	}
-	if newReview.Rating == 0 {
-		return errors.New("validate: rating must be filled")
+
+	if err := validatePhotos(newReview.Photos, 0); err != nil {
+		return err
 	}
-	if newReview.Rating > 5.0 {
-		return errors.New("validate: rating is between 1 to 5")
+
+	if _, err := srv.repo.GetTourById(ctx, newReview.TourId); err != nil {
+		return err
+	}
+
+	if !srv.repo.IsBooking(ctx, newReview.TourId, userId) {
+		return errors.New("cannot create review: you have not booked the tour yet")
 	}
 
-	tour, err := srv.repo.GetTourById(newReview.TourId)
+	booked, err := srv.repo.GetApprovedBookings(ctx, newReview.TourId, userId)
 	if err != nil {
 		return err
 	}
 
-	if time.Now().Before(tour.Start) {
+	if len(booked) == 0 {
+		return errors.New("cannot create review: your transaction has not finished or has been canceled")
+	}
+
+	// The tour is reviewed once the user has been on it, i.e. once any of
+	// the departures they booked has finished.
+	now := time.Now()
+	var started, finished bool
+	for _, booking := range booked {
+		started = started || !now.Before(booking.Start)
+		finished = finished || !now.Before(booking.Finish)
+	}
+
+	if !started {
 		return errors.New("cannot create review: tour has not started yet")
 	}
 
-	if time.Now().Before(tour.Finish) {
+	if !finished {
 		return errors.New("cannot create review: tour has not finished yet")
 	}
 
-	if !srv.repo.IsBooking(newReview.TourId, userId) {
-		return errors.New("cannot create review: you have not booked the tour yet")
+	if err := srv.repo.Create(ctx, userId, newReview); err != nil {
+		return err
 	}
 
-	if !srv.repo.IsApproved(newReview.TourId, userId) {
-		return errors.New("cannot create review: your transaction has not finished or has been canceled")
+	return nil
+}
+
+// GetAll lists the reviews matching flt. Anyone but an admin only gets to see
+// the reviews of a single tour, leaving the hidden ones out.
+func (srv *reviewService) GetAll(ctx context.Context, admin bool, flt filters.Filter) ([]reviews.Review, int, error) {
+	if !admin {
+		if flt.Review.TourId == 0 {
+			return nil, 0, errors.New("validate: invalid tour id")
+		}
+
+		flt.Review.Status = ""
+		flt.Review.Visible = true
+	} else if flt.Review.Status != "" && !reviews.ValidStatus(flt.Review.Status) {
+		return nil, 0, errors.New("validate: invalid review status")
+	}
+
+	result, totalData, err := srv.repo.GetAll(ctx, flt)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return result, totalData, nil
+}
+
+// Update changes the text and rating of a review. Only its author can.
+func (srv *reviewService) Update(ctx context.Context, userId uint, id uint, data reviews.Review) error {
+	if id == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	if err := validateReview(data); err != nil {
+		return err
+	}
+
+	review, err := srv.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if review.User.Id != userId {
+		return errors.New("not found: review not found")
 	}
 
-	if err := srv.repo.Create(userId, newReview); err != nil {
+	if err := srv.repo.Update(ctx, id, data); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Delete removes a review and the files of its photos, either by its author
+// or by an admin.
+func (srv *reviewService) Delete(ctx context.Context, userId uint, admin bool, id uint) error {
+	if id == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	review, err := srv.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !admin && review.User.Id != userId {
+		return errors.New("not found: review not found")
+	}
+
+	photos, err := srv.repo.Delete(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if len(photos) != 0 {
+		return srv.repo.DiscardPhotos(ctx, photos)
+	}
+
+	return nil
+}
+
+// Flag reports the review of another user to the admins.
+func (srv *reviewService) Flag(ctx context.Context, data reviews.Flag) error {
+	if data.ReviewId == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	if data.Reason == "" {
+		return errors.New("validate: reason can't be empty")
+	}
+
+	review, err := srv.repo.GetById(ctx, data.ReviewId)
+	if err != nil {
+		return err
+	}
+
+	if review.Status == reviews.StatusHidden {
+		return errors.New("not found: review not found")
+	}
+
+	if review.User.Id == data.UserId {
+		return errors.New("validate: you can't flag your own review")
+	}
+
+	if err := srv.repo.Flag(ctx, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Moderate settles a review, either keeping it visible or hiding it.
+func (srv *reviewService) Moderate(ctx context.Context, id uint, status string) error {
+	if id == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	if status != reviews.StatusVisible && status != reviews.StatusHidden {
+		return errors.New("validate: a review can only be made visible or hidden")
+	}
+
+	if err := srv.repo.UpdateStatus(ctx, id, status); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// AddPhotos attaches more photos to a review. Only its author can.
+func (srv *reviewService) AddPhotos(ctx context.Context, userId uint, id uint, photos []reviews.Photo) error {
+	if id == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	if len(photos) == 0 {
+		return errors.New("validate: photos can't be empty")
+	}
+
+	review, err := srv.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if review.User.Id != userId {
+		return errors.New("not found: review not found")
+	}
+
+	if err := validatePhotos(photos, len(review.Photos)); err != nil {
+		return err
+	}
+
+	if err := srv.repo.AddPhotos(ctx, id, photos); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// DeletePhoto removes a photo of a review, either by its author or by an
+// admin.
+func (srv *reviewService) DeletePhoto(ctx context.Context, userId uint, admin bool, id uint, photoId uint) error {
+	if id == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	if photoId == 0 {
+		return errors.New("validate: invalid photo id")
+	}
+
+	review, err := srv.repo.GetById(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if !admin && review.User.Id != userId {
+		return errors.New("not found: review not found")
+	}
+
+	if err := srv.repo.DeletePhoto(ctx, id, photoId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Vote marks the review of another user as helpful.
+func (srv *reviewService) Vote(ctx context.Context, data reviews.Vote) error {
+	if data.ReviewId == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	review, err := srv.repo.GetById(ctx, data.ReviewId)
+	if err != nil {
+		return err
+	}
+
+	if review.Status == reviews.StatusHidden {
+		return errors.New("not found: review not found")
+	}
+
+	if review.User.Id == data.UserId {
+		return errors.New("validate: you can't vote on your own review")
+	}
+
+	if err := srv.repo.Vote(ctx, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (srv *reviewService) Unvote(ctx context.Context, data reviews.Vote) error {
+	if data.ReviewId == 0 {
+		return errors.New("validate: invalid review id")
+	}
+
+	if err := srv.repo.Unvote(ctx, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func validateReview(data reviews.Review) error {
+	if data.Text == "" {
+		return errors.New("validate: review field can't be empty")
+	}
+	if data.Rating == 0 {
+		return errors.New("validate: rating must be filled")
+	}
+	if data.Rating < 1 || data.Rating > 5.0 {
+		return errors.New("validate: rating is between 1 to 5")
+	}
+
+	return nil
+}
+
+// validatePhotos checks the photos to add to a review already holding
+// existing ones.
+func validatePhotos(photos []reviews.Photo, existing int) error {
+	if existing+len(photos) > reviews.MaxPhotos {
+		return fmt.Errorf("validate: a review can't have more than %d photos", reviews.MaxPhotos)
+	}
+
+	for _, photo := range photos {
+		if !strings.HasPrefix(photo.Type, "image/") {
+			return errors.New("validate: photos must be images")
+		}
+
+		if photo.Size > reviews.MaxPhotoSize {
+			return fmt.Errorf("validate: a photo can't be larger than %d MB", reviews.MaxPhotoSize>>20)
+		}
+	}
+
+	return nil
+}
@@ -1,12 +1,15 @@
 package service_test
 
 import (
+	"context"
 	"errors"
+	"strings"
 	"testing"
 	"time"
 	"wanderer/features/reviews"
 	"wanderer/features/reviews/mocks"
 	"wanderer/features/reviews/service"
+	"wanderer/helpers/filters"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -14,6 +17,7 @@ import (
 func TestReviewServiceCreate(t *testing.T) {
 	var repo = mocks.NewRepository(t)
 	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
 
 	t.Run("invalid review", func(t *testing.T) {
 		var caseData = reviews.Review{
@@ -21,7 +25,7 @@ func TestReviewServiceCreate(t *testing.T) {
 			Rating: 4.8,
 		}
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
 		assert.ErrorContains(t, err, "review")
 	})
@@ -32,7 +36,7 @@ func TestReviewServiceCreate(t *testing.T) {
 			Rating: 0,
 		}
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
 		assert.ErrorContains(t, err, "rating")
 	})
@@ -43,116 +47,128 @@ func TestReviewServiceCreate(t *testing.T) {
 			Rating: 5.1,
 		}
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
 		assert.ErrorContains(t, err, "rating")
 	})
 
+	t.Run("too many photos", func(t *testing.T) {
+		var caseData = reviews.Review{
+			Text:   "Good",
+			Rating: 4.8,
+			Photos: make([]reviews.Photo, reviews.MaxPhotos+1),
+		}
+
+		err := srv.Create(ctx, uint(1), caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "photos")
+	})
+
 	t.Run("Error get tour by id", func(t *testing.T) {
 		var caseData = reviews.Review{
 			Text:   "Good",
 			Rating: 4.9,
 		}
 
-		repo.On("GetTourById", uint(0)).Return(nil, errors.New("some error from repository")).Once()
+		repo.On("GetTourById", ctx, uint(0)).Return(nil, errors.New("some error from repository")).Once()
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
 		assert.ErrorContains(t, err, "some error from repository")
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("tour has not started yet", func(t *testing.T) {
+	t.Run("tour has not booked yet", func(t *testing.T) {
 		var caseData = reviews.Review{
 			Text:   "Good",
 			Rating: 4.9,
 			TourId: 1,
 		}
 
-		startTime := time.Now().Add(time.Hour)
-		var tour = &reviews.Tour{
-			Id:    uint(1),
-			Start: startTime,
-		}
+		repo.On("GetTourById", ctx, uint(1)).Return(&reviews.Tour{Id: 1}, nil).Once()
 
-		repo.On("GetTourById", uint(1)).Return(tour, nil).Once()
+		repo.On("IsBooking", ctx, caseData.TourId, uint(1)).Return(false).Once()
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
-		assert.ErrorContains(t, err, "cannot create review: tour has not started yet")
+		assert.ErrorContains(t, err, "cannot create review: you have not booked the tour yet")
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("tour has not finished yet", func(t *testing.T) {
+	t.Run("transaction not approved", func(t *testing.T) {
 		var caseData = reviews.Review{
 			Text:   "Good",
 			Rating: 4.9,
 			TourId: 1,
 		}
 
-		finishTime := time.Now().Add(time.Hour)
-		var tour = &reviews.Tour{
-			Id:     uint(1),
-			Finish: finishTime,
-		}
+		repo.On("GetTourById", ctx, uint(1)).Return(&reviews.Tour{Id: 1}, nil).Once()
 
-		repo.On("GetTourById", uint(1)).Return(tour, nil).Once()
+		repo.On("IsBooking", ctx, caseData.TourId, uint(1)).Return(true).Once()
 
-		err := srv.Create(uint(1), caseData)
+		repo.On("GetApprovedBookings", ctx, caseData.TourId, uint(1)).Return(nil, nil).Once()
 
-		assert.ErrorContains(t, err, "cannot create review: tour has not finished yet")
+		err := srv.Create(ctx, uint(1), caseData)
+
+		assert.ErrorContains(t, err, "cannot create review: your transaction has not finished or has been canceled")
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("tour has not booked yet", func(t *testing.T) {
+	t.Run("tour has not started yet", func(t *testing.T) {
 		var caseData = reviews.Review{
 			Text:   "Good",
 			Rating: 4.9,
 			TourId: 1,
 		}
 
-		finishTime := time.Now()
-		var tour = &reviews.Tour{
-			Id:     uint(1),
-			Finish: finishTime,
+		var booked = []reviews.Booking{
+			{Code: "1", DepartureId: 1, Start: time.Now().Add(time.Hour), Finish: time.Now().Add(48 * time.Hour)},
 		}
 
-		repo.On("GetTourById", uint(1)).Return(tour, nil).Once()
+		repo.On("GetTourById", ctx, uint(1)).Return(&reviews.Tour{Id: 1}, nil).Once()
 
-		repo.On("IsBooking", caseData.TourId, uint(1)).Return(false).Once()
+		repo.On("IsBooking", ctx, caseData.TourId, uint(1)).Return(true).Once()
 
-		err := srv.Create(uint(1), caseData)
+		repo.On("GetApprovedBookings", ctx, caseData.TourId, uint(1)).Return(booked, nil).Once()
 
-		assert.ErrorContains(t, err, "cannot create review: you have not booked the tour yet")
+		err := srv.Create(ctx, uint(1), caseData)
+
+		assert.ErrorContains(t, err, "cannot create review: tour has not started yet")
 
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("transaction not approved", func(t *testing.T) {
+	t.Run("booked departure has not finished yet", func(t *testing.T) {
 		var caseData = reviews.Review{
 			Text:   "Good",
 			Rating: 4.9,
 			TourId: 1,
 		}
 
-		finishTime := time.Now()
+		// The tour ran before on another departure, but the user is on the
+		// one still going.
 		var tour = &reviews.Tour{
 			Id:     uint(1),
-			Finish: finishTime,
+			Start:  time.Now().Add(-30 * 24 * time.Hour),
+			Finish: time.Now().Add(-20 * 24 * time.Hour),
+		}
+		var booked = []reviews.Booking{
+			{Code: "1", DepartureId: 2, Start: time.Now().Add(-time.Hour), Finish: time.Now().Add(time.Hour)},
 		}
 
-		repo.On("GetTourById", uint(1)).Return(tour, nil).Once()
+		repo.On("GetTourById", ctx, uint(1)).Return(tour, nil).Once()
 
-		repo.On("IsBooking", caseData.TourId, uint(1)).Return(true).Once()
+		repo.On("IsBooking", ctx, caseData.TourId, uint(1)).Return(true).Once()
 
-		repo.On("IsApproved", caseData.TourId, uint(1)).Return(false).Once()
+		repo.On("GetApprovedBookings", ctx, caseData.TourId, uint(1)).Return(booked, nil).Once()
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
-		assert.ErrorContains(t, err, "cannot create review: your transaction has not finished or has been canceled")
+		assert.ErrorContains(t, err, "cannot create review: tour has not finished yet")
 
 		repo.AssertExpectations(t)
 	})
@@ -164,21 +180,19 @@ func TestReviewServiceCreate(t *testing.T) {
 			TourId: 1,
 		}
 
-		finishTime := time.Now()
-		var tour = &reviews.Tour{
-			Id:     uint(1),
-			Finish: finishTime,
+		var booked = []reviews.Booking{
+			{Code: "1", DepartureId: 1, Start: time.Now().Add(-48 * time.Hour), Finish: time.Now()},
 		}
 
-		repo.On("GetTourById", uint(1)).Return(tour, nil).Once()
+		repo.On("GetTourById", ctx, uint(1)).Return(&reviews.Tour{Id: 1}, nil).Once()
 
-		repo.On("IsBooking", caseData.TourId, uint(1)).Return(true).Once()
+		repo.On("IsBooking", ctx, caseData.TourId, uint(1)).Return(true).Once()
 
-		repo.On("IsApproved", caseData.TourId, uint(1)).Return(true).Once()
+		repo.On("GetApprovedBookings", ctx, caseData.TourId, uint(1)).Return(booked, nil).Once()
 
-		repo.On("Create", uint(1), caseData).Return(errors.New("some error from repository")).Once()
+		repo.On("Create", ctx, uint(1), caseData).Return(errors.New("some error from repository")).Once()
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Create(ctx, uint(1), caseData)
 
 		assert.ErrorContains(t, err, "some error from repository")
 
@@ -192,21 +206,602 @@ func TestReviewServiceCreate(t *testing.T) {
 			TourId: 1,
 		}
 
-		finishTime := time.Now()
+		// The user booked the tour twice: the earlier departure has
+		// finished, the later one hasn't started yet.
 		var tour = &reviews.Tour{
 			Id:     uint(1),
-			Finish: finishTime,
+			Start:  time.Now().Add(20 * 24 * time.Hour),
+			Finish: time.Now().Add(30 * 24 * time.Hour),
 		}
+		var booked = []reviews.Booking{
+			{Code: "2", DepartureId: 2, Start: time.Now().Add(20 * 24 * time.Hour), Finish: time.Now().Add(30 * 24 * time.Hour)},
+			{Code: "1", DepartureId: 1, Start: time.Now().Add(-10 * 24 * time.Hour), Finish: time.Now().Add(-5 * 24 * time.Hour)},
+		}
+
+		repo.On("GetTourById", ctx, uint(1)).Return(tour, nil).Once()
+
+		repo.On("IsBooking", ctx, caseData.TourId, uint(1)).Return(true).Once()
+
+		repo.On("GetApprovedBookings", ctx, caseData.TourId, uint(1)).Return(booked, nil).Once()
+
+		repo.On("Create", ctx, uint(1), caseData).Return(nil).Once()
+
+		err := srv.Create(ctx, uint(1), caseData)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceGetAll(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var data = []reviews.Review{
+		{Id: 1, TourId: 1, Text: "Good", Rating: 4.5, Status: reviews.StatusVisible},
+		{Id: 2, TourId: 1, Text: "Bad", Rating: 1, Status: reviews.StatusFlagged, Flags: 2},
+	}
+
+	var filter = filters.Filter{
+		Pagination: filters.Pagination{Limit: 10},
+		Sort:       filters.Sort{Column: "rating", Direction: true},
+	}
+
+	t.Run("public needs a tour", func(t *testing.T) {
+		result, totalData, err := srv.GetAll(ctx, false, filter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "tour")
+		assert.Nil(t, result)
+		assert.Equal(t, 0, totalData)
+	})
+
+	t.Run("public doesn't see hidden reviews", func(t *testing.T) {
+		var caseFilter = filter
+		caseFilter.Review = filters.Review{TourId: 1, Status: reviews.StatusHidden}
+
+		var expectedFilter = filter
+		expectedFilter.Review = filters.Review{TourId: 1, Visible: true}
+
+		repo.On("GetAll", ctx, expectedFilter).Return(data, 2, nil).Once()
+
+		result, totalData, err := srv.GetAll(ctx, false, caseFilter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+		assert.Equal(t, 2, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid status for admin", func(t *testing.T) {
+		var caseFilter = filter
+		caseFilter.Review = filters.Review{Status: "deleted"}
+
+		_, _, err := srv.GetAll(ctx, true, caseFilter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "status")
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		var caseFilter = filter
+		caseFilter.Review = filters.Review{Status: reviews.StatusFlagged}
+
+		repo.On("GetAll", ctx, caseFilter).Return(nil, 0, errors.New("some error from repository")).Once()
+
+		result, totalData, err := srv.GetAll(ctx, true, caseFilter)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+		assert.Equal(t, 0, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("moderation queue", func(t *testing.T) {
+		var caseFilter = filter
+		caseFilter.Review = filters.Review{Status: reviews.StatusFlagged}
+
+		repo.On("GetAll", ctx, caseFilter).Return(data[1:], 1, nil).Once()
+
+		result, totalData, err := srv.GetAll(ctx, true, caseFilter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, data[1:], result)
+		assert.Equal(t, 1, totalData)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceUpdate(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var data = reviews.Review{Text: "Great trip", Rating: 5}
+	var review = &reviews.Review{Id: 1, TourId: 1, Text: "Good", Rating: 4, User: reviews.User{Id: 1}}
+
+	t.Run("invalid review id", func(t *testing.T) {
+		err := srv.Update(ctx, 1, 0, data)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "review id")
+	})
+
+	t.Run("invalid rating", func(t *testing.T) {
+		var caseData = data
+		caseData.Rating = -1
+
+		err := srv.Update(ctx, 1, 1, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "rating")
+	})
+
+	t.Run("review not found", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(nil, errors.New("not found: review not found")).Once()
+
+		err := srv.Update(ctx, 1, 1, data)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.Update(ctx, 2, 1, data)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Update", ctx, uint(1), data).Return(errors.New("some error from repository")).Once()
+
+		err := srv.Update(ctx, 1, 1, data)
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Update", ctx, uint(1), data).Return(nil).Once()
+
+		err := srv.Update(ctx, 1, 1, data)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceDelete(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var review = &reviews.Review{Id: 1, TourId: 1, Text: "Good", Rating: 4, User: reviews.User{Id: 1}}
+
+	t.Run("invalid review id", func(t *testing.T) {
+		err := srv.Delete(ctx, 1, false, 0)
+
+		assert.ErrorContains(t, err, "validate")
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.Delete(ctx, 2, false, 1)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin deletes any review", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Delete", ctx, uint(1)).Return(nil, nil).Once()
+
+		err := srv.Delete(ctx, 2, true, 1)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Delete", ctx, uint(1)).Return(nil, errors.New("some error from repository")).Once()
+
+		err := srv.Delete(ctx, 1, false, 1)
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error discarding photos", func(t *testing.T) {
+		var photos = []reviews.Photo{{Id: 1, Url: "https://cloud.test/reviews/1.jpg"}}
+
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Delete", ctx, uint(1)).Return(photos, nil).Once()
+		repo.On("DiscardPhotos", ctx, photos).Return(errors.New("some error from cloud")).Once()
+
+		err := srv.Delete(ctx, 1, false, 1)
+
+		assert.ErrorContains(t, err, "some error from cloud")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success with photos", func(t *testing.T) {
+		var photos = []reviews.Photo{{Id: 1, Url: "https://cloud.test/reviews/1.jpg"}, {Id: 2, Url: "https://cloud.test/reviews/2.jpg"}}
+
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Delete", ctx, uint(1)).Return(photos, nil).Once()
+		repo.On("DiscardPhotos", ctx, photos).Return(nil).Once()
+
+		err := srv.Delete(ctx, 1, false, 1)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Delete", ctx, uint(1)).Return(nil, nil).Once()
+
+		err := srv.Delete(ctx, 1, false, 1)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceFlag(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
 
-		repo.On("GetTourById", uint(1)).Return(tour, nil).Once()
+	var data = reviews.Flag{ReviewId: 1, UserId: 2, Reason: "Spam"}
+	var review = &reviews.Review{Id: 1, TourId: 1, Status: reviews.StatusVisible, User: reviews.User{Id: 1}}
 
-		repo.On("IsBooking", caseData.TourId, uint(1)).Return(true).Once()
+	t.Run("empty reason", func(t *testing.T) {
+		var caseData = data
+		caseData.Reason = ""
 
-		repo.On("IsApproved", caseData.TourId, uint(1)).Return(true).Once()
+		err := srv.Flag(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "reason")
+	})
+
+	t.Run("hidden review", func(t *testing.T) {
+		var hidden = *review
+		hidden.Status = reviews.StatusHidden
+
+		repo.On("GetById", ctx, uint(1)).Return(&hidden, nil).Once()
+
+		err := srv.Flag(ctx, data)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("own review", func(t *testing.T) {
+		var caseData = data
+		caseData.UserId = 1
+
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.Flag(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "own review")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("already flagged", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Flag", ctx, data).Return(errors.New("used: review already flagged")).Once()
+
+		err := srv.Flag(ctx, data)
+
+		assert.ErrorContains(t, err, "used")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Flag", ctx, data).Return(nil).Once()
+
+		err := srv.Flag(ctx, data)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceModerate(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	t.Run("invalid review id", func(t *testing.T) {
+		err := srv.Moderate(ctx, 0, reviews.StatusHidden)
+
+		assert.ErrorContains(t, err, "validate")
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		err := srv.Moderate(ctx, 1, reviews.StatusFlagged)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "visible or hidden")
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("UpdateStatus", ctx, uint(1), reviews.StatusHidden).Return(errors.New("some error from repository")).Once()
+
+		err := srv.Moderate(ctx, 1, reviews.StatusHidden)
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("UpdateStatus", ctx, uint(1), reviews.StatusVisible).Return(nil).Once()
+
+		err := srv.Moderate(ctx, 1, reviews.StatusVisible)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceAddPhotos(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var photos = []reviews.Photo{
+		{Raw: strings.NewReader("photo"), Size: 1 << 20, Type: "image/jpeg"},
+	}
+	var review = &reviews.Review{Id: 1, TourId: 1, User: reviews.User{Id: 1}, Photos: []reviews.Photo{{Id: 1, Url: "https://example.com/1.jpg"}}}
+
+	t.Run("empty photos", func(t *testing.T) {
+		err := srv.AddPhotos(ctx, 1, 1, nil)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "photos")
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.AddPhotos(ctx, 2, 1, photos)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("not an image", func(t *testing.T) {
+		var casePhotos = []reviews.Photo{{Raw: strings.NewReader("text"), Size: 4, Type: "text/plain"}}
+
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.AddPhotos(ctx, 1, 1, casePhotos)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "images")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("photo too large", func(t *testing.T) {
+		var casePhotos = []reviews.Photo{{Raw: strings.NewReader("photo"), Size: reviews.MaxPhotoSize + 1, Type: "image/png"}}
+
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.AddPhotos(ctx, 1, 1, casePhotos)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "larger")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("too many photos", func(t *testing.T) {
+		var full = *review
+		full.Photos = make([]reviews.Photo, reviews.MaxPhotos)
+
+		repo.On("GetById", ctx, uint(1)).Return(&full, nil).Once()
+
+		err := srv.AddPhotos(ctx, 1, 1, photos)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "more than")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("AddPhotos", ctx, uint(1), photos).Return(errors.New("some error from repository")).Once()
+
+		err := srv.AddPhotos(ctx, 1, 1, photos)
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("AddPhotos", ctx, uint(1), photos).Return(nil).Once()
+
+		err := srv.AddPhotos(ctx, 1, 1, photos)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceDeletePhoto(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var review = &reviews.Review{Id: 1, TourId: 1, User: reviews.User{Id: 1}}
+
+	t.Run("invalid photo id", func(t *testing.T) {
+		err := srv.DeletePhoto(ctx, 1, false, 1, 0)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "photo id")
+	})
+
+	t.Run("not the author", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.DeletePhoto(ctx, 2, false, 1, 1)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("photo not found", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("DeletePhoto", ctx, uint(1), uint(9)).Return(errors.New("not found: photo not found")).Once()
+
+		err := srv.DeletePhoto(ctx, 1, false, 1, 9)
+
+		assert.ErrorContains(t, err, "photo not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("admin deletes any photo", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("DeletePhoto", ctx, uint(1), uint(1)).Return(nil).Once()
+
+		err := srv.DeletePhoto(ctx, 2, true, 1, 1)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceVote(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var data = reviews.Vote{ReviewId: 1, UserId: 2}
+	var review = &reviews.Review{Id: 1, TourId: 1, Status: reviews.StatusVisible, User: reviews.User{Id: 1}}
+
+	t.Run("invalid review id", func(t *testing.T) {
+		err := srv.Vote(ctx, reviews.Vote{UserId: 2})
+
+		assert.ErrorContains(t, err, "validate")
+	})
+
+	t.Run("hidden review", func(t *testing.T) {
+		var hidden = *review
+		hidden.Status = reviews.StatusHidden
+
+		repo.On("GetById", ctx, uint(1)).Return(&hidden, nil).Once()
+
+		err := srv.Vote(ctx, data)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("own review", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+
+		err := srv.Vote(ctx, reviews.Vote{ReviewId: 1, UserId: 1})
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "own review")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("already voted", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Vote", ctx, data).Return(errors.New("used: review already marked as helpful")).Once()
+
+		err := srv.Vote(ctx, data)
+
+		assert.ErrorContains(t, err, "used")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetById", ctx, uint(1)).Return(review, nil).Once()
+		repo.On("Vote", ctx, data).Return(nil).Once()
+
+		err := srv.Vote(ctx, data)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestReviewServiceUnvote(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewReviewService(repo)
+	var ctx = context.Background()
+
+	var data = reviews.Vote{ReviewId: 1, UserId: 2}
+
+	t.Run("invalid review id", func(t *testing.T) {
+		err := srv.Unvote(ctx, reviews.Vote{UserId: 2})
+
+		assert.ErrorContains(t, err, "validate")
+	})
+
+	t.Run("vote not found", func(t *testing.T) {
+		repo.On("Unvote", ctx, data).Return(errors.New("not found: vote not found")).Once()
+
+		err := srv.Unvote(ctx, data)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
 
-		repo.On("Create", uint(1), caseData).Return(nil).Once()
+	t.Run("success", func(t *testing.T) {
+		repo.On("Unvote", ctx, data).Return(nil).Once()
 
-		err := srv.Create(uint(1), caseData)
+		err := srv.Unvote(ctx, data)
 
 		assert.NoError(t, err)
 
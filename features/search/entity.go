@@ -0,0 +1,63 @@
+package search
+
+import (
+	"context"
+	"time"
+	"wanderer/helpers/filters"
+
+	"github.com/labstack/echo/v4"
+)
+
+// TourPublished is the status of the tours a search can find.
+const TourPublished = "published"
+
+// Tour is a published tour found by a search. Score ranks it against the other
+// tours found, the higher the more relevant.
+type Tour struct {
+	Id          uint
+	Title       string
+	Description string
+	Price       float64
+	Discount    int
+	Thumbnail   string
+	Start       time.Time
+	Finish      time.Time
+	Score       float64
+
+	Location  Location
+	Itinerary []Itinerary
+}
+
+type Location struct {
+	Id   uint
+	Name string
+}
+
+type Itinerary struct {
+	Location    string
+	Description string
+}
+
+// Hit is a tour found by a search, along with snippets of the fields the
+// search matched.
+type Hit struct {
+	Tour       Tour
+	Highlights []Highlight
+}
+
+type Highlight struct {
+	Field   string
+	Snippet string
+}
+
+type Handler interface {
+	Search() echo.HandlerFunc
+}
+
+type Service interface {
+	Search(ctx context.Context, flt filters.Filter) ([]Hit, int, error)
+}
+
+type Repository interface {
+	Search(ctx context.Context, flt filters.Filter) ([]Tour, int, error)
+}
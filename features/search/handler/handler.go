@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"wanderer/features/search"
+	"wanderer/helpers/filters"
+	"wanderer/helpers/paginate"
+
+	echo "github.com/labstack/echo/v4"
+)
+
+func NewSearchHandler(searchService search.Service) search.Handler {
+	return &searchHandler{
+		searchService: searchService,
+	}
+}
+
+type searchHandler struct {
+	searchService search.Service
+}
+
+func (hdl *searchHandler) Search() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var filter = new(filters.Filter)
+
+		c.Bind(&filter.Pagination)
+		c.Bind(&filter.Search)
+		if filter.Pagination.Limit == 0 {
+			filter.Pagination.Limit = 10
+		}
+
+		result, totalData, err := hdl.searchService.Search(c.Request().Context(), *filter)
+		if err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		var data = make([]HitResponse, 0, len(result))
+		for _, hit := range result {
+			var tmpHit = new(HitResponse)
+			tmpHit.FromEntity(hit)
+
+			data = append(data, *tmpHit)
+		}
+		response["data"] = data
+
+		response["pagination"] = paginate.Links(c, filter.Pagination, totalData)
+
+		response["message"] = "search success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"time"
+	"wanderer/features/search"
+)
+
+type HitResponse struct {
+	Id        uint       `json:"tour_id"`
+	Title     string     `json:"title"`
+	Price     float64    `json:"price"`
+	Discount  int        `json:"discount"`
+	Thumbnail string     `json:"thumbnail"`
+	Start     time.Time  `json:"start"`
+	Finish    *time.Time `json:"finish,omitempty"`
+	Score     float64    `json:"score"`
+
+	Location LocationResponse `json:"location"`
+
+	Highlights []HighlightResponse `json:"highlights"`
+}
+
+func (res *HitResponse) FromEntity(ent search.Hit) {
+	res.Id = ent.Tour.Id
+	res.Title = ent.Tour.Title
+	res.Price = ent.Tour.Price
+	res.Discount = ent.Tour.Discount
+	res.Start = ent.Tour.Start
+	if !ent.Tour.Finish.IsZero() {
+		res.Finish = &ent.Tour.Finish
+	}
+	res.Score = ent.Tour.Score
+
+	if ent.Tour.Thumbnail != "" {
+		res.Thumbnail = ent.Tour.Thumbnail
+	} else {
+		res.Thumbnail = "default"
+	}
+
+	res.Location = LocationResponse{Id: ent.Tour.Location.Id, Name: ent.Tour.Location.Name}
+
+	res.Highlights = make([]HighlightResponse, 0, len(ent.Highlights))
+	for _, highlight := range ent.Highlights {
+		res.Highlights = append(res.Highlights, HighlightResponse{Field: highlight.Field, Snippet: highlight.Snippet})
+	}
+}
+
+type LocationResponse struct {
+	Id   uint   `json:"location_id"`
+	Name string `json:"name"`
+}
+
+type HighlightResponse struct {
+	Field   string `json:"field"`
+	Snippet string `json:"snippet"`
+}
@@ -0,0 +1,167 @@
+package search
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	FieldTitle       = "title"
+	FieldDescription = "description"
+	FieldLocation    = "location"
+	FieldItinerary   = "itinerary"
+)
+
+const (
+	snippetBefore = 8
+	snippetAfter  = 24
+)
+
+type span struct {
+	start int
+	end   int
+}
+
+// words are the spans of the letters and digits runs of text, the way a
+// FULLTEXT index splits it into words.
+func words(text []rune) []span {
+	var result []span
+
+	start := -1
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if start == -1 {
+				start = i
+			}
+			continue
+		}
+
+		if start != -1 {
+			result = append(result, span{start: start, end: i})
+			start = -1
+		}
+	}
+
+	if start != -1 {
+		result = append(result, span{start: start, end: len(text)})
+	}
+
+	return result
+}
+
+// Words are the lower cased words of text, in order.
+func Words(text string) []string {
+	runes := []rune(text)
+
+	var result []string
+	for _, word := range words(runes) {
+		result = append(result, strings.ToLower(string(runes[word.start:word.end])))
+	}
+
+	return result
+}
+
+// Terms are the distinct words of a search keyword.
+func Terms(keyword string) []string {
+	var seen = make(map[string]bool)
+
+	var result []string
+	for _, word := range Words(keyword) {
+		if !seen[word] {
+			seen[word] = true
+			result = append(result, word)
+		}
+	}
+
+	return result
+}
+
+// Snippet is the part of text around the first of terms found in it, HTML
+// escaped, with every term found wrapped in a mark element. It reports
+// whether text has any of terms at all.
+func Snippet(text string, terms []string) (string, bool) {
+	var match = make(map[string]bool)
+	for _, term := range terms {
+		match[term] = true
+	}
+
+	runes := []rune(text)
+	spans := words(runes)
+
+	first := -1
+	for i, word := range spans {
+		if match[strings.ToLower(string(runes[word.start:word.end]))] {
+			first = i
+			break
+		}
+	}
+
+	if first == -1 {
+		return "", false
+	}
+
+	from, to := first-snippetBefore, first+snippetAfter
+	if from < 0 {
+		from = 0
+	}
+	if to > len(spans)-1 {
+		to = len(spans) - 1
+	}
+
+	var builder strings.Builder
+	if from > 0 {
+		builder.WriteString("… ")
+	}
+
+	last, end := spans[from].start, spans[to].end
+	if from == 0 {
+		last = 0
+	}
+	if to == len(spans)-1 {
+		end = len(runes)
+	}
+
+	for _, word := range spans[from : to+1] {
+		content := string(runes[word.start:word.end])
+		if !match[strings.ToLower(content)] {
+			continue
+		}
+
+		builder.WriteString(html.EscapeString(string(runes[last:word.start])))
+		builder.WriteString("<mark>" + html.EscapeString(content) + "</mark>")
+		last = word.end
+	}
+	builder.WriteString(html.EscapeString(string(runes[last:end])))
+
+	if to < len(spans)-1 {
+		builder.WriteString(" …")
+	}
+
+	return builder.String(), true
+}
+
+// Highlights are the snippets of the fields of tour that have any of terms,
+// one per matching itinerary.
+func Highlights(tour Tour, terms []string) []Highlight {
+	var result []Highlight
+
+	var add = func(field string, texts ...string) {
+		for _, text := range texts {
+			if snippet, ok := Snippet(text, terms); ok {
+				result = append(result, Highlight{Field: field, Snippet: snippet})
+				return
+			}
+		}
+	}
+
+	add(FieldTitle, tour.Title)
+	add(FieldDescription, tour.Description)
+	add(FieldLocation, tour.Location.Name)
+
+	for _, itinerary := range tour.Itinerary {
+		add(FieldItinerary, itinerary.Description, itinerary.Location)
+	}
+
+	return result
+}
@@ -0,0 +1,55 @@
+package search_test
+
+import (
+	"testing"
+	"wanderer/features/search"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"snorkeling", "di", "raja", "ampat"}, search.Terms("Snorkeling di Raja-Ampat, snorkeling!"))
+	assert.Empty(t, search.Terms("  ,.!  "))
+}
+
+func TestSnippet(t *testing.T) {
+	terms := search.Terms("snorkeling")
+
+	snippet, ok := search.Snippet("Morning Snorkeling at <Pianemo>", terms)
+	assert.True(t, ok)
+	assert.Equal(t, "Morning <mark>Snorkeling</mark> at &lt;Pianemo&gt;", snippet)
+
+	_, ok = search.Snippet("Snorkel gear included", terms)
+	assert.False(t, ok)
+
+	long := "one two three four five six seven eight nine ten snorkeling eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour twentyfive twentysix twentyseven"
+	snippet, ok = search.Snippet(long, terms)
+	assert.True(t, ok)
+	assert.Equal(t, "… three four five six seven eight nine ten <mark>snorkeling</mark> eleven twelve thirteen fourteen fifteen sixteen seventeen eighteen nineteen twenty twentyone twentytwo twentythree twentyfour twentyfive twentysix twentyseven", snippet)
+}
+
+func TestHighlights(t *testing.T) {
+	tour := search.Tour{
+		Title:       "Raja Ampat Explorer",
+		Description: "Island hopping across the archipelago.",
+		Location:    search.Location{Id: 1, Name: "Papua"},
+		Itinerary: []search.Itinerary{
+			{Location: "Sorong", Description: "Arrival and transfer to the harbour."},
+			{Location: "Pianemo", Description: "Snorkeling over the reefs of Pianemo."},
+		},
+	}
+
+	result := search.Highlights(tour, search.Terms("pianemo snorkeling"))
+
+	assert.Equal(t, []search.Highlight{
+		{Field: search.FieldItinerary, Snippet: "<mark>Snorkeling</mark> over the reefs of <mark>Pianemo</mark>."},
+	}, result)
+
+	result = search.Highlights(tour, search.Terms("raja papua sorong"))
+
+	assert.Equal(t, []search.Highlight{
+		{Field: search.FieldTitle, Snippet: "<mark>Raja</mark> Ampat Explorer"},
+		{Field: search.FieldLocation, Snippet: "<mark>Papua</mark>"},
+		{Field: search.FieldItinerary, Snippet: "<mark>Sorong</mark>"},
+	}, result)
+}
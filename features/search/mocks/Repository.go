@@ -0,0 +1,64 @@
+// Code generated by mockery v2.37.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	filters "wanderer/helpers/filters"
+
+	mock "github.com/stretchr/testify/mock"
+
+	search "wanderer/features/search"
+)
+
+// Repository is an autogenerated mock type for the Repository type
+type Repository struct {
+	mock.Mock
+}
+
+// Search provides a mock function with given fields: ctx, flt
+func (_m *Repository) Search(ctx context.Context, flt filters.Filter) ([]search.Tour, int, error) {
+	ret := _m.Called(ctx, flt)
+
+	var r0 []search.Tour
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) ([]search.Tour, int, error)); ok {
+		return rf(ctx, flt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) []search.Tour); ok {
+		r0 = rf(ctx, flt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]search.Tour)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, filters.Filter) int); ok {
+		r1 = rf(ctx, flt)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, filters.Filter) error); ok {
+		r2 = rf(ctx, flt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRepository(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Repository {
+	mock := &Repository{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,64 @@
+// Code generated by mockery v2.37.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	filters "wanderer/helpers/filters"
+
+	mock "github.com/stretchr/testify/mock"
+
+	search "wanderer/features/search"
+)
+
+// Service is an autogenerated mock type for the Service type
+type Service struct {
+	mock.Mock
+}
+
+// Search provides a mock function with given fields: ctx, flt
+func (_m *Service) Search(ctx context.Context, flt filters.Filter) ([]search.Hit, int, error) {
+	ret := _m.Called(ctx, flt)
+
+	var r0 []search.Hit
+	var r1 int
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) ([]search.Hit, int, error)); ok {
+		return rf(ctx, flt)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) []search.Hit); ok {
+		r0 = rf(ctx, flt)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]search.Hit)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, filters.Filter) int); ok {
+		r1 = rf(ctx, flt)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, filters.Filter) error); ok {
+		r2 = rf(ctx, flt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewService(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Service {
+	mock := &Service{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
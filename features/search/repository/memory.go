@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"sort"
+	"wanderer/features/search"
+	"wanderer/helpers/filters"
+)
+
+// NewMemorySearchRepository searches data without a database. Every tour of
+// data is taken as searchable, so it should only hold published, upcoming
+// tours. It ranks the tours like the MySQL repository, counting the words of
+// the keyword in each field instead of using FULLTEXT relevance.
+func NewMemorySearchRepository(data []search.Tour) search.Repository {
+	return &memorySearchRepository{
+		data: data,
+	}
+}
+
+type memorySearchRepository struct {
+	data []search.Tour
+}
+
+func (repo *memorySearchRepository) Search(ctx context.Context, flt filters.Filter) ([]search.Tour, int, error) {
+	terms := search.Terms(flt.Search.Keyword)
+
+	var result []search.Tour
+	for _, tour := range repo.data {
+		score := 2*matches(terms, tour.Title, tour.Description) + matches(terms, tour.Location.Name)
+
+		var itinerary float64
+		for _, item := range tour.Itinerary {
+			if itemScore := matches(terms, item.Location, item.Description); itemScore > itinerary {
+				itinerary = itemScore
+			}
+		}
+		score += itinerary
+
+		if score == 0 {
+			continue
+		}
+
+		tour.Score = score
+		result = append(result, tour)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		if result[i].Score != result[j].Score {
+			return result[i].Score > result[j].Score
+		}
+
+		return result[i].Id < result[j].Id
+	})
+
+	totalData := len(result)
+
+	if flt.Pagination.Limit != 0 {
+		if flt.Pagination.Start >= len(result) {
+			return nil, totalData, nil
+		}
+
+		end := flt.Pagination.Start + flt.Pagination.Limit
+		if end > len(result) {
+			end = len(result)
+		}
+
+		result = result[flt.Pagination.Start:end]
+	}
+
+	return result, totalData, nil
+}
+
+// matches counts the words of texts that are one of terms.
+func matches(terms []string, texts ...string) float64 {
+	var match = make(map[string]bool)
+	for _, term := range terms {
+		match[term] = true
+	}
+
+	var total float64
+	for _, text := range texts {
+		for _, word := range search.Words(text) {
+			if match[word] {
+				total++
+			}
+		}
+	}
+
+	return total
+}
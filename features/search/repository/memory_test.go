@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"wanderer/features/search"
+	"wanderer/helpers/filters"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemorySearchRepositorySearch(t *testing.T) {
+	data := []search.Tour{
+		{
+			Id:          1,
+			Title:       "Bali Getaway",
+			Description: "Beaches and temples of Bali.",
+			Location:    search.Location{Id: 1, Name: "Bali"},
+			Itinerary: []search.Itinerary{
+				{Location: "Nusa Penida", Description: "Snorkeling with manta rays."},
+			},
+		},
+		{
+			Id:          2,
+			Title:       "Raja Ampat Snorkeling Trip",
+			Description: "Snorkeling and diving in Raja Ampat.",
+			Location:    search.Location{Id: 2, Name: "Papua"},
+		},
+		{
+			Id:          3,
+			Title:       "Tokyo City Tour",
+			Description: "Shopping and food in Tokyo.",
+			Location:    search.Location{Id: 3, Name: "Jepang"},
+		},
+		{
+			Id:          4,
+			Title:       "Komodo Sailing",
+			Description: "Sailing around Komodo.",
+			Location:    search.Location{Id: 4, Name: "Nusa Tenggara"},
+			Itinerary: []search.Itinerary{
+				{Location: "Pink Beach", Description: "Snorkeling at Pink Beach."},
+			},
+		},
+	}
+
+	repo := NewMemorySearchRepository(data)
+	ctx := context.Background()
+
+	t.Run("ranks the matching tours", func(t *testing.T) {
+		result, totalData, err := repo.Search(ctx, filters.Filter{Search: filters.Search{Keyword: "snorkeling"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, totalData)
+
+		var ids []uint
+		for _, tour := range result {
+			ids = append(ids, tour.Id)
+		}
+		assert.Equal(t, []uint{2, 1, 4}, ids)
+		assert.Equal(t, float64(4), result[0].Score)
+		assert.Equal(t, float64(1), result[1].Score)
+	})
+
+	t.Run("paginates", func(t *testing.T) {
+		result, totalData, err := repo.Search(ctx, filters.Filter{Search: filters.Search{Keyword: "snorkeling"}, Pagination: filters.Pagination{Start: 1, Limit: 1}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, totalData)
+		assert.Len(t, result, 1)
+		assert.Equal(t, uint(1), result[0].Id)
+
+		result, totalData, err = repo.Search(ctx, filters.Filter{Search: filters.Search{Keyword: "snorkeling"}, Pagination: filters.Pagination{Start: 5, Limit: 1}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 3, totalData)
+		assert.Nil(t, result)
+	})
+
+	t.Run("matches the location name", func(t *testing.T) {
+		result, totalData, err := repo.Search(ctx, filters.Filter{Search: filters.Search{Keyword: "jepang"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, totalData)
+		assert.Equal(t, uint(3), result[0].Id)
+	})
+
+	t.Run("no match", func(t *testing.T) {
+		result, totalData, err := repo.Search(ctx, filters.Filter{Search: filters.Search{Keyword: "ski"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, totalData)
+		assert.Nil(t, result)
+	})
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"time"
+	"wanderer/features/search"
+
+	"gorm.io/gorm"
+)
+
+type Tour struct {
+	Id          uint      `gorm:"column:id; primaryKey;"`
+	Title       string    `gorm:"column:title;"`
+	Description string    `gorm:"column:description;"`
+	Price       float64   `gorm:"column:price;"`
+	Discount    int       `gorm:"column:discount;"`
+	Thumbnail   string    `gorm:"column:thumbnail;"`
+	Start       time.Time `gorm:"column:start;"`
+	Finish      time.Time `gorm:"column:finish;"`
+	Status      string    `gorm:"column:status;"`
+
+	LocationId uint
+
+	DeletedAt gorm.DeletedAt
+}
+
+func (mod *Tour) TableName() string {
+	return "tours"
+}
+
+type Departure struct {
+	Id     uint      `gorm:"column:id; primaryKey;"`
+	TourId uint      `gorm:"column:tour_id;"`
+	Start  time.Time `gorm:"column:start;"`
+	Finish time.Time `gorm:"column:finish;"`
+
+	DeletedAt gorm.DeletedAt
+}
+
+func (mod *Departure) TableName() string {
+	return "tour_departures"
+}
+
+type Itinerary struct {
+	Id          int    `gorm:"column:id; primaryKey;"`
+	Location    string `gorm:"column:location;"`
+	Description string `gorm:"column:description;"`
+
+	TourId uint
+
+	DeletedAt gorm.DeletedAt
+}
+
+func (mod *Itinerary) TableName() string {
+	return "itineraries"
+}
+
+func (mod *Itinerary) ToEntity() search.Itinerary {
+	return search.Itinerary{
+		Location:    mod.Location,
+		Description: mod.Description,
+	}
+}
+
+// Result is a tour found by a search, with its location and relevance.
+type Result struct {
+	Id           uint
+	Title        string
+	Description  string
+	Price        float64
+	Discount     int
+	Thumbnail    string
+	Start        time.Time
+	Finish       time.Time
+	LocationId   uint
+	LocationName string
+	Score        float64
+}
+
+func (mod *Result) ToEntity() search.Tour {
+	return search.Tour{
+		Id:          mod.Id,
+		Title:       mod.Title,
+		Description: mod.Description,
+		Price:       mod.Price,
+		Discount:    mod.Discount,
+		Thumbnail:   mod.Thumbnail,
+		Start:       mod.Start,
+		Finish:      mod.Finish,
+		Score:       mod.Score,
+		Location: search.Location{
+			Id:   mod.LocationId,
+			Name: mod.LocationName,
+		},
+	}
+}
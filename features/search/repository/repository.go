@@ -0,0 +1,120 @@
+package repository
+
+import (
+	"context"
+	"time"
+	"wanderer/features/search"
+	"wanderer/helpers/filters"
+
+	"gorm.io/gorm"
+)
+
+func NewSearchRepository(mysqlDB *gorm.DB) search.Repository {
+	return &searchRepository{
+		mysqlDB: mysqlDB,
+	}
+}
+
+type searchRepository struct {
+	mysqlDB *gorm.DB
+}
+
+// Search finds the published, upcoming tours whose title, description,
+// location or itinerary match the keyword of flt, using the FULLTEXT indexes
+// of those columns. A match on the title or description weighs twice as much
+// as one on the location or the best matching itinerary.
+func (repo *searchRepository) Search(ctx context.Context, flt filters.Filter) ([]search.Tour, int, error) {
+	keyword := flt.Search.Keyword
+
+	itineraries := repo.mysqlDB.Model(&Itinerary{}).
+		Select("tour_id, MAX(MATCH(location, description) AGAINST(? IN NATURAL LANGUAGE MODE)) AS score", keyword).
+		Where("MATCH(location, description) AGAINST(? IN NATURAL LANGUAGE MODE)", keyword).
+		Group("tour_id")
+
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Tour{}).
+		Joins("JOIN locations ON locations.id = tours.location_id").
+		Joins("LEFT JOIN (?) AS itinerary_matches ON itinerary_matches.tour_id = tours.id", itineraries).
+		Where("tours.status = ?", search.TourPublished).
+		Where("tours.finish > ?", time.Now()).
+		Where("(MATCH(tours.title, tours.description) AGAINST(? IN NATURAL LANGUAGE MODE) OR MATCH(locations.name) AGAINST(? IN NATURAL LANGUAGE MODE) OR itinerary_matches.tour_id IS NOT NULL)", keyword, keyword).
+		Session(&gorm.Session{})
+
+	var totalData int64
+	if err := qry.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	qry = qry.Select(
+		"tours.id, tours.title, tours.description, tours.price, tours.discount, tours.thumbnail, tours.start, tours.finish, "+
+			"locations.id AS location_id, locations.name AS location_name, "+
+			"2 * MATCH(tours.title, tours.description) AGAINST(? IN NATURAL LANGUAGE MODE) + MATCH(locations.name) AGAINST(? IN NATURAL LANGUAGE MODE) + COALESCE(itinerary_matches.score, 0) AS score",
+		keyword, keyword,
+	).Order("score desc, tours.id asc")
+
+	if flt.Pagination.Limit != 0 {
+		qry = qry.Offset(flt.Pagination.Start).Limit(flt.Pagination.Limit)
+	}
+
+	var mods []Result
+	if err := qry.Scan(&mods).Error; err != nil {
+		return nil, 0, err
+	}
+
+	if len(mods) == 0 {
+		return nil, int(totalData), nil
+	}
+
+	var ids []uint
+	for _, mod := range mods {
+		ids = append(ids, mod.Id)
+	}
+
+	var modItineraries []Itinerary
+	if err := repo.mysqlDB.WithContext(ctx).Where("tour_id IN ?", ids).Order("id asc").Find(&modItineraries).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var itinerary = make(map[uint][]search.Itinerary)
+	for _, mod := range modItineraries {
+		itinerary[mod.TourId] = append(itinerary[mod.TourId], mod.ToEntity())
+	}
+
+	next, err := repo.nextDepartures(ctx, ids)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var result []search.Tour
+	for _, mod := range mods {
+		if departure, ok := next[mod.Id]; ok {
+			mod.Start = departure.Start
+			mod.Finish = departure.Finish
+		}
+
+		tour := mod.ToEntity()
+		tour.Itinerary = itinerary[mod.Id]
+
+		result = append(result, tour)
+	}
+
+	return result, int(totalData), nil
+}
+
+// nextDepartures is the first upcoming departure of each of the tours, the
+// dates a found tour shows like it does in the tour listing.
+func (repo *searchRepository) nextDepartures(ctx context.Context, tourIds []uint) (map[uint]Departure, error) {
+	var result = make(map[uint]Departure)
+
+	first := repo.mysqlDB.Model(&Departure{}).Select("tour_id", "MIN(start)").Where("tour_id IN ? AND start > ?", tourIds, time.Now()).Group("tour_id")
+
+	var mod []Departure
+	if err := repo.mysqlDB.WithContext(ctx).Where("(tour_id, start) IN (?)", first).Find(&mod).Error; err != nil {
+		return nil, err
+	}
+
+	for _, departure := range mod {
+		result[departure.TourId] = departure
+	}
+
+	return result, nil
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"wanderer/features/search"
+	"wanderer/helpers/filters"
+)
+
+func NewSearchService(repo search.Repository) search.Service {
+	return &searchService{
+		repo: repo,
+	}
+}
+
+type searchService struct {
+	repo search.Repository
+}
+
+// Search finds the tours matching the keyword of flt, the most relevant first,
+// with snippets of where each of them matched.
+func (srv *searchService) Search(ctx context.Context, flt filters.Filter) ([]search.Hit, int, error) {
+	terms := search.Terms(flt.Search.Keyword)
+	if len(terms) == 0 {
+		return nil, 0, errors.New("validate: keyword can't be empty")
+	}
+
+	result, totalData, err := srv.repo.Search(ctx, flt)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var hits []search.Hit
+	for _, tour := range result {
+		hits = append(hits, search.Hit{Tour: tour, Highlights: search.Highlights(tour, terms)})
+	}
+
+	return hits, totalData, nil
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"wanderer/features/search"
+	"wanderer/features/search/mocks"
+	"wanderer/features/search/repository"
+	"wanderer/helpers/filters"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchServiceSearch(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewSearchService(repo)
+	ctx := context.Background()
+
+	data := []search.Tour{
+		{
+			Id:          1,
+			Title:       "Raja Ampat Explorer",
+			Description: "Island hopping across the archipelago.",
+			Location:    search.Location{Id: 1, Name: "Papua"},
+			Itinerary: []search.Itinerary{
+				{Location: "Pianemo", Description: "Snorkeling over the reefs."},
+			},
+		},
+	}
+
+	filter := filters.Filter{
+		Search:     filters.Search{Keyword: "snorkeling"},
+		Pagination: filters.Pagination{Limit: 10},
+	}
+
+	t.Run("empty keyword", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Search.Keyword = " - "
+
+		result, totalData, err := srv.Search(ctx, caseFilter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "keyword")
+		assert.Nil(t, result)
+		assert.Equal(t, 0, totalData)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("Search", ctx, filter).Return(nil, 0, errors.New("some error from repository")).Once()
+
+		result, totalData, err := srv.Search(ctx, filter)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+		assert.Equal(t, 0, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("Search", ctx, filter).Return(data, 1, nil).Once()
+
+		result, totalData, err := srv.Search(ctx, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, totalData)
+		assert.Equal(t, []search.Hit{
+			{
+				Tour:       data[0],
+				Highlights: []search.Highlight{{Field: search.FieldItinerary, Snippet: "<mark>Snorkeling</mark> over the reefs."}},
+			},
+		}, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("in memory", func(t *testing.T) {
+		srv := NewSearchService(repository.NewMemorySearchRepository(data))
+
+		result, totalData, err := srv.Search(ctx, filters.Filter{Search: filters.Search{Keyword: "Pianemo"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, totalData)
+		assert.Equal(t, float64(1), result[0].Tour.Score)
+		assert.Equal(t, []search.Highlight{{Field: search.FieldItinerary, Snippet: "<mark>Pianemo</mark>"}}, result[0].Highlights)
+	})
+}
@@ -3,17 +3,43 @@ package tours
 import (
 	"context"
 	"io"
+	"sort"
 	"time"
 	"wanderer/helpers/filters"
 
 	"github.com/labstack/echo/v4"
 )
 
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// ValidStatus reports whether status is one a tour can be in.
+func ValidStatus(status string) bool {
+	switch status {
+	case StatusDraft, StatusPublished, StatusArchived:
+		return true
+	}
+
+	return false
+}
+
+// Tour is a tour package. Its Start, Finish, Quota and Available sum up its
+// departures, from the first start to the last finish. Rating is the average
+// of its ReviewCount reviews, RatingStars how many of them give 1 to 5 stars
+// and RatingScore the Bayesian average tours are ranked by.
+//
+// Price is what adults pay. ChildPrice and InfantPrice are what children and
+// infants pay, a nil one meaning they pay the adult price.
 type Tour struct {
 	Id          uint
 	Title       string
 	Description string
 	Price       float64
+	ChildPrice  *float64
+	InfantPrice *float64
 	AdminFee    float64
 	Discount    int
 	Start       time.Time
@@ -21,12 +47,18 @@ type Tour struct {
 	Quota       int
 	Available   int
 	Rating      float32
+	ReviewCount int
+	RatingStars [5]int
+	RatingScore float32
+	Status      string
 
 	Thumbnail File
 	Picture   []File
 
 	Itinerary []Itinerary
 
+	Departures []Departure
+
 	FacilityInclude []Facility
 	FacilityExclude []Facility
 	Airline         Airline
@@ -57,6 +89,21 @@ type Itinerary struct {
 	DeletedAt time.Time
 }
 
+// Departure is one run of a tour package, with its own dates and seats. A
+// zero Price means the package price applies.
+type Departure struct {
+	Id        uint
+	TourId    uint
+	Start     time.Time
+	Finish    time.Time
+	Quota     int
+	Available int
+	Price     float64
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type Facility struct {
 	Id   uint
 	Name string
@@ -76,34 +123,118 @@ type Review struct {
 	Id        uint
 	Text      string
 	Rating    float32
+	Helpful   int
+	Photos    []string
 	CreatedAt time.Time
 	User      User
 	TourId    uint
 }
 
+// The orders the reviews of a tour can be shown in.
+const (
+	ReviewSortLatest  = "latest"
+	ReviewSortHelpful = "helpful"
+	ReviewSortRating  = "rating"
+)
+
+// SortReviews orders reviews by sort, one of the ReviewSort constants, keeping
+// the newest first among equals. It reports false for an unknown sort.
+func SortReviews(reviews []Review, by string) bool {
+	var less func(a Review, b Review) bool
+	switch by {
+	case ReviewSortLatest:
+		less = func(a Review, b Review) bool { return false }
+	case ReviewSortHelpful:
+		less = func(a Review, b Review) bool { return a.Helpful > b.Helpful }
+	case ReviewSortRating:
+		less = func(a Review, b Review) bool { return a.Rating > b.Rating }
+	default:
+		return false
+	}
+
+	sort.SliceStable(reviews, func(i, j int) bool {
+		if less(reviews[i], reviews[j]) {
+			return true
+		}
+
+		if less(reviews[j], reviews[i]) {
+			return false
+		}
+
+		return reviews[i].CreatedAt.After(reviews[j].CreatedAt)
+	})
+
+	return true
+}
+
 type User struct {
 	Id    uint
 	Name  string
 	Image string
 }
 
+// PriceBuckets bound the price facets, each facet runs from one bound up to
+// the next one. The first facet starts at zero and the last has no upper bound.
+var PriceBuckets = []float64{5000000, 10000000, 20000000, 50000000}
+
+// Facets count the tours of a listing per location, airline and price
+// bucket. Each count ignores the listing's own condition on that facet, so
+// it tells how many tours picking it would list.
+type Facets struct {
+	Locations []Facet
+	Airlines  []Facet
+	Prices    []PriceFacet
+}
+
+type Facet struct {
+	Id    uint
+	Name  string
+	Count int
+}
+
+// PriceFacet counts the tours priced from Min up to, but not including, Max.
+// A zero Max has no upper bound.
+type PriceFacet struct {
+	Min   float64
+	Max   float64
+	Count int
+}
+
 type Handler interface {
 	GetAll() echo.HandlerFunc
 	GetDetail() echo.HandlerFunc
 	Create() echo.HandlerFunc
 	Update() echo.HandlerFunc
+	UpdateStatus() echo.HandlerFunc
+	Delete() echo.HandlerFunc
+	CreateDeparture() echo.HandlerFunc
+	UpdateDeparture() echo.HandlerFunc
+	DeleteDeparture() echo.HandlerFunc
 }
 
 type Service interface {
-	GetAll(ctx context.Context, flt filters.Filter) ([]Tour, int, error)
-	GetDetail(ctx context.Context, id uint) (*Tour, error)
+	GetAll(ctx context.Context, admin bool, flt filters.Filter) ([]Tour, int, error)
+	GetFacets(ctx context.Context, admin bool, flt filters.Filter) (*Facets, error)
+	GetDetail(ctx context.Context, admin bool, userId uint, id uint, reviewSort string) (*Tour, error)
 	Create(ctx context.Context, data Tour) error
 	Update(ctx context.Context, id uint, data Tour) error
+	UpdateStatus(ctx context.Context, id uint, status string) error
+	Delete(ctx context.Context, id uint) error
+	CreateDeparture(ctx context.Context, tourId uint, data Departure) error
+	UpdateDeparture(ctx context.Context, tourId uint, departureId uint, data Departure) error
+	DeleteDeparture(ctx context.Context, tourId uint, departureId uint) error
 }
 
 type Repository interface {
 	GetAll(ctx context.Context, flt filters.Filter) ([]Tour, int, error)
+	GetFacets(ctx context.Context, flt filters.Filter) (*Facets, error)
 	GetDetail(ctx context.Context, id uint) (*Tour, error)
+	IsBooked(ctx context.Context, id uint, userId uint) (bool, error)
 	Create(ctx context.Context, data Tour) error
 	Update(ctx context.Context, id uint, data Tour) error
+	UpdateStatus(ctx context.Context, id uint, status string) error
+	Delete(ctx context.Context, id uint) error
+	CreateDeparture(ctx context.Context, tourId uint, data Departure) error
+	UpdateDeparture(ctx context.Context, tourId uint, departureId uint, data Departure) error
+	DeleteDeparture(ctx context.Context, tourId uint, departureId uint) error
 }
@@ -2,13 +2,14 @@ package handler
 
 import (
 	"context"
-	"fmt"
 	"net/http"
 	"strconv"
 	"strings"
 	"wanderer/config"
 	"wanderer/features/tours"
+	"wanderer/helpers/authorization"
 	"wanderer/helpers/filters"
+	"wanderer/helpers/paginate"
 
 	echo "github.com/labstack/echo/v4"
 )
@@ -28,8 +29,6 @@ type tourHandler struct {
 func (hdl *tourHandler) GetAll() echo.HandlerFunc {
 	return func(c echo.Context) error {
 		var response = make(map[string]any)
-		var baseUrl = c.Scheme() + "://" + c.Request().Host
-
 		var pagination = new(filters.Pagination)
 		c.Bind(pagination)
 		if pagination.Start != 0 && pagination.Limit == 0 {
@@ -42,10 +41,19 @@ func (hdl *tourHandler) GetAll() echo.HandlerFunc {
 		var sort = new(filters.Sort)
 		c.Bind(sort)
 
-		result, totalData, err := hdl.tourService.GetAll(context.Background(), filters.Filter{Search: *search, Pagination: *pagination, Sort: *sort})
+		var tour = new(filters.Tour)
+		c.Bind(tour)
+
+		var flt = filters.Filter{Search: *search, Pagination: *pagination, Sort: *sort, Tour: *tour}
+		result, totalData, err := hdl.tourService.GetAll(context.Background(), authorization.IsAdmin(c), flt)
 		if err != nil {
 			c.Logger().Error(err)
 
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
 			response["message"] = "internal server error"
 			return c.JSON(http.StatusInternalServerError, response)
 		}
@@ -59,52 +67,20 @@ func (hdl *tourHandler) GetAll() echo.HandlerFunc {
 		}
 		response["data"] = data
 
-		if pagination.Limit != 0 {
-			var paginationResponse = make(map[string]any)
-			if pagination.Start >= (pagination.Limit) {
-				prev := fmt.Sprintf("%s%s?start=%d&limit=%d", baseUrl, c.Path(), pagination.Start-pagination.Limit, pagination.Limit)
-
-				if search.Keyword != "" {
-					prev += "&keyword=" + search.Keyword
-				}
-
-				if sort.Column != "" {
-					prev += "&sort=" + sort.Column
-				}
-
-				if sort.Direction {
-					prev += "&dir=true"
-				} else {
-					prev += "&dir=false"
-				}
-
-				paginationResponse["prev"] = prev
-			} else {
-				paginationResponse["prev"] = nil
-			}
-
-			if totalData > pagination.Start+pagination.Limit {
-				next := fmt.Sprintf("%s%s?start=%d&limit=%d", baseUrl, c.Path(), pagination.Start+pagination.Limit, pagination.Limit)
-
-				if search.Keyword != "" {
-					next += "&keyword=" + search.Keyword
-				}
+		facets, err := hdl.tourService.GetFacets(c.Request().Context(), authorization.IsAdmin(c), flt)
+		if err != nil {
+			c.Logger().Error(err)
 
-				if sort.Column != "" {
-					next += "&sort=" + sort.Column
-				}
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
 
-				if sort.Direction {
-					next += "&dir=true"
-				} else {
-					next += "&dir=false"
-				}
+		var facetsResponse = new(FacetsResponse)
+		facetsResponse.FromEntity(*facets)
+		response["facets"] = facetsResponse
 
-				paginationResponse["next"] = next
-			} else {
-				paginationResponse["next"] = nil
-			}
-			response["pagination"] = paginationResponse
+		if links := paginate.Links(c, *pagination, totalData); links != nil {
+			response["pagination"] = links
 		}
 
 		response["message"] = "get all tour success"
@@ -124,7 +100,8 @@ func (hdl *tourHandler) GetDetail() echo.HandlerFunc {
 			return c.JSON(http.StatusBadRequest, response)
 		}
 
-		result, err := hdl.tourService.GetDetail(c.Request().Context(), uint(tourId))
+		userId, role := authorization.Identity(c)
+		result, err := hdl.tourService.GetDetail(c.Request().Context(), role == authorization.RoleAdmin, userId, uint(tourId), c.QueryParam("review_sort"))
 		if err != nil {
 			c.Logger().Error(err)
 
@@ -231,3 +208,233 @@ func (hdl *tourHandler) Update() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, response)
 	}
 }
+
+func (hdl *tourHandler) UpdateStatus() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(TourStatusRequest)
+
+		tourId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid tour id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.tourService.UpdateStatus(c.Request().Context(), uint(tourId), request.Status); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "update tour status success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *tourHandler) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		tourId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid tour id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.tourService.Delete(c.Request().Context(), uint(tourId)); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "used: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "used: ", "")
+				return c.JSON(http.StatusConflict, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "delete tour success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *tourHandler) CreateDeparture() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(TourDepartureRequest)
+
+		tourId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid tour id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.tourService.CreateDeparture(c.Request().Context(), uint(tourId), request.ToEntity()); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "create departure success"
+		return c.JSON(http.StatusCreated, response)
+	}
+}
+
+func (hdl *tourHandler) UpdateDeparture() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(TourDepartureRequest)
+
+		tourId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid tour id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		departureId, err := strconv.Atoi(c.Param("departureId"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid departure id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.tourService.UpdateDeparture(c.Request().Context(), uint(tourId), uint(departureId), request.ToEntity()); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "update departure success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *tourHandler) DeleteDeparture() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		tourId, err := strconv.Atoi(c.Param("id"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid tour id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		departureId, err := strconv.Atoi(c.Param("departureId"))
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "invalid departure id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.tourService.DeleteDeparture(c.Request().Context(), uint(tourId), uint(departureId)); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "not found: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+				return c.JSON(http.StatusNotFound, response)
+			}
+
+			if strings.Contains(err.Error(), "used: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "used: ", "")
+				return c.JSON(http.StatusConflict, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "delete departure success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
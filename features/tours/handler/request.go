@@ -13,11 +13,14 @@ type TourCreateUpdateRequest struct {
 	Title       string    `formam:"title"`
 	Description string    `formam:"description"`
 	Price       float64   `formam:"price"`
+	ChildPrice  *float64  `formam:"child_price"`
+	InfantPrice *float64  `formam:"infant_price"`
 	AdminFee    float64   `formam:"admin_fee"`
 	Discount    int       `formam:"discount"`
 	Start       time.Time `formam:"start"`
 	Finish      time.Time `formam:"finish"`
 	Quota       int       `formam:"quota"`
+	Status      string    `formam:"status"`
 
 	Thumbnail io.Reader
 	Picture   []io.Reader
@@ -26,6 +29,8 @@ type TourCreateUpdateRequest struct {
 
 	Itinerary []TourItineraryCreateRequest `formam:"itinerary"`
 
+	Departures []TourDepartureRequest `formam:"departures"`
+
 	LocationId uint `formam:"location_id"`
 	AirlineId  uint `formam:"airline_id"`
 }
@@ -90,6 +95,9 @@ func (req *TourCreateUpdateRequest) ToEntity() tours.Tour {
 		ent.Price = req.Price
 	}
 
+	ent.ChildPrice = req.ChildPrice
+	ent.InfantPrice = req.InfantPrice
+
 	if req.AdminFee != 0 {
 		ent.AdminFee = req.AdminFee
 	}
@@ -110,6 +118,10 @@ func (req *TourCreateUpdateRequest) ToEntity() tours.Tour {
 		ent.Quota = req.Quota
 	}
 
+	if req.Status != "" {
+		ent.Status = req.Status
+	}
+
 	if req.Thumbnail != nil {
 		ent.Thumbnail.Raw = req.Thumbnail
 	}
@@ -130,6 +142,10 @@ func (req *TourCreateUpdateRequest) ToEntity() tours.Tour {
 		ent.Itinerary = append(ent.Itinerary, it.ToEntity())
 	}
 
+	for _, departure := range req.Departures {
+		ent.Departures = append(ent.Departures, departure.ToEntity())
+	}
+
 	if req.LocationId != 0 {
 		ent.Location.Id = req.LocationId
 	}
@@ -159,3 +175,36 @@ func (req *TourItineraryCreateRequest) ToEntity() tours.Itinerary {
 
 	return *ent
 }
+
+type TourStatusRequest struct {
+	Status string `json:"status"`
+}
+
+type TourDepartureRequest struct {
+	Start  time.Time `json:"start" formam:"start"`
+	Finish time.Time `json:"finish" formam:"finish"`
+	Quota  int       `json:"quota" formam:"quota"`
+	Price  float64   `json:"price" formam:"price"`
+}
+
+func (req *TourDepartureRequest) ToEntity() tours.Departure {
+	var ent = new(tours.Departure)
+
+	if !req.Start.IsZero() {
+		ent.Start = req.Start
+	}
+
+	if !req.Finish.IsZero() {
+		ent.Finish = req.Finish
+	}
+
+	if req.Quota != 0 {
+		ent.Quota = req.Quota
+	}
+
+	if req.Price != 0 {
+		ent.Price = req.Price
+	}
+
+	return *ent
+}
@@ -11,6 +11,8 @@ type TourResponse struct {
 	Title       string     `json:"title,omitempty"`
 	Description string     `json:"description,omitempty"`
 	Price       float64    `json:"price"`
+	ChildPrice  *float64   `json:"child_price,omitempty"`
+	InfantPrice *float64   `json:"infant_price,omitempty"`
 	AdminFee    *float64   `json:"admin_fee,omitempty"`
 	Discount    float64    `json:"discount"`
 	Start       time.Time  `json:"start,omitempty"`
@@ -18,6 +20,11 @@ type TourResponse struct {
 	Quota       int        `json:"quota,omitempty"`
 	Available   int        `json:"available,omitempty"`
 	Rating      float32    `json:"rating"`
+	ReviewCount int        `json:"review_count"`
+	RatingScore float32    `json:"rating_score"`
+	Status      string     `json:"status,omitempty"`
+
+	RatingBreakdown RatingBreakdownResponse `json:"rating_breakdown"`
 
 	Thumbnail string   `json:"thumbnail"`
 	Picture   []string `json:"picture,omitempty"`
@@ -30,6 +37,8 @@ type TourResponse struct {
 
 	Itinerary []ItineraryResponse `json:"itinerary,omitempty"`
 
+	Departures []DepartureResponse `json:"departures,omitempty"`
+
 	Location LocationResponse `json:"location"`
 	Airline  *AirlineResponse `json:"airline,omitempty"`
 
@@ -44,6 +53,8 @@ func (res *TourResponse) FromEntity(ent tours.Tour, discountCurrency bool) {
 	res.Title = ent.Title
 	res.Description = ent.Description
 	res.Price = ent.Price
+	res.ChildPrice = ent.ChildPrice
+	res.InfantPrice = ent.InfantPrice
 	if ent.AdminFee != -1 {
 		res.AdminFee = &ent.AdminFee
 	}
@@ -60,6 +71,10 @@ func (res *TourResponse) FromEntity(ent tours.Tour, discountCurrency bool) {
 	res.Quota = ent.Quota
 	res.Available = ent.Available
 	res.Rating = ent.Rating
+	res.ReviewCount = ent.ReviewCount
+	res.RatingScore = ent.RatingScore
+	res.RatingBreakdown.FromEntity(ent.RatingStars)
+	res.Status = ent.Status
 
 	if ent.Thumbnail.Url != "" {
 		res.Thumbnail = ent.Thumbnail.Url
@@ -97,6 +112,13 @@ func (res *TourResponse) FromEntity(ent tours.Tour, discountCurrency bool) {
 		res.Itinerary = append(res.Itinerary, *tmpItinerary)
 	}
 
+	for _, departure := range ent.Departures {
+		var tmpDeparture = new(DepartureResponse)
+		tmpDeparture.FromEntity(departure, ent.Price)
+
+		res.Departures = append(res.Departures, *tmpDeparture)
+	}
+
 	res.Location = LocationResponse{Id: ent.Location.Id, Name: ent.Location.Name}
 	if !reflect.ValueOf(ent.Airline).IsZero() {
 		res.Airline = &AirlineResponse{Id: ent.Airline.Id, Name: ent.Airline.Name}
@@ -110,6 +132,23 @@ func (res *TourResponse) FromEntity(ent tours.Tour, discountCurrency bool) {
 	}
 }
 
+// RatingBreakdownResponse is how many reviews give each of 1 to 5 stars.
+type RatingBreakdownResponse struct {
+	One   int `json:"1"`
+	Two   int `json:"2"`
+	Three int `json:"3"`
+	Four  int `json:"4"`
+	Five  int `json:"5"`
+}
+
+func (res *RatingBreakdownResponse) FromEntity(stars [5]int) {
+	res.One = stars[0]
+	res.Two = stars[1]
+	res.Three = stars[2]
+	res.Four = stars[3]
+	res.Five = stars[4]
+}
+
 type ItineraryResponse struct {
 	Location    string `json:"location"`
 	Description string `json:"description"`
@@ -120,6 +159,30 @@ func (res *ItineraryResponse) FromEntity(ent tours.Itinerary) {
 	res.Description = ent.Description
 }
 
+type DepartureResponse struct {
+	Id        uint      `json:"departure_id"`
+	Start     time.Time `json:"start"`
+	Finish    time.Time `json:"finish"`
+	Quota     int       `json:"quota"`
+	Available int       `json:"available"`
+	Price     float64   `json:"price"`
+}
+
+// FromEntity fills the response from ent, showing tourPrice as the price of a
+// departure that doesn't override it.
+func (res *DepartureResponse) FromEntity(ent tours.Departure, tourPrice float64) {
+	res.Id = ent.Id
+	res.Start = ent.Start
+	res.Finish = ent.Finish
+	res.Quota = ent.Quota
+	res.Available = ent.Available
+
+	res.Price = tourPrice
+	if ent.Price != 0 {
+		res.Price = ent.Price
+	}
+}
+
 type LocationResponse struct {
 	Id   uint   `json:"location_id"`
 	Name string `json:"name"`
@@ -131,8 +194,12 @@ type AirlineResponse struct {
 }
 
 type ReviewResponse struct {
+	Id        uint         `json:"review_id"`
 	User      UserResponse `json:"user"`
 	Text      string       `json:"text,omitempty"`
+	Rating    float32      `json:"rating"`
+	Helpful   int          `json:"helpful"`
+	Photos    []string     `json:"photos"`
 	CreatedAt time.Time    `json:"created_at"`
 }
 
@@ -143,10 +210,18 @@ func (res *ReviewResponse) FromEntity(ent tours.Review) {
 		res.User = *tmpUser
 	}
 
+	res.Id = ent.Id
+
 	if ent.Text != "" {
 		res.Text = ent.Text
 	}
 
+	res.Rating = ent.Rating
+	res.Helpful = ent.Helpful
+
+	res.Photos = make([]string, 0, len(ent.Photos))
+	res.Photos = append(res.Photos, ent.Photos...)
+
 	res.CreatedAt = ent.CreatedAt
 }
 
@@ -171,3 +246,43 @@ func (res *UserResponse) FromEntity(ent tours.User) {
 		res.Image = "default"
 	}
 }
+
+type FacetsResponse struct {
+	Locations []FacetResponse      `json:"locations"`
+	Airlines  []FacetResponse      `json:"airlines"`
+	Prices    []PriceFacetResponse `json:"prices"`
+}
+
+func (res *FacetsResponse) FromEntity(ent tours.Facets) {
+	res.Locations = make([]FacetResponse, 0, len(ent.Locations))
+	for _, facet := range ent.Locations {
+		res.Locations = append(res.Locations, FacetResponse{Id: facet.Id, Name: facet.Name, Count: facet.Count})
+	}
+
+	res.Airlines = make([]FacetResponse, 0, len(ent.Airlines))
+	for _, facet := range ent.Airlines {
+		res.Airlines = append(res.Airlines, FacetResponse{Id: facet.Id, Name: facet.Name, Count: facet.Count})
+	}
+
+	res.Prices = make([]PriceFacetResponse, 0, len(ent.Prices))
+	for _, facet := range ent.Prices {
+		var tmpPrice = PriceFacetResponse{Min: facet.Min, Count: facet.Count}
+		if max := facet.Max; max != 0 {
+			tmpPrice.Max = &max
+		}
+
+		res.Prices = append(res.Prices, tmpPrice)
+	}
+}
+
+type FacetResponse struct {
+	Id    uint   `json:"id"`
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+type PriceFacetResponse struct {
+	Min   float64  `json:"min"`
+	Max   *float64 `json:"max"`
+	Count int      `json:"count"`
+}
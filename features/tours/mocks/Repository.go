@@ -30,6 +30,48 @@ func (_m *Repository) Create(ctx context.Context, data tours.Tour) error {
 	return r0
 }
 
+// CreateDeparture provides a mock function with given fields: ctx, tourId, data
+func (_m *Repository) CreateDeparture(ctx context.Context, tourId uint, data tours.Departure) error {
+	ret := _m.Called(ctx, tourId, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, tours.Departure) error); ok {
+		r0 = rf(ctx, tourId, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *Repository) Delete(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeparture provides a mock function with given fields: ctx, tourId, departureId
+func (_m *Repository) DeleteDeparture(ctx context.Context, tourId uint, departureId uint) error {
+	ret := _m.Called(ctx, tourId, departureId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, tourId, departureId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // GetAll provides a mock function with given fields: ctx, flt
 func (_m *Repository) GetAll(ctx context.Context, flt filters.Filter) ([]tours.Tour, int, error) {
 	ret := _m.Called(ctx, flt)
@@ -63,20 +105,20 @@ func (_m *Repository) GetAll(ctx context.Context, flt filters.Filter) ([]tours.T
 	return r0, r1, r2
 }
 
-// GetByLocation provides a mock function with given fields: ctx, id
-func (_m *Repository) GetByLocation(ctx context.Context, id uint) ([]tours.Tour, error) {
+// GetDetail provides a mock function with given fields: ctx, id
+func (_m *Repository) GetDetail(ctx context.Context, id uint) (*tours.Tour, error) {
 	ret := _m.Called(ctx, id)
 
-	var r0 []tours.Tour
+	var r0 *tours.Tour
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]tours.Tour, error)); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (*tours.Tour, error)); ok {
 		return rf(ctx, id)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint) []tours.Tour); ok {
+	if rf, ok := ret.Get(0).(func(context.Context, uint) *tours.Tour); ok {
 		r0 = rf(ctx, id)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]tours.Tour)
+			r0 = ret.Get(0).(*tours.Tour)
 		}
 	}
 
@@ -89,25 +131,49 @@ func (_m *Repository) GetByLocation(ctx context.Context, id uint) ([]tours.Tour,
 	return r0, r1
 }
 
-// GetDetail provides a mock function with given fields: ctx, id
-func (_m *Repository) GetDetail(ctx context.Context, id uint) (*tours.Tour, error) {
-	ret := _m.Called(ctx, id)
+// GetFacets provides a mock function with given fields: ctx, flt
+func (_m *Repository) GetFacets(ctx context.Context, flt filters.Filter) (*tours.Facets, error) {
+	ret := _m.Called(ctx, flt)
 
-	var r0 *tours.Tour
+	var r0 *tours.Facets
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) (*tours.Tour, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) (*tours.Facets, error)); ok {
+		return rf(ctx, flt)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint) *tours.Tour); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) *tours.Facets); ok {
+		r0 = rf(ctx, flt)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*tours.Tour)
+			r0 = ret.Get(0).(*tours.Facets)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, filters.Filter) error); ok {
+		r1 = rf(ctx, flt)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsBooked provides a mock function with given fields: ctx, id, userId
+func (_m *Repository) IsBooked(ctx context.Context, id uint, userId uint) (bool, error) {
+	ret := _m.Called(ctx, id, userId)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) (bool, error)); ok {
+		return rf(ctx, id, userId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) bool); ok {
+		r0 = rf(ctx, id, userId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint, uint) error); ok {
+		r1 = rf(ctx, id, userId)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -129,13 +195,27 @@ func (_m *Repository) Update(ctx context.Context, id uint, data tours.Tour) erro
 	return r0
 }
 
-// UpdateRating provides a mock function with given fields: ctx, id, data
-func (_m *Repository) UpdateRating(ctx context.Context, id uint, data tours.Tour) error {
-	ret := _m.Called(ctx, id, data)
+// UpdateDeparture provides a mock function with given fields: ctx, tourId, departureId, data
+func (_m *Repository) UpdateDeparture(ctx context.Context, tourId uint, departureId uint, data tours.Departure) error {
+	ret := _m.Called(ctx, tourId, departureId, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, tours.Tour) error); ok {
-		r0 = rf(ctx, id, data)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, tours.Departure) error); ok {
+		r0 = rf(ctx, tourId, departureId, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, id, status
+func (_m *Repository) UpdateStatus(ctx context.Context, id uint, status string) error {
+	ret := _m.Called(ctx, id, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, id, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -30,32 +30,74 @@ func (_m *Service) Create(ctx context.Context, data tours.Tour) error {
 	return r0
 }
 
-// GetAll provides a mock function with given fields: ctx, flt
-func (_m *Service) GetAll(ctx context.Context, flt filters.Filter) ([]tours.Tour, int, error) {
-	ret := _m.Called(ctx, flt)
+// CreateDeparture provides a mock function with given fields: ctx, tourId, data
+func (_m *Service) CreateDeparture(ctx context.Context, tourId uint, data tours.Departure) error {
+	ret := _m.Called(ctx, tourId, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, tours.Departure) error); ok {
+		r0 = rf(ctx, tourId, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Delete provides a mock function with given fields: ctx, id
+func (_m *Service) Delete(ctx context.Context, id uint) error {
+	ret := _m.Called(ctx, id)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) error); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteDeparture provides a mock function with given fields: ctx, tourId, departureId
+func (_m *Service) DeleteDeparture(ctx context.Context, tourId uint, departureId uint) error {
+	ret := _m.Called(ctx, tourId, departureId)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint) error); ok {
+		r0 = rf(ctx, tourId, departureId)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// GetAll provides a mock function with given fields: ctx, admin, flt
+func (_m *Service) GetAll(ctx context.Context, admin bool, flt filters.Filter) ([]tours.Tour, int, error) {
+	ret := _m.Called(ctx, admin, flt)
 
 	var r0 []tours.Tour
 	var r1 int
 	var r2 error
-	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) ([]tours.Tour, int, error)); ok {
-		return rf(ctx, flt)
+	if rf, ok := ret.Get(0).(func(context.Context, bool, filters.Filter) ([]tours.Tour, int, error)); ok {
+		return rf(ctx, admin, flt)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, filters.Filter) []tours.Tour); ok {
-		r0 = rf(ctx, flt)
+	if rf, ok := ret.Get(0).(func(context.Context, bool, filters.Filter) []tours.Tour); ok {
+		r0 = rf(ctx, admin, flt)
 	} else {
 		if ret.Get(0) != nil {
 			r0 = ret.Get(0).([]tours.Tour)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, filters.Filter) int); ok {
-		r1 = rf(ctx, flt)
+	if rf, ok := ret.Get(1).(func(context.Context, bool, filters.Filter) int); ok {
+		r1 = rf(ctx, admin, flt)
 	} else {
 		r1 = ret.Get(1).(int)
 	}
 
-	if rf, ok := ret.Get(2).(func(context.Context, filters.Filter) error); ok {
-		r2 = rf(ctx, flt)
+	if rf, ok := ret.Get(2).(func(context.Context, bool, filters.Filter) error); ok {
+		r2 = rf(ctx, admin, flt)
 	} else {
 		r2 = ret.Error(2)
 	}
@@ -63,25 +105,25 @@ func (_m *Service) GetAll(ctx context.Context, flt filters.Filter) ([]tours.Tour
 	return r0, r1, r2
 }
 
-// GetByLocation provides a mock function with given fields: ctx, id
-func (_m *Service) GetByLocation(ctx context.Context, id uint) ([]tours.Tour, error) {
-	ret := _m.Called(ctx, id)
+// GetDetail provides a mock function with given fields: ctx, admin, userId, id, reviewSort
+func (_m *Service) GetDetail(ctx context.Context, admin bool, userId uint, id uint, reviewSort string) (*tours.Tour, error) {
+	ret := _m.Called(ctx, admin, userId, id, reviewSort)
 
-	var r0 []tours.Tour
+	var r0 *tours.Tour
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) ([]tours.Tour, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, bool, uint, uint, string) (*tours.Tour, error)); ok {
+		return rf(ctx, admin, userId, id, reviewSort)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint) []tours.Tour); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, bool, uint, uint, string) *tours.Tour); ok {
+		r0 = rf(ctx, admin, userId, id, reviewSort)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]tours.Tour)
+			r0 = ret.Get(0).(*tours.Tour)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, bool, uint, uint, string) error); ok {
+		r1 = rf(ctx, admin, userId, id, reviewSort)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -89,25 +131,25 @@ func (_m *Service) GetByLocation(ctx context.Context, id uint) ([]tours.Tour, er
 	return r0, r1
 }
 
-// GetDetail provides a mock function with given fields: ctx, id
-func (_m *Service) GetDetail(ctx context.Context, id uint) (*tours.Tour, error) {
-	ret := _m.Called(ctx, id)
+// GetFacets provides a mock function with given fields: ctx, admin, flt
+func (_m *Service) GetFacets(ctx context.Context, admin bool, flt filters.Filter) (*tours.Facets, error) {
+	ret := _m.Called(ctx, admin, flt)
 
-	var r0 *tours.Tour
+	var r0 *tours.Facets
 	var r1 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint) (*tours.Tour, error)); ok {
-		return rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, bool, filters.Filter) (*tours.Facets, error)); ok {
+		return rf(ctx, admin, flt)
 	}
-	if rf, ok := ret.Get(0).(func(context.Context, uint) *tours.Tour); ok {
-		r0 = rf(ctx, id)
+	if rf, ok := ret.Get(0).(func(context.Context, bool, filters.Filter) *tours.Facets); ok {
+		r0 = rf(ctx, admin, flt)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*tours.Tour)
+			r0 = ret.Get(0).(*tours.Facets)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
-		r1 = rf(ctx, id)
+	if rf, ok := ret.Get(1).(func(context.Context, bool, filters.Filter) error); ok {
+		r1 = rf(ctx, admin, flt)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -129,13 +171,27 @@ func (_m *Service) Update(ctx context.Context, id uint, data tours.Tour) error {
 	return r0
 }
 
-// UpdateRating provides a mock function with given fields: ctx, id, data
-func (_m *Service) UpdateRating(ctx context.Context, id uint, data tours.Tour) error {
-	ret := _m.Called(ctx, id, data)
+// UpdateDeparture provides a mock function with given fields: ctx, tourId, departureId, data
+func (_m *Service) UpdateDeparture(ctx context.Context, tourId uint, departureId uint, data tours.Departure) error {
+	ret := _m.Called(ctx, tourId, departureId, data)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(context.Context, uint, tours.Tour) error); ok {
-		r0 = rf(ctx, id, data)
+	if rf, ok := ret.Get(0).(func(context.Context, uint, uint, tours.Departure) error); ok {
+		r0 = rf(ctx, tourId, departureId, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// UpdateStatus provides a mock function with given fields: ctx, id, status
+func (_m *Service) UpdateStatus(ctx context.Context, id uint, status string) error {
+	ret := _m.Called(ctx, id, status)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint, string) error); ok {
+		r0 = rf(ctx, id, status)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -11,16 +11,27 @@ import (
 
 type Tour struct {
 	Id          uint      `gorm:"column:id; primaryKey;"`
-	Title       string    `gorm:"column:title; type:varchar(200); index;"`
-	Description string    `gorm:"column:description; type:text;"`
+	Title       string    `gorm:"column:title; type:varchar(200); index; index:idx_tours_search,class:FULLTEXT;"`
+	Description string    `gorm:"column:description; type:text; index:idx_tours_search,class:FULLTEXT;"`
 	Price       float64   `gorm:"column:price; type:decimal(16,2); index;"`
+	ChildPrice  *float64  `gorm:"column:child_price; type:decimal(16,2);"`
+	InfantPrice *float64  `gorm:"column:infant_price; type:decimal(16,2);"`
 	AdminFee    float64   `gorm:"column:admin_fee; type:decimal(16,2);"`
 	Discount    int       `gorm:"column:discount; index;"`
 	Start       time.Time `gorm:"column:start; type:timestamp;"`
 	Finish      time.Time `gorm:"column:finish; type:timestamp;"`
 	Quota       int       `gorm:"column:quota;"`
-	Available   int       `gorm:"column:available;"`
+	Available   int       `gorm:"column:available; check:chk_tours_available,available >= 0;"`
 	Rating      float32   `gorm:"column:rating; type:float; index;"`
+	ReviewCount int       `gorm:"column:review_count; default:0;"`
+	RatingTotal float64   `gorm:"column:rating_total; type:double; default:0;"`
+	Rating1     int       `gorm:"column:rating_1; default:0;"`
+	Rating2     int       `gorm:"column:rating_2; default:0;"`
+	Rating3     int       `gorm:"column:rating_3; default:0;"`
+	Rating4     int       `gorm:"column:rating_4; default:0;"`
+	Rating5     int       `gorm:"column:rating_5; default:0;"`
+	RatingScore float32   `gorm:"column:rating_score; type:float; default:3; index;"`
+	Status      string    `gorm:"column:status; type:enum('draft', 'published', 'archived'); default:'published'; index;"`
 
 	ThumbnailUrl string    `gorm:"column:thumbnail; type:text;"`
 	ThumbnailRaw io.Reader `gorm:"-"`
@@ -31,6 +42,8 @@ type Tour struct {
 
 	Itinerary []Itinerary `gorm:"foreignKey:TourId"`
 
+	Departures []Departure `gorm:"foreignKey:TourId"`
+
 	AirlineId uint
 	Airline   Airline
 
@@ -61,6 +74,9 @@ func (mod *Tour) FromEntity(ent tours.Tour) {
 		mod.Price = ent.Price
 	}
 
+	mod.ChildPrice = ent.ChildPrice
+	mod.InfantPrice = ent.InfantPrice
+
 	if ent.AdminFee != 0 {
 		mod.AdminFee = ent.AdminFee
 	}
@@ -81,6 +97,10 @@ func (mod *Tour) FromEntity(ent tours.Tour) {
 		mod.Quota = ent.Quota
 	}
 
+	if ent.Status != "" {
+		mod.Status = ent.Status
+	}
+
 	if ent.Thumbnail.Raw != nil {
 		mod.ThumbnailRaw = ent.Thumbnail.Raw
 	}
@@ -103,6 +123,12 @@ func (mod *Tour) FromEntity(ent tours.Tour) {
 		mod.Itinerary = append(mod.Itinerary, *modItinerary)
 	}
 
+	for _, departure := range ent.Departures {
+		var modDeparture = new(Departure)
+		modDeparture.FromEntity(departure)
+		mod.Departures = append(mod.Departures, *modDeparture)
+	}
+
 	if ent.Airline.Id != 0 {
 		mod.AirlineId = ent.Airline.Id
 	}
@@ -131,6 +157,9 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *tours.Tour {
 		ent.Price = mod.Price
 	}
 
+	ent.ChildPrice = mod.ChildPrice
+	ent.InfantPrice = mod.InfantPrice
+
 	if mod.AdminFee != 0 {
 		ent.AdminFee = mod.AdminFee
 	}
@@ -153,6 +182,10 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *tours.Tour {
 
 	ent.Available = mod.Available
 	ent.Rating = mod.Rating
+	ent.ReviewCount = mod.ReviewCount
+	ent.RatingStars = [5]int{mod.Rating1, mod.Rating2, mod.Rating3, mod.Rating4, mod.Rating5}
+	ent.RatingScore = mod.RatingScore
+	ent.Status = mod.Status
 
 	if mod.ThumbnailUrl != "" {
 		ent.Thumbnail.Url = mod.ThumbnailUrl
@@ -182,6 +215,10 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *tours.Tour {
 		}
 	}
 
+	for _, departure := range mod.Departures {
+		ent.Departures = append(ent.Departures, departure.ToEntity())
+	}
+
 	if !reflect.ValueOf(mod.Airline).IsZero() {
 		ent.Airline = mod.Airline.ToEntity()
 	}
@@ -209,6 +246,29 @@ func (mod *Tour) ToEntity(excludeFacility []Facility) *tours.Tour {
 	return ent
 }
 
+// sumDepartures opens every departure with all of its seats available and
+// sums them up into the tour's own dates and seats.
+func (mod *Tour) sumDepartures() {
+	mod.Quota = 0
+	mod.Available = 0
+
+	for i := range mod.Departures {
+		departure := &mod.Departures[i]
+		departure.Available = departure.Quota
+
+		if i == 0 || departure.Start.Before(mod.Start) {
+			mod.Start = departure.Start
+		}
+
+		if i == 0 || departure.Finish.After(mod.Finish) {
+			mod.Finish = departure.Finish
+		}
+
+		mod.Quota += departure.Quota
+		mod.Available += departure.Available
+	}
+}
+
 type File struct {
 	Id int `gorm:"column:id; primaryKey;"`
 
@@ -244,8 +304,8 @@ func (mod *File) ToEntity() tours.File {
 
 type Itinerary struct {
 	Id          int    `gorm:"column:id; primaryKey;"`
-	Location    string `gorm:"column:location; type:varchar(200);"`
-	Description string `gorm:"column:description; type:text;"`
+	Location    string `gorm:"column:location; type:varchar(200); index:idx_itineraries_search,class:FULLTEXT;"`
+	Description string `gorm:"column:description; type:text; index:idx_itineraries_search,class:FULLTEXT;"`
 
 	TourId uint
 
@@ -294,6 +354,66 @@ func (mod *Itinerary) ToEntity() tours.Itinerary {
 	return *ent
 }
 
+type Departure struct {
+	Id        uint      `gorm:"column:id; primaryKey;"`
+	TourId    uint      `gorm:"column:tour_id; index;"`
+	Start     time.Time `gorm:"column:start; type:timestamp; index;"`
+	Finish    time.Time `gorm:"column:finish; type:timestamp;"`
+	Quota     int       `gorm:"column:quota;"`
+	Available int       `gorm:"column:available; check:chk_tour_departures_available,available >= 0;"`
+	Price     float64   `gorm:"column:price; type:decimal(16,2);"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (mod *Departure) TableName() string {
+	return "tour_departures"
+}
+
+func (mod *Departure) FromEntity(ent tours.Departure) {
+	if ent.Id != 0 {
+		mod.Id = ent.Id
+	}
+
+	if !ent.Start.IsZero() {
+		mod.Start = ent.Start
+	}
+
+	if !ent.Finish.IsZero() {
+		mod.Finish = ent.Finish
+	}
+
+	if ent.Quota != 0 {
+		mod.Quota = ent.Quota
+	}
+
+	mod.Price = ent.Price
+}
+
+func (mod *Departure) ToEntity() tours.Departure {
+	var ent = new(tours.Departure)
+
+	ent.Id = mod.Id
+	ent.TourId = mod.TourId
+	ent.Start = mod.Start
+	ent.Finish = mod.Finish
+	ent.Quota = mod.Quota
+	ent.Available = mod.Available
+	ent.Price = mod.Price
+
+	if !mod.CreatedAt.IsZero() {
+		ent.CreatedAt = mod.CreatedAt
+	}
+
+	if !mod.UpdatedAt.IsZero() {
+		ent.UpdatedAt = mod.UpdatedAt
+	}
+
+	return *ent
+}
+
 type Facility struct {
 	Id   uint
 	Name string
@@ -359,6 +479,15 @@ type Review struct {
 	UserId    uint
 	User      User
 	TourId    uint
+	Helpful   int
+	Photos    []ReviewPhoto `gorm:"foreignKey:ReviewId"`
+	DeletedAt gorm.DeletedAt
+}
+
+type ReviewPhoto struct {
+	Id       uint
+	ReviewId uint
+	Url      string
 }
 
 func (mod *Review) ToEntity() *tours.Review {
@@ -376,6 +505,12 @@ func (mod *Review) ToEntity() *tours.Review {
 		ent.Rating = mod.Rating
 	}
 
+	ent.Helpful = mod.Helpful
+
+	for _, photo := range mod.Photos {
+		ent.Photos = append(ent.Photos, photo.Url)
+	}
+
 	if !reflect.ValueOf(mod.User).IsZero() {
 		ent.User = mod.User.ToEntity()
 	}
@@ -410,3 +545,22 @@ func (mod *User) ToEntity() tours.User {
 
 	return *ent
 }
+
+type Facet struct {
+	Id    uint
+	Name  string
+	Count int
+}
+
+func (mod *Facet) ToEntity() tours.Facet {
+	return tours.Facet{
+		Id:    mod.Id,
+		Name:  mod.Name,
+		Count: mod.Count,
+	}
+}
+
+type PriceFacet struct {
+	Bucket int
+	Count  int
+}
@@ -3,11 +3,15 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
+	"time"
 	"wanderer/features/tours"
 	"wanderer/helpers/filters"
 	"wanderer/utils/files"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func NewTourRepository(mysqlDB *gorm.DB, cloud files.Cloud) tours.Repository {
@@ -35,16 +39,25 @@ func (repo *tourRepository) GetAll(ctx context.Context, flt filters.Filter) ([]t
 		"tours.available",
 		"tours.discount",
 		"tours.rating",
+		"tours.review_count",
+		"tours.rating_1",
+		"tours.rating_2",
+		"tours.rating_3",
+		"tours.rating_4",
+		"tours.rating_5",
+		"tours.rating_score",
 		"tours.price",
 		"tours.thumbnail",
 		"tours.start",
+		"tours.finish",
+		"tours.status",
 	)
 
-	if flt.Search.Keyword != "" {
-		qry = qry.Where("title like ?", "%"+flt.Search.Keyword+"%")
-	}
+	qry = filterTours(qry, flt)
 
-	qry.Count(&totalData)
+	if err := qry.Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
 
 	if flt.Sort.Column != "" {
 		dir := "asc"
@@ -53,7 +66,9 @@ func (repo *tourRepository) GetAll(ctx context.Context, flt filters.Filter) ([]t
 		}
 
 		switch flt.Sort.Column {
-		case "rating", "price", "discount":
+		case "rating":
+			qry = qry.Order("tours.rating_score " + dir).Order("tours.review_count " + dir)
+		case "price", "discount":
 			qry = qry.Order(flt.Sort.Column + " " + dir)
 		case "location":
 			qry = qry.Order("Location.name " + dir)
@@ -78,14 +93,213 @@ func (repo *tourRepository) GetAll(ctx context.Context, flt filters.Filter) ([]t
 		return nil, 0, err
 	}
 
+	var tourIds []uint
+	for _, tour := range mod {
+		tourIds = append(tourIds, tour.Id)
+	}
+
+	next, err := repo.nextDepartures(ctx, tourIds)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	var result []tours.Tour
 	for _, tour := range mod {
+		if departure, ok := next[tour.Id]; ok {
+			tour.Start = departure.Start
+			tour.Finish = departure.Finish
+		}
+
 		result = append(result, *tour.ToEntity(nil))
 	}
 
 	return result, int(totalData), nil
 }
 
+// filterTours narrows qry, a query on tours, down to the tours matching flt.
+func filterTours(qry *gorm.DB, flt filters.Filter) *gorm.DB {
+	if flt.Search.Keyword != "" {
+		qry = qry.Where("tours.title like ?", "%"+flt.Search.Keyword+"%")
+	}
+
+	tour := flt.Tour
+
+	if tour.Status != "" {
+		qry = qry.Where("tours.status = ?", tour.Status)
+	}
+
+	if tour.Upcoming {
+		qry = qry.Where("tours.finish > ?", time.Now())
+	}
+
+	if tour.LocationId != 0 {
+		qry = qry.Where("tours.location_id = ?", tour.LocationId)
+	}
+
+	if tour.AirlineId != 0 {
+		qry = qry.Where("tours.airline_id = ?", tour.AirlineId)
+	}
+
+	if tour.MinPrice != 0 {
+		qry = qry.Where("tours.price >= ?", tour.MinPrice)
+	}
+
+	if tour.MaxPrice != 0 {
+		qry = qry.Where("tours.price <= ?", tour.MaxPrice)
+	}
+
+	if tour.MinRating != 0 {
+		qry = qry.Where("tours.rating >= ?", tour.MinRating)
+	}
+
+	if len(tour.Facilities) != 0 {
+		var facilities = make(map[uint]bool)
+		for _, facility := range tour.Facilities {
+			facilities[facility] = true
+		}
+
+		included := qry.Session(&gorm.Session{NewDB: true}).Table("tour_facility").Select("tour_id").
+			Where("facility_id IN ?", tour.Facilities).
+			Group("tour_id").
+			Having("COUNT(DISTINCT facility_id) = ?", len(facilities))
+
+		qry = qry.Where("tours.id IN (?)", included)
+	}
+
+	if tour.Departure() {
+		departures := qry.Session(&gorm.Session{NewDB: true}).Model(&Departure{}).Select("1").Where("tour_departures.tour_id = tours.id")
+
+		if tour.Upcoming {
+			departures = departures.Where("tour_departures.start > ?", time.Now())
+		}
+
+		if !tour.StartFrom.IsZero() {
+			departures = departures.Where("tour_departures.start >= ?", tour.StartFrom)
+		}
+
+		if !tour.StartTo.IsZero() {
+			departures = departures.Where("tour_departures.start <= ?", tour.StartTo)
+		}
+
+		if tour.MinDuration != 0 {
+			departures = departures.Where("DATEDIFF(tour_departures.finish, tour_departures.start) >= ?", tour.MinDuration)
+		}
+
+		if tour.MaxDuration != 0 {
+			departures = departures.Where("DATEDIFF(tour_departures.finish, tour_departures.start) <= ?", tour.MaxDuration)
+		}
+
+		if tour.Seats != 0 {
+			departures = departures.Where("tour_departures.available >= ?", tour.Seats)
+		}
+
+		qry = qry.Where("EXISTS (?)", departures)
+	}
+
+	return qry
+}
+
+// GetFacets counts the tours matching flt per location, airline and price
+// bucket, each without flt's own condition on that facet.
+func (repo *tourRepository) GetFacets(ctx context.Context, flt filters.Filter) (*tours.Facets, error) {
+	var result = new(tours.Facets)
+
+	byLocation := flt
+	byLocation.Tour.LocationId = 0
+
+	var modLocations []Facet
+	qry := filterTours(repo.mysqlDB.WithContext(ctx).Model(&Tour{}), byLocation).
+		Select("locations.id, locations.name, COUNT(*) AS count").
+		Joins("JOIN locations ON locations.id = tours.location_id").
+		Group("locations.id, locations.name").
+		Order("count desc, locations.name asc")
+	if err := qry.Scan(&modLocations).Error; err != nil {
+		return nil, err
+	}
+
+	for _, facet := range modLocations {
+		result.Locations = append(result.Locations, facet.ToEntity())
+	}
+
+	byAirline := flt
+	byAirline.Tour.AirlineId = 0
+
+	var modAirlines []Facet
+	qry = filterTours(repo.mysqlDB.WithContext(ctx).Model(&Tour{}), byAirline).
+		Select("airlines.id, airlines.name, COUNT(*) AS count").
+		Joins("JOIN airlines ON airlines.id = tours.airline_id").
+		Group("airlines.id, airlines.name").
+		Order("count desc, airlines.name asc")
+	if err := qry.Scan(&modAirlines).Error; err != nil {
+		return nil, err
+	}
+
+	for _, facet := range modAirlines {
+		result.Airlines = append(result.Airlines, facet.ToEntity())
+	}
+
+	byPrice := flt
+	byPrice.Tour.MinPrice = 0
+	byPrice.Tour.MaxPrice = 0
+
+	var cases []string
+	var bounds []any
+	for i, bound := range tours.PriceBuckets {
+		cases = append(cases, fmt.Sprintf("WHEN tours.price < ? THEN %d", i))
+		bounds = append(bounds, bound)
+	}
+	bucket := fmt.Sprintf("CASE %s ELSE %d END", strings.Join(cases, " "), len(tours.PriceBuckets))
+
+	var modPrices []PriceFacet
+	qry = filterTours(repo.mysqlDB.WithContext(ctx).Model(&Tour{}), byPrice).
+		Select(bucket+" AS bucket, COUNT(*) AS count", bounds...).
+		Group("bucket")
+	if err := qry.Scan(&modPrices).Error; err != nil {
+		return nil, err
+	}
+
+	var counts = make(map[int]int)
+	for _, facet := range modPrices {
+		counts[facet.Bucket] = facet.Count
+	}
+
+	var min float64
+	for i := 0; i <= len(tours.PriceBuckets); i++ {
+		var facet = tours.PriceFacet{Min: min, Count: counts[i]}
+		if i < len(tours.PriceBuckets) {
+			facet.Max = tours.PriceBuckets[i]
+			min = facet.Max
+		}
+
+		result.Prices = append(result.Prices, facet)
+	}
+
+	return result, nil
+}
+
+// nextDepartures is the first upcoming departure of each of the tours, so a
+// listed tour shows the dates it can still be booked for.
+func (repo *tourRepository) nextDepartures(ctx context.Context, tourIds []uint) (map[uint]Departure, error) {
+	var result = make(map[uint]Departure)
+	if len(tourIds) == 0 {
+		return result, nil
+	}
+
+	now := time.Now()
+	first := repo.mysqlDB.Model(&Departure{}).Select("tour_id", "MIN(start)").Where("tour_id IN ? AND start > ?", tourIds, now).Group("tour_id")
+
+	var mod []Departure
+	if err := repo.mysqlDB.WithContext(ctx).Where("(tour_id, start) IN (?)", first).Find(&mod).Error; err != nil {
+		return nil, err
+	}
+
+	for _, departure := range mod {
+		result[departure.TourId] = departure
+	}
+
+	return result, nil
+}
+
 func (repo *tourRepository) GetDetail(ctx context.Context, id uint) (*tours.Tour, error) {
 	var modTour = new(Tour)
 	if err := repo.mysqlDB.WithContext(ctx).Joins("Airline").Joins("Location").Where(&Tour{Id: id}).First(modTour).Error; err != nil {
@@ -123,8 +337,14 @@ func (repo *tourRepository) GetDetail(ctx context.Context, id uint) (*tours.Tour
 	}
 	modTour.Itinerary = modItinerary
 
+	var modDepartures []Departure
+	if err := repo.mysqlDB.WithContext(ctx).Where("tour_id = ? AND start > ?", id, time.Now()).Order("start asc").Find(&modDepartures).Error; err != nil {
+		return nil, err
+	}
+	modTour.Departures = modDepartures
+
 	var modReviews []Review
-	if err := repo.mysqlDB.WithContext(ctx).Where("tour_id = ?", id).Joins("User").Find(&modReviews).Error; err != nil {
+	if err := repo.mysqlDB.WithContext(ctx).Where("reviews.tour_id = ? AND reviews.status <> ?", id, "hidden").Joins("User").Preload("Photos").Find(&modReviews).Error; err != nil {
 		return nil, err
 	}
 	modTour.Reviews = modReviews
@@ -132,10 +352,21 @@ func (repo *tourRepository) GetDetail(ctx context.Context, id uint) (*tours.Tour
 	return modTour.ToEntity(modFacilityExclude), nil
 }
 
+// IsBooked tells whether the user userId has ever booked the tour id.
+func (repo *tourRepository) IsBooked(ctx context.Context, id uint, userId uint) (bool, error) {
+	var total int64
+	qry := repo.mysqlDB.WithContext(ctx).Table("bookings").Where("tour_id = ? AND user_id = ? AND deleted_at IS NULL", id, userId)
+	if err := qry.Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total != 0, nil
+}
+
 func (repo *tourRepository) Create(ctx context.Context, data tours.Tour) error {
 	var mod = new(Tour)
 	mod.FromEntity(data)
-	mod.Available = mod.Quota
+	mod.sumDepartures()
 
 	tx := repo.mysqlDB.WithContext(ctx).Begin()
 	defer func() {
@@ -254,7 +485,16 @@ func (repo *tourRepository) Update(ctx context.Context, id uint, data tours.Tour
 			mod.ThumbnailUrl = *url
 		}
 
-		return txTour.Where(&Tour{Id: id}).Updates(mod).Error
+		if err := txTour.Where(&Tour{Id: id}).Updates(mod).Error; err != nil {
+			return err
+		}
+
+		// Updates skips nil fields, so the optional prices are written
+		// explicitly to let an update clear them.
+		return txTour.Model(&Tour{}).Where("id = ?", id).Updates(map[string]any{
+			"child_price":  mod.ChildPrice,
+			"infant_price": mod.InfantPrice,
+		}).Error
 	})
 	if err != nil {
 		tx.Rollback()
@@ -267,3 +507,168 @@ func (repo *tourRepository) Update(ctx context.Context, id uint, data tours.Tour
 
 	return nil
 }
+
+func (repo *tourRepository) UpdateStatus(ctx context.Context, id uint, status string) error {
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Tour{}).Where("id = ?", id).Update("status", status)
+	if qry.Error != nil {
+		return qry.Error
+	}
+
+	if qry.RowsAffected == 0 {
+		return errors.New("not found: tour not found")
+	}
+
+	return nil
+}
+
+// Delete soft deletes a tour that has no active bookings left. The tour row
+// is locked first, so no booking can be made for it in the meantime.
+func (repo *tourRepository) Delete(ctx context.Context, id uint) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var mod = new(Tour)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where(&Tour{Id: id}).First(mod).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("not found: tour not found")
+			}
+			return err
+		}
+
+		// Bookings still being paid or refunded keep the tour, and so do the
+		// approved ones until their departure is over.
+		var active int64
+		qry := tx.Table("bookings").
+			Joins("LEFT JOIN tour_departures ON tour_departures.id = bookings.departure_id").
+			Where("bookings.tour_id = ? AND bookings.deleted_at IS NULL", id).
+			Where("bookings.status IN ? OR (bookings.status = ? AND (tour_departures.finish IS NULL OR tour_departures.finish > ?))", []string{"pending", "refund"}, "approved", time.Now())
+		if err := qry.Count(&active).Error; err != nil {
+			return err
+		}
+
+		if active != 0 {
+			return errors.New("used: tour has active bookings")
+		}
+
+		if err := tx.Where("tour_id = ?", id).Delete(&Departure{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(mod).Error
+	})
+}
+
+func (repo *tourRepository) CreateDeparture(ctx context.Context, tourId uint, data tours.Departure) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTour(tx, tourId); err != nil {
+			return err
+		}
+
+		var mod = new(Departure)
+		mod.FromEntity(data)
+		mod.TourId = tourId
+		mod.Available = mod.Quota
+
+		if err := tx.Create(mod).Error; err != nil {
+			return err
+		}
+
+		return syncTour(tx, tourId)
+	})
+}
+
+// UpdateDeparture changes the dates, quota and price of a departure. Its
+// booked seats stay taken, so the quota can't drop below them.
+func (repo *tourRepository) UpdateDeparture(ctx context.Context, tourId uint, departureId uint, data tours.Departure) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTour(tx, tourId); err != nil {
+			return err
+		}
+
+		var mod = new(Departure)
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where(&Departure{Id: departureId, TourId: tourId}).First(mod).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("not found: departure not found")
+			}
+			return err
+		}
+
+		booked := mod.Quota - mod.Available
+		if data.Quota < booked {
+			return errors.New("unprocessable: quota can't be less than the seats already booked")
+		}
+
+		err := tx.Model(mod).Updates(map[string]any{
+			"start":     data.Start,
+			"finish":    data.Finish,
+			"quota":     data.Quota,
+			"available": data.Quota - booked,
+			"price":     data.Price,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return syncTour(tx, tourId)
+	})
+}
+
+// DeleteDeparture removes a departure that has no active bookings left.
+func (repo *tourRepository) DeleteDeparture(ctx context.Context, tourId uint, departureId uint) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := lockTour(tx, tourId); err != nil {
+			return err
+		}
+
+		var mod = new(Departure)
+		if err := tx.Select("id").Where(&Departure{Id: departureId, TourId: tourId}).First(mod).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return errors.New("not found: departure not found")
+			}
+			return err
+		}
+
+		var active int64
+		qry := tx.Table("bookings").Where("departure_id = ? AND status IN ? AND deleted_at IS NULL", departureId, []string{"pending", "approved", "refund"})
+		if err := qry.Count(&active).Error; err != nil {
+			return err
+		}
+
+		if active != 0 {
+			return errors.New("used: departure has active bookings")
+		}
+
+		if err := tx.Delete(mod).Error; err != nil {
+			return err
+		}
+
+		return syncTour(tx, tourId)
+	})
+}
+
+// lockTour holds the row lock of a tour. Bookings take the same lock before
+// touching seats, so departures never change under a reservation.
+func lockTour(tx *gorm.DB, tourId uint) error {
+	var mod = new(Tour)
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id").Where(&Tour{Id: tourId}).First(mod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return errors.New("not found: tour not found")
+		}
+		return err
+	}
+
+	return nil
+}
+
+// syncTour sums the departures of a tour up into its own dates and seats. A
+// tour left without departures keeps its last dates.
+func syncTour(tx *gorm.DB, tourId uint) error {
+	departures := func(column string) *gorm.DB {
+		return tx.Model(&Departure{}).Select(column).Where("tour_id = ?", tourId)
+	}
+
+	return tx.Model(&Tour{}).Where("id = ?", tourId).Updates(map[string]any{
+		"start":     gorm.Expr("COALESCE((?), start)", departures("MIN(start)")),
+		"finish":    gorm.Expr("COALESCE((?), finish)", departures("MAX(finish)")),
+		"quota":     departures("COALESCE(SUM(quota), 0)"),
+		"available": departures("COALESCE(SUM(available), 0)"),
+	}).Error
+}
@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 	"wanderer/features/tours"
 	"wanderer/helpers/filters"
 )
@@ -17,7 +18,14 @@ type tourService struct {
 	repo tours.Repository
 }
 
-func (srv *tourService) GetAll(ctx context.Context, flt filters.Filter) ([]tours.Tour, int, error) {
+// GetAll lists the tours matching flt. Anyone but an admin only gets to see
+// the published tours that haven't finished yet.
+func (srv *tourService) GetAll(ctx context.Context, admin bool, flt filters.Filter) ([]tours.Tour, int, error) {
+	flt, err := visible(admin, flt)
+	if err != nil {
+		return nil, 0, err
+	}
+
 	result, totalData, err := srv.repo.GetAll(ctx, flt)
 	if err != nil {
 		return nil, 0, err
@@ -26,16 +34,79 @@ func (srv *tourService) GetAll(ctx context.Context, flt filters.Filter) ([]tours
 	return result, totalData, nil
 }
 
-func (srv *tourService) GetDetail(ctx context.Context, id uint) (*tours.Tour, error) {
+// GetFacets counts the tours GetAll would list with flt by location, airline
+// and price range.
+func (srv *tourService) GetFacets(ctx context.Context, admin bool, flt filters.Filter) (*tours.Facets, error) {
+	flt, err := visible(admin, flt)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := srv.repo.GetFacets(ctx, flt)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// visible checks the tour conditions of flt and limits anyone but an admin to
+// the published tours that haven't finished yet.
+func visible(admin bool, flt filters.Filter) (filters.Filter, error) {
+	if !admin {
+		flt.Tour.Status = tours.StatusPublished
+		flt.Tour.Upcoming = true
+	} else if flt.Tour.Status != "" && !tours.ValidStatus(flt.Tour.Status) {
+		return flt, errors.New("validate: invalid tour status")
+	}
+
+	if err := flt.Tour.Validate(); err != nil {
+		return flt, err
+	}
+
+	return flt, nil
+}
+
+// GetDetail is a tour by its id, its reviews ordered by reviewSort when set.
+// Drafts are only shown to admins, while archived tours stay visible to the
+// customers who booked them.
+func (srv *tourService) GetDetail(ctx context.Context, admin bool, userId uint, id uint, reviewSort string) (*tours.Tour, error) {
 	if id == 0 {
 		return nil, errors.New("validate: invalid tour id")
 	}
 
+	if reviewSort != "" && !tours.SortReviews(nil, reviewSort) {
+		return nil, errors.New("validate: invalid review sort")
+	}
+
 	result, err := srv.repo.GetDetail(ctx, id)
 	if err != nil {
 		return nil, err
 	}
 
+	if !admin && result.Status == tours.StatusDraft {
+		return nil, errors.New("not found: tour not found")
+	}
+
+	if !admin && result.Status == tours.StatusArchived {
+		if userId == 0 {
+			return nil, errors.New("not found: tour not found")
+		}
+
+		booked, err := srv.repo.IsBooked(ctx, id, userId)
+		if err != nil {
+			return nil, err
+		}
+
+		if !booked {
+			return nil, errors.New("not found: tour not found")
+		}
+	}
+
+	if reviewSort != "" {
+		tours.SortReviews(result.Reviews, reviewSort)
+	}
+
 	return result, nil
 }
 
@@ -52,16 +123,19 @@ func (srv *tourService) Create(ctx context.Context, data tours.Tour) error {
 		return errors.New("validate: price can't be empty")
 	}
 
-	if data.Start.IsZero() {
-		return errors.New("validate: start date can't be empty")
+	if err := validatePassengerPrices(data); err != nil {
+		return err
 	}
 
-	if data.Finish.IsZero() {
-		return errors.New("validate: finish date can't be empty")
+	// A tour created without departures runs once, on its own dates.
+	if len(data.Departures) == 0 {
+		data.Departures = []tours.Departure{{Start: data.Start, Finish: data.Finish, Quota: data.Quota}}
 	}
 
-	if data.Quota == 0 {
-		return errors.New("validate: quota can't be empty")
+	for _, departure := range data.Departures {
+		if err := validateDeparture(departure); err != nil {
+			return err
+		}
 	}
 
 	if data.Thumbnail.Raw == nil {
@@ -80,6 +154,14 @@ func (srv *tourService) Create(ctx context.Context, data tours.Tour) error {
 		return errors.New("validate: airline can't be empty")
 	}
 
+	switch data.Status {
+	case "":
+		data.Status = tours.StatusDraft
+	case tours.StatusDraft, tours.StatusPublished:
+	default:
+		return errors.New("validate: a new tour can only be a draft or published")
+	}
+
 	if err := srv.repo.Create(ctx, data); err != nil {
 		return err
 	}
@@ -104,16 +186,8 @@ func (srv *tourService) Update(ctx context.Context, id uint, data tours.Tour) er
 		return errors.New("validate: price can't be empty")
 	}
 
-	if data.Start.IsZero() {
-		return errors.New("validate: start date can't be empty")
-	}
-
-	if data.Finish.IsZero() {
-		return errors.New("validate: finish date can't be empty")
-	}
-
-	if data.Quota == 0 {
-		return errors.New("validate: quota can't be empty")
+	if err := validatePassengerPrices(data); err != nil {
+		return err
 	}
 
 	if len(data.Itinerary) == 0 {
@@ -128,9 +202,132 @@ func (srv *tourService) Update(ctx context.Context, id uint, data tours.Tour) er
 		return errors.New("validate: airline can't be empty")
 	}
 
+	// The status only changes through UpdateStatus, the dates and seats only
+	// through the departures.
+	data.Status = ""
+	data.Start = time.Time{}
+	data.Finish = time.Time{}
+	data.Quota = 0
+	data.Departures = nil
+
 	if err := srv.repo.Update(ctx, id, data); err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// UpdateStatus publishes, unpublishes or archives a tour. A tour that has
+// already finished can't be published anymore.
+func (srv *tourService) UpdateStatus(ctx context.Context, id uint, status string) error {
+	if id == 0 {
+		return errors.New("validate: invalid tour id")
+	}
+
+	if !tours.ValidStatus(status) {
+		return errors.New("validate: status must be draft, published or archived")
+	}
+
+	tour, err := srv.repo.GetDetail(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if tour.Status == status {
+		return nil
+	}
+
+	if status == tours.StatusPublished && !tour.Finish.After(time.Now()) {
+		return errors.New("unprocessable: a finished tour can't be published")
+	}
+
+	return srv.repo.UpdateStatus(ctx, id, status)
+}
+
+// Delete removes a tour, unless it still has active bookings.
+func (srv *tourService) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("validate: invalid tour id")
+	}
+
+	return srv.repo.Delete(ctx, id)
+}
+
+func (srv *tourService) CreateDeparture(ctx context.Context, tourId uint, data tours.Departure) error {
+	if tourId == 0 {
+		return errors.New("validate: invalid tour id")
+	}
+
+	if err := validateDeparture(data); err != nil {
+		return err
+	}
+
+	return srv.repo.CreateDeparture(ctx, tourId, data)
+}
+
+func (srv *tourService) UpdateDeparture(ctx context.Context, tourId uint, departureId uint, data tours.Departure) error {
+	if tourId == 0 {
+		return errors.New("validate: invalid tour id")
+	}
+
+	if departureId == 0 {
+		return errors.New("validate: invalid departure id")
+	}
+
+	if err := validateDeparture(data); err != nil {
+		return err
+	}
+
+	return srv.repo.UpdateDeparture(ctx, tourId, departureId, data)
+}
+
+// DeleteDeparture removes a departure, unless it still has active bookings.
+func (srv *tourService) DeleteDeparture(ctx context.Context, tourId uint, departureId uint) error {
+	if tourId == 0 {
+		return errors.New("validate: invalid tour id")
+	}
+
+	if departureId == 0 {
+		return errors.New("validate: invalid departure id")
+	}
+
+	return srv.repo.DeleteDeparture(ctx, tourId, departureId)
+}
+
+// validatePassengerPrices checks that children and infants don't pay more
+// than adults.
+func validatePassengerPrices(data tours.Tour) error {
+	if data.ChildPrice != nil && (*data.ChildPrice < 0 || *data.ChildPrice > data.Price) {
+		return errors.New("validate: child price must be between 0 and the price")
+	}
+
+	if data.InfantPrice != nil && (*data.InfantPrice < 0 || *data.InfantPrice > data.Price) {
+		return errors.New("validate: infant price must be between 0 and the price")
+	}
+
+	return nil
+}
+
+func validateDeparture(data tours.Departure) error {
+	if data.Start.IsZero() {
+		return errors.New("validate: start date can't be empty")
+	}
+
+	if data.Finish.IsZero() {
+		return errors.New("validate: finish date can't be empty")
+	}
+
+	if !data.Finish.After(data.Start) {
+		return errors.New("validate: finish date must be after start date")
+	}
+
+	if data.Quota <= 0 {
+		return errors.New("validate: quota can't be empty")
+	}
+
+	if data.Price < 0 {
+		return errors.New("validate: price can't be negative")
+	}
+
+	return nil
+}
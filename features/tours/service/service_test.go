@@ -65,10 +65,13 @@ func TestTourServiceGetAll(t *testing.T) {
 		},
 	}
 
+	publicFilter := filter
+	publicFilter.Tour = filters.Tour{Status: tours.StatusPublished, Upcoming: true}
+
 	t.Run("error from repository", func(t *testing.T) {
-		repo.On("GetAll", ctx, filter).Return(nil, 0, errors.New("some error from repository")).Once()
+		repo.On("GetAll", ctx, publicFilter).Return(nil, 0, errors.New("some error from repository")).Once()
 
-		result, totalData, err := srv.GetAll(ctx, filter)
+		result, totalData, err := srv.GetAll(ctx, false, filter)
 
 		assert.ErrorContains(t, err, "some error from repository")
 		assert.Nil(t, result)
@@ -80,9 +83,9 @@ func TestTourServiceGetAll(t *testing.T) {
 	t.Run("success", func(t *testing.T) {
 		resultData := data
 
-		repo.On("GetAll", ctx, filter).Return(resultData, 10, nil).Once()
+		repo.On("GetAll", ctx, publicFilter).Return(resultData, 10, nil).Once()
 
-		result, totalData, err := srv.GetAll(ctx, filter)
+		result, totalData, err := srv.GetAll(ctx, false, filter)
 
 		assert.NoError(t, err)
 		assert.Equal(t, data, result)
@@ -90,6 +93,140 @@ func TestTourServiceGetAll(t *testing.T) {
 
 		repo.AssertExpectations(t)
 	})
+
+	t.Run("public can't pick the status", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour = filters.Tour{Status: tours.StatusDraft}
+
+		repo.On("GetAll", ctx, publicFilter).Return(data, 2, nil).Once()
+
+		_, _, err := srv.GetAll(ctx, false, caseFilter)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid status for admin", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour = filters.Tour{Status: "sold"}
+
+		_, _, err := srv.GetAll(ctx, true, caseFilter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "status")
+	})
+
+	t.Run("admin sees every tour", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour = filters.Tour{Status: tours.StatusDraft}
+
+		repo.On("GetAll", ctx, caseFilter).Return(data, 2, nil).Once()
+
+		result, totalData, err := srv.GetAll(ctx, true, caseFilter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, data, result)
+		assert.Equal(t, 2, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("public keeps the other filters", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour = filters.Tour{LocationId: 1, MinPrice: 10000000, MaxPrice: 40000000, Seats: 2}
+
+		expectedFilter := caseFilter
+		expectedFilter.Tour.Status = tours.StatusPublished
+		expectedFilter.Tour.Upcoming = true
+
+		repo.On("GetAll", ctx, expectedFilter).Return(data, 2, nil).Once()
+
+		_, _, err := srv.GetAll(ctx, false, caseFilter)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid filter", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour = filters.Tour{MinPrice: 40000000, MaxPrice: 10000000}
+
+		_, _, err := srv.GetAll(ctx, false, caseFilter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "price")
+	})
+}
+
+func TestTourServiceGetFacets(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewTourService(repo)
+	ctx := context.Background()
+
+	data := tours.Facets{
+		Locations: []tours.Facet{{Id: 1, Name: "Jepang", Count: 2}},
+		Airlines:  []tours.Facet{{Id: 1, Name: "Garuda Indonesia", Count: 2}},
+		Prices:    []tours.PriceFacet{{Min: 0, Max: 5000000, Count: 0}, {Min: 20000000, Max: 50000000, Count: 2}},
+	}
+
+	filter := filters.Filter{
+		Search: filters.Search{
+			Keyword: "Jepang",
+		},
+		Tour: filters.Tour{
+			AirlineId: 1,
+		},
+	}
+
+	publicFilter := filter
+	publicFilter.Tour.Status = tours.StatusPublished
+	publicFilter.Tour.Upcoming = true
+
+	t.Run("invalid filter", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour.MinRating = 6
+
+		result, err := srv.GetFacets(ctx, false, caseFilter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "rating")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid status for admin", func(t *testing.T) {
+		caseFilter := filter
+		caseFilter.Tour.Status = "sold"
+
+		result, err := srv.GetFacets(ctx, true, caseFilter)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "status")
+		assert.Nil(t, result)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetFacets", ctx, publicFilter).Return(nil, errors.New("some error from repository")).Once()
+
+		result, err := srv.GetFacets(ctx, false, filter)
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetFacets", ctx, publicFilter).Return(&data, nil).Once()
+
+		result, err := srv.GetFacets(ctx, false, filter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &data, result)
+
+		repo.AssertExpectations(t)
+	})
 }
 
 func TestTourServiceGetDetail(t *testing.T) {
@@ -132,7 +269,7 @@ func TestTourServiceGetDetail(t *testing.T) {
 	}
 
 	t.Run("invalid id", func(t *testing.T) {
-		result, err := srv.GetDetail(ctx, 0)
+		result, err := srv.GetDetail(ctx, false, 0, 0, "")
 
 		assert.ErrorContains(t, err, "validate")
 		assert.ErrorContains(t, err, "id")
@@ -142,7 +279,7 @@ func TestTourServiceGetDetail(t *testing.T) {
 	t.Run("error from repository", func(t *testing.T) {
 		repo.On("GetDetail", ctx, uint(1)).Return(nil, errors.New("some error from repository")).Once()
 
-		result, err := srv.GetDetail(ctx, 1)
+		result, err := srv.GetDetail(ctx, false, 0, 1, "")
 
 		assert.ErrorContains(t, err, "some error from repository")
 		assert.Nil(t, result)
@@ -155,13 +292,111 @@ func TestTourServiceGetDetail(t *testing.T) {
 
 		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Once()
 
-		result, err := srv.GetDetail(ctx, 1)
+		result, err := srv.GetDetail(ctx, false, 0, 1, "")
 
 		assert.NoError(t, err)
 		assert.Equal(t, &data, result)
 
 		repo.AssertExpectations(t)
 	})
+
+	t.Run("draft is hidden from public", func(t *testing.T) {
+		resultData := data
+		resultData.Status = tours.StatusDraft
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Twice()
+
+		result, err := srv.GetDetail(ctx, false, 0, 1, "")
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, result)
+
+		result, err = srv.GetDetail(ctx, true, 0, 1, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, tours.StatusDraft, result.Status)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("archived is only shown to its customers", func(t *testing.T) {
+		resultData := data
+		resultData.Status = tours.StatusArchived
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Times(4)
+		repo.On("IsBooked", ctx, uint(1), uint(2)).Return(false, nil).Once()
+		repo.On("IsBooked", ctx, uint(1), uint(3)).Return(true, nil).Once()
+
+		result, err := srv.GetDetail(ctx, false, 0, 1, "")
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, result)
+
+		result, err = srv.GetDetail(ctx, false, 2, 1, "")
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, result)
+
+		result, err = srv.GetDetail(ctx, false, 3, 1, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, tours.StatusArchived, result.Status)
+
+		result, err = srv.GetDetail(ctx, true, 1, 1, "")
+
+		assert.NoError(t, err)
+		assert.Equal(t, tours.StatusArchived, result.Status)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository on archived", func(t *testing.T) {
+		resultData := data
+		resultData.Status = tours.StatusArchived
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Once()
+		repo.On("IsBooked", ctx, uint(1), uint(2)).Return(false, errors.New("some error from repository")).Once()
+
+		result, err := srv.GetDetail(ctx, false, 2, 1, "")
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("invalid review sort", func(t *testing.T) {
+		result, err := srv.GetDetail(ctx, false, 0, 1, "oldest")
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "review sort")
+		assert.Nil(t, result)
+	})
+
+	t.Run("most helpful reviews first", func(t *testing.T) {
+		now := time.Now()
+
+		resultData := data
+		resultData.Reviews = []tours.Review{
+			{Id: 1, Helpful: 1, CreatedAt: now.Add(-3 * time.Hour)},
+			{Id: 2, Helpful: 5, CreatedAt: now.Add(-2 * time.Hour)},
+			{Id: 3, Helpful: 1, CreatedAt: now.Add(-time.Hour)},
+		}
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Once()
+
+		result, err := srv.GetDetail(ctx, false, 0, 1, tours.ReviewSortHelpful)
+
+		assert.NoError(t, err)
+
+		var ids []uint
+		for _, review := range result.Reviews {
+			ids = append(ids, review.Id)
+		}
+		assert.Equal(t, []uint{2, 3, 1}, ids)
+
+		repo.AssertExpectations(t)
+	})
 }
 
 func TestTourServiceCreate(t *testing.T) {
@@ -203,6 +438,8 @@ func TestTourServiceCreate(t *testing.T) {
 		},
 	}
 
+	departures := []tours.Departure{{Start: data.Start, Finish: data.Finish, Quota: data.Quota}}
+
 	t.Run("invalid title", func(t *testing.T) {
 		caseData := data
 		caseData.Title = ""
@@ -233,6 +470,28 @@ func TestTourServiceCreate(t *testing.T) {
 		assert.ErrorContains(t, err, "price")
 	})
 
+	t.Run("child price above price", func(t *testing.T) {
+		caseData := data
+		childPrice := caseData.Price + 1
+		caseData.ChildPrice = &childPrice
+
+		err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "child price")
+	})
+
+	t.Run("negative infant price", func(t *testing.T) {
+		caseData := data
+		infantPrice := float64(-1)
+		caseData.InfantPrice = &infantPrice
+
+		err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "infant price")
+	})
+
 	t.Run("invalid start date", func(t *testing.T) {
 		caseData := data
 		caseData.Start = time.Time{}
@@ -303,10 +562,48 @@ func TestTourServiceCreate(t *testing.T) {
 		assert.ErrorContains(t, err, "airline")
 	})
 
+	t.Run("invalid status", func(t *testing.T) {
+		caseData := data
+		caseData.Status = tours.StatusArchived
+
+		err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "draft")
+	})
+
+	t.Run("invalid departure dates", func(t *testing.T) {
+		caseData := data
+		caseData.Departures = []tours.Departure{
+			{Start: time.Now().Add(time.Hour * 24), Finish: time.Now().Add(time.Hour * 72), Quota: 10},
+			{Start: time.Now().Add(time.Hour * 96), Finish: time.Now().Add(time.Hour * 48), Quota: 10},
+		}
+
+		err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "after start date")
+	})
+
+	t.Run("invalid departure price", func(t *testing.T) {
+		caseData := data
+		caseData.Departures = []tours.Departure{
+			{Start: time.Now().Add(time.Hour * 24), Finish: time.Now().Add(time.Hour * 72), Quota: 10, Price: -1},
+		}
+
+		err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "price")
+	})
+
 	t.Run("error from repository", func(t *testing.T) {
 		caseData := data
+		repoData := data
+		repoData.Status = tours.StatusDraft
+		repoData.Departures = departures
 
-		repo.On("Create", ctx, caseData).Return(errors.New("some error from repository")).Once()
+		repo.On("Create", ctx, repoData).Return(errors.New("some error from repository")).Once()
 
 		err := srv.Create(ctx, caseData)
 
@@ -315,8 +612,43 @@ func TestTourServiceCreate(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 
-	t.Run("success", func(t *testing.T) {
+	t.Run("success as draft", func(t *testing.T) {
 		caseData := data
+		repoData := data
+		repoData.Status = tours.StatusDraft
+		repoData.Departures = departures
+
+		repo.On("Create", ctx, repoData).Return(nil).Once()
+
+		err := srv.Create(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success as published", func(t *testing.T) {
+		caseData := data
+		caseData.Status = tours.StatusPublished
+		repoData := caseData
+		repoData.Departures = departures
+
+		repo.On("Create", ctx, repoData).Return(nil).Once()
+
+		err := srv.Create(ctx, caseData)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success with departures", func(t *testing.T) {
+		caseData := data
+		caseData.Status = tours.StatusPublished
+		caseData.Departures = []tours.Departure{
+			{Start: time.Now().Add(time.Hour * 24), Finish: time.Now().Add(time.Hour * 72), Quota: 10},
+			{Start: time.Now().Add(time.Hour * 192), Finish: time.Now().Add(time.Hour * 240), Quota: 20, Price: 35000000},
+		}
 
 		repo.On("Create", ctx, caseData).Return(nil).Once()
 
@@ -406,84 +738,321 @@ func TestTourServiceUpdate(t *testing.T) {
 		assert.ErrorContains(t, err, "price")
 	})
 
-	t.Run("invalid start date", func(t *testing.T) {
+	t.Run("invalid itinerary", func(t *testing.T) {
 		caseData := data
-		caseData.Start = time.Time{}
+		caseData.Itinerary = nil
 
 		err := srv.Update(ctx, 1, caseData)
 
 		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "start date")
+		assert.ErrorContains(t, err, "itinerary")
 	})
 
-	t.Run("invalid finish date", func(t *testing.T) {
+	t.Run("invalid location", func(t *testing.T) {
 		caseData := data
-		caseData.Finish = time.Time{}
+		caseData.Location.Id = 0
 
 		err := srv.Update(ctx, 1, caseData)
 
 		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "finish date")
+		assert.ErrorContains(t, err, "location")
 	})
 
-	t.Run("invalid quota", func(t *testing.T) {
+	t.Run("invalid airline", func(t *testing.T) {
 		caseData := data
-		caseData.Quota = 0
+		caseData.Airline.Id = 0
 
 		err := srv.Update(ctx, 1, caseData)
 
 		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "quota")
+		assert.ErrorContains(t, err, "airline")
 	})
 
-	t.Run("invalid itinerary", func(t *testing.T) {
+	repoData := data
+	repoData.Start = time.Time{}
+	repoData.Finish = time.Time{}
+	repoData.Quota = 0
+
+	t.Run("error from repository", func(t *testing.T) {
 		caseData := data
-		caseData.Itinerary = nil
+
+		repo.On("Update", ctx, uint(1), repoData).Return(errors.New("some error from repository")).Once()
 
 		err := srv.Update(ctx, 1, caseData)
 
-		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "itinerary")
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
 	})
 
-	t.Run("invalid location", func(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
 		caseData := data
-		caseData.Location.Id = 0
+
+		repo.On("Update", ctx, uint(1), repoData).Return(nil).Once()
 
 		err := srv.Update(ctx, 1, caseData)
 
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTourServiceUpdateStatus(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewTourService(repo)
+	ctx := context.Background()
+
+	data := tours.Tour{
+		Id:     1,
+		Title:  "Jepang Winter Golden Route & Mount Fuji",
+		Start:  time.Now().Add(time.Hour * 24),
+		Finish: time.Now().Add(time.Hour * 48),
+		Status: tours.StatusDraft,
+	}
+
+	t.Run("invalid id", func(t *testing.T) {
+		err := srv.UpdateStatus(ctx, 0, tours.StatusPublished)
+
 		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "location")
+		assert.ErrorContains(t, err, "id")
 	})
 
-	t.Run("invalid airline", func(t *testing.T) {
+	t.Run("invalid status", func(t *testing.T) {
+		err := srv.UpdateStatus(ctx, 1, "sold")
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "status")
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("GetDetail", ctx, uint(1)).Return(nil, errors.New("not found: tour not found")).Once()
+
+		err := srv.UpdateStatus(ctx, 1, tours.StatusPublished)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("finished tour can't be published", func(t *testing.T) {
+		resultData := data
+		resultData.Start = time.Now().Add(-time.Hour * 48)
+		resultData.Finish = time.Now().Add(-time.Hour * 24)
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Once()
+
+		err := srv.UpdateStatus(ctx, 1, tours.StatusPublished)
+
+		assert.ErrorContains(t, err, "unprocessable")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("unchanged status", func(t *testing.T) {
+		resultData := data
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Once()
+
+		err := srv.UpdateStatus(ctx, 1, tours.StatusDraft)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		resultData := data
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&resultData, nil).Once()
+		repo.On("UpdateStatus", ctx, uint(1), tours.StatusPublished).Return(nil).Once()
+
+		err := srv.UpdateStatus(ctx, 1, tours.StatusPublished)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTourServiceDelete(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewTourService(repo)
+	ctx := context.Background()
+
+	t.Run("invalid id", func(t *testing.T) {
+		err := srv.Delete(ctx, 0)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "id")
+	})
+
+	t.Run("active bookings", func(t *testing.T) {
+		repo.On("Delete", ctx, uint(1)).Return(errors.New("used: tour has active bookings")).Once()
+
+		err := srv.Delete(ctx, 1)
+
+		assert.ErrorContains(t, err, "used")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("Delete", ctx, uint(1)).Return(nil).Once()
+
+		err := srv.Delete(ctx, 1)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTourServiceCreateDeparture(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewTourService(repo)
+	ctx := context.Background()
+
+	data := tours.Departure{
+		Start:  time.Now().Add(time.Hour * 24),
+		Finish: time.Now().Add(time.Hour * 72),
+		Quota:  20,
+	}
+
+	t.Run("invalid tour id", func(t *testing.T) {
+		err := srv.CreateDeparture(ctx, 0, data)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "tour id")
+	})
+
+	t.Run("invalid start date", func(t *testing.T) {
 		caseData := data
-		caseData.Airline.Id = 0
+		caseData.Start = time.Time{}
 
-		err := srv.Update(ctx, 1, caseData)
+		err := srv.CreateDeparture(ctx, 1, caseData)
 
 		assert.ErrorContains(t, err, "validate")
-		assert.ErrorContains(t, err, "airline")
+		assert.ErrorContains(t, err, "start date")
 	})
 
-	t.Run("error from repository", func(t *testing.T) {
+	t.Run("invalid finish date", func(t *testing.T) {
 		caseData := data
+		caseData.Finish = caseData.Start
 
-		repo.On("Update", ctx, uint(1), caseData).Return(errors.New("some error from repository")).Once()
+		err := srv.CreateDeparture(ctx, 1, caseData)
 
-		err := srv.Update(ctx, 1, caseData)
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "finish date")
+	})
 
-		assert.ErrorContains(t, err, "some error from repository")
+	t.Run("invalid quota", func(t *testing.T) {
+		caseData := data
+		caseData.Quota = 0
+
+		err := srv.CreateDeparture(ctx, 1, caseData)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "quota")
+	})
+
+	t.Run("tour not found", func(t *testing.T) {
+		repo.On("CreateDeparture", ctx, uint(1), data).Return(errors.New("not found: tour not found")).Once()
+
+		err := srv.CreateDeparture(ctx, 1, data)
+
+		assert.ErrorContains(t, err, "not found")
 
 		repo.AssertExpectations(t)
 	})
 
 	t.Run("success", func(t *testing.T) {
+		repo.On("CreateDeparture", ctx, uint(1), data).Return(nil).Once()
+
+		err := srv.CreateDeparture(ctx, 1, data)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTourServiceUpdateDeparture(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewTourService(repo)
+	ctx := context.Background()
+
+	data := tours.Departure{
+		Start:  time.Now().Add(time.Hour * 24),
+		Finish: time.Now().Add(time.Hour * 72),
+		Quota:  20,
+		Price:  25000000,
+	}
+
+	t.Run("invalid departure id", func(t *testing.T) {
+		err := srv.UpdateDeparture(ctx, 1, 0, data)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "departure id")
+	})
+
+	t.Run("invalid quota", func(t *testing.T) {
 		caseData := data
+		caseData.Quota = -1
 
-		repo.On("Update", ctx, uint(1), caseData).Return(nil).Once()
+		err := srv.UpdateDeparture(ctx, 1, 2, caseData)
 
-		err := srv.Update(ctx, 1, caseData)
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "quota")
+	})
+
+	t.Run("quota below booked seats", func(t *testing.T) {
+		repo.On("UpdateDeparture", ctx, uint(1), uint(2), data).Return(errors.New("unprocessable: quota can't be less than the seats already booked")).Once()
+
+		err := srv.UpdateDeparture(ctx, 1, 2, data)
+
+		assert.ErrorContains(t, err, "unprocessable")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("UpdateDeparture", ctx, uint(1), uint(2), data).Return(nil).Once()
+
+		err := srv.UpdateDeparture(ctx, 1, 2, data)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTourServiceDeleteDeparture(t *testing.T) {
+	repo := mocks.NewRepository(t)
+	srv := NewTourService(repo)
+	ctx := context.Background()
+
+	t.Run("invalid departure id", func(t *testing.T) {
+		err := srv.DeleteDeparture(ctx, 1, 0)
+
+		assert.ErrorContains(t, err, "validate")
+		assert.ErrorContains(t, err, "departure id")
+	})
+
+	t.Run("active bookings", func(t *testing.T) {
+		repo.On("DeleteDeparture", ctx, uint(1), uint(2)).Return(errors.New("used: departure has active bookings")).Once()
+
+		err := srv.DeleteDeparture(ctx, 1, 2)
+
+		assert.ErrorContains(t, err, "used")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("DeleteDeparture", ctx, uint(1), uint(2)).Return(nil).Once()
+
+		err := srv.DeleteDeparture(ctx, 1, 2)
 
 		assert.NoError(t, err)
 
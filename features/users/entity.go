@@ -1,8 +1,10 @@
 package users
 
 import (
+	"context"
 	"io"
 	"time"
+	"wanderer/helpers/tokens"
 
 	"github.com/labstack/echo/v4"
 )
@@ -15,6 +17,8 @@ type User struct {
 	Password string
 	Role     string
 
+	EmailVerifiedAt time.Time
+
 	ImageUrl string
 	ImageRaw io.Reader
 
@@ -28,7 +32,7 @@ type User struct {
 }
 
 type Booking struct {
-	Code        int
+	Code        string
 	DetailCount int
 	Status      string
 	Tour        Tour
@@ -39,12 +43,47 @@ type Tour struct {
 	Title string
 }
 
+// Token is a pair of a short lived access token and the refresh token to
+// renew it with.
+type Token struct {
+	AccessToken     string
+	AccessExpiresAt time.Time
+	RefreshToken    string
+}
+
+// RefreshToken is a stored refresh token. Tokens renewed from each other share
+// a Family, which is revoked as a whole once a used token shows up again.
+type RefreshToken struct {
+	Id        uint
+	UserId    uint
+	Hash      string
+	Family    string
+	ExpiresAt time.Time
+	RevokedAt time.Time
+}
+
+// UserToken is a single use token sent by email, to reset the password or to
+// verify the email address. Issuing a new one invalidates the previous tokens
+// of the same purpose.
+type UserToken struct {
+	TokenId   string
+	UserId    uint
+	Purpose   string
+	ExpiresAt time.Time
+}
+
 type Handler interface {
 	Register() echo.HandlerFunc
 	Login() echo.HandlerFunc
 	Update() echo.HandlerFunc
 	Delete() echo.HandlerFunc
 	Detail() echo.HandlerFunc
+	Refresh() echo.HandlerFunc
+	Logout() echo.HandlerFunc
+	ForgotPassword() echo.HandlerFunc
+	ResetPassword() echo.HandlerFunc
+	VerifyEmail() echo.HandlerFunc
+	ResendVerification() echo.HandlerFunc
 }
 
 type Service interface {
@@ -53,6 +92,13 @@ type Service interface {
 	Update(id uint, updateUser User) error
 	Delete(id uint) error
 	Detail(id uint) (*User, error)
+	IssueToken(ctx context.Context, user User) (*Token, error)
+	Refresh(ctx context.Context, refreshToken string) (*Token, error)
+	Logout(ctx context.Context, claims tokens.Claims, refreshToken string) error
+	ForgotPassword(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token string, password string) error
+	SendVerification(ctx context.Context, email string) error
+	VerifyEmail(ctx context.Context, token string) error
 }
 
 type Repository interface {
@@ -61,4 +107,16 @@ type Repository interface {
 	Update(id uint, updateUser User) error
 	Delete(id uint) error
 	Detail(id uint) (*User, error)
+	GetRole(ctx context.Context, id uint) (string, error)
+
+	CreateRefreshToken(ctx context.Context, data RefreshToken) error
+	GetRefreshToken(ctx context.Context, hash string) (*RefreshToken, error)
+	RotateRefreshToken(ctx context.Context, old RefreshToken, data RefreshToken) error
+	RevokeRefreshFamily(ctx context.Context, family string) error
+	RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenId string) (bool, error)
+
+	CreateUserToken(ctx context.Context, data UserToken) error
+	ResetPassword(ctx context.Context, token UserToken, password string) error
+	VerifyEmail(ctx context.Context, token UserToken) error
 }
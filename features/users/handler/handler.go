@@ -82,6 +82,11 @@ func (hdl *userHandler) Login() echo.HandlerFunc {
 				return c.JSON(http.StatusBadRequest, response)
 			}
 
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
 			if strings.Contains(err.Error(), "wrong password") {
 				response["message"] = err.Error()
 				return c.JSON(http.StatusBadRequest, response)
@@ -96,14 +101,17 @@ func (hdl *userHandler) Login() echo.HandlerFunc {
 			return c.JSON(http.StatusInternalServerError, response)
 		}
 
-		strToken, err := tokens.GenerateJWT(hdl.jwtConfig.Secret, result.Id)
+		token, err := hdl.userService.IssueToken(c.Request().Context(), *result)
 		if err != nil {
-			return err
+			c.Logger().Error(err)
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
 		}
 
 		var data = new(LoginResponse)
 		data.FromEntity(*result)
-		data.Token = strToken
+		data.FromToken(*token)
 
 		response["message"] = "login success"
 		response["data"] = data
@@ -252,3 +260,211 @@ func (hdl *userHandler) Detail() echo.HandlerFunc {
 		return c.JSON(http.StatusOK, response)
 	}
 }
+
+func (hdl *userHandler) Refresh() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(RefreshRequest)
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "please fill input correctly"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		token, err := hdl.userService.Refresh(c.Request().Context(), request.RefreshToken)
+		if err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "unauthorized: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unauthorized: ", "")
+				return c.JSON(http.StatusUnauthorized, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		var data = new(TokenResponse)
+		data.FromEntity(*token)
+
+		response["message"] = "refresh token success"
+		response["data"] = data
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *userHandler) Logout() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(RefreshRequest)
+
+		token, ok := c.Get("user").(*jwt.Token)
+		if !ok {
+			response["message"] = "unauthorized access"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		claims, err := tokens.ExtractClaims(token)
+		if err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "unauthorized"
+			return c.JSON(http.StatusUnauthorized, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "please fill input correctly"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.userService.Logout(c.Request().Context(), *claims, request.RefreshToken); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "logout success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *userHandler) ForgotPassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(EmailRequest)
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "please fill input correctly"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.userService.ForgotPassword(c.Request().Context(), request.Email); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "if the email is registered, a link to reset the password has been sent"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *userHandler) ResetPassword() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(ResetPasswordRequest)
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "please fill input correctly"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.userService.ResetPassword(c.Request().Context(), request.Token, request.Password); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "reset password success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *userHandler) VerifyEmail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(VerifyEmailRequest)
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "please fill input correctly"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.userService.VerifyEmail(c.Request().Context(), request.Token); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			if strings.Contains(err.Error(), "unprocessable: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "unprocessable: ", "")
+				return c.JSON(http.StatusUnprocessableEntity, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "verify email success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *userHandler) ResendVerification() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(EmailRequest)
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "please fill input correctly"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.userService.SendVerification(c.Request().Context(), request.Email); err != nil {
+			c.Logger().Error(err)
+
+			if strings.Contains(err.Error(), "validate: ") {
+				response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+				return c.JSON(http.StatusBadRequest, response)
+			}
+
+			response["message"] = "internal server error"
+			return c.JSON(http.StatusInternalServerError, response)
+		}
+
+		response["message"] = "if the email is registered and not verified yet, a verification link has been sent"
+		return c.JSON(http.StatusOK, response)
+	}
+}
@@ -19,6 +19,23 @@ type LoginRequest struct {
 	Password string `json:"password,omitempty"`
 }
 
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
 func (req *RegisterRequest) ToEntity() *users.User {
 	var ent = new(users.User)
 
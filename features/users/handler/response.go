@@ -2,6 +2,7 @@ package handler
 
 import (
 	"reflect"
+	"time"
 	"wanderer/features/users"
 )
 
@@ -62,14 +63,14 @@ func (res *UserResponse) FromEntity(ent users.User) {
 }
 
 type BookingResponse struct {
-	Code        int          `json:"booking_code,omitempty"`
+	Code        string       `json:"booking_code,omitempty"`
 	Status      string       `json:"status,omitempty"`
 	DetailCount int          `json:"detail_count,omitempty"`
 	Tour        TourResponse `json:"tour,omitempty"`
 }
 
 func (res *BookingResponse) FromEntity(ent users.Booking) {
-	if ent.Code != 0 {
+	if ent.Code != "" {
 		res.Code = ent.Code
 	}
 
@@ -106,7 +107,27 @@ type LoginResponse struct {
 	Name  string `json:"fullname,omitempty"`
 	Image string `json:"image,omitempty"`
 	Role  string `json:"role,omitempty"`
-	Token string `json:"token,omitempty"`
+
+	TokenResponse
+}
+
+func (res *LoginResponse) FromToken(ent users.Token) {
+	res.TokenResponse.FromEntity(ent)
+}
+
+type TokenResponse struct {
+	Token        string     `json:"token,omitempty"`
+	TokenExpired *time.Time `json:"token_expired,omitempty"`
+	RefreshToken string     `json:"refresh_token,omitempty"`
+}
+
+func (res *TokenResponse) FromEntity(ent users.Token) {
+	res.Token = ent.AccessToken
+	res.RefreshToken = ent.RefreshToken
+
+	if !ent.AccessExpiresAt.IsZero() {
+		res.TokenExpired = &ent.AccessExpiresAt
+	}
 }
 
 func (res *LoginResponse) FromEntity(ent users.User) {
@@ -44,6 +44,22 @@ func (_m *Handler) Detail() echo.HandlerFunc {
 	return r0
 }
 
+// ForgotPassword provides a mock function with given fields:
+func (_m *Handler) ForgotPassword() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // Login provides a mock function with given fields:
 func (_m *Handler) Login() echo.HandlerFunc {
 	ret := _m.Called()
@@ -60,6 +76,38 @@ func (_m *Handler) Login() echo.HandlerFunc {
 	return r0
 }
 
+// Logout provides a mock function with given fields:
+func (_m *Handler) Logout() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// Refresh provides a mock function with given fields:
+func (_m *Handler) Refresh() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // Register provides a mock function with given fields:
 func (_m *Handler) Register() echo.HandlerFunc {
 	ret := _m.Called()
@@ -76,6 +124,38 @@ func (_m *Handler) Register() echo.HandlerFunc {
 	return r0
 }
 
+// ResendVerification provides a mock function with given fields:
+func (_m *Handler) ResendVerification() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
+// ResetPassword provides a mock function with given fields:
+func (_m *Handler) ResetPassword() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields:
 func (_m *Handler) Update() echo.HandlerFunc {
 	ret := _m.Called()
@@ -92,6 +172,22 @@ func (_m *Handler) Update() echo.HandlerFunc {
 	return r0
 }
 
+// VerifyEmail provides a mock function with given fields:
+func (_m *Handler) VerifyEmail() echo.HandlerFunc {
+	ret := _m.Called()
+
+	var r0 echo.HandlerFunc
+	if rf, ok := ret.Get(0).(func() echo.HandlerFunc); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(echo.HandlerFunc)
+		}
+	}
+
+	return r0
+}
+
 // NewHandler creates a new instance of Handler. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewHandler(t interface {
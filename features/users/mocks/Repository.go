@@ -3,9 +3,12 @@
 package mocks
 
 import (
-	users "wanderer/features/users"
+	context "context"
+	time "time"
 
 	mock "github.com/stretchr/testify/mock"
+
+	users "wanderer/features/users"
 )
 
 // Repository is an autogenerated mock type for the Repository type
@@ -13,6 +16,34 @@ type Repository struct {
 	mock.Mock
 }
 
+// CreateRefreshToken provides a mock function with given fields: ctx, data
+func (_m *Repository) CreateRefreshToken(ctx context.Context, data users.RefreshToken) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.RefreshToken) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CreateUserToken provides a mock function with given fields: ctx, data
+func (_m *Repository) CreateUserToken(ctx context.Context, data users.UserToken) error {
+	ret := _m.Called(ctx, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.UserToken) error); ok {
+		r0 = rf(ctx, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Delete provides a mock function with given fields: id
 func (_m *Repository) Delete(id uint) error {
 	ret := _m.Called(id)
@@ -53,6 +84,80 @@ func (_m *Repository) Detail(id uint) (*users.User, error) {
 	return r0, r1
 }
 
+// GetRefreshToken provides a mock function with given fields: ctx, hash
+func (_m *Repository) GetRefreshToken(ctx context.Context, hash string) (*users.RefreshToken, error) {
+	ret := _m.Called(ctx, hash)
+
+	var r0 *users.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*users.RefreshToken, error)); ok {
+		return rf(ctx, hash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *users.RefreshToken); ok {
+		r0 = rf(ctx, hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetRole provides a mock function with given fields: ctx, id
+func (_m *Repository) GetRole(ctx context.Context, id uint) (string, error) {
+	ret := _m.Called(ctx, id)
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, uint) (string, error)); ok {
+		return rf(ctx, id)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, uint) string); ok {
+		r0 = rf(ctx, id)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, uint) error); ok {
+		r1 = rf(ctx, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// IsTokenRevoked provides a mock function with given fields: ctx, tokenId
+func (_m *Repository) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	ret := _m.Called(ctx, tokenId)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, tokenId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, tokenId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Login provides a mock function with given fields: email
 func (_m *Repository) Login(email string) (*users.User, error) {
 	ret := _m.Called(email)
@@ -93,6 +198,62 @@ func (_m *Repository) Register(newUser users.User) error {
 	return r0
 }
 
+// ResetPassword provides a mock function with given fields: ctx, token, password
+func (_m *Repository) ResetPassword(ctx context.Context, token users.UserToken, password string) error {
+	ret := _m.Called(ctx, token, password)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.UserToken, string) error); ok {
+		r0 = rf(ctx, token, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeRefreshFamily provides a mock function with given fields: ctx, family
+func (_m *Repository) RevokeRefreshFamily(ctx context.Context, family string) error {
+	ret := _m.Called(ctx, family)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, family)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RevokeToken provides a mock function with given fields: ctx, tokenId, expiresAt
+func (_m *Repository) RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, tokenId, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tokenId, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// RotateRefreshToken provides a mock function with given fields: ctx, old, data
+func (_m *Repository) RotateRefreshToken(ctx context.Context, old users.RefreshToken, data users.RefreshToken) error {
+	ret := _m.Called(ctx, old, data)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.RefreshToken, users.RefreshToken) error); ok {
+		r0 = rf(ctx, old, data)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: id, updateUser
 func (_m *Repository) Update(id uint, updateUser users.User) error {
 	ret := _m.Called(id, updateUser)
@@ -107,6 +268,20 @@ func (_m *Repository) Update(id uint, updateUser users.User) error {
 	return r0
 }
 
+// VerifyEmail provides a mock function with given fields: ctx, token
+func (_m *Repository) VerifyEmail(ctx context.Context, token users.UserToken) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.UserToken) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewRepository creates a new instance of Repository. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewRepository(t interface {
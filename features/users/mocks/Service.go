@@ -3,9 +3,12 @@
 package mocks
 
 import (
-	users "wanderer/features/users"
+	context "context"
+	tokens "wanderer/helpers/tokens"
 
 	mock "github.com/stretchr/testify/mock"
+
+	users "wanderer/features/users"
 )
 
 // Service is an autogenerated mock type for the Service type
@@ -53,6 +56,46 @@ func (_m *Service) Detail(id uint) (*users.User, error) {
 	return r0, r1
 }
 
+// ForgotPassword provides a mock function with given fields: ctx, email
+func (_m *Service) ForgotPassword(ctx context.Context, email string) error {
+	ret := _m.Called(ctx, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// IssueToken provides a mock function with given fields: ctx, user
+func (_m *Service) IssueToken(ctx context.Context, user users.User) (*users.Token, error) {
+	ret := _m.Called(ctx, user)
+
+	var r0 *users.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, users.User) (*users.Token, error)); ok {
+		return rf(ctx, user)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, users.User) *users.Token); ok {
+		r0 = rf(ctx, user)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, users.User) error); ok {
+		r1 = rf(ctx, user)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Login provides a mock function with given fields: email, password
 func (_m *Service) Login(email string, password string) (*users.User, error) {
 	ret := _m.Called(email, password)
@@ -79,6 +122,46 @@ func (_m *Service) Login(email string, password string) (*users.User, error) {
 	return r0, r1
 }
 
+// Logout provides a mock function with given fields: ctx, claims, refreshToken
+func (_m *Service) Logout(ctx context.Context, claims tokens.Claims, refreshToken string) error {
+	ret := _m.Called(ctx, claims, refreshToken)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, tokens.Claims, string) error); ok {
+		r0 = rf(ctx, claims, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Refresh provides a mock function with given fields: ctx, refreshToken
+func (_m *Service) Refresh(ctx context.Context, refreshToken string) (*users.Token, error) {
+	ret := _m.Called(ctx, refreshToken)
+
+	var r0 *users.Token
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (*users.Token, error)); ok {
+		return rf(ctx, refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) *users.Token); ok {
+		r0 = rf(ctx, refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*users.Token)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, refreshToken)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Register provides a mock function with given fields: newUser
 func (_m *Service) Register(newUser users.User) error {
 	ret := _m.Called(newUser)
@@ -93,6 +176,34 @@ func (_m *Service) Register(newUser users.User) error {
 	return r0
 }
 
+// ResetPassword provides a mock function with given fields: ctx, token, password
+func (_m *Service) ResetPassword(ctx context.Context, token string, password string) error {
+	ret := _m.Called(ctx, token, password)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, string) error); ok {
+		r0 = rf(ctx, token, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SendVerification provides a mock function with given fields: ctx, email
+func (_m *Service) SendVerification(ctx context.Context, email string) error {
+	ret := _m.Called(ctx, email)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, email)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // Update provides a mock function with given fields: id, updateUser
 func (_m *Service) Update(id uint, updateUser users.User) error {
 	ret := _m.Called(id, updateUser)
@@ -107,6 +218,20 @@ func (_m *Service) Update(id uint, updateUser users.User) error {
 	return r0
 }
 
+// VerifyEmail provides a mock function with given fields: ctx, token
+func (_m *Service) VerifyEmail(ctx context.Context, token string) error {
+	ret := _m.Called(ctx, token)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // NewService creates a new instance of Service. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
 // The first argument is typically a *testing.T value.
 func NewService(t interface {
@@ -17,6 +17,8 @@ type User struct {
 	Image    string `gorm:"column:image; type:text; default:null;"`
 	Role     string `gorm:"column:role; type:enum('admin', 'user');"`
 
+	EmailVerifiedAt *time.Time `gorm:"column:email_verified_at;"`
+
 	TourCount   int       `gorm:"-"`
 	ReviewCount int       `gorm:"-"`
 	Bookings    []Booking `gorm:"-"`
@@ -87,6 +89,10 @@ func (mod *User) ToEntity() *users.User {
 		ent.Role = mod.Role
 	}
 
+	if mod.EmailVerifiedAt != nil {
+		ent.EmailVerifiedAt = *mod.EmailVerifiedAt
+	}
+
 	if mod.ReviewCount != 0 {
 		ent.ReviewCount = mod.ReviewCount
 	}
@@ -115,7 +121,7 @@ func (mod *User) ToEntity() *users.User {
 }
 
 type Booking struct {
-	Code   int
+	Code   string
 	Status string
 
 	DetailCount int
@@ -129,7 +135,7 @@ type Booking struct {
 func (mod *Booking) ToEntity() *users.Booking {
 	var ent = new(users.Booking)
 
-	if mod.Code != 0 {
+	if mod.Code != "" {
 		ent.Code = mod.Code
 	}
 
@@ -168,6 +174,67 @@ func (mod *Tour) ToEntity() *users.Tour {
 }
 
 type Review struct {
-	Id     uint
-	UserId uint
+	Id        uint
+	UserId    uint
+	DeletedAt gorm.DeletedAt
+}
+
+type RefreshToken struct {
+	Id        uint       `gorm:"column:id; primaryKey;"`
+	UserId    uint       `gorm:"column:user_id; index;"`
+	Hash      string     `gorm:"column:hash; type:varchar(64); uniqueIndex;"`
+	Family    string     `gorm:"column:family; type:varchar(64); index;"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;"`
+	RevokedAt *time.Time `gorm:"column:revoked_at;"`
+
+	CreatedAt time.Time
+}
+
+func (mod *RefreshToken) FromEntity(ent users.RefreshToken) {
+	mod.UserId = ent.UserId
+	mod.Hash = ent.Hash
+	mod.Family = ent.Family
+	mod.ExpiresAt = ent.ExpiresAt
+
+	if !ent.RevokedAt.IsZero() {
+		mod.RevokedAt = &ent.RevokedAt
+	}
+}
+
+func (mod *RefreshToken) ToEntity() *users.RefreshToken {
+	var ent = new(users.RefreshToken)
+
+	ent.Id = mod.Id
+	ent.UserId = mod.UserId
+	ent.Hash = mod.Hash
+	ent.Family = mod.Family
+	ent.ExpiresAt = mod.ExpiresAt
+
+	if mod.RevokedAt != nil {
+		ent.RevokedAt = *mod.RevokedAt
+	}
+
+	return ent
+}
+
+type RevokedToken struct {
+	TokenId   string    `gorm:"column:token_id; type:varchar(64); primaryKey;"`
+	ExpiresAt time.Time `gorm:"column:expires_at; index;"`
+}
+
+type UserToken struct {
+	TokenId   string     `gorm:"column:token_id; type:varchar(64); primaryKey;"`
+	UserId    uint       `gorm:"column:user_id; index;"`
+	Purpose   string     `gorm:"column:purpose; type:varchar(20);"`
+	ExpiresAt time.Time  `gorm:"column:expires_at;"`
+	UsedAt    *time.Time `gorm:"column:used_at;"`
+
+	CreatedAt time.Time
+}
+
+func (mod *UserToken) FromEntity(ent users.UserToken) {
+	mod.TokenId = ent.TokenId
+	mod.UserId = ent.UserId
+	mod.Purpose = ent.Purpose
+	mod.ExpiresAt = ent.ExpiresAt
 }
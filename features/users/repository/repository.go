@@ -3,10 +3,12 @@ package repository
 import (
 	"context"
 	"errors"
+	"time"
 	"wanderer/features/users"
 	"wanderer/utils/files"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 func NewUserRepository(mysqlDB *gorm.DB, cloud files.Cloud) users.Repository {
@@ -55,11 +57,16 @@ func (repo *userRepository) Update(id uint, updateUser users.User) error {
 	var model = new(User)
 	model.FromEntity(updateUser)
 
-	if err := repo.mysqlDB.Where(&User{Id: id}).Updates(model).Error; err != nil {
-		return err
-	}
+	return repo.mysqlDB.Transaction(func(tx *gorm.DB) error {
+		// A new email address has to be verified again.
+		if updateUser.Email != "" {
+			if err := tx.Model(&User{}).Where("id = ? AND email <> ?", id, updateUser.Email).Update("email_verified_at", nil).Error; err != nil {
+				return err
+			}
+		}
 
-	return nil
+		return tx.Where(&User{Id: id}).Updates(model).Error
+	})
 }
 
 func (repo *userRepository) Delete(id uint) error {
@@ -104,3 +111,137 @@ func (repo *userRepository) Detail(id uint) (*users.User, error) {
 
 	return modUser.ToEntity(), nil
 }
+
+func (repo *userRepository) GetRole(ctx context.Context, id uint) (string, error) {
+	var model = new(User)
+	if err := repo.mysqlDB.WithContext(ctx).Select("id", "role").Where(&User{Id: id}).First(model).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", errors.New("not found: user not found")
+		}
+
+		return "", err
+	}
+
+	return model.Role, nil
+}
+
+func (repo *userRepository) CreateRefreshToken(ctx context.Context, data users.RefreshToken) error {
+	var mod = new(RefreshToken)
+	mod.FromEntity(data)
+
+	return repo.mysqlDB.WithContext(ctx).Create(mod).Error
+}
+
+func (repo *userRepository) GetRefreshToken(ctx context.Context, hash string) (*users.RefreshToken, error) {
+	var mod = new(RefreshToken)
+	if err := repo.mysqlDB.WithContext(ctx).Where("hash = ?", hash).First(mod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found: refresh token not found")
+		}
+
+		return nil, err
+	}
+
+	return mod.ToEntity(), nil
+}
+
+// RotateRefreshToken revokes old and stores data in its place. Only one of two
+// concurrent rotations of the same token succeeds.
+func (repo *userRepository) RotateRefreshToken(ctx context.Context, old users.RefreshToken, data users.RefreshToken) error {
+	var mod = new(RefreshToken)
+	mod.FromEntity(data)
+
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		qry := tx.Model(&RefreshToken{}).Where("id = ? AND revoked_at IS NULL", old.Id).Update("revoked_at", time.Now())
+		if qry.Error != nil {
+			return qry.Error
+		}
+
+		if qry.RowsAffected == 0 {
+			return errors.New("unauthorized: refresh token has been used")
+		}
+
+		return tx.Create(mod).Error
+	})
+}
+
+func (repo *userRepository) RevokeRefreshFamily(ctx context.Context, family string) error {
+	return repo.mysqlDB.WithContext(ctx).Model(&RefreshToken{}).Where("family = ? AND revoked_at IS NULL", family).Update("revoked_at", time.Now()).Error
+}
+
+func (repo *userRepository) RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	db := repo.mysqlDB.WithContext(ctx)
+
+	// Revoked tokens are only kept until they would have expired anyway.
+	if err := db.Where("expires_at < ?", time.Now()).Delete(&RevokedToken{}).Error; err != nil {
+		return err
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&RevokedToken{TokenId: tokenId, ExpiresAt: expiresAt}).Error
+}
+
+func (repo *userRepository) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	var total int64
+	if err := repo.mysqlDB.WithContext(ctx).Model(&RevokedToken{}).Where("token_id = ?", tokenId).Count(&total).Error; err != nil {
+		return false, err
+	}
+
+	return total != 0, nil
+}
+
+func (repo *userRepository) CreateUserToken(ctx context.Context, data users.UserToken) error {
+	var mod = new(UserToken)
+	mod.FromEntity(data)
+
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&UserToken{}).Where("user_id = ? AND purpose = ? AND used_at IS NULL", data.UserId, data.Purpose).Update("used_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(mod).Error
+	})
+}
+
+// ResetPassword uses token to replace the password of its user. Every session
+// of the user is ended along with it.
+func (repo *userRepository) ResetPassword(ctx context.Context, token users.UserToken, password string) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := useToken(tx, token); err != nil {
+			return err
+		}
+
+		if err := tx.Model(&User{}).Where("id = ?", token.UserId).Update("password", password).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&RefreshToken{}).Where("user_id = ? AND revoked_at IS NULL", token.UserId).Update("revoked_at", time.Now()).Error
+	})
+}
+
+func (repo *userRepository) VerifyEmail(ctx context.Context, token users.UserToken) error {
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := useToken(tx, token); err != nil {
+			return err
+		}
+
+		return tx.Model(&User{}).Where("id = ? AND email_verified_at IS NULL", token.UserId).Update("email_verified_at", time.Now()).Error
+	})
+}
+
+// useToken marks token as used. Only one of two concurrent uses of the same
+// token succeeds.
+func useToken(tx *gorm.DB, token users.UserToken) error {
+	qry := tx.Model(&UserToken{}).
+		Where("token_id = ? AND user_id = ? AND purpose = ?", token.TokenId, token.UserId, token.Purpose).
+		Where("used_at IS NULL AND expires_at > ?", time.Now()).
+		Update("used_at", time.Now())
+	if qry.Error != nil {
+		return qry.Error
+	}
+
+	if qry.RowsAffected == 0 {
+		return errors.New("unprocessable: token has been used or expired")
+	}
+
+	return nil
+}
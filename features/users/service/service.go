@@ -1,21 +1,41 @@
 package service
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+	"wanderer/config"
 	"wanderer/features/users"
 	"wanderer/helpers/encrypt"
+	"wanderer/helpers/tokens"
+	"wanderer/utils/mail"
 )
 
-func NewUserService(repo users.Repository, enc encrypt.BcryptHash) users.Service {
+func NewUserService(repo users.Repository, enc encrypt.BcryptHash, jwt tokens.JWT, mailer mail.Mailer, account config.Account) users.Service {
 	return &userService{
-		repo: repo,
-		enc:  enc,
+		repo:    repo,
+		enc:     enc,
+		jwt:     jwt,
+		mailer:  mailer,
+		account: account,
 	}
 }
 
+// verificationTimeout bounds sending the verification email of a new
+// account.
+var verificationTimeout = 30 * time.Second
+
 type userService struct {
-	repo users.Repository
-	enc  encrypt.BcryptHash
+	repo    users.Repository
+	enc     encrypt.BcryptHash
+	jwt     tokens.JWT
+	mailer  mail.Mailer
+	account config.Account
 }
 
 func (srv *userService) Register(newUser users.User) error {
@@ -44,6 +64,18 @@ func (srv *userService) Register(newUser users.User) error {
 		return err
 	}
 
+	// The account exists at this point, so the verification email goes out
+	// in the background instead of holding up the response. One that fails to
+	// go out can still be requested again.
+	go func(email string) {
+		ctx, cancel := context.WithTimeout(context.Background(), verificationTimeout)
+		defer cancel()
+
+		if err := srv.SendVerification(ctx, email); err != nil {
+			log.Println("send verification:", err)
+		}
+	}(newUser.Email)
+
 	return nil
 }
 
@@ -65,6 +97,10 @@ func (srv *userService) Login(email string, password string) (*users.User, error
 		return nil, errors.New("validate: wrong password")
 	}
 
+	if srv.account.RequireVerifiedEmail && result.EmailVerifiedAt.IsZero() {
+		return nil, errors.New("unprocessable: email has not been verified")
+	}
+
 	return result, nil
 }
 
@@ -113,3 +149,242 @@ func (srv *userService) Detail(id uint) (*users.User, error) {
 
 	return result, nil
 }
+
+func (srv *userService) IssueToken(ctx context.Context, user users.User) (*users.Token, error) {
+	if user.Id == 0 {
+		return nil, errors.New("validate: invalid user id")
+	}
+
+	return srv.issueToken(ctx, user.Id, user.Role, nil)
+}
+
+func (srv *userService) Refresh(ctx context.Context, refreshToken string) (*users.Token, error) {
+	if refreshToken == "" {
+		return nil, errors.New("validate: refresh token can't be empty")
+	}
+
+	stored, err := srv.repo.GetRefreshToken(ctx, tokens.HashToken(refreshToken))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found: ") {
+			return nil, errors.New("unauthorized: invalid refresh token")
+		}
+
+		return nil, err
+	}
+
+	// A refresh token is used once. Seeing it again means it may have leaked,
+	// so every token renewed from the same login is revoked.
+	if !stored.RevokedAt.IsZero() {
+		if err := srv.repo.RevokeRefreshFamily(ctx, stored.Family); err != nil {
+			return nil, err
+		}
+
+		return nil, errors.New("unauthorized: refresh token has been used")
+	}
+
+	if stored.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("unauthorized: refresh token has expired")
+	}
+
+	role, err := srv.repo.GetRole(ctx, stored.UserId)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found: ") {
+			return nil, errors.New("unauthorized: invalid refresh token")
+		}
+
+		return nil, err
+	}
+
+	return srv.issueToken(ctx, stored.UserId, role, stored)
+}
+
+func (srv *userService) Logout(ctx context.Context, claims tokens.Claims, refreshToken string) error {
+	if claims.ID == "" || claims.ExpiresAt == nil {
+		return errors.New("validate: invalid access token")
+	}
+
+	if err := srv.repo.RevokeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		return err
+	}
+
+	if refreshToken == "" {
+		return nil
+	}
+
+	stored, err := srv.repo.GetRefreshToken(ctx, tokens.HashToken(refreshToken))
+	if err != nil {
+		if strings.Contains(err.Error(), "not found: ") {
+			return nil
+		}
+
+		return err
+	}
+
+	if strconv.FormatUint(uint64(stored.UserId), 10) != claims.Subject {
+		return nil
+	}
+
+	return srv.repo.RevokeRefreshFamily(ctx, stored.Family)
+}
+
+// ForgotPassword emails a link to reset the password. Unknown addresses are
+// not reported, so the endpoint can't be used to find out who has an account.
+func (srv *userService) ForgotPassword(ctx context.Context, email string) error {
+	if email == "" {
+		return errors.New("validate: email can't be empty")
+	}
+
+	user, err := srv.repo.Login(email)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+
+		return err
+	}
+
+	link, err := srv.link(ctx, user.Id, tokens.PurposeResetPassword, srv.account.ResetPasswordTTL, "/reset-password")
+	if err != nil {
+		return err
+	}
+
+	return srv.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Reset your Wanderer password",
+		Body:    fmt.Sprintf("Hi %s,\n\nUse the link below to choose a new password. It expires in %s and can only be used once.\n\n%s\n\nIf you didn't ask for this, you can ignore this email.\n", user.Name, srv.account.ResetPasswordTTL, link),
+	})
+}
+
+func (srv *userService) ResetPassword(ctx context.Context, token string, password string) error {
+	if token == "" {
+		return errors.New("validate: token can't be empty")
+	}
+
+	if password == "" {
+		return errors.New("validate: password can't be empty")
+	}
+
+	userToken, err := srv.parseToken(token, tokens.PurposeResetPassword)
+	if err != nil {
+		return err
+	}
+
+	hash, err := srv.enc.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return srv.repo.ResetPassword(ctx, *userToken, hash)
+}
+
+// SendVerification emails a link to verify the email address. Like
+// ForgotPassword, it doesn't tell whether the address is known or has been
+// verified already.
+func (srv *userService) SendVerification(ctx context.Context, email string) error {
+	if email == "" {
+		return errors.New("validate: email can't be empty")
+	}
+
+	user, err := srv.repo.Login(email)
+	if err != nil {
+		if strings.Contains(err.Error(), "not found") {
+			return nil
+		}
+
+		return err
+	}
+
+	if !user.EmailVerifiedAt.IsZero() {
+		return nil
+	}
+
+	link, err := srv.link(ctx, user.Id, tokens.PurposeVerifyEmail, srv.account.VerifyEmailTTL, "/verify-email")
+	if err != nil {
+		return err
+	}
+
+	return srv.mailer.Send(ctx, mail.Message{
+		To:      user.Email,
+		Subject: "Verify your Wanderer email address",
+		Body:    fmt.Sprintf("Hi %s,\n\nUse the link below to verify your email address. It expires in %s.\n\n%s\n", user.Name, srv.account.VerifyEmailTTL, link),
+	})
+}
+
+func (srv *userService) VerifyEmail(ctx context.Context, token string) error {
+	if token == "" {
+		return errors.New("validate: token can't be empty")
+	}
+
+	userToken, err := srv.parseToken(token, tokens.PurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+
+	return srv.repo.VerifyEmail(ctx, *userToken)
+}
+
+// link issues a token for purpose and returns the link to path carrying it.
+func (srv *userService) link(ctx context.Context, userId uint, purpose string, ttl time.Duration, path string) (string, error) {
+	token, claims, err := srv.jwt.GeneratePurposeToken(userId, purpose, ttl)
+	if err != nil {
+		return "", err
+	}
+
+	if err := srv.repo.CreateUserToken(ctx, users.UserToken{TokenId: claims.ID, UserId: userId, Purpose: purpose, ExpiresAt: claims.ExpiresAt.Time}); err != nil {
+		return "", err
+	}
+
+	return strings.TrimRight(srv.account.LinkBaseUrl, "/") + path + "?token=" + url.QueryEscape(token), nil
+}
+
+func (srv *userService) parseToken(token string, purpose string) (*users.UserToken, error) {
+	claims, err := srv.jwt.ParsePurposeToken(token, purpose)
+	if err != nil {
+		return nil, errors.New("unprocessable: invalid or expired token")
+	}
+
+	userId, err := claims.UserId()
+	if err != nil {
+		return nil, errors.New("unprocessable: invalid or expired token")
+	}
+
+	return &users.UserToken{TokenId: claims.ID, UserId: userId, Purpose: purpose, ExpiresAt: claims.ExpiresAt.Time}, nil
+}
+
+// issueToken signs a new access token and stores a new refresh token, which
+// replaces previous when the tokens are renewed.
+func (srv *userService) issueToken(ctx context.Context, userId uint, role string, previous *users.RefreshToken) (*users.Token, error) {
+	accessToken, claims, err := srv.jwt.GenerateJWT(userId, role)
+	if err != nil {
+		return nil, err
+	}
+
+	refresh, err := srv.jwt.GenerateRefreshToken()
+	if err != nil {
+		return nil, err
+	}
+
+	var data = users.RefreshToken{
+		UserId:    userId,
+		Hash:      refresh.Hash,
+		Family:    refresh.Hash,
+		ExpiresAt: refresh.ExpiresAt,
+	}
+
+	if previous == nil {
+		err = srv.repo.CreateRefreshToken(ctx, data)
+	} else {
+		data.Family = previous.Family
+		err = srv.repo.RotateRefreshToken(ctx, *previous, data)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &users.Token{
+		AccessToken:     accessToken,
+		AccessExpiresAt: claims.ExpiresAt.Time,
+		RefreshToken:    refresh.Token,
+	}, nil
+}
@@ -1,20 +1,30 @@
 package service_test
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
+	"wanderer/config"
 	"wanderer/features/users"
 	"wanderer/features/users/mocks"
 	"wanderer/features/users/service"
 	encMock "wanderer/helpers/encrypt/mocks"
+	"wanderer/helpers/tokens"
+	tokenMock "wanderer/helpers/tokens/mocks"
+	"wanderer/utils/mail"
 
+	gojwt "github.com/golang-jwt/jwt/v5"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 )
 
 func TestUserServiceRegister(t *testing.T) {
 	var repo = mocks.NewRepository(t)
 	var enc = encMock.NewBcryptHash(t)
-	var srv = service.NewUserService(repo, enc)
+	var jwt = tokenMock.NewJWT(t)
+	var mailer = mail.NewMemory()
+	var srv = service.NewUserService(repo, enc, jwt, mailer, config.Account{LinkBaseUrl: "https://wanderer.test"})
 
 	t.Run("invalid name", func(t *testing.T) {
 		var caseData = users.User{
@@ -123,21 +133,34 @@ func TestUserServiceRegister(t *testing.T) {
 
 		caseData.Password = "secret"
 		repo.On("Register", caseData).Return(nil).Once()
+		repo.On("Login", caseData.Email).Return(&users.User{Id: 1, Name: "Galih", Email: caseData.Email}, nil).Once()
+		jwt.On("GeneratePurposeToken", uint(1), tokens.PurposeVerifyEmail, time.Duration(0)).Return("verify", &tokens.Claims{Purpose: tokens.PurposeVerifyEmail, RegisteredClaims: gojwt.RegisteredClaims{ID: "token-id", ExpiresAt: gojwt.NewNumericDate(time.Now())}}, nil).Once()
+		repo.On("CreateUserToken", mock.Anything, mock.MatchedBy(func(data users.UserToken) bool {
+			return data.TokenId == "token-id" && data.UserId == 1 && data.Purpose == tokens.PurposeVerifyEmail
+		})).Return(nil).Once()
 
 		caseData.Password = "test"
 		err := srv.Register(caseData)
 
 		assert.NoError(t, err)
 
+		assert.Eventually(t, func() bool { return len(mailer.Messages()) == 1 }, time.Second, 10*time.Millisecond)
+		messages := mailer.Messages()
+		assert.Len(t, messages, 1)
+		assert.Equal(t, caseData.Email, messages[0].To)
+		assert.Contains(t, messages[0].Body, "https://wanderer.test/verify-email?token=verify")
+
 		enc.AssertExpectations(t)
 		repo.AssertExpectations(t)
+		jwt.AssertExpectations(t)
 	})
 }
 
 func TestUserServiceLogin(t *testing.T) {
 	var repo = mocks.NewRepository(t)
 	var enc = encMock.NewBcryptHash(t)
-	var srv = service.NewUserService(repo, enc)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
 
 	t.Run("invalid email", func(t *testing.T) {
 		var caseData = users.User{
@@ -205,6 +228,20 @@ func TestUserServiceLogin(t *testing.T) {
 
 	})
 
+	t.Run("unverified email", func(t *testing.T) {
+		var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{RequireVerifiedEmail: true})
+
+		repo.On("Login", "galih@gmail.com").Return(&users.User{Id: 1, Password: "test"}, nil).Once()
+		enc.On("Compare", "test", "test").Return(nil).Once()
+		res, err := srv.Login("galih@gmail.com", "test")
+
+		assert.ErrorContains(t, err, "unprocessable: email has not been verified")
+		assert.Nil(t, res)
+
+		enc.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
 	t.Run("success", func(t *testing.T) {
 		var caseData = users.User{
 			Email:    "galih@gmail.com",
@@ -235,7 +272,8 @@ func TestUserServiceLogin(t *testing.T) {
 func TestUserServiceUpdate(t *testing.T) {
 	var repo = mocks.NewRepository(t)
 	var enc = encMock.NewBcryptHash(t)
-	var srv = service.NewUserService(repo, enc)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
 
 	t.Run("invalid user id", func(t *testing.T) {
 		caseData := users.User{
@@ -297,7 +335,8 @@ func TestUserServiceUpdate(t *testing.T) {
 func TestUserServiceDelete(t *testing.T) {
 	var repo = mocks.NewRepository(t)
 	var enc = encMock.NewBcryptHash(t)
-	var srv = service.NewUserService(repo, enc)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
 
 	t.Run("invalid user id", func(t *testing.T) {
 		var id = uint(0)
@@ -334,7 +373,8 @@ func TestUserServiceDelete(t *testing.T) {
 func TestUserServiceDetail(t *testing.T) {
 	var repo = mocks.NewRepository(t)
 	var enc = encMock.NewBcryptHash(t)
-	var srv = service.NewUserService(repo, enc)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
 
 	t.Run("invalid user id", func(t *testing.T) {
 		var id = uint(0)
@@ -370,7 +410,7 @@ func TestUserServiceDetail(t *testing.T) {
 			ReviewCount: 2,
 			Bookings: []users.Booking{
 				{
-					Code:        13123112113,
+					Code:        "13123112113",
 					DetailCount: 2,
 					Status:      "Pending",
 					Tour: users.Tour{
@@ -391,3 +431,394 @@ func TestUserServiceDetail(t *testing.T) {
 		repo.AssertExpectations(t)
 	})
 }
+
+func TestUserServiceIssueToken(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
+	var ctx = context.Background()
+
+	var expiresAt = time.Now().Add(time.Hour)
+	var claims = &tokens.Claims{RegisteredClaims: gojwt.RegisteredClaims{ExpiresAt: gojwt.NewNumericDate(expiresAt)}}
+	var refresh = &tokens.RefreshToken{Token: "refresh", Hash: "hash", ExpiresAt: expiresAt}
+
+	t.Run("invalid user id", func(t *testing.T) {
+		result, err := srv.IssueToken(ctx, users.User{})
+
+		assert.ErrorContains(t, err, "validate: ")
+		assert.Nil(t, result)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		jwt.On("GenerateJWT", uint(1), "user").Return("access", claims, nil).Once()
+		jwt.On("GenerateRefreshToken").Return(refresh, nil).Once()
+		repo.On("CreateRefreshToken", ctx, users.RefreshToken{UserId: 1, Hash: "hash", Family: "hash", ExpiresAt: expiresAt}).Return(errors.New("some error from repository")).Once()
+
+		result, err := srv.IssueToken(ctx, users.User{Id: 1, Role: "user"})
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		jwt.On("GenerateJWT", uint(1), "user").Return("access", claims, nil).Once()
+		jwt.On("GenerateRefreshToken").Return(refresh, nil).Once()
+		repo.On("CreateRefreshToken", ctx, users.RefreshToken{UserId: 1, Hash: "hash", Family: "hash", ExpiresAt: expiresAt}).Return(nil).Once()
+
+		result, err := srv.IssueToken(ctx, users.User{Id: 1, Role: "user"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, &users.Token{AccessToken: "access", AccessExpiresAt: claims.ExpiresAt.Time, RefreshToken: "refresh"}, result)
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserServiceRefresh(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
+	var ctx = context.Background()
+
+	var hash = tokens.HashToken("refresh")
+	var expiresAt = time.Now().Add(time.Hour)
+	var claims = &tokens.Claims{RegisteredClaims: gojwt.RegisteredClaims{ExpiresAt: gojwt.NewNumericDate(expiresAt)}}
+	var stored = func() *users.RefreshToken {
+		return &users.RefreshToken{Id: 7, UserId: 1, Hash: hash, Family: "family", ExpiresAt: expiresAt}
+	}
+
+	t.Run("empty refresh token", func(t *testing.T) {
+		result, err := srv.Refresh(ctx, "")
+
+		assert.ErrorContains(t, err, "validate: ")
+		assert.Nil(t, result)
+	})
+
+	t.Run("unknown refresh token", func(t *testing.T) {
+		repo.On("GetRefreshToken", ctx, hash).Return(nil, errors.New("not found: refresh token not found")).Once()
+
+		result, err := srv.Refresh(ctx, "refresh")
+
+		assert.ErrorContains(t, err, "unauthorized: invalid refresh token")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("reused refresh token revokes the family", func(t *testing.T) {
+		caseData := stored()
+		caseData.RevokedAt = time.Now()
+
+		repo.On("GetRefreshToken", ctx, hash).Return(caseData, nil).Once()
+		repo.On("RevokeRefreshFamily", ctx, "family").Return(nil).Once()
+
+		result, err := srv.Refresh(ctx, "refresh")
+
+		assert.ErrorContains(t, err, "unauthorized: refresh token has been used")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("expired refresh token", func(t *testing.T) {
+		caseData := stored()
+		caseData.ExpiresAt = time.Now().Add(-time.Minute)
+
+		repo.On("GetRefreshToken", ctx, hash).Return(caseData, nil).Once()
+
+		result, err := srv.Refresh(ctx, "refresh")
+
+		assert.ErrorContains(t, err, "unauthorized: refresh token has expired")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("deleted user", func(t *testing.T) {
+		repo.On("GetRefreshToken", ctx, hash).Return(stored(), nil).Once()
+		repo.On("GetRole", ctx, uint(1)).Return("", errors.New("not found: user not found")).Once()
+
+		result, err := srv.Refresh(ctx, "refresh")
+
+		assert.ErrorContains(t, err, "unauthorized: ")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("GetRefreshToken", ctx, hash).Return(stored(), nil).Once()
+		repo.On("GetRole", ctx, uint(1)).Return("admin", nil).Once()
+		jwt.On("GenerateJWT", uint(1), "admin").Return("access", claims, nil).Once()
+		jwt.On("GenerateRefreshToken").Return(&tokens.RefreshToken{Token: "next", Hash: "next hash", ExpiresAt: expiresAt}, nil).Once()
+		repo.On("RotateRefreshToken", ctx, *stored(), users.RefreshToken{UserId: 1, Hash: "next hash", Family: "family", ExpiresAt: expiresAt}).Return(nil).Once()
+
+		result, err := srv.Refresh(ctx, "refresh")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "access", result.AccessToken)
+		assert.Equal(t, "next", result.RefreshToken)
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserServiceLogout(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
+	var ctx = context.Background()
+
+	var hash = tokens.HashToken("refresh")
+	var expiresAt = time.Now().Add(time.Hour)
+	var claims = tokens.Claims{RegisteredClaims: gojwt.RegisteredClaims{Subject: "1", ID: "token-id", ExpiresAt: gojwt.NewNumericDate(expiresAt)}}
+
+	t.Run("invalid access token", func(t *testing.T) {
+		err := srv.Logout(ctx, tokens.Claims{}, "")
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("RevokeToken", ctx, "token-id", claims.ExpiresAt.Time).Return(errors.New("some error from repository")).Once()
+
+		err := srv.Logout(ctx, claims, "refresh")
+
+		assert.ErrorContains(t, err, "some error from repository")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("refresh token of another user", func(t *testing.T) {
+		repo.On("RevokeToken", ctx, "token-id", claims.ExpiresAt.Time).Return(nil).Once()
+		repo.On("GetRefreshToken", ctx, hash).Return(&users.RefreshToken{UserId: 2, Family: "family"}, nil).Once()
+
+		err := srv.Logout(ctx, claims, "refresh")
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("RevokeToken", ctx, "token-id", claims.ExpiresAt.Time).Return(nil).Once()
+		repo.On("GetRefreshToken", ctx, hash).Return(&users.RefreshToken{UserId: 1, Family: "family"}, nil).Once()
+		repo.On("RevokeRefreshFamily", ctx, "family").Return(nil).Once()
+
+		err := srv.Logout(ctx, claims, "refresh")
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserServiceForgotPassword(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var mailer = mail.NewMemory()
+	var account = config.Account{ResetPasswordTTL: time.Hour, LinkBaseUrl: "https://wanderer.test/"}
+	var srv = service.NewUserService(repo, enc, jwt, mailer, account)
+	var ctx = context.Background()
+
+	var claims = tokens.Claims{Purpose: tokens.PurposeResetPassword, RegisteredClaims: gojwt.RegisteredClaims{ID: "token-id", ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Hour))}}
+
+	t.Run("invalid email", func(t *testing.T) {
+		err := srv.ForgotPassword(ctx, "")
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("unknown email", func(t *testing.T) {
+		repo.On("Login", "nobody@gmail.com").Return(nil, errors.New("record not found")).Once()
+
+		err := srv.ForgotPassword(ctx, "nobody@gmail.com")
+
+		assert.NoError(t, err)
+		assert.Empty(t, mailer.Messages())
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("error from repository", func(t *testing.T) {
+		repo.On("Login", "galih@gmail.com").Return(&users.User{Id: 1, Email: "galih@gmail.com"}, nil).Once()
+		jwt.On("GeneratePurposeToken", uint(1), tokens.PurposeResetPassword, time.Hour).Return("reset", &claims, nil).Once()
+		repo.On("CreateUserToken", ctx, users.UserToken{TokenId: "token-id", UserId: 1, Purpose: tokens.PurposeResetPassword, ExpiresAt: claims.ExpiresAt.Time}).Return(errors.New("some error from repository")).Once()
+
+		err := srv.ForgotPassword(ctx, "galih@gmail.com")
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Empty(t, mailer.Messages())
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("Login", "galih@gmail.com").Return(&users.User{Id: 1, Name: "Galih", Email: "galih@gmail.com"}, nil).Once()
+		jwt.On("GeneratePurposeToken", uint(1), tokens.PurposeResetPassword, time.Hour).Return("reset", &claims, nil).Once()
+		repo.On("CreateUserToken", ctx, users.UserToken{TokenId: "token-id", UserId: 1, Purpose: tokens.PurposeResetPassword, ExpiresAt: claims.ExpiresAt.Time}).Return(nil).Once()
+
+		err := srv.ForgotPassword(ctx, "galih@gmail.com")
+
+		assert.NoError(t, err)
+
+		messages := mailer.Messages()
+		assert.Len(t, messages, 1)
+		assert.Equal(t, "galih@gmail.com", messages[0].To)
+		assert.Contains(t, messages[0].Body, "https://wanderer.test/reset-password?token=reset")
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserServiceResetPassword(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
+	var ctx = context.Background()
+
+	var claims = tokens.Claims{Purpose: tokens.PurposeResetPassword, RegisteredClaims: gojwt.RegisteredClaims{Subject: "1", ID: "token-id", ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Hour))}}
+	var userToken = users.UserToken{TokenId: "token-id", UserId: 1, Purpose: tokens.PurposeResetPassword, ExpiresAt: claims.ExpiresAt.Time}
+
+	t.Run("invalid token", func(t *testing.T) {
+		err := srv.ResetPassword(ctx, "", "secret")
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("invalid password", func(t *testing.T) {
+		err := srv.ResetPassword(ctx, "reset", "")
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		jwt.On("ParsePurposeToken", "reset", tokens.PurposeResetPassword).Return(nil, errors.New("invalid token")).Once()
+
+		err := srv.ResetPassword(ctx, "reset", "secret")
+
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		jwt.AssertExpectations(t)
+	})
+
+	t.Run("used token", func(t *testing.T) {
+		jwt.On("ParsePurposeToken", "reset", tokens.PurposeResetPassword).Return(&claims, nil).Once()
+		enc.On("Hash", "secret").Return("hash", nil).Once()
+		repo.On("ResetPassword", ctx, userToken, "hash").Return(errors.New("unprocessable: token has been used or expired")).Once()
+
+		err := srv.ResetPassword(ctx, "reset", "secret")
+
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		jwt.AssertExpectations(t)
+		enc.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		jwt.On("ParsePurposeToken", "reset", tokens.PurposeResetPassword).Return(&claims, nil).Once()
+		enc.On("Hash", "secret").Return("hash", nil).Once()
+		repo.On("ResetPassword", ctx, userToken, "hash").Return(nil).Once()
+
+		err := srv.ResetPassword(ctx, "reset", "secret")
+
+		assert.NoError(t, err)
+
+		jwt.AssertExpectations(t)
+		enc.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserServiceSendVerification(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var mailer = mail.NewMemory()
+	var srv = service.NewUserService(repo, enc, jwt, mailer, config.Account{VerifyEmailTTL: time.Hour})
+	var ctx = context.Background()
+
+	t.Run("invalid email", func(t *testing.T) {
+		err := srv.SendVerification(ctx, "")
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("already verified", func(t *testing.T) {
+		repo.On("Login", "galih@gmail.com").Return(&users.User{Id: 1, Email: "galih@gmail.com", EmailVerifiedAt: time.Now()}, nil).Once()
+
+		err := srv.SendVerification(ctx, "galih@gmail.com")
+
+		assert.NoError(t, err)
+		assert.Empty(t, mailer.Messages())
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var claims = tokens.Claims{Purpose: tokens.PurposeVerifyEmail, RegisteredClaims: gojwt.RegisteredClaims{ID: "token-id", ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Hour))}}
+
+		repo.On("Login", "galih@gmail.com").Return(&users.User{Id: 1, Email: "galih@gmail.com"}, nil).Once()
+		jwt.On("GeneratePurposeToken", uint(1), tokens.PurposeVerifyEmail, time.Hour).Return("verify", &claims, nil).Once()
+		repo.On("CreateUserToken", ctx, users.UserToken{TokenId: "token-id", UserId: 1, Purpose: tokens.PurposeVerifyEmail, ExpiresAt: claims.ExpiresAt.Time}).Return(nil).Once()
+
+		err := srv.SendVerification(ctx, "galih@gmail.com")
+
+		assert.NoError(t, err)
+		assert.Len(t, mailer.Messages(), 1)
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestUserServiceVerifyEmail(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var enc = encMock.NewBcryptHash(t)
+	var jwt = tokenMock.NewJWT(t)
+	var srv = service.NewUserService(repo, enc, jwt, mail.NewMemory(), config.Account{})
+	var ctx = context.Background()
+
+	var claims = tokens.Claims{Purpose: tokens.PurposeVerifyEmail, RegisteredClaims: gojwt.RegisteredClaims{Subject: "1", ID: "token-id", ExpiresAt: gojwt.NewNumericDate(time.Now().Add(time.Hour))}}
+
+	t.Run("invalid token", func(t *testing.T) {
+		err := srv.VerifyEmail(ctx, "")
+
+		assert.ErrorContains(t, err, "validate: ")
+	})
+
+	t.Run("token of another purpose", func(t *testing.T) {
+		jwt.On("ParsePurposeToken", "reset", tokens.PurposeVerifyEmail).Return(nil, errors.New("invalid token")).Once()
+
+		err := srv.VerifyEmail(ctx, "reset")
+
+		assert.ErrorContains(t, err, "unprocessable: ")
+
+		jwt.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		jwt.On("ParsePurposeToken", "verify", tokens.PurposeVerifyEmail).Return(&claims, nil).Once()
+		repo.On("VerifyEmail", ctx, users.UserToken{TokenId: "token-id", UserId: 1, Purpose: tokens.PurposeVerifyEmail, ExpiresAt: claims.ExpiresAt.Time}).Return(nil).Once()
+
+		err := srv.VerifyEmail(ctx, "verify")
+
+		assert.NoError(t, err)
+
+		jwt.AssertExpectations(t)
+		repo.AssertExpectations(t)
+	})
+}
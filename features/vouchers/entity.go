@@ -0,0 +1,112 @@
+package vouchers
+
+import (
+	"context"
+	"time"
+	"wanderer/helpers/filters"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	TypePercentage = "percentage"
+	TypeFixed      = "fixed"
+)
+
+// A voucher code is at most MaxCodeLength letters, digits, dashes or
+// underscores, stored upper cased.
+const MaxCodeLength = 32
+
+// Voucher is a promo code taking money off a booking, either a percentage of
+// its price, capped at MaxDiscount, or a fixed amount. It can be redeemed
+// from StartAt until EndAt on bookings spending at least MinSpend, Quota times
+// in all and UserLimit times per user. It applies to the tours in TourIds and
+// to every tour of the locations in LocationIds, or to any tour when both are
+// empty. A voucher that isn't Stackable can't be used on a tour that already
+// has a discount of its own. A zero MaxDiscount, Quota, UserLimit or EndAt
+// means no limit.
+//
+// Used counts the bookings that redeemed the voucher, leaving the canceled
+// ones out.
+type Voucher struct {
+	Id          uint
+	Code        string
+	Description string
+	Type        string
+	Value       float64
+	MaxDiscount float64
+	MinSpend    float64
+	Quota       int
+	UserLimit   int
+	Stackable   bool
+	StartAt     time.Time
+	EndAt       time.Time
+	TourIds     []uint
+	LocationIds []uint
+
+	Used int
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Redemption is a booking that redeemed a voucher.
+type Redemption struct {
+	BookingCode string
+	Status      string
+	Discount    float64
+	Total       float64
+	BookedAt    time.Time
+
+	User User
+	Tour Tour
+}
+
+// Summary sums up the redemptions of a voucher. Redemptions counts the
+// bookings that aren't canceled, while Approved, Discount and Revenue only
+// count the paid ones.
+type Summary struct {
+	Redemptions int
+	Approved    int
+	Discount    float64
+	Revenue     float64
+}
+
+type User struct {
+	Id    uint
+	Name  string
+	Email string
+}
+
+type Tour struct {
+	Id    uint
+	Title string
+}
+
+type Handler interface {
+	Create() echo.HandlerFunc
+	GetAll() echo.HandlerFunc
+	GetDetail() echo.HandlerFunc
+	Update() echo.HandlerFunc
+	Delete() echo.HandlerFunc
+	GetRedemptions() echo.HandlerFunc
+}
+
+type Service interface {
+	Create(ctx context.Context, data Voucher) (*Voucher, error)
+	GetAll(ctx context.Context, flt filters.Filter) ([]Voucher, int, error)
+	GetDetail(ctx context.Context, id uint) (*Voucher, error)
+	Update(ctx context.Context, id uint, data Voucher) error
+	Delete(ctx context.Context, id uint) error
+	GetRedemptions(ctx context.Context, id uint, flt filters.Filter) ([]Redemption, *Summary, int, error)
+}
+
+type Repository interface {
+	Create(ctx context.Context, data Voucher) (*Voucher, error)
+	GetAll(ctx context.Context, flt filters.Filter) ([]Voucher, int, error)
+	GetDetail(ctx context.Context, id uint) (*Voucher, error)
+	Update(ctx context.Context, id uint, data Voucher) error
+	Delete(ctx context.Context, id uint) error
+	GetRedemptions(ctx context.Context, id uint, flt filters.Filter) ([]Redemption, int, error)
+	GetSummary(ctx context.Context, id uint) (*Summary, error)
+}
@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"wanderer/features/vouchers"
+	"wanderer/helpers/filters"
+	"wanderer/helpers/paginate"
+
+	"github.com/labstack/echo/v4"
+)
+
+func NewVoucherHandler(voucherService vouchers.Service) vouchers.Handler {
+	return &voucherHandler{
+		voucherService: voucherService,
+	}
+}
+
+type voucherHandler struct {
+	voucherService vouchers.Service
+}
+
+func (hdl *voucherHandler) Create() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(VoucherRequest)
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		result, err := hdl.voucherService.Create(c.Request().Context(), request.ToEntity())
+		if err != nil {
+			c.Logger().Error(err)
+
+			return writeError(c, err)
+		}
+
+		var data = new(VoucherResponse)
+		data.FromEntity(*result)
+
+		response["message"] = "create voucher success"
+		response["data"] = data
+		return c.JSON(http.StatusCreated, response)
+	}
+}
+
+func (hdl *voucherHandler) GetAll() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var filter = new(filters.Filter)
+
+		c.Bind(&filter.Search)
+		c.Bind(&filter.Pagination)
+		if filter.Pagination.Start != 0 && filter.Pagination.Limit == 0 {
+			filter.Pagination.Limit = 10
+		}
+
+		result, totalData, err := hdl.voucherService.GetAll(c.Request().Context(), *filter)
+		if err != nil {
+			c.Logger().Error(err)
+
+			return writeError(c, err)
+		}
+
+		var data = make([]VoucherResponse, 0, len(result))
+		for _, voucher := range result {
+			var tmpVoucher = new(VoucherResponse)
+			tmpVoucher.FromEntity(voucher)
+
+			data = append(data, *tmpVoucher)
+		}
+		response["data"] = data
+
+		if pagination := paginate.Links(c, filter.Pagination, totalData); pagination != nil {
+			response["pagination"] = pagination
+		}
+
+		response["message"] = "get all voucher success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *voucherHandler) GetDetail() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			response["message"] = "invalid voucher id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		result, err := hdl.voucherService.GetDetail(c.Request().Context(), uint(id))
+		if err != nil {
+			c.Logger().Error(err)
+
+			return writeError(c, err)
+		}
+
+		var data = new(VoucherResponse)
+		data.FromEntity(*result)
+
+		response["message"] = "get detail voucher success"
+		response["data"] = data
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *voucherHandler) Update() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var request = new(VoucherRequest)
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			response["message"] = "invalid voucher id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := c.Bind(request); err != nil {
+			c.Logger().Error(err)
+
+			response["message"] = "bad request"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.voucherService.Update(c.Request().Context(), uint(id), request.ToEntity()); err != nil {
+			c.Logger().Error(err)
+
+			return writeError(c, err)
+		}
+
+		response["message"] = "update voucher success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func (hdl *voucherHandler) Delete() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			response["message"] = "invalid voucher id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		if err := hdl.voucherService.Delete(c.Request().Context(), uint(id)); err != nil {
+			c.Logger().Error(err)
+
+			return writeError(c, err)
+		}
+
+		response["message"] = "delete voucher success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+// GetRedemptions reports the bookings that redeemed the voucher in the path,
+// with a summary of what it cost and brought in.
+func (hdl *voucherHandler) GetRedemptions() echo.HandlerFunc {
+	return func(c echo.Context) error {
+		var response = make(map[string]any)
+		var filter = new(filters.Filter)
+
+		id, err := strconv.Atoi(c.Param("id"))
+		if err != nil || id <= 0 {
+			response["message"] = "invalid voucher id"
+			return c.JSON(http.StatusBadRequest, response)
+		}
+
+		c.Bind(&filter.Booking)
+		c.Bind(&filter.Pagination)
+		if filter.Pagination.Start != 0 && filter.Pagination.Limit == 0 {
+			filter.Pagination.Limit = 10
+		}
+
+		result, summary, totalData, err := hdl.voucherService.GetRedemptions(c.Request().Context(), uint(id), *filter)
+		if err != nil {
+			c.Logger().Error(err)
+
+			return writeError(c, err)
+		}
+
+		var data = make([]RedemptionResponse, 0, len(result))
+		for _, redemption := range result {
+			var tmpRedemption = new(RedemptionResponse)
+			tmpRedemption.FromEntity(redemption)
+
+			data = append(data, *tmpRedemption)
+		}
+		response["data"] = data
+
+		var summaryResponse = new(SummaryResponse)
+		summaryResponse.FromEntity(*summary)
+		response["summary"] = summaryResponse
+
+		if pagination := paginate.Links(c, filter.Pagination, totalData); pagination != nil {
+			response["pagination"] = pagination
+		}
+
+		response["message"] = "get voucher redemptions success"
+		return c.JSON(http.StatusOK, response)
+	}
+}
+
+func writeError(c echo.Context, err error) error {
+	var response = make(map[string]any)
+
+	if strings.Contains(err.Error(), "validate: ") {
+		response["message"] = strings.ReplaceAll(err.Error(), "validate: ", "")
+		return c.JSON(http.StatusBadRequest, response)
+	}
+
+	if strings.Contains(err.Error(), "not found: ") {
+		response["message"] = strings.ReplaceAll(err.Error(), "not found: ", "")
+		return c.JSON(http.StatusNotFound, response)
+	}
+
+	if strings.Contains(err.Error(), "used: ") {
+		response["message"] = strings.ReplaceAll(err.Error(), "used: ", "")
+		return c.JSON(http.StatusConflict, response)
+	}
+
+	response["message"] = "internal server error"
+	return c.JSON(http.StatusInternalServerError, response)
+}
@@ -0,0 +1,40 @@
+package handler
+
+import (
+	"time"
+	"wanderer/features/vouchers"
+)
+
+type VoucherRequest struct {
+	Code        string    `json:"code"`
+	Description string    `json:"description"`
+	Type        string    `json:"type"`
+	Value       float64   `json:"value"`
+	MaxDiscount float64   `json:"max_discount"`
+	MinSpend    float64   `json:"min_spend"`
+	Quota       int       `json:"quota"`
+	UserLimit   int       `json:"user_limit"`
+	Stackable   bool      `json:"stackable"`
+	StartAt     time.Time `json:"start_at"`
+	EndAt       time.Time `json:"end_at"`
+	TourIds     []uint    `json:"tour_ids"`
+	LocationIds []uint    `json:"location_ids"`
+}
+
+func (req *VoucherRequest) ToEntity() vouchers.Voucher {
+	return vouchers.Voucher{
+		Code:        req.Code,
+		Description: req.Description,
+		Type:        req.Type,
+		Value:       req.Value,
+		MaxDiscount: req.MaxDiscount,
+		MinSpend:    req.MinSpend,
+		Quota:       req.Quota,
+		UserLimit:   req.UserLimit,
+		Stackable:   req.Stackable,
+		StartAt:     req.StartAt,
+		EndAt:       req.EndAt,
+		TourIds:     req.TourIds,
+		LocationIds: req.LocationIds,
+	}
+}
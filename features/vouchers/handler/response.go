@@ -0,0 +1,102 @@
+package handler
+
+import (
+	"time"
+	"wanderer/features/vouchers"
+)
+
+type VoucherResponse struct {
+	Id          uint       `json:"voucher_id"`
+	Code        string     `json:"code"`
+	Description string     `json:"description,omitempty"`
+	Type        string     `json:"type"`
+	Value       float64    `json:"value"`
+	MaxDiscount float64    `json:"max_discount"`
+	MinSpend    float64    `json:"min_spend"`
+	Quota       int        `json:"quota"`
+	UserLimit   int        `json:"user_limit"`
+	Used        int        `json:"used"`
+	Stackable   bool       `json:"stackable"`
+	StartAt     time.Time  `json:"start_at"`
+	EndAt       *time.Time `json:"end_at"`
+	TourIds     []uint     `json:"tour_ids"`
+	LocationIds []uint     `json:"location_ids"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+func (res *VoucherResponse) FromEntity(ent vouchers.Voucher) {
+	res.Id = ent.Id
+	res.Code = ent.Code
+	res.Description = ent.Description
+	res.Type = ent.Type
+	res.Value = ent.Value
+	res.MaxDiscount = ent.MaxDiscount
+	res.MinSpend = ent.MinSpend
+	res.Quota = ent.Quota
+	res.UserLimit = ent.UserLimit
+	res.Used = ent.Used
+	res.Stackable = ent.Stackable
+	res.StartAt = ent.StartAt
+
+	if !ent.EndAt.IsZero() {
+		res.EndAt = &ent.EndAt
+	}
+
+	res.TourIds = make([]uint, 0, len(ent.TourIds))
+	res.TourIds = append(res.TourIds, ent.TourIds...)
+
+	res.LocationIds = make([]uint, 0, len(ent.LocationIds))
+	res.LocationIds = append(res.LocationIds, ent.LocationIds...)
+
+	res.CreatedAt = ent.CreatedAt
+	res.UpdatedAt = ent.UpdatedAt
+}
+
+type RedemptionResponse struct {
+	BookingCode string    `json:"booking_code"`
+	Status      string    `json:"status"`
+	Discount    float64   `json:"discount"`
+	Total       float64   `json:"total"`
+	BookedAt    time.Time `json:"booked_at"`
+
+	User struct {
+		Id    uint   `json:"user_id"`
+		Name  string `json:"fullname"`
+		Email string `json:"email"`
+	} `json:"user"`
+
+	Tour struct {
+		Id    uint   `json:"tour_id"`
+		Title string `json:"title"`
+	} `json:"tour"`
+}
+
+func (res *RedemptionResponse) FromEntity(ent vouchers.Redemption) {
+	res.BookingCode = ent.BookingCode
+	res.Status = ent.Status
+	res.Discount = ent.Discount
+	res.Total = ent.Total
+	res.BookedAt = ent.BookedAt
+
+	res.User.Id = ent.User.Id
+	res.User.Name = ent.User.Name
+	res.User.Email = ent.User.Email
+
+	res.Tour.Id = ent.Tour.Id
+	res.Tour.Title = ent.Tour.Title
+}
+
+type SummaryResponse struct {
+	Redemptions int     `json:"redemptions"`
+	Approved    int     `json:"approved"`
+	Discount    float64 `json:"discount"`
+	Revenue     float64 `json:"revenue"`
+}
+
+func (res *SummaryResponse) FromEntity(ent vouchers.Summary) {
+	res.Redemptions = ent.Redemptions
+	res.Approved = ent.Approved
+	res.Discount = ent.Discount
+	res.Revenue = ent.Revenue
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"time"
+	"wanderer/features/vouchers"
+
+	"gorm.io/gorm"
+)
+
+type Voucher struct {
+	Id          uint       `gorm:"column:id; primaryKey;"`
+	Code        string     `gorm:"column:code; type:varchar(32); uniqueIndex;"`
+	Description string     `gorm:"column:description; type:text;"`
+	Type        string     `gorm:"column:type; type:enum('percentage', 'fixed');"`
+	Value       float64    `gorm:"column:value; type:decimal(16,2);"`
+	MaxDiscount float64    `gorm:"column:max_discount; type:decimal(16,2); default:0;"`
+	MinSpend    float64    `gorm:"column:min_spend; type:decimal(16,2); default:0;"`
+	Quota       int        `gorm:"column:quota; default:0;"`
+	UserLimit   int        `gorm:"column:user_limit; default:0;"`
+	Stackable   bool       `gorm:"column:stackable; default:false;"`
+	StartAt     time.Time  `gorm:"column:start_at; type:timestamp;"`
+	EndAt       *time.Time `gorm:"column:end_at; type:timestamp NULL;"`
+
+	Tours     []VoucherTour     `gorm:"foreignKey:VoucherId;"`
+	Locations []VoucherLocation `gorm:"foreignKey:VoucherId;"`
+
+	Used int `gorm:"->; -:migration;"`
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+	DeletedAt gorm.DeletedAt `gorm:"index"`
+}
+
+func (mod *Voucher) FromEntity(ent vouchers.Voucher) {
+	mod.Code = ent.Code
+	mod.Description = ent.Description
+	mod.Type = ent.Type
+	mod.Value = ent.Value
+	mod.MaxDiscount = ent.MaxDiscount
+	mod.MinSpend = ent.MinSpend
+	mod.Quota = ent.Quota
+	mod.UserLimit = ent.UserLimit
+	mod.Stackable = ent.Stackable
+	mod.StartAt = ent.StartAt
+
+	if !ent.EndAt.IsZero() {
+		mod.EndAt = &ent.EndAt
+	}
+
+	for _, tourId := range ent.TourIds {
+		mod.Tours = append(mod.Tours, VoucherTour{TourId: tourId})
+	}
+
+	for _, locationId := range ent.LocationIds {
+		mod.Locations = append(mod.Locations, VoucherLocation{LocationId: locationId})
+	}
+}
+
+func (mod *Voucher) ToEntity() *vouchers.Voucher {
+	var ent = new(vouchers.Voucher)
+
+	ent.Id = mod.Id
+	ent.Code = mod.Code
+	ent.Description = mod.Description
+	ent.Type = mod.Type
+	ent.Value = mod.Value
+	ent.MaxDiscount = mod.MaxDiscount
+	ent.MinSpend = mod.MinSpend
+	ent.Quota = mod.Quota
+	ent.UserLimit = mod.UserLimit
+	ent.Stackable = mod.Stackable
+	ent.StartAt = mod.StartAt
+	ent.Used = mod.Used
+
+	if mod.EndAt != nil {
+		ent.EndAt = *mod.EndAt
+	}
+
+	for _, tour := range mod.Tours {
+		ent.TourIds = append(ent.TourIds, tour.TourId)
+	}
+
+	for _, location := range mod.Locations {
+		ent.LocationIds = append(ent.LocationIds, location.LocationId)
+	}
+
+	ent.CreatedAt = mod.CreatedAt
+	ent.UpdatedAt = mod.UpdatedAt
+
+	return ent
+}
+
+// VoucherTour scopes a voucher to a tour.
+type VoucherTour struct {
+	VoucherId uint `gorm:"column:voucher_id; primaryKey;"`
+	TourId    uint `gorm:"column:tour_id; primaryKey;"`
+	Tour      Tour `gorm:"foreignKey:TourId;"`
+}
+
+// VoucherLocation scopes a voucher to every tour of a location.
+type VoucherLocation struct {
+	VoucherId  uint     `gorm:"column:voucher_id; primaryKey;"`
+	LocationId uint     `gorm:"column:location_id; primaryKey;"`
+	Location   Location `gorm:"foreignKey:LocationId;"`
+}
+
+type Tour struct {
+	Id    uint
+	Title string
+}
+
+type Location struct {
+	Id uint
+}
+
+type User struct {
+	Id    uint
+	Name  string `gorm:"column:fullname;"`
+	Email string
+}
+
+type Booking struct {
+	Code            string
+	Status          string
+	Total           float64
+	VoucherId       uint
+	VoucherDiscount float64
+	BookedAt        time.Time
+
+	UserId uint
+	User   User `gorm:"foreignKey:UserId;"`
+
+	TourId uint
+	Tour   Tour `gorm:"foreignKey:TourId;"`
+
+	DeletedAt gorm.DeletedAt
+}
+
+func (mod *Booking) ToEntity() vouchers.Redemption {
+	return vouchers.Redemption{
+		BookingCode: mod.Code,
+		Status:      mod.Status,
+		Discount:    mod.VoucherDiscount,
+		Total:       mod.Total,
+		BookedAt:    mod.BookedAt,
+		User: vouchers.User{
+			Id:    mod.User.Id,
+			Name:  mod.User.Name,
+			Email: mod.User.Email,
+		},
+		Tour: vouchers.Tour{
+			Id:    mod.Tour.Id,
+			Title: mod.Tour.Title,
+		},
+	}
+}
+
+type Summary struct {
+	Redemptions int
+	Approved    int
+	Discount    float64
+	Revenue     float64
+}
+
+func (mod *Summary) ToEntity() *vouchers.Summary {
+	return &vouchers.Summary{
+		Redemptions: mod.Redemptions,
+		Approved:    mod.Approved,
+		Discount:    mod.Discount,
+		Revenue:     mod.Revenue,
+	}
+}
@@ -0,0 +1,239 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"wanderer/features/vouchers"
+	"wanderer/helpers/filters"
+
+	"gorm.io/gorm"
+)
+
+func NewVoucherRepository(mysqlDB *gorm.DB) vouchers.Repository {
+	return &voucherRepository{
+		mysqlDB: mysqlDB,
+	}
+}
+
+type voucherRepository struct {
+	mysqlDB *gorm.DB
+}
+
+func (repo *voucherRepository) Create(ctx context.Context, data vouchers.Voucher) (*vouchers.Voucher, error) {
+	var mod = new(Voucher)
+	mod.FromEntity(data)
+
+	if err := repo.mysqlDB.WithContext(ctx).Create(mod).Error; err != nil {
+		return nil, voucherError(err)
+	}
+
+	return mod.ToEntity(), nil
+}
+
+func (repo *voucherRepository) GetAll(ctx context.Context, flt filters.Filter) ([]vouchers.Voucher, int, error) {
+	var mod []Voucher
+	var totalData int64
+
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Voucher{})
+
+	if flt.Search.Keyword != "" {
+		qry = qry.Where("vouchers.code LIKE ?", "%"+strings.ToUpper(flt.Search.Keyword)+"%")
+	}
+
+	if err := qry.Session(&gorm.Session{}).Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	qry = repo.withUsage(qry).Order("vouchers.id desc")
+
+	if flt.Pagination.Limit != 0 {
+		qry = qry.Limit(flt.Pagination.Limit)
+	}
+
+	if flt.Pagination.Start != 0 {
+		qry = qry.Offset(flt.Pagination.Start)
+	}
+
+	if err := qry.Find(&mod).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var result []vouchers.Voucher
+	for _, voucher := range mod {
+		result = append(result, *voucher.ToEntity())
+	}
+
+	return result, int(totalData), nil
+}
+
+func (repo *voucherRepository) GetDetail(ctx context.Context, id uint) (*vouchers.Voucher, error) {
+	var mod = new(Voucher)
+
+	qry := repo.withUsage(repo.mysqlDB.WithContext(ctx).Model(&Voucher{}))
+	if err := qry.Where("vouchers.id = ?", id).First(mod).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("not found: voucher not found")
+		}
+		return nil, err
+	}
+
+	return mod.ToEntity(), nil
+}
+
+// withUsage selects the vouchers of qry along with their scope and how often
+// each has been redeemed.
+func (repo *voucherRepository) withUsage(qry *gorm.DB) *gorm.DB {
+	used := repo.mysqlDB.Model(&Booking{}).Select("COUNT(*)").Where("bookings.voucher_id = vouchers.id AND bookings.status <> ?", "cancel")
+
+	return qry.Select("vouchers.*, (?) AS used", used).Preload("Tours").Preload("Locations")
+}
+
+// Update replaces the voucher id with data, scope included. Bookings that
+// already redeemed it keep the discount they got.
+func (repo *voucherRepository) Update(ctx context.Context, id uint, data vouchers.Voucher) error {
+	var mod = new(Voucher)
+	mod.FromEntity(data)
+
+	return repo.mysqlDB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		qry := tx.Model(&Voucher{}).Where("id = ?", id).Updates(map[string]any{
+			"code":         mod.Code,
+			"description":  mod.Description,
+			"type":         mod.Type,
+			"value":        mod.Value,
+			"max_discount": mod.MaxDiscount,
+			"min_spend":    mod.MinSpend,
+			"quota":        mod.Quota,
+			"user_limit":   mod.UserLimit,
+			"stackable":    mod.Stackable,
+			"start_at":     mod.StartAt,
+			"end_at":       mod.EndAt,
+		})
+		if err := qry.Error; err != nil {
+			return voucherError(err)
+		}
+
+		if qry.RowsAffected == 0 {
+			var exist int64
+			if err := tx.Model(&Voucher{}).Where("id = ?", id).Count(&exist).Error; err != nil {
+				return err
+			}
+
+			if exist == 0 {
+				return errors.New("not found: voucher not found")
+			}
+		}
+
+		if err := tx.Where("voucher_id = ?", id).Delete(&VoucherTour{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("voucher_id = ?", id).Delete(&VoucherLocation{}).Error; err != nil {
+			return err
+		}
+
+		for i := range mod.Tours {
+			mod.Tours[i].VoucherId = id
+		}
+
+		for i := range mod.Locations {
+			mod.Locations[i].VoucherId = id
+		}
+
+		if len(mod.Tours) != 0 {
+			if err := tx.Create(mod.Tours).Error; err != nil {
+				return voucherError(err)
+			}
+		}
+
+		if len(mod.Locations) != 0 {
+			if err := tx.Create(mod.Locations).Error; err != nil {
+				return voucherError(err)
+			}
+		}
+
+		return nil
+	})
+}
+
+// Delete retires the voucher id. It stays around for the bookings that
+// redeemed it.
+func (repo *voucherRepository) Delete(ctx context.Context, id uint) error {
+	qry := repo.mysqlDB.WithContext(ctx).Where("id = ?", id).Delete(&Voucher{})
+	if err := qry.Error; err != nil {
+		return err
+	}
+
+	if qry.RowsAffected == 0 {
+		return errors.New("not found: voucher not found")
+	}
+
+	return nil
+}
+
+func (repo *voucherRepository) GetRedemptions(ctx context.Context, id uint, flt filters.Filter) ([]vouchers.Redemption, int, error) {
+	var mod []Booking
+	var totalData int64
+
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).Where("bookings.voucher_id = ?", id)
+
+	if flt.Booking.Status != "" {
+		qry = qry.Where("bookings.status = ?", flt.Booking.Status)
+	}
+
+	if err := qry.Session(&gorm.Session{}).Count(&totalData).Error; err != nil {
+		return nil, 0, err
+	}
+
+	qry = qry.Joins("User").Joins("Tour").Order("bookings.booked_at desc")
+
+	if flt.Pagination.Limit != 0 {
+		qry = qry.Limit(flt.Pagination.Limit)
+	}
+
+	if flt.Pagination.Start != 0 {
+		qry = qry.Offset(flt.Pagination.Start)
+	}
+
+	if err := qry.Find(&mod).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var result []vouchers.Redemption
+	for _, booking := range mod {
+		result = append(result, booking.ToEntity())
+	}
+
+	return result, int(totalData), nil
+}
+
+func (repo *voucherRepository) GetSummary(ctx context.Context, id uint) (*vouchers.Summary, error) {
+	var mod = new(Summary)
+
+	qry := repo.mysqlDB.WithContext(ctx).Model(&Booking{}).Select(
+		"COUNT(CASE WHEN bookings.status <> 'cancel' THEN 1 END) AS redemptions",
+		"COUNT(CASE WHEN bookings.status = 'approved' THEN 1 END) AS approved",
+		"COALESCE(SUM(CASE WHEN bookings.status = 'approved' THEN bookings.voucher_discount END), 0) AS discount",
+		"COALESCE(SUM(CASE WHEN bookings.status = 'approved' THEN bookings.total END), 0) AS revenue",
+	).Where("bookings.voucher_id = ?", id)
+
+	if err := qry.Scan(mod).Error; err != nil {
+		return nil, err
+	}
+
+	return mod.ToEntity(), nil
+}
+
+// voucherError turns the constraint violations of writing a voucher into the
+// errors the handlers know.
+func voucherError(err error) error {
+	if strings.Contains(err.Error(), "1062") {
+		return errors.New("used: voucher code already exist")
+	}
+
+	if strings.Contains(err.Error(), "1452") {
+		return errors.New("not found: tour or location not found")
+	}
+
+	return err
+}
@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"wanderer/features/vouchers"
+	"wanderer/helpers/filters"
+)
+
+func NewVoucherService(repo vouchers.Repository) vouchers.Service {
+	return &voucherService{
+		repo: repo,
+	}
+}
+
+type voucherService struct {
+	repo vouchers.Repository
+}
+
+func (srv *voucherService) Create(ctx context.Context, data vouchers.Voucher) (*vouchers.Voucher, error) {
+	if err := normalizeVoucher(&data); err != nil {
+		return nil, err
+	}
+
+	result, err := srv.repo.Create(ctx, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (srv *voucherService) GetAll(ctx context.Context, flt filters.Filter) ([]vouchers.Voucher, int, error) {
+	result, totalData, err := srv.repo.GetAll(ctx, flt)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return result, totalData, nil
+}
+
+func (srv *voucherService) GetDetail(ctx context.Context, id uint) (*vouchers.Voucher, error) {
+	if id == 0 {
+		return nil, errors.New("validate: invalid voucher id")
+	}
+
+	result, err := srv.repo.GetDetail(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (srv *voucherService) Update(ctx context.Context, id uint, data vouchers.Voucher) error {
+	if id == 0 {
+		return errors.New("validate: invalid voucher id")
+	}
+
+	if err := normalizeVoucher(&data); err != nil {
+		return err
+	}
+
+	if err := srv.repo.Update(ctx, id, data); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (srv *voucherService) Delete(ctx context.Context, id uint) error {
+	if id == 0 {
+		return errors.New("validate: invalid voucher id")
+	}
+
+	if err := srv.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// GetRedemptions lists the bookings that redeemed the voucher id, along with
+// a summary of all of them.
+func (srv *voucherService) GetRedemptions(ctx context.Context, id uint, flt filters.Filter) ([]vouchers.Redemption, *vouchers.Summary, int, error) {
+	if id == 0 {
+		return nil, nil, 0, errors.New("validate: invalid voucher id")
+	}
+
+	switch flt.Booking.Status {
+	case "", "pending", "cancel", "approved", "refund", "refunded":
+	default:
+		return nil, nil, 0, errors.New("validate: invalid booking status")
+	}
+
+	if _, err := srv.repo.GetDetail(ctx, id); err != nil {
+		return nil, nil, 0, err
+	}
+
+	result, totalData, err := srv.repo.GetRedemptions(ctx, id, flt)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	summary, err := srv.repo.GetSummary(ctx, id)
+	if err != nil {
+		return nil, nil, 0, err
+	}
+
+	return result, summary, totalData, nil
+}
+
+// normalizeVoucher validates data, upper casing its code and starting it
+// right away when it has no start.
+func normalizeVoucher(data *vouchers.Voucher) error {
+	data.Code = strings.ToUpper(strings.TrimSpace(data.Code))
+	if data.Code == "" {
+		return errors.New("validate: code can't be empty")
+	}
+
+	if len(data.Code) > vouchers.MaxCodeLength {
+		return fmt.Errorf("validate: code can't be longer than %d characters", vouchers.MaxCodeLength)
+	}
+
+	for _, r := range data.Code {
+		if (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return errors.New("validate: code can only hold letters, digits, dashes and underscores")
+		}
+	}
+
+	switch data.Type {
+	case vouchers.TypePercentage:
+		if data.Value <= 0 || data.Value > 100 {
+			return errors.New("validate: percentage must be between 0 and 100")
+		}
+	case vouchers.TypeFixed:
+		if data.Value <= 0 {
+			return errors.New("validate: value must be more than 0")
+		}
+	default:
+		return errors.New("validate: type must be percentage or fixed")
+	}
+
+	if data.MaxDiscount < 0 || data.MinSpend < 0 {
+		return errors.New("validate: max discount and min spend can't be negative")
+	}
+
+	if data.Quota < 0 || data.UserLimit < 0 {
+		return errors.New("validate: usage limits can't be negative")
+	}
+
+	if data.StartAt.IsZero() {
+		data.StartAt = time.Now()
+	}
+
+	if !data.EndAt.IsZero() && !data.EndAt.After(data.StartAt) {
+		return errors.New("validate: end must be after start")
+	}
+
+	return nil
+}
@@ -0,0 +1,361 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+	"wanderer/features/vouchers"
+	"wanderer/features/vouchers/mocks"
+	"wanderer/features/vouchers/service"
+	"wanderer/helpers/filters"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestVoucherServiceCreate(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewVoucherService(repo)
+	var ctx = context.Background()
+
+	t.Run("empty code", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  "  ",
+			Type:  vouchers.TypeFixed,
+			Value: 50000,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "code")
+		assert.Nil(t, result)
+	})
+
+	t.Run("code too long", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  strings.Repeat("A", vouchers.MaxCodeLength+1),
+			Type:  vouchers.TypeFixed,
+			Value: 50000,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "code")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid code", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  "SUMMER SALE",
+			Type:  vouchers.TypeFixed,
+			Value: 50000,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "code")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  "SUMMER",
+			Type:  "cashback",
+			Value: 50000,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "type")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid percentage", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  "SUMMER",
+			Type:  vouchers.TypePercentage,
+			Value: 120,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "percentage")
+		assert.Nil(t, result)
+	})
+
+	t.Run("invalid fixed value", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  "SUMMER",
+			Type:  vouchers.TypeFixed,
+			Value: 0,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "value")
+		assert.Nil(t, result)
+	})
+
+	t.Run("negative limit", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:  "SUMMER",
+			Type:  vouchers.TypeFixed,
+			Value: 50000,
+			Quota: -1,
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "limits")
+		assert.Nil(t, result)
+	})
+
+	t.Run("end before start", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:    "SUMMER",
+			Type:    vouchers.TypeFixed,
+			Value:   50000,
+			StartAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			EndAt:   time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "end")
+		assert.Nil(t, result)
+	})
+
+	t.Run("repository error", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:    "summer",
+			Type:    vouchers.TypeFixed,
+			Value:   50000,
+			StartAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+		}
+
+		repo.On("Create", ctx, mock.AnythingOfType("vouchers.Voucher")).Return(nil, errors.New("used: voucher code already exist")).Once()
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.ErrorContains(t, err, "already exist")
+		assert.Nil(t, result)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var caseData = vouchers.Voucher{
+			Code:    " summer-24 ",
+			Type:    vouchers.TypePercentage,
+			Value:   10,
+			StartAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+			TourIds: []uint{1},
+		}
+
+		var caseResult = caseData
+		caseResult.Id = 1
+		caseResult.Code = "SUMMER-24"
+
+		repo.On("Create", ctx, mock.MatchedBy(func(data vouchers.Voucher) bool {
+			return data.Code == "SUMMER-24"
+		})).Return(&caseResult, nil).Once()
+
+		result, err := srv.Create(ctx, caseData)
+
+		assert.NoError(t, err)
+		assert.Equal(t, &caseResult, result)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestVoucherServiceGetAll(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewVoucherService(repo)
+	var ctx = context.Background()
+
+	t.Run("repository error", func(t *testing.T) {
+		repo.On("GetAll", ctx, filters.Filter{}).Return(nil, 0, errors.New("some error from repository")).Once()
+
+		result, totalData, err := srv.GetAll(ctx, filters.Filter{})
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+		assert.Equal(t, 0, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var caseResult = []vouchers.Voucher{
+			{Id: 1, Code: "SUMMER", Type: vouchers.TypeFixed, Value: 50000},
+		}
+
+		repo.On("GetAll", ctx, filters.Filter{}).Return(caseResult, 1, nil).Once()
+
+		result, totalData, err := srv.GetAll(ctx, filters.Filter{})
+
+		assert.NoError(t, err)
+		assert.Equal(t, caseResult, result)
+		assert.Equal(t, 1, totalData)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestVoucherServiceUpdate(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewVoucherService(repo)
+	var ctx = context.Background()
+
+	var caseData = vouchers.Voucher{
+		Code:    "SUMMER",
+		Type:    vouchers.TypeFixed,
+		Value:   50000,
+		StartAt: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	}
+
+	t.Run("invalid id", func(t *testing.T) {
+		err := srv.Update(ctx, 0, caseData)
+
+		assert.ErrorContains(t, err, "invalid voucher id")
+	})
+
+	t.Run("invalid data", func(t *testing.T) {
+		var invalidData = caseData
+		invalidData.Value = -1
+
+		err := srv.Update(ctx, 1, invalidData)
+
+		assert.ErrorContains(t, err, "value")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo.On("Update", ctx, uint(1), caseData).Return(errors.New("not found: voucher not found")).Once()
+
+		err := srv.Update(ctx, 1, caseData)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("Update", ctx, uint(1), caseData).Return(nil).Once()
+
+		err := srv.Update(ctx, 1, caseData)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestVoucherServiceDelete(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewVoucherService(repo)
+	var ctx = context.Background()
+
+	t.Run("invalid id", func(t *testing.T) {
+		err := srv.Delete(ctx, 0)
+
+		assert.ErrorContains(t, err, "invalid voucher id")
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		repo.On("Delete", ctx, uint(1)).Return(errors.New("not found: voucher not found")).Once()
+
+		err := srv.Delete(ctx, 1)
+
+		assert.ErrorContains(t, err, "not found")
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		repo.On("Delete", ctx, uint(1)).Return(nil).Once()
+
+		err := srv.Delete(ctx, 1)
+
+		assert.NoError(t, err)
+
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestVoucherServiceGetRedemptions(t *testing.T) {
+	var repo = mocks.NewRepository(t)
+	var srv = service.NewVoucherService(repo)
+	var ctx = context.Background()
+
+	t.Run("invalid id", func(t *testing.T) {
+		result, summary, totalData, err := srv.GetRedemptions(ctx, 0, filters.Filter{})
+
+		assert.ErrorContains(t, err, "invalid voucher id")
+		assert.Nil(t, result)
+		assert.Nil(t, summary)
+		assert.Equal(t, 0, totalData)
+	})
+
+	t.Run("invalid status", func(t *testing.T) {
+		var caseFilter = filters.Filter{Booking: filters.Booking{Status: "paid"}}
+
+		result, summary, totalData, err := srv.GetRedemptions(ctx, 1, caseFilter)
+
+		assert.ErrorContains(t, err, "status")
+		assert.Nil(t, result)
+		assert.Nil(t, summary)
+		assert.Equal(t, 0, totalData)
+	})
+
+	t.Run("voucher not found", func(t *testing.T) {
+		repo.On("GetDetail", ctx, uint(1)).Return(nil, errors.New("not found: voucher not found")).Once()
+
+		result, summary, totalData, err := srv.GetRedemptions(ctx, 1, filters.Filter{})
+
+		assert.ErrorContains(t, err, "not found")
+		assert.Nil(t, result)
+		assert.Nil(t, summary)
+		assert.Equal(t, 0, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("summary error", func(t *testing.T) {
+		repo.On("GetDetail", ctx, uint(1)).Return(&vouchers.Voucher{Id: 1}, nil).Once()
+		repo.On("GetRedemptions", ctx, uint(1), filters.Filter{}).Return([]vouchers.Redemption{}, 0, nil).Once()
+		repo.On("GetSummary", ctx, uint(1)).Return(nil, errors.New("some error from repository")).Once()
+
+		result, summary, totalData, err := srv.GetRedemptions(ctx, 1, filters.Filter{})
+
+		assert.ErrorContains(t, err, "some error from repository")
+		assert.Nil(t, result)
+		assert.Nil(t, summary)
+		assert.Equal(t, 0, totalData)
+
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("success", func(t *testing.T) {
+		var caseFilter = filters.Filter{Booking: filters.Booking{Status: "approved"}}
+		var caseResult = []vouchers.Redemption{
+			{BookingCode: "1234567890", Status: "approved", Discount: 50000, Total: 950000},
+		}
+		var caseSummary = &vouchers.Summary{Redemptions: 2, Approved: 1, Discount: 50000, Revenue: 950000}
+
+		repo.On("GetDetail", ctx, uint(1)).Return(&vouchers.Voucher{Id: 1}, nil).Once()
+		repo.On("GetRedemptions", ctx, uint(1), caseFilter).Return(caseResult, 1, nil).Once()
+		repo.On("GetSummary", ctx, uint(1)).Return(caseSummary, nil).Once()
+
+		result, summary, totalData, err := srv.GetRedemptions(ctx, 1, caseFilter)
+
+		assert.NoError(t, err)
+		assert.Equal(t, caseResult, result)
+		assert.Equal(t, caseSummary, summary)
+		assert.Equal(t, 1, totalData)
+
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,150 @@
+package authorization
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"wanderer/helpers/tokens"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	RoleAdmin = "admin"
+	RoleUser  = "user"
+
+	contextUserId   = "auth_user_id"
+	contextUserRole = "auth_user_role"
+)
+
+type Policy string
+
+const (
+	// Public routes are reachable without a token.
+	Public Policy = "public"
+
+	// Optional routes are reachable without a token, but still identify the
+	// caller when a valid one is sent, so the handler can tell admins apart.
+	Optional Policy = "optional"
+
+	// Owner routes need a valid token; the handler scopes the resource to the caller.
+	Owner Policy = "owner"
+
+	// Admin routes need a valid token that belongs to an admin account.
+	Admin Policy = "admin"
+)
+
+type RoleResolver interface {
+	GetRole(ctx context.Context, userId uint) (string, error)
+}
+
+func Authorize(secret string, resolver RoleResolver, revocation tokens.Revocation, policy Policy) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if policy == Public {
+				return next(c)
+			}
+
+			var response = make(map[string]any)
+
+			token, ok := c.Get("user").(*jwt.Token)
+			if !ok || token == nil {
+				if policy == Optional {
+					return next(c)
+				}
+
+				response["message"] = "unauthorized access"
+				return c.JSON(http.StatusUnauthorized, response)
+			}
+
+			userId, err := tokens.ExtractToken(secret, token)
+			if err != nil {
+				c.Logger().Error(err)
+
+				if policy == Optional {
+					return next(c)
+				}
+
+				response["message"] = "unauthorized"
+				return c.JSON(http.StatusUnauthorized, response)
+			}
+
+			claims, _ := tokens.ExtractClaims(token)
+			revoked, err := revocation.IsTokenRevoked(c.Request().Context(), claims.ID)
+			if err != nil {
+				c.Logger().Error(err)
+
+				response["message"] = "internal server error"
+				return c.JSON(http.StatusInternalServerError, response)
+			}
+
+			if revoked {
+				if policy == Optional {
+					return next(c)
+				}
+
+				response["message"] = "unauthorized"
+				return c.JSON(http.StatusUnauthorized, response)
+			}
+
+			role, err := resolver.GetRole(c.Request().Context(), userId)
+			if err != nil {
+				c.Logger().Error(err)
+
+				if strings.Contains(err.Error(), "not found: ") {
+					if policy == Optional {
+						return next(c)
+					}
+
+					response["message"] = "unauthorized"
+					return c.JSON(http.StatusUnauthorized, response)
+				}
+
+				response["message"] = "internal server error"
+				return c.JSON(http.StatusInternalServerError, response)
+			}
+
+			if !Allowed(policy, role) {
+				return Forbidden(c)
+			}
+
+			c.Set(contextUserId, userId)
+			c.Set(contextUserRole, role)
+
+			return next(c)
+		}
+	}
+}
+
+func Allowed(policy Policy, role string) bool {
+	switch policy {
+	case Public, Optional:
+		return true
+	case Owner:
+		return role == RoleAdmin || role == RoleUser
+	case Admin:
+		return role == RoleAdmin
+	default:
+		return false
+	}
+}
+
+func Forbidden(c echo.Context) error {
+	var response = make(map[string]any)
+
+	response["message"] = "forbidden access"
+	return c.JSON(http.StatusForbidden, response)
+}
+
+func Identity(c echo.Context) (uint, string) {
+	userId, _ := c.Get(contextUserId).(uint)
+	role, _ := c.Get(contextUserRole).(string)
+
+	return userId, role
+}
+
+func IsAdmin(c echo.Context) bool {
+	_, role := Identity(c)
+	return role == RoleAdmin
+}
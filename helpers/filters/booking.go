@@ -0,0 +1,13 @@
+package filters
+
+import "time"
+
+type Booking struct {
+	UserId        uint      `query:"user_id"`
+	TourId        uint      `query:"tour_id"`
+	LocationId    uint      `query:"location_id"`
+	Status        string    `query:"status"`
+	PaymentMethod string    `query:"payment_method"`
+	BookedStart   time.Time `query:"booked_start"`
+	BookedEnd     time.Time `query:"booked_end"`
+}
@@ -4,4 +4,7 @@ type Filter struct {
 	Search     Search
 	Pagination Pagination
 	Sort       Sort
+	Booking    Booking
+	Tour       Tour
+	Review     Review
 }
@@ -0,0 +1,9 @@
+package filters
+
+// Review narrows a review listing down. Zero fields don't filter, and Visible
+// leaves the hidden reviews out.
+type Review struct {
+	TourId  uint   `query:"tour_id"`
+	Status  string `query:"status"`
+	Visible bool   `query:"visible"`
+}
@@ -0,0 +1,65 @@
+package filters
+
+import (
+	"errors"
+	"time"
+)
+
+// Tour narrows a tour listing down. Zero fields don't filter. The start,
+// duration and seats conditions have to be met by a single departure of the
+// tour, while the price is the package price.
+type Tour struct {
+	Status   string `query:"status"`
+	Upcoming bool   `query:"upcoming"`
+
+	LocationId uint    `query:"location_id"`
+	AirlineId  uint    `query:"airline_id"`
+	MinPrice   float64 `query:"min_price"`
+	MaxPrice   float64 `query:"max_price"`
+	MinRating  float32 `query:"min_rating"`
+	Facilities []uint  `query:"facility"`
+
+	StartFrom   time.Time `query:"start_from"`
+	StartTo     time.Time `query:"start_to"`
+	MinDuration int       `query:"min_duration"`
+	MaxDuration int       `query:"max_duration"`
+	Seats       int       `query:"seats"`
+}
+
+// Validate reports the first condition of flt that can't match any tour.
+func (flt Tour) Validate() error {
+	if flt.MinPrice < 0 || flt.MaxPrice < 0 {
+		return errors.New("validate: price can't be negative")
+	}
+
+	if flt.MaxPrice != 0 && flt.MinPrice > flt.MaxPrice {
+		return errors.New("validate: min price can't be more than max price")
+	}
+
+	if flt.MinRating < 0 || flt.MinRating > 5 {
+		return errors.New("validate: min rating must be between 0 and 5")
+	}
+
+	if !flt.StartFrom.IsZero() && !flt.StartTo.IsZero() && flt.StartTo.Before(flt.StartFrom) {
+		return errors.New("validate: start to can't be before start from")
+	}
+
+	if flt.MinDuration < 0 || flt.MaxDuration < 0 {
+		return errors.New("validate: duration can't be negative")
+	}
+
+	if flt.MaxDuration != 0 && flt.MinDuration > flt.MaxDuration {
+		return errors.New("validate: min duration can't be more than max duration")
+	}
+
+	if flt.Seats < 0 {
+		return errors.New("validate: seats can't be negative")
+	}
+
+	return nil
+}
+
+// Departure reports whether flt has conditions on the departures of a tour.
+func (flt Tour) Departure() bool {
+	return !flt.StartFrom.IsZero() || !flt.StartTo.IsZero() || flt.MinDuration != 0 || flt.MaxDuration != 0 || flt.Seats != 0
+}
@@ -0,0 +1,48 @@
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTourValidate(t *testing.T) {
+	start := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+
+	var testCases = []struct {
+		name   string
+		filter Tour
+		err    string
+	}{
+		{name: "empty", filter: Tour{}},
+		{name: "complete", filter: Tour{MinPrice: 1000000, MaxPrice: 5000000, MinRating: 4, StartFrom: start, StartTo: start.AddDate(0, 1, 0), MinDuration: 3, MaxDuration: 7, Seats: 2}},
+		{name: "min price only", filter: Tour{MinPrice: 1000000}},
+		{name: "negative price", filter: Tour{MinPrice: -1}, err: "price can't be negative"},
+		{name: "min price over max price", filter: Tour{MinPrice: 5000000, MaxPrice: 1000000}, err: "min price"},
+		{name: "rating out of range", filter: Tour{MinRating: 5.5}, err: "rating"},
+		{name: "start to before start from", filter: Tour{StartFrom: start, StartTo: start.AddDate(0, 0, -1)}, err: "start to"},
+		{name: "negative duration", filter: Tour{MaxDuration: -1}, err: "duration can't be negative"},
+		{name: "min duration over max duration", filter: Tour{MinDuration: 7, MaxDuration: 3}, err: "min duration"},
+		{name: "negative seats", filter: Tour{Seats: -2}, err: "seats"},
+	}
+
+	for _, tc := range testCases {
+		err := tc.filter.Validate()
+
+		if tc.err == "" {
+			assert.NoError(t, err, tc.name)
+			continue
+		}
+
+		assert.ErrorContains(t, err, "validate: ", tc.name)
+		assert.ErrorContains(t, err, tc.err, tc.name)
+	}
+}
+
+func TestTourDeparture(t *testing.T) {
+	assert.False(t, Tour{Status: "published", Upcoming: true, LocationId: 1, MinPrice: 1000000}.Departure())
+	assert.True(t, Tour{Seats: 2}.Departure())
+	assert.True(t, Tour{StartFrom: time.Now()}.Departure())
+	assert.True(t, Tour{MaxDuration: 5}.Departure())
+}
@@ -0,0 +1,47 @@
+package paginate
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"wanderer/helpers/filters"
+
+	"github.com/labstack/echo/v4"
+)
+
+// Links links the previous and next page of a listing of totalData items, or
+// is nil when the listing isn't paginated.
+func Links(c echo.Context, pagination filters.Pagination, totalData int) map[string]any {
+	if pagination.Limit == 0 {
+		return nil
+	}
+
+	var paginationResponse = make(map[string]any)
+	if pagination.Start >= pagination.Limit {
+		paginationResponse["prev"] = Link(c, pagination.Start-pagination.Limit, pagination.Limit)
+	} else {
+		paginationResponse["prev"] = nil
+	}
+
+	if totalData > pagination.Start+pagination.Limit {
+		paginationResponse["next"] = Link(c, pagination.Start+pagination.Limit, pagination.Limit)
+	} else {
+		paginationResponse["next"] = nil
+	}
+
+	return paginationResponse
+}
+
+// Link is the current listing url moved to another page, keeping every other
+// parameter of the request.
+func Link(c echo.Context, start int, limit int) string {
+	var query = url.Values{}
+	for key, values := range c.QueryParams() {
+		query[key] = values
+	}
+
+	query.Set("start", strconv.Itoa(start))
+	query.Set("limit", strconv.Itoa(limit))
+
+	return fmt.Sprintf("%s://%s%s?%s", c.Scheme(), c.Request().Host, c.Request().URL.Path, query.Encode())
+}
@@ -0,0 +1,36 @@
+package paginate_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"wanderer/helpers/filters"
+	"wanderer/helpers/paginate"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinks(t *testing.T) {
+	e := echo.New()
+
+	t.Run("not paginated", func(t *testing.T) {
+		c := e.NewContext(httptest.NewRequest("GET", "/tours", nil), httptest.NewRecorder())
+
+		assert.Nil(t, paginate.Links(c, filters.Pagination{}, 30))
+	})
+
+	t.Run("middle page keeps the other parameters", func(t *testing.T) {
+		c := e.NewContext(httptest.NewRequest("GET", "http://wanderer.test/tours/1/reviews?start=10&limit=10&sort=rating", nil), httptest.NewRecorder())
+
+		assert.Equal(t, map[string]any{
+			"prev": "http://wanderer.test/tours/1/reviews?limit=10&sort=rating&start=0",
+			"next": "http://wanderer.test/tours/1/reviews?limit=10&sort=rating&start=20",
+		}, paginate.Links(c, filters.Pagination{Start: 10, Limit: 10}, 30))
+	})
+
+	t.Run("first and last page", func(t *testing.T) {
+		c := e.NewContext(httptest.NewRequest("GET", "http://wanderer.test/search?keyword=bali", nil), httptest.NewRecorder())
+
+		assert.Equal(t, map[string]any{"prev": nil, "next": nil}, paginate.Links(c, filters.Pagination{Limit: 10}, 5))
+	})
+}
@@ -1,32 +1,153 @@
 package tokens
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
+	"strconv"
 	"time"
+	"wanderer/config"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func GenerateJWT(secret string, idUser uint) (string, error) {
-	if secret == "" {
-		return "", errors.New("invalid token secret")
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
+
+// Claims of an access token, or of a single purpose token sent by email when
+// Purpose is set. Access tokens never carry a purpose.
+type Claims struct {
+	Role    string `json:"role,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// RefreshToken is an opaque token handed to the client. Only its Hash is
+// meant to be stored.
+type RefreshToken struct {
+	Token     string
+	Hash      string
+	ExpiresAt time.Time
+}
+
+// Revocation keeps the ids of access tokens revoked before they expire.
+type Revocation interface {
+	RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error
+	IsTokenRevoked(ctx context.Context, tokenId string) (bool, error)
+}
+
+type JWT interface {
+	GenerateJWT(userId uint, role string) (string, *Claims, error)
+	GenerateRefreshToken() (*RefreshToken, error)
+	GeneratePurposeToken(userId uint, purpose string, ttl time.Duration) (string, *Claims, error)
+	ParsePurposeToken(token string, purpose string) (*Claims, error)
+	Keyfunc(t *jwt.Token) (any, error)
+}
+
+// NewJWT signs tokens with cfg.Secret under the key id cfg.KeyId and accepts
+// tokens signed with any of cfg.Keys, so the secret can be rotated without
+// logging everyone out.
+func NewJWT(cfg config.JWT) JWT {
+	return &jwtSigner{config: cfg}
+}
+
+type jwtSigner struct {
+	config config.JWT
+}
+
+func (sig *jwtSigner) GenerateJWT(userId uint, role string) (string, *Claims, error) {
+	return sig.sign(userId, Claims{Role: role}, sig.config.AccessTTL)
+}
+
+func (sig *jwtSigner) GeneratePurposeToken(userId uint, purpose string, ttl time.Duration) (string, *Claims, error) {
+	if purpose == "" {
+		return "", nil, errors.New("invalid token purpose")
+	}
+
+	return sig.sign(userId, Claims{Purpose: purpose}, ttl)
+}
+
+func (sig *jwtSigner) ParsePurposeToken(strToken string, purpose string) (*Claims, error) {
+	var claims = new(Claims)
+
+	token, err := jwt.ParseWithClaims(strToken, claims, sig.Keyfunc, jwt.WithExpirationRequired())
+	if err != nil || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+
+	if purpose == "" || claims.Purpose != purpose || claims.ID == "" {
+		return nil, errors.New("invalid token")
 	}
 
-	if idUser == 0 {
-		return "", errors.New("invalid user id")
+	return claims, nil
+}
+
+func (sig *jwtSigner) sign(userId uint, claims Claims, ttl time.Duration) (string, *Claims, error) {
+	if sig.config.Secret == "" {
+		return "", nil, errors.New("invalid token secret")
+	}
+
+	if userId == 0 {
+		return "", nil, errors.New("invalid user id")
 	}
 
-	var claim = jwt.MapClaims{}
-	claim["id"] = idUser
-	claim["iat"] = time.Now().UnixMilli()
-	claim["exp"] = time.Now().Add(time.Hour * 2).UnixMilli()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claim)
-	strToken, err := token.SignedString([]byte("altamantul"))
+	tokenId, err := randomString(16)
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	var now = time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		Subject:   strconv.FormatUint(uint64(userId), 10),
+		ID:        tokenId,
+		IssuedAt:  jwt.NewNumericDate(now),
+		ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
 	}
 
-	return strToken, nil
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &claims)
+	token.Header["kid"] = sig.config.KeyId
+
+	strToken, err := token.SignedString([]byte(sig.config.Secret))
+	if err != nil {
+		return "", nil, err
+	}
+
+	return strToken, &claims, nil
+}
+
+func (sig *jwtSigner) GenerateRefreshToken() (*RefreshToken, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RefreshToken{
+		Token:     token,
+		Hash:      HashToken(token),
+		ExpiresAt: time.Now().Add(sig.config.RefreshTTL),
+	}, nil
+}
+
+func (sig *jwtSigner) Keyfunc(t *jwt.Token) (any, error) {
+	if t.Method != jwt.SigningMethodHS256 {
+		return nil, errors.New("unexpected signing method")
+	}
+
+	keyId, _ := t.Header["kid"].(string)
+	if keyId == sig.config.KeyId {
+		return []byte(sig.config.Secret), nil
+	}
+
+	if secret, ok := sig.config.Keys[keyId]; ok {
+		return []byte(secret), nil
+	}
+
+	return nil, errors.New("unknown signing key")
 }
 
 func ExtractToken(secret string, t *jwt.Token) (uint, error) {
@@ -34,29 +155,53 @@ func ExtractToken(secret string, t *jwt.Token) (uint, error) {
 		return 0, errors.New("invalid token secret")
 	}
 
-	if t == nil {
-		return 0, errors.New("invalid jwt token")
-	}
-
-	var userID uint
-	expiredTime, err := t.Claims.GetExpirationTime()
+	claims, err := ExtractClaims(t)
 	if err != nil {
 		return 0, err
 	}
 
-	var eTime = *expiredTime
+	return claims.UserId()
+}
+
+// ExtractClaims returns the claims of a token that has already been verified.
+func ExtractClaims(t *jwt.Token) (*Claims, error) {
+	if t == nil || !t.Valid {
+		return nil, errors.New("invalid jwt token")
+	}
 
-	if t.Valid && eTime.Compare(time.Now()) > 0 {
-		var tokenClaims = t.Claims.(jwt.MapClaims)
+	claims, ok := t.Claims.(*Claims)
+	if !ok || claims.Purpose != "" {
+		return nil, errors.New("invalid jwt claims")
+	}
 
-		if tokenClaims["id"] == nil {
-			return 0, errors.New("id user tidak ditemukan")
-		}
+	if claims.ExpiresAt == nil || claims.ExpiresAt.Before(time.Now()) {
+		return nil, errors.New("token expired")
+	}
 
-		userID = uint(tokenClaims["id"].(float64))
+	return claims, nil
+}
+
+// HashToken is the form a refresh token is stored and looked up in.
+func HashToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
+
+func randomString(size int) (string, error) {
+	var buf = make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
 
-		return userID, nil
+// UserId is the id of the user the token was issued to.
+func (claims *Claims) UserId() (uint, error) {
+	userId, err := strconv.ParseUint(claims.Subject, 10, 0)
+	if err != nil || userId == 0 {
+		return 0, errors.New("invalid user id")
 	}
 
-	return 0, errors.New("token tidak valid")
+	return uint(userId), nil
 }
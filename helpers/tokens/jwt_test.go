@@ -0,0 +1,121 @@
+package tokens_test
+
+import (
+	"testing"
+	"time"
+	"wanderer/config"
+	"wanderer/helpers/tokens"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJWT(t *testing.T) {
+	var cfg = config.JWT{
+		Secret:     "secret",
+		KeyId:      "current",
+		Keys:       map[string]string{"previous": "old secret"},
+		AccessTTL:  15 * time.Minute,
+		RefreshTTL: time.Hour,
+	}
+	var signer = tokens.NewJWT(cfg)
+
+	parse := func(strToken string) (*jwt.Token, error) {
+		return jwt.ParseWithClaims(strToken, new(tokens.Claims), signer.Keyfunc)
+	}
+
+	t.Run("invalid user id", func(t *testing.T) {
+		_, _, err := signer.GenerateJWT(0, "user")
+
+		assert.ErrorContains(t, err, "invalid user id")
+	})
+
+	t.Run("standard claims in seconds", func(t *testing.T) {
+		strToken, claims, err := signer.GenerateJWT(7, "admin")
+		assert.NoError(t, err)
+
+		token, err := parse(strToken)
+		assert.NoError(t, err)
+		assert.Equal(t, "current", token.Header["kid"])
+
+		parsed := token.Claims.(*tokens.Claims)
+		assert.Equal(t, "7", parsed.Subject)
+		assert.Equal(t, "admin", parsed.Role)
+		assert.Equal(t, claims.ID, parsed.ID)
+		assert.NotEmpty(t, parsed.ID)
+		assert.WithinDuration(t, time.Now().Add(cfg.AccessTTL), parsed.ExpiresAt.Time, 2*time.Second)
+
+		userId, err := tokens.ExtractToken(cfg.Secret, token)
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), userId)
+	})
+
+	t.Run("unique token ids", func(t *testing.T) {
+		_, first, _ := signer.GenerateJWT(7, "admin")
+		_, second, _ := signer.GenerateJWT(7, "admin")
+
+		assert.NotEqual(t, first.ID, second.ID)
+	})
+
+	t.Run("rotated keys", func(t *testing.T) {
+		var claims = tokens.Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "7", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+
+		sign := func(keyId string, secret string) string {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			token.Header["kid"] = keyId
+
+			strToken, err := token.SignedString([]byte(secret))
+			assert.NoError(t, err)
+
+			return strToken
+		}
+
+		_, err := parse(sign("previous", "old secret"))
+		assert.NoError(t, err)
+
+		_, err = parse(sign("retired", "old secret"))
+		assert.Error(t, err)
+
+		_, err = parse(sign("previous", "secret"))
+		assert.Error(t, err)
+	})
+
+	t.Run("purpose token", func(t *testing.T) {
+		strToken, claims, err := signer.GeneratePurposeToken(7, tokens.PurposeResetPassword, time.Hour)
+		assert.NoError(t, err)
+
+		parsed, err := signer.ParsePurposeToken(strToken, tokens.PurposeResetPassword)
+		assert.NoError(t, err)
+		assert.Equal(t, claims.ID, parsed.ID)
+
+		userId, err := parsed.UserId()
+		assert.NoError(t, err)
+		assert.Equal(t, uint(7), userId)
+
+		_, err = signer.ParsePurposeToken(strToken, tokens.PurposeVerifyEmail)
+		assert.ErrorContains(t, err, "invalid token")
+
+		token, err := parse(strToken)
+		assert.NoError(t, err)
+
+		_, err = tokens.ExtractClaims(token)
+		assert.ErrorContains(t, err, "invalid jwt claims")
+
+		access, _, _ := signer.GenerateJWT(7, "user")
+		_, err = signer.ParsePurposeToken(access, tokens.PurposeResetPassword)
+		assert.ErrorContains(t, err, "invalid token")
+
+		expired, _, _ := signer.GeneratePurposeToken(7, tokens.PurposeResetPassword, -time.Minute)
+		_, err = signer.ParsePurposeToken(expired, tokens.PurposeResetPassword)
+		assert.ErrorContains(t, err, "invalid token")
+	})
+
+	t.Run("refresh token", func(t *testing.T) {
+		refresh, err := signer.GenerateRefreshToken()
+
+		assert.NoError(t, err)
+		assert.NotEmpty(t, refresh.Token)
+		assert.Equal(t, tokens.HashToken(refresh.Token), refresh.Hash)
+		assert.WithinDuration(t, time.Now().Add(cfg.RefreshTTL), refresh.ExpiresAt, 2*time.Second)
+	})
+}
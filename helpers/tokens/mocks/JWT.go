@@ -0,0 +1,175 @@
+// Code generated by mockery v2.37.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	jwt "github.com/golang-jwt/jwt/v5"
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+
+	tokens "wanderer/helpers/tokens"
+)
+
+// JWT is an autogenerated mock type for the JWT type
+type JWT struct {
+	mock.Mock
+}
+
+// GenerateJWT provides a mock function with given fields: userId, role
+func (_m *JWT) GenerateJWT(userId uint, role string) (string, *tokens.Claims, error) {
+	ret := _m.Called(userId, role)
+
+	var r0 string
+	var r1 *tokens.Claims
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uint, string) (string, *tokens.Claims, error)); ok {
+		return rf(userId, role)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string) string); ok {
+		r0 = rf(userId, role)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string) *tokens.Claims); ok {
+		r1 = rf(userId, role)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*tokens.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uint, string) error); ok {
+		r2 = rf(userId, role)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GeneratePurposeToken provides a mock function with given fields: userId, purpose, ttl
+func (_m *JWT) GeneratePurposeToken(userId uint, purpose string, ttl time.Duration) (string, *tokens.Claims, error) {
+	ret := _m.Called(userId, purpose, ttl)
+
+	var r0 string
+	var r1 *tokens.Claims
+	var r2 error
+	if rf, ok := ret.Get(0).(func(uint, string, time.Duration) (string, *tokens.Claims, error)); ok {
+		return rf(userId, purpose, ttl)
+	}
+	if rf, ok := ret.Get(0).(func(uint, string, time.Duration) string); ok {
+		r0 = rf(userId, purpose, ttl)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(uint, string, time.Duration) *tokens.Claims); ok {
+		r1 = rf(userId, purpose, ttl)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).(*tokens.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(uint, string, time.Duration) error); ok {
+		r2 = rf(userId, purpose, ttl)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// GenerateRefreshToken provides a mock function with given fields:
+func (_m *JWT) GenerateRefreshToken() (*tokens.RefreshToken, error) {
+	ret := _m.Called()
+
+	var r0 *tokens.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (*tokens.RefreshToken, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() *tokens.RefreshToken); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tokens.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Keyfunc provides a mock function with given fields: t
+func (_m *JWT) Keyfunc(t *jwt.Token) (interface{}, error) {
+	ret := _m.Called(t)
+
+	var r0 interface{}
+	var r1 error
+	if rf, ok := ret.Get(0).(func(*jwt.Token) (interface{}, error)); ok {
+		return rf(t)
+	}
+	if rf, ok := ret.Get(0).(func(*jwt.Token) interface{}); ok {
+		r0 = rf(t)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(interface{})
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(*jwt.Token) error); ok {
+		r1 = rf(t)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ParsePurposeToken provides a mock function with given fields: token, purpose
+func (_m *JWT) ParsePurposeToken(token string, purpose string) (*tokens.Claims, error) {
+	ret := _m.Called(token, purpose)
+
+	var r0 *tokens.Claims
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*tokens.Claims, error)); ok {
+		return rf(token, purpose)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *tokens.Claims); ok {
+		r0 = rf(token, purpose)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*tokens.Claims)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(token, purpose)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewJWT creates a new instance of JWT. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewJWT(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *JWT {
+	mock := &JWT{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
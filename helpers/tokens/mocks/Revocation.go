@@ -0,0 +1,67 @@
+// Code generated by mockery v2.37.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	time "time"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Revocation is an autogenerated mock type for the Revocation type
+type Revocation struct {
+	mock.Mock
+}
+
+// IsTokenRevoked provides a mock function with given fields: ctx, tokenId
+func (_m *Revocation) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	ret := _m.Called(ctx, tokenId)
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (bool, error)); ok {
+		return rf(ctx, tokenId)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) bool); ok {
+		r0 = rf(ctx, tokenId)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, tokenId)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RevokeToken provides a mock function with given fields: ctx, tokenId, expiresAt
+func (_m *Revocation) RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	ret := _m.Called(ctx, tokenId, expiresAt)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string, time.Time) error); ok {
+		r0 = rf(ctx, tokenId, expiresAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewRevocation creates a new instance of Revocation. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewRevocation(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Revocation {
+	mock := &Revocation{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
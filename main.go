@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 	"wanderer/config"
 	"wanderer/helpers/encrypt"
+	"wanderer/helpers/tokens"
 	"wanderer/routes"
 	"wanderer/utils/database"
 	"wanderer/utils/files"
+	"wanderer/utils/mail"
 	"wanderer/utils/payments"
+	"wanderer/utils/scheduler"
 
 	uh "wanderer/features/users/handler"
 	ur "wanderer/features/users/repository"
@@ -40,6 +50,14 @@ import (
 	rer "wanderer/features/reports/repository"
 	res "wanderer/features/reports/service"
 
+	seh "wanderer/features/search/handler"
+	ser "wanderer/features/search/repository"
+	ses "wanderer/features/search/service"
+
+	vh "wanderer/features/vouchers/handler"
+	vr "wanderer/features/vouchers/repository"
+	vs "wanderer/features/vouchers/service"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
 )
@@ -74,16 +92,62 @@ func main() {
 		panic(err)
 	}
 
-	var mdtConfig = new(config.Midtrans)
-	if err := mdtConfig.LoadFromEnv(); err != nil {
+	var payConfig = new(config.Payment)
+	if err := payConfig.LoadFromEnv(); err != nil {
+		panic(err)
+	}
+
+	var gateway payments.Gateway
+	switch payConfig.Gateway {
+	case "fake":
+		gateway = payments.NewFake(*payConfig)
+	case "midtrans":
+		var mdtConfig = new(config.Midtrans)
+		if err := mdtConfig.LoadFromEnv(); err != nil {
+			panic(err)
+		}
+		gateway = payments.NewMidtrans(*mdtConfig)
+	default:
+		panic("unsupported payment gateway: " + payConfig.Gateway)
+	}
+
+	var refundConfig = new(config.Refund)
+	if err := refundConfig.LoadFromEnv(); err != nil {
+		panic(err)
+	}
+
+	var schConfig = new(config.Scheduler)
+	if err := schConfig.LoadFromEnv(); err != nil {
+		panic(err)
+	}
+
+	var mailConfig = new(config.Mail)
+	if err := mailConfig.LoadFromEnv(); err != nil {
+		panic(err)
+	}
+
+	var mailer mail.Mailer
+	switch mailConfig.Driver {
+	case "smtp":
+		mailer = mail.NewSMTP(*mailConfig)
+	case "file":
+		mailer = mail.NewFile(mailConfig.From, mailConfig.Dir)
+	case "memory":
+		mailer = mail.NewMemory()
+	default:
+		panic("unsupported mail driver: " + mailConfig.Driver)
+	}
+
+	var accountConfig = new(config.Account)
+	if err := accountConfig.LoadFromEnv(); err != nil {
 		panic(err)
 	}
-	mdt := payments.NewMidtrans(*mdtConfig)
 
 	enc := encrypt.NewBcrypt(10)
+	jwt := tokens.NewJWT(*jwtConfig)
 
 	userRepository := ur.NewUserRepository(dbConnection, cld)
-	userService := us.NewUserService(userRepository, enc)
+	userService := us.NewUserService(userRepository, enc, jwt, mailer, *accountConfig)
 	userHandler := uh.NewUserHandler(userService, *jwtConfig)
 
 	airlineRepository := ar.NewAirlineRepository(dbConnection, cld)
@@ -102,24 +166,35 @@ func main() {
 	locationService := ls.NewLocationService(locationRepository)
 	locationHandler := lh.NewLocationHandler(locationService)
 
-	reviewRepository := rr.NewReviewRepository(dbConnection)
+	reviewRepository := rr.NewReviewRepository(dbConnection, cld)
 	reviewService := rs.NewReviewService(reviewRepository)
 	reviewHandler := rh.NewReviewHandler(reviewService, *jwtConfig)
 
-	bookingRepository := br.NewBookingRepository(dbConnection, mdt, cld)
-	bookingService := bs.NewBookingService(bookingRepository)
+	bookingRepository := br.NewBookingRepository(dbConnection, cld)
+	bookingService := bs.NewBookingService(bookingRepository, gateway, *refundConfig, mailer)
 	bookingHandler := bh.NewBookingHandler(bookingService, *jwtConfig)
 
 	reportRepository := rer.NewReportRepository(dbConnection)
 	reportService := res.NewReportService(reportRepository)
 	reportHandler := reh.NewReportHandler(reportService)
 
+	searchRepository := ser.NewSearchRepository(dbConnection)
+	searchService := ses.NewSearchService(searchRepository)
+	searchHandler := seh.NewSearchHandler(searchService)
+
+	voucherRepository := vr.NewVoucherRepository(dbConnection)
+	voucherService := vs.NewVoucherService(voucherRepository)
+	voucherHandler := vh.NewVoucherHandler(voucherService)
+
 	app := echo.New()
 	app.Use(middleware.Recover())
 	app.Use(middleware.CORS())
 
 	route := routes.Routes{
 		JWTKey:          jwtConfig.Secret,
+		JWT:             jwt,
+		Revocation:      userRepository,
+		RoleResolver:    userRepository,
 		Server:          app,
 		UserHandler:     userHandler,
 		AirlineHandler:  airlineHandler,
@@ -129,9 +204,60 @@ func main() {
 		ReviewHandler:   reviewHandler,
 		BookingHandler:  bookingHandler,
 		ReportHandler:   reportHandler,
+		SearchHandler:   searchHandler,
+		VoucherHandler:  voucherHandler,
 	}
 
 	route.InitRouter()
 
-	app.Logger.Fatal(app.Start(":8000"))
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	sch := scheduler.New()
+	sch.Every(ctx, schConfig.BookingExpiryInterval, func(ctx context.Context) error {
+		total, err := bookingService.ExpirePendingBookings(ctx)
+		if total > 0 {
+			app.Logger.Infof("expired %d pending bookings", total)
+		}
+		return err
+	}, func(err error) {
+		app.Logger.Error(err)
+	})
+
+	sch.Every(ctx, schConfig.NotificationInterval, func(ctx context.Context) error {
+		total, err := bookingService.SendNotifications(ctx)
+		if total > 0 {
+			app.Logger.Infof("sent %d booking notifications", total)
+		}
+		return err
+	}, func(err error) {
+		app.Logger.Error(err)
+	})
+
+	sch.Every(ctx, schConfig.ExportInterval, func(ctx context.Context) error {
+		total, err := bookingService.RunExportSchedules(ctx)
+		if total > 0 {
+			app.Logger.Infof("made %d scheduled exports", total)
+		}
+		return err
+	}, func(err error) {
+		app.Logger.Error(err)
+	})
+
+	go func() {
+		if err := app.Start(":8000"); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			app.Logger.Fatal(err)
+		}
+	}()
+
+	<-ctx.Done()
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := app.Shutdown(shutdownCtx); err != nil {
+		app.Logger.Error(err)
+	}
+
+	sch.Wait()
 }
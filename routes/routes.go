@@ -7,15 +7,23 @@ import (
 	"wanderer/features/locations"
 	"wanderer/features/reports"
 	"wanderer/features/reviews"
+	"wanderer/features/search"
 	"wanderer/features/tours"
 	"wanderer/features/users"
+	"wanderer/features/vouchers"
+	"wanderer/helpers/authorization"
+	"wanderer/helpers/tokens"
 
+	"github.com/golang-jwt/jwt/v5"
 	echojwt "github.com/labstack/echo-jwt/v4"
 	"github.com/labstack/echo/v4"
 )
 
 type Routes struct {
 	JWTKey          string
+	JWT             tokens.JWT
+	Revocation      tokens.Revocation
+	RoleResolver    authorization.RoleResolver
 	Server          *echo.Echo
 	UserHandler     users.Handler
 	AirlineHandler  airlines.Handler
@@ -25,6 +33,8 @@ type Routes struct {
 	ReviewHandler   reviews.Handler
 	BookingHandler  bookings.Handler
 	ReportHandler   reports.Handler
+	SearchHandler   search.Handler
+	VoucherHandler  vouchers.Handler
 }
 
 func (router Routes) InitRouter() {
@@ -36,65 +46,135 @@ func (router Routes) InitRouter() {
 	router.ReviewRouter()
 	router.BookingRouter()
 	router.ReportRouter()
+	router.SearchRouter()
+	router.VoucherRouter()
+}
+
+func (router *Routes) handle(method string, path string, handler echo.HandlerFunc, policy authorization.Policy) {
+	if policy == authorization.Public {
+		router.Server.Add(method, path, handler)
+		return
+	}
+
+	var config = echojwt.Config{
+		KeyFunc: router.JWT.Keyfunc,
+		NewClaimsFunc: func(c echo.Context) jwt.Claims {
+			return new(tokens.Claims)
+		},
+	}
+
+	// A missing or bad token on an optional route just leaves the caller
+	// anonymous.
+	if policy == authorization.Optional {
+		config.ContinueOnIgnoredError = true
+		config.ErrorHandler = func(c echo.Context, err error) error {
+			return nil
+		}
+	}
+
+	verify := echojwt.WithConfig(config)
+
+	router.Server.Add(method, path, handler, verify, authorization.Authorize(router.JWTKey, router.RoleResolver, router.Revocation, policy))
 }
 
 func (router *Routes) UserRouter() {
-	router.Server.POST("/register", router.UserHandler.Register())
-	router.Server.POST("/login", router.UserHandler.Login())
-	router.Server.PATCH("/users", router.UserHandler.Update(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.DELETE("/users", router.UserHandler.Delete(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/users", router.UserHandler.Detail(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.POST, "/register", router.UserHandler.Register(), authorization.Public)
+	router.handle(echo.POST, "/login", router.UserHandler.Login(), authorization.Public)
+	router.handle(echo.POST, "/auth/refresh", router.UserHandler.Refresh(), authorization.Public)
+	router.handle(echo.POST, "/logout", router.UserHandler.Logout(), authorization.Owner)
+	router.handle(echo.POST, "/users/password/forgot", router.UserHandler.ForgotPassword(), authorization.Public)
+	router.handle(echo.POST, "/users/password/reset", router.UserHandler.ResetPassword(), authorization.Public)
+	router.handle(echo.POST, "/users/verify-email", router.UserHandler.VerifyEmail(), authorization.Public)
+	router.handle(echo.POST, "/users/verify-email/resend", router.UserHandler.ResendVerification(), authorization.Public)
+	router.handle(echo.PATCH, "/users", router.UserHandler.Update(), authorization.Owner)
+	router.handle(echo.DELETE, "/users", router.UserHandler.Delete(), authorization.Owner)
+	router.handle(echo.GET, "/users", router.UserHandler.Detail(), authorization.Owner)
 }
 
 func (router *Routes) AirlineRouter() {
-	router.Server.POST("/airlines", router.AirlineHandler.Create(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/airlines", router.AirlineHandler.GetAll())
-	router.Server.PUT("/airlines/:id", router.AirlineHandler.Update(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.DELETE("/airlines/:id", router.AirlineHandler.Delete(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/airlines/import", router.AirlineHandler.ImportTemplate())
-	router.Server.POST("/airlines/import", router.AirlineHandler.Import(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.POST, "/airlines", router.AirlineHandler.Create(), authorization.Admin)
+	router.handle(echo.GET, "/airlines", router.AirlineHandler.GetAll(), authorization.Public)
+	router.handle(echo.PUT, "/airlines/:id", router.AirlineHandler.Update(), authorization.Admin)
+	router.handle(echo.DELETE, "/airlines/:id", router.AirlineHandler.Delete(), authorization.Admin)
+	router.handle(echo.GET, "/airlines/import", router.AirlineHandler.ImportTemplate(), authorization.Public)
+	router.handle(echo.POST, "/airlines/import", router.AirlineHandler.Import(), authorization.Admin)
 }
 
 func (router *Routes) LocationRouter() {
-	router.Server.GET("/locations", router.LocationHandler.GetAll())
-	router.Server.POST("/locations", router.LocationHandler.Create(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.PUT("/locations/:id", router.LocationHandler.Update(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.DELETE("/locations/:id", router.LocationHandler.Delete(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/locations/:id", router.LocationHandler.GetDetail())
-	router.Server.GET("/locations/import", router.LocationHandler.ImportTemplate())
-	router.Server.POST("/locations/import", router.LocationHandler.Import(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.GET, "/locations", router.LocationHandler.GetAll(), authorization.Public)
+	router.handle(echo.POST, "/locations", router.LocationHandler.Create(), authorization.Admin)
+	router.handle(echo.PUT, "/locations/:id", router.LocationHandler.Update(), authorization.Admin)
+	router.handle(echo.DELETE, "/locations/:id", router.LocationHandler.Delete(), authorization.Admin)
+	router.handle(echo.GET, "/locations/:id", router.LocationHandler.GetDetail(), authorization.Public)
+	router.handle(echo.GET, "/locations/import", router.LocationHandler.ImportTemplate(), authorization.Public)
+	router.handle(echo.POST, "/locations/import", router.LocationHandler.Import(), authorization.Admin)
 }
 
 func (router *Routes) FacilityRouter() {
-	router.Server.POST("/facilities", router.FacilityHandler.Create(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/facilities", router.FacilityHandler.GetAll())
-	router.Server.PUT("/facilities/:id", router.FacilityHandler.Update(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.DELETE("/facilities/:id", router.FacilityHandler.Delete(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/facilities/import", router.FacilityHandler.ImportTemplate())
-	router.Server.POST("/facilities/import", router.FacilityHandler.Import(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.POST, "/facilities", router.FacilityHandler.Create(), authorization.Admin)
+	router.handle(echo.GET, "/facilities", router.FacilityHandler.GetAll(), authorization.Public)
+	router.handle(echo.PUT, "/facilities/:id", router.FacilityHandler.Update(), authorization.Admin)
+	router.handle(echo.DELETE, "/facilities/:id", router.FacilityHandler.Delete(), authorization.Admin)
+	router.handle(echo.GET, "/facilities/import", router.FacilityHandler.ImportTemplate(), authorization.Public)
+	router.handle(echo.POST, "/facilities/import", router.FacilityHandler.Import(), authorization.Admin)
 }
 
 func (router *Routes) TourRouter() {
-	router.Server.GET("/tours", router.TourHandler.GetAll())
-	router.Server.POST("/tours", router.TourHandler.Create(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.PUT("/tours/:id", router.TourHandler.Update(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/tours/:id", router.TourHandler.GetDetail())
+	router.handle(echo.GET, "/tours", router.TourHandler.GetAll(), authorization.Optional)
+	router.handle(echo.POST, "/tours", router.TourHandler.Create(), authorization.Admin)
+	router.handle(echo.PUT, "/tours/:id", router.TourHandler.Update(), authorization.Admin)
+	router.handle(echo.PATCH, "/tours/:id/status", router.TourHandler.UpdateStatus(), authorization.Admin)
+	router.handle(echo.DELETE, "/tours/:id", router.TourHandler.Delete(), authorization.Admin)
+	router.handle(echo.GET, "/tours/:id", router.TourHandler.GetDetail(), authorization.Optional)
+	router.handle(echo.POST, "/tours/:id/departures", router.TourHandler.CreateDeparture(), authorization.Admin)
+	router.handle(echo.PUT, "/tours/:id/departures/:departureId", router.TourHandler.UpdateDeparture(), authorization.Admin)
+	router.handle(echo.DELETE, "/tours/:id/departures/:departureId", router.TourHandler.DeleteDeparture(), authorization.Admin)
 }
 
 func (router *Routes) ReviewRouter() {
-	router.Server.POST("/reviews", router.ReviewHandler.Create(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.POST, "/reviews", router.ReviewHandler.Create(), authorization.Owner)
+	router.handle(echo.GET, "/reviews", router.ReviewHandler.GetAll(), authorization.Admin)
+	router.handle(echo.GET, "/tours/:id/reviews", router.ReviewHandler.GetByTour(), authorization.Optional)
+	router.handle(echo.PATCH, "/reviews/:id", router.ReviewHandler.Update(), authorization.Owner)
+	router.handle(echo.DELETE, "/reviews/:id", router.ReviewHandler.Delete(), authorization.Owner)
+	router.handle(echo.POST, "/reviews/:id/flag", router.ReviewHandler.Flag(), authorization.Owner)
+	router.handle(echo.PATCH, "/reviews/:id/status", router.ReviewHandler.Moderate(), authorization.Admin)
+	router.handle(echo.POST, "/reviews/:id/photos", router.ReviewHandler.AddPhotos(), authorization.Owner)
+	router.handle(echo.DELETE, "/reviews/:id/photos/:photoId", router.ReviewHandler.DeletePhoto(), authorization.Owner)
+	router.handle(echo.POST, "/reviews/:id/helpful", router.ReviewHandler.Vote(), authorization.Owner)
+	router.handle(echo.DELETE, "/reviews/:id/helpful", router.ReviewHandler.Unvote(), authorization.Owner)
 }
 
 func (router *Routes) BookingRouter() {
-	router.Server.GET("/bookings", router.BookingHandler.GetAll(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.POST("/bookings", router.BookingHandler.Create(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.GET("/bookings/:code", router.BookingHandler.GetDetail(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.PATCH("/bookings/:code", router.BookingHandler.Update(), echojwt.JWT([]byte(router.JWTKey)))
-	router.Server.POST("/payments", router.BookingHandler.PaymentNotification())
+	router.handle(echo.GET, "/bookings", router.BookingHandler.GetAll(), authorization.Owner)
+	router.handle(echo.POST, "/bookings", router.BookingHandler.Create(), authorization.Owner)
+	router.handle(echo.GET, "/bookings/:code", router.BookingHandler.GetDetail(), authorization.Owner)
+	router.handle(echo.PATCH, "/bookings/:code", router.BookingHandler.Update(), authorization.Owner)
+	router.handle(echo.GET, "/bookings/:code/invoice", router.BookingHandler.Invoice(), authorization.Owner)
+	router.handle(echo.GET, "/bookings/:code/ticket", router.BookingHandler.Ticket(), authorization.Owner)
+	router.handle(echo.POST, "/payments", router.BookingHandler.PaymentNotification(), authorization.Public)
 
-	router.Server.GET("/bookings/export", router.BookingHandler.ExportReportTransaction(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.GET, "/bookings/export", router.BookingHandler.ExportReportTransaction(), authorization.Admin)
+	router.handle(echo.GET, "/bookings/exports", router.BookingHandler.GetExportFiles(), authorization.Admin)
+	router.handle(echo.GET, "/bookings/exports/:id", router.BookingHandler.DownloadExport(), authorization.Admin)
+	router.handle(echo.GET, "/bookings/export-schedules", router.BookingHandler.GetExportSchedules(), authorization.Admin)
+	router.handle(echo.POST, "/bookings/export-schedules", router.BookingHandler.CreateExportSchedule(), authorization.Admin)
+	router.handle(echo.DELETE, "/bookings/export-schedules/:id", router.BookingHandler.DeleteExportSchedule(), authorization.Admin)
 }
 
 func (router *Routes) ReportRouter() {
-	router.Server.GET("/reports", router.ReportHandler.Dashboard(), echojwt.JWT([]byte(router.JWTKey)))
+	router.handle(echo.GET, "/reports", router.ReportHandler.Dashboard(), authorization.Admin)
+}
+
+func (router *Routes) SearchRouter() {
+	router.handle(echo.GET, "/search", router.SearchHandler.Search(), authorization.Public)
+}
+
+func (router *Routes) VoucherRouter() {
+	router.handle(echo.POST, "/vouchers", router.VoucherHandler.Create(), authorization.Admin)
+	router.handle(echo.GET, "/vouchers", router.VoucherHandler.GetAll(), authorization.Admin)
+	router.handle(echo.GET, "/vouchers/:id", router.VoucherHandler.GetDetail(), authorization.Admin)
+	router.handle(echo.PUT, "/vouchers/:id", router.VoucherHandler.Update(), authorization.Admin)
+	router.handle(echo.DELETE, "/vouchers/:id", router.VoucherHandler.Delete(), authorization.Admin)
+	router.handle(echo.GET, "/vouchers/:id/redemptions", router.VoucherHandler.GetRedemptions(), authorization.Admin)
 }
@@ -0,0 +1,363 @@
+package routes
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wanderer/config"
+	"wanderer/helpers/authorization"
+	"wanderer/helpers/tokens"
+
+	am "wanderer/features/airlines/mocks"
+	bm "wanderer/features/bookings/mocks"
+	fm "wanderer/features/facilities/mocks"
+	lm "wanderer/features/locations/mocks"
+	rem "wanderer/features/reports/mocks"
+	rm "wanderer/features/reviews/mocks"
+	sem "wanderer/features/search/mocks"
+	tm "wanderer/features/tours/mocks"
+	um "wanderer/features/users/mocks"
+	vm "wanderer/features/vouchers/mocks"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const testJWTKey = "secret"
+
+var testJWTConfig = config.JWT{
+	Secret:    testJWTKey,
+	KeyId:     "current",
+	Keys:      map[string]string{"previous": "old secret"},
+	AccessTTL: time.Hour,
+}
+
+type revocation map[string]bool
+
+func (rev revocation) RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	rev[tokenId] = true
+	return nil
+}
+
+func (rev revocation) IsTokenRevoked(ctx context.Context, tokenId string) (bool, error) {
+	return rev[tokenId], nil
+}
+
+type roleResolver map[uint]string
+
+func (res roleResolver) GetRole(ctx context.Context, userId uint) (string, error) {
+	role, ok := res[userId]
+	if !ok {
+		return "", errors.New("not found: user not found")
+	}
+
+	return role, nil
+}
+
+func okHandler(c echo.Context) error {
+	return c.NoContent(http.StatusOK)
+}
+
+func stubHandler(m *mock.Mock, methods ...string) {
+	for _, method := range methods {
+		m.On(method).Return(echo.HandlerFunc(okHandler)).Once()
+	}
+}
+
+func newTestServer(t *testing.T, revoked revocation) *echo.Echo {
+	userHandler := um.NewHandler(t)
+	stubHandler(&userHandler.Mock, "Register", "Login", "Refresh", "Logout", "ForgotPassword", "ResetPassword", "VerifyEmail", "ResendVerification", "Update", "Delete", "Detail")
+
+	airlineHandler := am.NewHandler(t)
+	stubHandler(&airlineHandler.Mock, "Create", "GetAll", "Update", "Delete", "ImportTemplate", "Import")
+
+	locationHandler := lm.NewHandler(t)
+	stubHandler(&locationHandler.Mock, "GetAll", "Create", "Update", "Delete", "GetDetail", "ImportTemplate", "Import")
+
+	facilityHandler := fm.NewHandler(t)
+	stubHandler(&facilityHandler.Mock, "Create", "GetAll", "Update", "Delete", "ImportTemplate", "Import")
+
+	tourHandler := tm.NewHandler(t)
+	stubHandler(&tourHandler.Mock, "GetAll", "Create", "Update", "UpdateStatus", "Delete", "GetDetail", "CreateDeparture", "UpdateDeparture", "DeleteDeparture")
+
+	reviewHandler := rm.NewHandler(t)
+	stubHandler(&reviewHandler.Mock, "Create", "GetAll", "GetByTour", "Update", "Delete", "Flag", "Moderate", "AddPhotos", "DeletePhoto", "Vote", "Unvote")
+
+	bookingHandler := bm.NewHandler(t)
+	stubHandler(&bookingHandler.Mock, "GetAll", "Create", "GetDetail", "Update", "PaymentNotification", "ExportReportTransaction", "CreateExportSchedule", "GetExportSchedules", "DeleteExportSchedule", "GetExportFiles", "DownloadExport", "Invoice", "Ticket")
+
+	reportHandler := rem.NewHandler(t)
+	stubHandler(&reportHandler.Mock, "Dashboard")
+
+	searchHandler := sem.NewHandler(t)
+	stubHandler(&searchHandler.Mock, "Search")
+
+	voucherHandler := vm.NewHandler(t)
+	stubHandler(&voucherHandler.Mock, "Create", "GetAll", "GetDetail", "Update", "Delete", "GetRedemptions")
+
+	app := echo.New()
+	route := Routes{
+		JWTKey:          testJWTKey,
+		JWT:             tokens.NewJWT(testJWTConfig),
+		Revocation:      revoked,
+		RoleResolver:    roleResolver{1: authorization.RoleAdmin, 2: authorization.RoleUser},
+		Server:          app,
+		UserHandler:     userHandler,
+		AirlineHandler:  airlineHandler,
+		LocationHandler: locationHandler,
+		FacilityHandler: facilityHandler,
+		TourHandler:     tourHandler,
+		ReviewHandler:   reviewHandler,
+		BookingHandler:  bookingHandler,
+		ReportHandler:   reportHandler,
+		SearchHandler:   searchHandler,
+		VoucherHandler:  voucherHandler,
+	}
+	route.InitRouter()
+
+	return app
+}
+
+func newTestToken(t *testing.T, userId uint) string {
+	token, _, err := tokens.NewJWT(testJWTConfig).GenerateJWT(userId, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return token
+}
+
+func TestRoutePolicies(t *testing.T) {
+	app := newTestServer(t, revocation{})
+
+	adminToken := newTestToken(t, 1)
+	userToken := newTestToken(t, 2)
+	deletedToken := newTestToken(t, 3)
+
+	var cases = []struct {
+		method string
+		path   string
+		policy authorization.Policy
+	}{
+		{http.MethodPost, "/register", authorization.Public},
+		{http.MethodPost, "/login", authorization.Public},
+		{http.MethodPost, "/auth/refresh", authorization.Public},
+		{http.MethodPost, "/logout", authorization.Owner},
+		{http.MethodPost, "/users/password/forgot", authorization.Public},
+		{http.MethodPost, "/users/password/reset", authorization.Public},
+		{http.MethodPost, "/users/verify-email", authorization.Public},
+		{http.MethodPost, "/users/verify-email/resend", authorization.Public},
+		{http.MethodPatch, "/users", authorization.Owner},
+		{http.MethodDelete, "/users", authorization.Owner},
+		{http.MethodGet, "/users", authorization.Owner},
+
+		{http.MethodPost, "/airlines", authorization.Admin},
+		{http.MethodGet, "/airlines", authorization.Public},
+		{http.MethodPut, "/airlines/1", authorization.Admin},
+		{http.MethodDelete, "/airlines/1", authorization.Admin},
+		{http.MethodGet, "/airlines/import", authorization.Public},
+		{http.MethodPost, "/airlines/import", authorization.Admin},
+
+		{http.MethodGet, "/locations", authorization.Public},
+		{http.MethodPost, "/locations", authorization.Admin},
+		{http.MethodPut, "/locations/1", authorization.Admin},
+		{http.MethodDelete, "/locations/1", authorization.Admin},
+		{http.MethodGet, "/locations/1", authorization.Public},
+		{http.MethodGet, "/locations/import", authorization.Public},
+		{http.MethodPost, "/locations/import", authorization.Admin},
+
+		{http.MethodPost, "/facilities", authorization.Admin},
+		{http.MethodGet, "/facilities", authorization.Public},
+		{http.MethodPut, "/facilities/1", authorization.Admin},
+		{http.MethodDelete, "/facilities/1", authorization.Admin},
+		{http.MethodGet, "/facilities/import", authorization.Public},
+		{http.MethodPost, "/facilities/import", authorization.Admin},
+
+		{http.MethodGet, "/tours", authorization.Optional},
+		{http.MethodPost, "/tours", authorization.Admin},
+		{http.MethodPut, "/tours/1", authorization.Admin},
+		{http.MethodPatch, "/tours/1/status", authorization.Admin},
+		{http.MethodDelete, "/tours/1", authorization.Admin},
+		{http.MethodGet, "/tours/1", authorization.Optional},
+		{http.MethodPost, "/tours/1/departures", authorization.Admin},
+		{http.MethodPut, "/tours/1/departures/1", authorization.Admin},
+		{http.MethodDelete, "/tours/1/departures/1", authorization.Admin},
+
+		{http.MethodPost, "/reviews", authorization.Owner},
+		{http.MethodGet, "/reviews", authorization.Admin},
+		{http.MethodGet, "/tours/1/reviews", authorization.Optional},
+		{http.MethodPatch, "/reviews/1", authorization.Owner},
+		{http.MethodDelete, "/reviews/1", authorization.Owner},
+		{http.MethodPost, "/reviews/1/flag", authorization.Owner},
+		{http.MethodPatch, "/reviews/1/status", authorization.Admin},
+		{http.MethodPost, "/reviews/1/photos", authorization.Owner},
+		{http.MethodDelete, "/reviews/1/photos/1", authorization.Owner},
+		{http.MethodPost, "/reviews/1/helpful", authorization.Owner},
+		{http.MethodDelete, "/reviews/1/helpful", authorization.Owner},
+
+		{http.MethodGet, "/bookings", authorization.Owner},
+		{http.MethodPost, "/bookings", authorization.Owner},
+		{http.MethodGet, "/bookings/123", authorization.Owner},
+		{http.MethodPatch, "/bookings/123", authorization.Owner},
+		{http.MethodGet, "/bookings/123/invoice", authorization.Owner},
+		{http.MethodGet, "/bookings/123/ticket", authorization.Owner},
+		{http.MethodPost, "/payments", authorization.Public},
+		{http.MethodGet, "/bookings/export", authorization.Admin},
+		{http.MethodGet, "/bookings/exports", authorization.Admin},
+		{http.MethodGet, "/bookings/exports/1", authorization.Admin},
+		{http.MethodGet, "/bookings/export-schedules", authorization.Admin},
+		{http.MethodPost, "/bookings/export-schedules", authorization.Admin},
+		{http.MethodDelete, "/bookings/export-schedules/1", authorization.Admin},
+
+		{http.MethodGet, "/reports", authorization.Admin},
+
+		{http.MethodGet, "/search", authorization.Public},
+
+		{http.MethodPost, "/vouchers", authorization.Admin},
+		{http.MethodGet, "/vouchers", authorization.Admin},
+		{http.MethodGet, "/vouchers/1", authorization.Admin},
+		{http.MethodPut, "/vouchers/1", authorization.Admin},
+		{http.MethodDelete, "/vouchers/1", authorization.Admin},
+		{http.MethodGet, "/vouchers/1/redemptions", authorization.Admin},
+	}
+
+	serve := func(method string, path string, token string) int {
+		req := httptest.NewRequest(method, path, nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.method+" "+tc.path, func(t *testing.T) {
+			switch tc.policy {
+			case authorization.Public:
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, ""))
+			case authorization.Optional:
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, ""))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, "malformed"))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, deletedToken))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, userToken))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, adminToken))
+			case authorization.Owner:
+				assert.Equal(t, http.StatusUnauthorized, serve(tc.method, tc.path, ""))
+				assert.Equal(t, http.StatusUnauthorized, serve(tc.method, tc.path, deletedToken))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, userToken))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, adminToken))
+			case authorization.Admin:
+				assert.Equal(t, http.StatusUnauthorized, serve(tc.method, tc.path, ""))
+				assert.Equal(t, http.StatusUnauthorized, serve(tc.method, tc.path, deletedToken))
+				assert.Equal(t, http.StatusForbidden, serve(tc.method, tc.path, userToken))
+				assert.Equal(t, http.StatusOK, serve(tc.method, tc.path, adminToken))
+			}
+		})
+	}
+}
+
+func TestRouteOptionalIdentity(t *testing.T) {
+	app := echo.New()
+	route := Routes{
+		JWTKey:       testJWTKey,
+		JWT:          tokens.NewJWT(testJWTConfig),
+		Revocation:   revocation{},
+		RoleResolver: roleResolver{1: authorization.RoleAdmin, 2: authorization.RoleUser},
+		Server:       app,
+	}
+
+	route.handle(http.MethodGet, "/optional", func(c echo.Context) error {
+		_, role := authorization.Identity(c)
+		return c.String(http.StatusOK, role)
+	}, authorization.Optional)
+
+	serve := func(token string) string {
+		req := httptest.NewRequest(http.MethodGet, "/optional", nil)
+		if token != "" {
+			req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+		}
+
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		return rec.Body.String()
+	}
+
+	assert.Equal(t, "", serve(""))
+	assert.Equal(t, "", serve("malformed"))
+	assert.Equal(t, authorization.RoleUser, serve(newTestToken(t, 2)))
+	assert.Equal(t, authorization.RoleAdmin, serve(newTestToken(t, 1)))
+}
+
+func TestRouteTokenVerification(t *testing.T) {
+	revoked := revocation{}
+	app := newTestServer(t, revoked)
+
+	serve := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/users", nil)
+		req.Header.Set(echo.HeaderAuthorization, "Bearer "+token)
+
+		rec := httptest.NewRecorder()
+		app.ServeHTTP(rec, req)
+
+		return rec.Code
+	}
+
+	sign := func(keyId string, secret string, claims tokens.Claims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+		token.Header["kid"] = keyId
+
+		strToken, err := token.SignedString([]byte(secret))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return strToken
+	}
+
+	claims := func(exp time.Time) tokens.Claims {
+		return tokens.Claims{RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   "2",
+			ID:        "token-id",
+			ExpiresAt: jwt.NewNumericDate(exp),
+		}}
+	}
+
+	t.Run("token signed with a previous key", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(sign("previous", "old secret", claims(time.Now().Add(time.Hour)))))
+	})
+
+	t.Run("token signed with an unknown key", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(sign("unknown", "old secret", claims(time.Now().Add(time.Hour)))))
+		assert.Equal(t, http.StatusUnauthorized, serve(sign("current", "old secret", claims(time.Now().Add(time.Hour)))))
+	})
+
+	t.Run("expired token", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(sign("current", testJWTKey, claims(time.Now().Add(-time.Minute)))))
+	})
+
+	t.Run("token sent by email", func(t *testing.T) {
+		token, _, err := tokens.NewJWT(testJWTConfig).GeneratePurposeToken(2, tokens.PurposeResetPassword, time.Hour)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		assert.Equal(t, http.StatusUnauthorized, serve(token))
+	})
+
+	t.Run("revoked token", func(t *testing.T) {
+		token := sign("current", testJWTKey, claims(time.Now().Add(time.Hour)))
+		assert.Equal(t, http.StatusOK, serve(token))
+
+		revoked["token-id"] = true
+		assert.Equal(t, http.StatusUnauthorized, serve(token))
+	})
+}
@@ -2,7 +2,9 @@ package database
 
 import (
 	"fmt"
+	"strings"
 	"wanderer/config"
+	"wanderer/features/reviews"
 
 	ar "wanderer/features/airlines/repository"
 	br "wanderer/features/bookings/repository"
@@ -11,6 +13,7 @@ import (
 	rr "wanderer/features/reviews/repository"
 	tr "wanderer/features/tours/repository"
 	ur "wanderer/features/users/repository"
+	vr "wanderer/features/vouchers/repository"
 
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
@@ -26,22 +29,156 @@ func MysqlInit(cfg config.DatabaseMysql) (*gorm.DB, error) {
 }
 
 func MysqlMigrate(db *gorm.DB) error {
+	if err := migrateBookingCodes(db); err != nil {
+		return err
+	}
+
+	// Tours got their ratings kept along with the rating count column, so
+	// only a database still without it has ratings to recount.
+	migrator := db.Migrator()
+	recountRatings := migrator.HasTable(&tr.Tour{}) && !migrator.HasColumn(&tr.Tour{}, "ReviewCount")
+
 	err := db.AutoMigrate(
 		&ur.User{},
+		&ur.RefreshToken{},
+		&ur.RevokedToken{},
+		&ur.UserToken{},
 		&ar.Airline{},
 		&lr.Location{},
 		&fr.Facility{},
 		&tr.File{},
 		&tr.Tour{},
+		&tr.Departure{},
 		&tr.Itinerary{},
 		&rr.Review{},
+		&rr.ReviewFlag{},
+		&rr.ReviewPhoto{},
+		&rr.ReviewVote{},
 		&br.Booking{},
 		&br.BookingDetail{},
+		&br.PaymentRejection{},
+		&br.SeatHold{},
+		&br.Refund{},
+		&br.RefundPassenger{},
+		&br.RefundHistory{},
+		&br.BookingStatusHistory{},
+		&br.BookingNotification{},
+		&br.BookingExportSchedule{},
+		&br.BookingExportFile{},
+		&vr.Voucher{},
+		&vr.VoucherTour{},
+		&vr.VoucherLocation{},
 	)
 
 	if err != nil {
 		return err
 	}
 
+	if err := migrateTourDepartures(db); err != nil {
+		return err
+	}
+
+	if recountRatings {
+		if err := migrateTourRatings(db); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// migrateTourRatings recounts the ratings of every tour from its reviews, the
+// way the reviews repository keeps them up to date, so tours reviewed before
+// ratings were kept get theirs too.
+func migrateTourRatings(db *gorm.DB) error {
+	return db.Exec(`UPDATE tours LEFT JOIN (
+			SELECT tour_id, COUNT(*) AS total_count, SUM(rating) AS total,
+				SUM(FLOOR(rating + 0.5) <= 1) AS stars_1, SUM(FLOOR(rating + 0.5) = 2) AS stars_2, SUM(FLOOR(rating + 0.5) = 3) AS stars_3,
+				SUM(FLOOR(rating + 0.5) = 4) AS stars_4, SUM(FLOOR(rating + 0.5) >= 5) AS stars_5
+			FROM reviews WHERE status <> ? AND deleted_at IS NULL GROUP BY tour_id
+		) AS stats ON stats.tour_id = tours.id
+		SET tours.review_count = COALESCE(stats.total_count, 0),
+			tours.rating_total = COALESCE(stats.total, 0),
+			tours.rating_1 = COALESCE(stats.stars_1, 0),
+			tours.rating_2 = COALESCE(stats.stars_2, 0),
+			tours.rating_3 = COALESCE(stats.stars_3, 0),
+			tours.rating_4 = COALESCE(stats.stars_4, 0),
+			tours.rating_5 = COALESCE(stats.stars_5, 0),
+			tours.rating = COALESCE(stats.total / stats.total_count, 0),
+			tours.rating_score = (? + COALESCE(stats.total, 0)) / (? + COALESCE(stats.total_count, 0))`,
+		reviews.StatusHidden, reviews.PriorRating*reviews.PriorWeight, reviews.PriorWeight).Error
+}
+
+// migrateTourDepartures gives every tour of older databases a departure on
+// its own dates and seats, and points the bookings and seat holds made before
+// departures existed at it.
+func migrateTourDepartures(db *gorm.DB) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec(`INSERT INTO tour_departures (tour_id, start, finish, quota, available, price, created_at, updated_at)
+			SELECT tours.id, tours.start, tours.finish, tours.quota, tours.available, 0, NOW(), NOW() FROM tours
+			WHERE NOT EXISTS (SELECT 1 FROM tour_departures WHERE tour_departures.tour_id = tours.id)`).Error
+		if err != nil {
+			return err
+		}
+
+		for _, table := range []string{"bookings", "seat_holds"} {
+			err := tx.Exec(`UPDATE ` + table + ` SET departure_id = (SELECT MIN(tour_departures.id) FROM tour_departures WHERE tour_departures.tour_id = ` + table + `.tour_id)
+				WHERE departure_id IS NULL OR departure_id = 0`).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateBookingCodes turns the numeric booking codes of older databases into
+// strings. Existing codes keep their digits, so they can still be looked up and
+// still match the order id their payment was made with.
+func migrateBookingCodes(db *gorm.DB) error {
+	migrator := db.Migrator()
+	if !migrator.HasTable(&br.Booking{}) {
+		return nil
+	}
+
+	columns, err := migrator.ColumnTypes(&br.Booking{})
+	if err != nil {
+		return err
+	}
+
+	for _, column := range columns {
+		if column.Name() == "code" && strings.Contains(strings.ToLower(column.DatabaseTypeName()), "char") {
+			return nil
+		}
+	}
+
+	if migrator.HasConstraint(&br.Booking{}, "Detail") {
+		if err := migrator.DropConstraint(&br.Booking{}, "Detail"); err != nil {
+			return err
+		}
+	}
+
+	var references = []any{
+		&br.BookingDetail{},
+		&br.SeatHold{},
+		&br.Refund{},
+		&br.BookingStatusHistory{},
+	}
+
+	if err := migrator.AlterColumn(&br.Booking{}, "Code"); err != nil {
+		return err
+	}
+
+	for _, mod := range references {
+		if !migrator.HasTable(mod) {
+			continue
+		}
+
+		if err := migrator.AlterColumn(mod, "BookingCode"); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
@@ -0,0 +1,38 @@
+package exporter
+
+import (
+	"encoding/csv"
+	"io"
+)
+
+type csvWriter struct {
+	writer *csv.Writer
+}
+
+func newCsvWriter(w io.Writer, header []string) (Writer, error) {
+	var exp = &csvWriter{writer: csv.NewWriter(w)}
+	if err := exp.writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	return exp, nil
+}
+
+func (exp *csvWriter) Write(row []any) error {
+	var record = make([]string, len(row))
+	for i, value := range row {
+		record[i] = text(value)
+	}
+
+	if err := exp.writer.Write(record); err != nil {
+		return err
+	}
+
+	exp.writer.Flush()
+	return exp.writer.Error()
+}
+
+func (exp *csvWriter) Close() error {
+	exp.writer.Flush()
+	return exp.writer.Error()
+}
@@ -0,0 +1,73 @@
+package exporter
+
+import (
+	"io"
+
+	"github.com/xuri/excelize/v2"
+)
+
+const excelSheet = "Sheet1"
+
+type excelWriter struct {
+	w      io.Writer
+	file   *excelize.File
+	stream *excelize.StreamWriter
+	row    int
+}
+
+func newExcelWriter(w io.Writer, header []string) (_ Writer, err error) {
+	file := excelize.NewFile()
+	// The writer closes the file once done with it, until then it is only
+	// closed here when the writer can't be made.
+	defer func() {
+		if err != nil {
+			file.Close()
+		}
+	}()
+
+	stream, err := file.NewStreamWriter(excelSheet)
+	if err != nil {
+		return nil, err
+	}
+
+	style, err := file.NewStyle(&excelize.Style{
+		Font: &excelize.Font{Bold: true},
+		Fill: excelize.Fill{Type: "pattern", Color: []string{"#ffc430"}, Pattern: 1},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var cells = make([]any, len(header))
+	for i, column := range header {
+		cells[i] = excelize.Cell{StyleID: style, Value: column}
+	}
+
+	if err := stream.SetRow("A1", cells); err != nil {
+		return nil, err
+	}
+
+	return &excelWriter{w: w, file: file, stream: stream, row: 1}, nil
+}
+
+func (exp *excelWriter) Write(row []any) error {
+	exp.row++
+
+	cell, err := excelize.CoordinatesToCellName(1, exp.row)
+	if err != nil {
+		return err
+	}
+
+	return exp.stream.SetRow(cell, row)
+}
+
+func (exp *excelWriter) Close() error {
+	defer exp.file.Close()
+
+	if err := exp.stream.Flush(); err != nil {
+		return err
+	}
+
+	_, err := exp.file.WriteTo(exp.w)
+	return err
+}
@@ -0,0 +1,81 @@
+package exporter
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+type Format string
+
+const (
+	CSV   Format = "csv"
+	Excel Format = "xlsx"
+	PDF   Format = "pdf"
+)
+
+func ParseFormat(format string) (Format, error) {
+	switch Format(format) {
+	case CSV, Excel, PDF:
+		return Format(format), nil
+	}
+
+	return "", errors.New("unsupported file type")
+}
+
+func (f Format) ContentType() string {
+	switch f {
+	case CSV:
+		return "text/csv"
+	case Excel:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case PDF:
+		return "application/pdf"
+	}
+
+	return "application/octet-stream"
+}
+
+// Filename names an export after its subject and the time it was made, so
+// exports never share a name.
+func (f Format) Filename(name string, at time.Time) string {
+	return fmt.Sprintf("%s-%s.%s", name, at.Format("20060102-150405"), f)
+}
+
+// Writer writes a table one row at a time. Close must be called once every
+// row is written, it finishes the file.
+type Writer interface {
+	Write(row []any) error
+	Close() error
+}
+
+// NewWriter writes a table with the given header to w in format. CSV rows
+// go out as they are written, the other formats are only complete on Close.
+func NewWriter(format Format, w io.Writer, title string, header []string) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCsvWriter(w, header)
+	case Excel:
+		return newExcelWriter(w, header)
+	case PDF:
+		return newPdfWriter(w, title, header)
+	}
+
+	return nil, errors.New("unsupported file type")
+}
+
+// text formats a cell value for the formats that only hold text.
+func text(value any) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case nil:
+		return ""
+	}
+
+	return fmt.Sprint(value)
+}
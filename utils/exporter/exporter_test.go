@@ -0,0 +1,68 @@
+package exporter_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"wanderer/utils/exporter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/xuri/excelize/v2"
+)
+
+var header = []string{"Booking Code", "Name", "Price"}
+
+func write(t *testing.T, format exporter.Format, rows ...[]any) []byte {
+	var buf bytes.Buffer
+
+	exp, err := exporter.NewWriter(format, &buf, "Transactions", header)
+	assert.NoError(t, err)
+
+	for _, row := range rows {
+		assert.NoError(t, exp.Write(row))
+	}
+	assert.NoError(t, exp.Close())
+
+	return buf.Bytes()
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := exporter.ParseFormat("xlsx")
+	assert.NoError(t, err)
+	assert.Equal(t, exporter.Excel, format)
+
+	_, err = exporter.ParseFormat("doc")
+	assert.ErrorContains(t, err, "unsupported file type")
+
+	at := time.Date(2023, 12, 1, 8, 30, 0, 0, time.UTC)
+	assert.Equal(t, "transactions-20231201-083000.csv", exporter.CSV.Filename("transactions", at))
+}
+
+func TestCSV(t *testing.T) {
+	result := write(t, exporter.CSV, []any{"WNDR4Q7K2W", "Maman, Jr.", 10000.5})
+
+	assert.Equal(t, "Booking Code,Name,Price\nWNDR4Q7K2W,\"Maman, Jr.\",10000.5\n", string(result))
+}
+
+func TestExcel(t *testing.T) {
+	result := write(t, exporter.Excel, []any{"WNDR4Q7K2W", "Maman", 10000.0}, []any{"WNDR4Q7K3T", "Asep", 20000.0})
+
+	file, err := excelize.OpenReader(bytes.NewReader(result))
+	assert.NoError(t, err)
+
+	rows, err := file.GetRows("Sheet1")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"Booking Code", "Name", "Price"}, {"WNDR4Q7K2W", "Maman", "10000"}, {"WNDR4Q7K3T", "Asep", "20000"}}, rows)
+}
+
+func TestPDF(t *testing.T) {
+	var rows [][]any
+	for i := 0; i < 100; i++ {
+		rows = append(rows, []any{"WNDR4Q7K2W", "Maman", 10000.0})
+	}
+
+	result := write(t, exporter.PDF, rows...)
+
+	assert.True(t, strings.HasPrefix(string(result), "%PDF-"))
+}
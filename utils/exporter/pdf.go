@@ -0,0 +1,74 @@
+package exporter
+
+import (
+	"io"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+type pdfWriter struct {
+	w       io.Writer
+	pdf     *gofpdf.Fpdf
+	tr      func(string) string
+	header  []string
+	width   float64
+	started bool
+}
+
+func newPdfWriter(w io.Writer, title string, header []string) (Writer, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	pdf.SetTitle(title, true)
+
+	pageWidth, _ := pdf.GetPageSize()
+	left, _, right, _ := pdf.GetMargins()
+
+	var exp = &pdfWriter{
+		w:      w,
+		pdf:    pdf,
+		tr:     pdf.UnicodeTranslatorFromDescriptor(""),
+		header: header,
+		width:  (pageWidth - left - right) / float64(len(header)),
+	}
+
+	// The header is repeated on top of every page after the first, where it
+	// goes below the title.
+	pdf.SetHeaderFunc(func() {
+		if exp.started {
+			exp.writeHeader()
+		}
+	})
+	pdf.AddPage()
+
+	if title != "" {
+		pdf.SetFont("Arial", "B", 14)
+		pdf.CellFormat(0, 10, exp.tr(title), "", 1, "L", false, 0, "")
+	}
+
+	exp.writeHeader()
+	exp.started = true
+
+	return exp, pdf.Error()
+}
+
+func (exp *pdfWriter) writeHeader() {
+	exp.pdf.SetFont("Arial", "B", 10)
+	exp.pdf.SetFillColor(255, 196, 48)
+	for _, column := range exp.header {
+		exp.pdf.CellFormat(exp.width, 8, exp.tr(column), "1", 0, "C", true, 0, "")
+	}
+	exp.pdf.Ln(-1)
+	exp.pdf.SetFont("Arial", "", 10)
+}
+
+func (exp *pdfWriter) Write(row []any) error {
+	for _, value := range row {
+		exp.pdf.CellFormat(exp.width, 8, exp.tr(text(value)), "1", 0, "L", false, 0, "")
+	}
+	exp.pdf.Ln(-1)
+
+	return exp.pdf.Error()
+}
+
+func (exp *pdfWriter) Close() error {
+	return exp.pdf.Output(exp.w)
+}
@@ -2,7 +2,12 @@ package files
 
 import (
 	"context"
+	"errors"
 	"io"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
 	"wanderer/config"
 
 	cld "github.com/cloudinary/cloudinary-go/v2"
@@ -39,3 +44,55 @@ func (cloud *cloudinary) Upload(ctx context.Context, folder string, Raw io.Reade
 
 	return &res.SecureURL, nil
 }
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// Delete removes the file uploaded to fileUrl. A file that is already gone
+// counts as deleted.
+func (cloud *cloudinary) Delete(ctx context.Context, fileUrl string) error {
+	resourceType, publicId, err := parseFileUrl(fileUrl)
+	if err != nil {
+		return err
+	}
+
+	res, err := cloud.client.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicId,
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.Error.Message != "" {
+		return errors.New(res.Error.Message)
+	}
+
+	return nil
+}
+
+// parseFileUrl finds the resource type and public id of a file in its
+// delivery url, which looks like
+// https://res.cloudinary.com/<cloud>/<resource type>/upload/v<version>/<public id>.<format>.
+func parseFileUrl(fileUrl string) (string, string, error) {
+	parsed, err := url.Parse(fileUrl)
+	if err != nil {
+		return "", "", err
+	}
+
+	segments := strings.Split(strings.Trim(parsed.Path, "/"), "/")
+	if len(segments) < 4 || segments[2] != "upload" {
+		return "", "", errors.New("not a cloudinary file url: " + fileUrl)
+	}
+
+	resourceType, rest := segments[1], segments[3:]
+	if len(rest) > 1 && versionSegment.MatchString(rest[0]) {
+		rest = rest[1:]
+	}
+
+	publicId := strings.Join(rest, "/")
+	if resourceType != "raw" {
+		publicId = strings.TrimSuffix(publicId, path.Ext(publicId))
+	}
+
+	return resourceType, publicId, nil
+}
@@ -0,0 +1,31 @@
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFileUrl(t *testing.T) {
+	t.Run("image", func(t *testing.T) {
+		resourceType, publicId, err := parseFileUrl("https://res.cloudinary.com/wanderer/image/upload/v1700000000/reviews/abc123.jpg")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "image", resourceType)
+		assert.Equal(t, "reviews/abc123", publicId)
+	})
+
+	t.Run("raw keeps its extension", func(t *testing.T) {
+		resourceType, publicId, err := parseFileUrl("https://res.cloudinary.com/wanderer/raw/upload/v1700000000/exports/bookings.csv")
+
+		assert.NoError(t, err)
+		assert.Equal(t, "raw", resourceType)
+		assert.Equal(t, "exports/bookings.csv", publicId)
+	})
+
+	t.Run("not a cloudinary url", func(t *testing.T) {
+		_, _, err := parseFileUrl("https://example.com/photo.jpg")
+
+		assert.ErrorContains(t, err, "not a cloudinary file url")
+	})
+}
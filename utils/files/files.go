@@ -7,4 +7,5 @@ import (
 
 type Cloud interface {
 	Upload(ctx context.Context, folder string, Raw io.Reader) (*string, error)
+	Delete(ctx context.Context, fileUrl string) error
 }
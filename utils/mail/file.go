@@ -0,0 +1,33 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^a-zA-Z0-9@._-]`)
+
+// NewFile writes every message as an .eml file into dir instead of sending
+// it, for local runs.
+func NewFile(from string, dir string) Mailer {
+	return &fileMailer{from: from, dir: dir}
+}
+
+type fileMailer struct {
+	from string
+	dir  string
+}
+
+func (mlr *fileMailer) Send(ctx context.Context, msg Message) error {
+	if err := os.MkdirAll(mlr.dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), unsafeFileChars.ReplaceAllString(msg.To, "_"))
+
+	return os.WriteFile(filepath.Join(mlr.dir, name), render(mlr.from, msg), 0o644)
+}
@@ -0,0 +1,35 @@
+package mail_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wanderer/utils/mail"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	mlr := mail.NewMemory()
+
+	assert.NoError(t, mlr.Send(context.Background(), mail.Message{To: "maman@example.com", Subject: "hello", Body: "world"}))
+	assert.Equal(t, []mail.Message{{To: "maman@example.com", Subject: "hello", Body: "world"}}, mlr.Messages())
+}
+
+func TestFile(t *testing.T) {
+	dir := t.TempDir()
+	mlr := mail.NewFile("Wanderer <no-reply@wanderer.local>", dir)
+
+	assert.NoError(t, mlr.Send(context.Background(), mail.Message{To: "maman@example.com", Subject: "hello", Body: "line one\nline two"}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*maman@example.com.eml"))
+	assert.NoError(t, err)
+	if assert.Len(t, files, 1) {
+		content, err := os.ReadFile(files[0])
+		assert.NoError(t, err)
+		assert.True(t, strings.Contains(string(content), "Subject: hello\r\n"))
+		assert.True(t, strings.HasSuffix(string(content), "\r\n\r\nline one\r\nline two"))
+	}
+}
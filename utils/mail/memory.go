@@ -0,0 +1,31 @@
+package mail
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory keeps sent messages in memory, for tests.
+type Memory struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+func NewMemory() *Memory {
+	return new(Memory)
+}
+
+func (mlr *Memory) Send(ctx context.Context, msg Message) error {
+	mlr.mu.Lock()
+	defer mlr.mu.Unlock()
+
+	mlr.messages = append(mlr.messages, msg)
+	return nil
+}
+
+func (mlr *Memory) Messages() []Message {
+	mlr.mu.Lock()
+	defer mlr.mu.Unlock()
+
+	return append([]Message(nil), mlr.messages...)
+}
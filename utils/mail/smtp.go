@@ -0,0 +1,48 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"net/smtp"
+	"wanderer/config"
+)
+
+func NewSMTP(config config.Mail) Mailer {
+	return &smtpMailer{config: config}
+}
+
+type smtpMailer struct {
+	config config.Mail
+}
+
+func (mlr *smtpMailer) Send(ctx context.Context, msg Message) error {
+	from, err := mail.ParseAddress(mlr.config.From)
+	if err != nil {
+		return err
+	}
+
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if mlr.config.Username != "" {
+		auth = smtp.PlainAuth("", mlr.config.Username, mlr.config.Password, mlr.config.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", mlr.config.Host, mlr.config.Port)
+
+	var done = make(chan error, 1)
+	go func() {
+		done <- smtp.SendMail(addr, auth, from.Address, []string{to.Address}, render(mlr.config.From, msg))
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
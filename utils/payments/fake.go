@@ -0,0 +1,298 @@
+package payments
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"wanderer/config"
+	"wanderer/features/bookings"
+)
+
+// Fake is an in-process gateway for local runs and integration tests. It never
+// leaves the process except for the optional webhook it posts to CallbackUrl.
+type Fake interface {
+	Gateway
+	Settle(code string) error
+	Expire(code string) error
+}
+
+func NewFake(config config.Payment) Fake {
+	return &fake{
+		config:       config,
+		client:       &http.Client{Timeout: 10 * time.Second},
+		transactions: make(map[string]*fakeTransaction),
+	}
+}
+
+type fake struct {
+	config config.Payment
+	client *http.Client
+
+	mu           sync.Mutex
+	transactions map[string]*fakeTransaction
+}
+
+type fakeTransaction struct {
+	id          string
+	paymentType string
+	status      string
+	grossAmount float64
+	refunded    float64
+	refundKeys  map[string]bool
+	expiredAt   time.Time
+}
+
+var fakeBankPrefix = map[string]string{
+	"bca":     "12345",
+	"bni":     "98801",
+	"bri":     "26215",
+	"permata": "85600",
+}
+
+func (pay *fake) NewBookingPayment(data bookings.Booking) (*bookings.Payment, error) {
+	var transaction = &fakeTransaction{
+		id:          fmt.Sprintf("fake-%s-%d", data.Code, time.Now().UnixNano()),
+		status:      "pending",
+		grossAmount: float64(int64(data.Total)),
+		expiredAt:   time.Now().Add(pay.config.FakeExpiry),
+	}
+
+	switch data.Payment.Bank {
+	case "bca", "bni", "bri", "permata":
+		transaction.paymentType = "bank_transfer"
+		data.Payment.VirtualNumber = fakeBankPrefix[data.Payment.Bank] + fmt.Sprintf("%011d", rand.Int63n(1e11))
+	case "mandiri":
+		transaction.paymentType = "echannel"
+		data.Payment.BillKey = fmt.Sprintf("%012d", rand.Int63n(1e12))
+		data.Payment.BillCode = "70012"
+	default:
+		return nil, errors.New("unsupported payment")
+	}
+
+	pay.mu.Lock()
+	pay.transactions[data.Code] = transaction
+	pay.mu.Unlock()
+
+	code := data.Code
+	time.AfterFunc(pay.config.FakeExpiry, func() {
+		if !pay.current(code, transaction) {
+			return
+		}
+
+		if err := pay.Expire(code); err != nil {
+			log.Println("fake payment:", err)
+		}
+	})
+
+	if pay.config.FakeAutoSettle != 0 {
+		time.AfterFunc(pay.config.FakeAutoSettle, func() {
+			if !pay.current(code, transaction) {
+				return
+			}
+
+			if err := pay.Settle(code); err != nil {
+				log.Println("fake payment:", err)
+			}
+		})
+	}
+
+	data.Payment.Method = transaction.paymentType
+	data.Payment.Status = transaction.status
+	data.Payment.ExpiredAt = transaction.expiredAt
+	data.Payment.BookingTotal = data.Total
+
+	return &data.Payment, nil
+}
+
+func (pay *fake) CancelBookingPayment(code string) error {
+	pay.mu.Lock()
+	defer pay.mu.Unlock()
+
+	transaction, ok := pay.transactions[code]
+	if !ok {
+		return errors.New("not found: transaction doesn't exist")
+	}
+
+	switch transaction.status {
+	case "pending":
+		transaction.status = "cancel"
+	case "cancel", "expire":
+	default:
+		return fmt.Errorf("can't cancel transaction with status %s", transaction.status)
+	}
+
+	return nil
+}
+
+func (pay *fake) CheckBookingPayment(code string) (*bookings.PaymentNotification, error) {
+	pay.mu.Lock()
+	defer pay.mu.Unlock()
+
+	transaction, ok := pay.transactions[code]
+	if !ok {
+		return nil, errors.New("transaction doesn't exist")
+	}
+
+	if transaction.status == "pending" && time.Now().After(transaction.expiredAt) {
+		transaction.status = "expire"
+	}
+
+	return pay.notification(code, transaction), nil
+}
+
+func (pay *fake) RefundBookingPayment(code string, refundId uint, amount float64, reason string) error {
+	pay.mu.Lock()
+	defer pay.mu.Unlock()
+
+	transaction, ok := pay.transactions[code]
+	if !ok {
+		return errors.New("transaction doesn't exist")
+	}
+
+	// Like the real gateway, a refund key that was already paid out isn't
+	// paid out again.
+	key := refundKey(code, refundId)
+	if transaction.refundKeys[key] {
+		return nil
+	}
+
+	if transaction.status != "settlement" && transaction.status != "partial_refund" {
+		return errors.New("transaction status is not eligible for refund")
+	}
+
+	if amount <= 0 || transaction.refunded+amount > transaction.grossAmount {
+		return errors.New("refund amount exceeds transaction amount")
+	}
+
+	transaction.refunded += amount
+	if transaction.refundKeys == nil {
+		transaction.refundKeys = make(map[string]bool)
+	}
+	transaction.refundKeys[key] = true
+
+	if transaction.refunded == transaction.grossAmount {
+		transaction.status = "refund"
+	} else {
+		transaction.status = "partial_refund"
+	}
+
+	return nil
+}
+
+func (pay *fake) VerifyNotification(data bookings.PaymentNotification) (*bookings.PaymentNotification, error) {
+	if !validSignature(data, pay.config.FakeKey) {
+		return nil, errors.New("invalid signature key")
+	}
+
+	return &data, nil
+}
+
+func (pay *fake) Settle(code string) error {
+	notification, err := pay.transition(code, "settlement", "pending")
+	if err != nil {
+		return err
+	}
+
+	return pay.emit(*notification)
+}
+
+func (pay *fake) Expire(code string) error {
+	notification, err := pay.transition(code, "expire", "pending")
+	if err != nil {
+		return err
+	}
+
+	return pay.emit(*notification)
+}
+
+func (pay *fake) current(code string, transaction *fakeTransaction) bool {
+	pay.mu.Lock()
+	defer pay.mu.Unlock()
+
+	return pay.transactions[code] == transaction
+}
+
+func (pay *fake) transition(code string, status string, from string) (*bookings.PaymentNotification, error) {
+	pay.mu.Lock()
+	defer pay.mu.Unlock()
+
+	transaction, ok := pay.transactions[code]
+	if !ok {
+		return nil, errors.New("transaction doesn't exist")
+	}
+
+	if transaction.status != from {
+		return nil, fmt.Errorf("can't change transaction status from %s to %s", transaction.status, status)
+	}
+
+	transaction.status = status
+
+	return pay.notification(code, transaction), nil
+}
+
+func (pay *fake) notification(code string, transaction *fakeTransaction) *bookings.PaymentNotification {
+	var data = &bookings.PaymentNotification{
+		OrderId:       code,
+		TransactionId: transaction.id,
+		StatusCode:    statusCode(transaction.status),
+		GrossAmount:   strconv.FormatFloat(transaction.grossAmount, 'f', 2, 64),
+		Status:        transaction.status,
+		PaymentType:   transaction.paymentType,
+		FraudStatus:   "accept",
+	}
+	data.SignatureKey = signature(*data, pay.config.FakeKey)
+
+	return data
+}
+
+func (pay *fake) emit(data bookings.PaymentNotification) error {
+	if pay.config.CallbackUrl == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(map[string]string{
+		"order_id":           data.OrderId,
+		"transaction_id":     data.TransactionId,
+		"status_code":        data.StatusCode,
+		"gross_amount":       data.GrossAmount,
+		"transaction_status": data.Status,
+		"payment_type":       data.PaymentType,
+		"fraud_status":       data.FraudStatus,
+		"signature_key":      data.SignatureKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	res, err := pay.client.Post(pay.config.CallbackUrl, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("callback responded with status %d", res.StatusCode)
+	}
+
+	return nil
+}
+
+func statusCode(status string) string {
+	switch status {
+	case "settlement", "capture", "refund", "partial_refund":
+		return "200"
+	case "pending":
+		return "201"
+	case "expire":
+		return "407"
+	default:
+		return "202"
+	}
+}
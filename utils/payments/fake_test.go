@@ -0,0 +1,91 @@
+package payments
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wanderer/config"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFakeGateway(t *testing.T) {
+	var received = make(chan bookings.PaymentNotification, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+
+		received <- bookings.PaymentNotification{
+			OrderId:      body["order_id"],
+			StatusCode:   body["status_code"],
+			GrossAmount:  body["gross_amount"],
+			Status:       body["transaction_status"],
+			SignatureKey: body["signature_key"],
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	pay := NewFake(config.Payment{CallbackUrl: server.URL, FakeKey: "key", FakeExpiry: time.Hour})
+
+	t.Run("unsupported bank", func(t *testing.T) {
+		result, err := pay.NewBookingPayment(bookings.Booking{Code: "1", Total: 1000, Payment: bookings.Payment{Bank: "ovo"}})
+
+		assert.ErrorContains(t, err, "unsupported")
+		assert.Nil(t, result)
+	})
+
+	t.Run("charge and settle", func(t *testing.T) {
+		result, err := pay.NewBookingPayment(bookings.Booking{Code: "2", Total: 1000, Payment: bookings.Payment{Bank: "bca"}})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "pending", result.Status)
+		assert.Len(t, result.VirtualNumber, 16)
+
+		assert.NoError(t, pay.Settle("2"))
+
+		notification := <-received
+		assert.Equal(t, "settlement", notification.Status)
+		assert.Equal(t, "1000.00", notification.GrossAmount)
+
+		verified, err := pay.VerifyNotification(notification)
+		assert.NoError(t, err)
+		assert.Equal(t, "2", verified.OrderId)
+
+		notification.GrossAmount = "1.00"
+		_, err = pay.VerifyNotification(notification)
+		assert.ErrorContains(t, err, "signature")
+	})
+
+	t.Run("refund", func(t *testing.T) {
+		assert.ErrorContains(t, pay.RefundBookingPayment("2", 1, 2000, "cancel"), "exceeds")
+		assert.NoError(t, pay.RefundBookingPayment("2", 1, 400, "cancel"))
+		assert.NoError(t, pay.RefundBookingPayment("2", 1, 400, "cancel"))
+
+		status, err := pay.CheckBookingPayment("2")
+		assert.NoError(t, err)
+		assert.Equal(t, "partial_refund", status.Status)
+
+		assert.NoError(t, pay.RefundBookingPayment("2", 2, 600, "cancel"))
+
+		status, err = pay.CheckBookingPayment("2")
+		assert.NoError(t, err)
+		assert.Equal(t, "refund", status.Status)
+	})
+
+	t.Run("expire", func(t *testing.T) {
+		_, err := pay.NewBookingPayment(bookings.Booking{Code: "3", Total: 1000, Payment: bookings.Payment{Bank: "mandiri"}})
+		assert.NoError(t, err)
+
+		assert.NoError(t, pay.Expire("3"))
+		assert.Equal(t, "expire", (<-received).Status)
+
+		assert.Error(t, pay.Settle("3"))
+		assert.NoError(t, pay.CancelBookingPayment("3"))
+		assert.Error(t, pay.CancelBookingPayment("2"))
+		assert.ErrorContains(t, pay.CancelBookingPayment("4"), "not found")
+	})
+}
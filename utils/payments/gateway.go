@@ -0,0 +1,31 @@
+package payments
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"wanderer/features/bookings"
+)
+
+type Gateway interface {
+	NewBookingPayment(data bookings.Booking) (*bookings.Payment, error)
+	CancelBookingPayment(code string) error
+	CheckBookingPayment(code string) (*bookings.PaymentNotification, error)
+	RefundBookingPayment(code string, refundId uint, amount float64, reason string) error
+	VerifyNotification(data bookings.PaymentNotification) (*bookings.PaymentNotification, error)
+}
+
+// refundKey identifies the refund refundId of booking code at the gateway.
+func refundKey(code string, refundId uint) string {
+	return fmt.Sprintf("%s-%d", code, refundId)
+}
+
+func signature(data bookings.PaymentNotification, serverKey string) string {
+	hash := sha512.Sum512([]byte(data.OrderId + data.StatusCode + data.GrossAmount + serverKey))
+	return hex.EncodeToString(hash[:])
+}
+
+func validSignature(data bookings.PaymentNotification, serverKey string) bool {
+	return subtle.ConstantTimeCompare([]byte(signature(data, serverKey)), []byte(data.SignatureKey)) == 1
+}
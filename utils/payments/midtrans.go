@@ -11,12 +11,7 @@ import (
 	"github.com/midtrans/midtrans-go/coreapi"
 )
 
-type Midtrans interface {
-	NewBookingPayment(data bookings.Booking) (*bookings.Payment, error)
-	CancelBookingPayment(code int) error
-}
-
-func NewMidtrans(config config.Midtrans) Midtrans {
+func NewMidtrans(config config.Midtrans) Gateway {
 	var client coreapi.Client
 	client.New(config.ApiKey, config.Env)
 
@@ -34,7 +29,7 @@ type midtrans struct {
 func (pay *midtrans) NewBookingPayment(data bookings.Booking) (*bookings.Payment, error) {
 	req := new(coreapi.ChargeReq)
 	req.TransactionDetails = mdt.TransactionDetails{
-		OrderID:  fmt.Sprintf("%d", data.Code),
+		OrderID:  data.Code,
 		GrossAmt: int64(data.Total),
 	}
 
@@ -44,21 +39,7 @@ func (pay *midtrans) NewBookingPayment(data bookings.Booking) (*bookings.Payment
 		Phone: data.User.Phone,
 	}
 
-	pricePerPassenger := int64(data.Total) / int64(len(data.Detail))
-
-	var reqItem []mdt.ItemDetails
-	for pos, detail := range data.Detail {
-		if (pricePerPassenger*int64(len(data.Detail))) != int64(data.Total) && pos == len(data.Detail)-1 {
-			pricePerPassenger += int64(data.Total) - (pricePerPassenger * int64(len(data.Detail)))
-		}
-
-		reqItem = append(reqItem, mdt.ItemDetails{
-			ID:    detail.DocumentNumber,
-			Name:  detail.Greeting + " " + detail.Name,
-			Price: pricePerPassenger,
-			Qty:   1,
-		})
-	}
+	reqItem := itemDetails(data)
 	req.Items = &reqItem
 
 	switch data.Payment.Bank {
@@ -87,7 +68,6 @@ func (pay *midtrans) NewBookingPayment(data bookings.Booking) (*bookings.Payment
 		req.EChannel = &coreapi.EChannelDetail{
 			BillInfo1: "Wanderer Booking",
 			BillInfo2: fmt.Sprintf("%d person", len(data.Detail)),
-			BillKey:   fmt.Sprintf("%d", data.Code),
 		}
 	default:
 		return nil, errors.New("unsupported payment")
@@ -133,11 +113,141 @@ func (pay *midtrans) NewBookingPayment(data bookings.Booking) (*bookings.Payment
 	return &data.Payment, nil
 }
 
-func (pay *midtrans) CancelBookingPayment(code int) error {
-	res, _ := pay.client.CancelTransaction(fmt.Sprintf("%d", code))
-	if res.StatusCode != "200" && res.StatusCode != "412" {
+// CancelBookingPayment cancels the transaction of booking code. A transaction
+// that already expired or can't be changed anymore counts as canceled; one
+// the gateway doesn't know is reported as not found.
+func (pay *midtrans) CancelBookingPayment(code string) error {
+	res, _ := pay.client.CancelTransaction(code)
+	switch res.StatusCode {
+	case "200", "407", "412":
+	case "404":
+		return errors.New("not found: " + res.StatusMessage)
+	default:
+		return errors.New(res.StatusMessage)
+	}
+
+	return nil
+}
+
+func (pay *midtrans) VerifyNotification(data bookings.PaymentNotification) (*bookings.PaymentNotification, error) {
+	if !validSignature(data, pay.config.ApiKey) {
+		return nil, errors.New("invalid signature key")
+	}
+
+	if !pay.config.CheckStatus {
+		return &data, nil
+	}
+
+	if data.OrderId == "" {
+		return nil, errors.New("invalid order id")
+	}
+
+	res, err := pay.CheckBookingPayment(data.OrderId)
+	if err != nil {
+		return nil, err
+	}
+
+	res.SignatureKey = data.SignatureKey
+
+	return res, nil
+}
+
+func (pay *midtrans) CheckBookingPayment(code string) (*bookings.PaymentNotification, error) {
+	res, err := pay.client.CheckTransaction(code)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != "200" && res.StatusCode != "201" && res.StatusCode != "202" && res.StatusCode != "407" {
+		return nil, errors.New(res.StatusMessage)
+	}
+
+	if res.OrderID != code {
+		return nil, errors.New("order id mismatch")
+	}
+
+	return &bookings.PaymentNotification{
+		OrderId:       res.OrderID,
+		TransactionId: res.TransactionID,
+		StatusCode:    res.StatusCode,
+		GrossAmount:   res.GrossAmount,
+		Status:        res.TransactionStatus,
+		PaymentType:   res.PaymentType,
+		FraudStatus:   res.FraudStatus,
+	}, nil
+}
+
+// RefundBookingPayment refunds amount of the payment of booking code. The
+// refund key comes from refundId, so retrying the same refund never pays it
+// out twice.
+func (pay *midtrans) RefundBookingPayment(code string, refundId uint, amount float64, reason string) error {
+	res, err := pay.client.RefundTransaction(code, &coreapi.RefundReq{
+		RefundKey: refundKey(code, refundId),
+		Amount:    int64(amount),
+		Reason:    reason,
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.StatusCode != "200" {
 		return errors.New(res.StatusMessage)
 	}
 
 	return nil
 }
+
+// itemDetails breaks the total of a booking down into a seat per passenger at
+// the discounted price of their category, the admin fee and the voucher
+// discount. The gateway wants the items to add up to the gross amount, so
+// whatever rounding leaves over is put on the priciest seat, never on a free
+// one.
+func itemDetails(data bookings.Booking) []mdt.ItemDetails {
+	var items []mdt.ItemDetails
+
+	for _, detail := range data.Detail {
+		items = append(items, mdt.ItemDetails{
+			ID:       detail.DocumentNumber,
+			Name:     detail.Greeting + " " + detail.Name,
+			Price:    int64(data.Tour.Discounted(detail.Price)),
+			Qty:      1,
+			Category: detail.Category,
+		})
+	}
+
+	if data.Tour.AdminFee != 0 {
+		items = append(items, mdt.ItemDetails{
+			ID:    "admin-fee",
+			Name:  "Admin fee",
+			Price: int64(data.Tour.AdminFee),
+			Qty:   1,
+		})
+	}
+
+	if data.Discount != 0 {
+		items = append(items, mdt.ItemDetails{
+			ID:    "voucher",
+			Name:  "Voucher " + data.Voucher.Code,
+			Price: -int64(data.Discount),
+			Qty:   1,
+		})
+	}
+
+	var sum int64
+	for _, item := range items {
+		sum += item.Price * int64(item.Qty)
+	}
+
+	if len(data.Detail) != 0 {
+		var priciest int
+		for i := range data.Detail {
+			if items[i].Price > items[priciest].Price {
+				priciest = i
+			}
+		}
+
+		items[priciest].Price += int64(data.Total) - sum
+	}
+
+	return items
+}
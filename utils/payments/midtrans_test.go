@@ -0,0 +1,55 @@
+package payments
+
+import (
+	"testing"
+	"wanderer/features/bookings"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestItemDetails(t *testing.T) {
+	var data = bookings.Booking{
+		Tour:     bookings.Tour{Price: 1000001, Discount: 10, AdminFee: 5000},
+		Discount: 50000,
+		Voucher:  bookings.Voucher{Code: "HOLIDAY"},
+		Detail: []bookings.Detail{
+			{DocumentNumber: "A1", Greeting: "Mr", Name: "One", Category: bookings.PassengerAdult, Price: 1000001},
+			{DocumentNumber: "A2", Greeting: "Miss", Name: "Two", Category: bookings.PassengerChild, Price: 500001},
+			{DocumentNumber: "A3", Greeting: "Mstr", Name: "Three", Category: bookings.PassengerInfant, Price: 0},
+		},
+	}
+	data.Total = bookings.TourTotal(data.Tour, data.Detail) - data.Discount + data.Tour.AdminFee
+
+	items := itemDetails(data)
+
+	assert.Len(t, items, 5)
+	assert.Equal(t, "A1", items[0].ID)
+	assert.Equal(t, int64(900001), items[0].Price)
+	assert.Equal(t, bookings.PassengerChild, items[1].Category)
+	assert.Equal(t, int64(450000), items[1].Price)
+	assert.Equal(t, int64(0), items[2].Price)
+	assert.Equal(t, int64(5000), items[3].Price)
+	assert.Equal(t, "Voucher HOLIDAY", items[4].Name)
+	assert.Equal(t, int64(-50000), items[4].Price)
+
+	var sum int64
+	for _, item := range items {
+		sum += item.Price * int64(item.Qty)
+	}
+	assert.Equal(t, int64(data.Total), sum)
+}
+
+func TestItemDetailsWithoutExtras(t *testing.T) {
+	var data = bookings.Booking{
+		Tour:   bookings.Tour{Price: 100},
+		Total:  300,
+		Detail: []bookings.Detail{{DocumentNumber: "A1", Price: 100}, {DocumentNumber: "A2", Price: 100}, {DocumentNumber: "A3", Price: 100}},
+	}
+
+	items := itemDetails(data)
+
+	assert.Len(t, items, 3)
+	for _, item := range items {
+		assert.Equal(t, int64(100), item.Price)
+	}
+}
@@ -0,0 +1,162 @@
+// Code generated by mockery v2.37.1. DO NOT EDIT.
+
+package mocks
+
+import (
+	bookings "wanderer/features/bookings"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Fake is an autogenerated mock type for the Fake type
+type Fake struct {
+	mock.Mock
+}
+
+// CancelBookingPayment provides a mock function with given fields: code
+func (_m *Fake) CancelBookingPayment(code string) error {
+	ret := _m.Called(code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// CheckBookingPayment provides a mock function with given fields: code
+func (_m *Fake) CheckBookingPayment(code string) (*bookings.PaymentNotification, error) {
+	ret := _m.Called(code)
+
+	var r0 *bookings.PaymentNotification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*bookings.PaymentNotification, error)); ok {
+		return rf(code)
+	}
+	if rf, ok := ret.Get(0).(func(string) *bookings.PaymentNotification); ok {
+		r0 = rf(code)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.PaymentNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(code)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Expire provides a mock function with given fields: code
+func (_m *Fake) Expire(code string) error {
+	ret := _m.Called(code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewBookingPayment provides a mock function with given fields: data
+func (_m *Fake) NewBookingPayment(data bookings.Booking) (*bookings.Payment, error) {
+	ret := _m.Called(data)
+
+	var r0 *bookings.Payment
+	var r1 error
+	if rf, ok := ret.Get(0).(func(bookings.Booking) (*bookings.Payment, error)); ok {
+		return rf(data)
+	}
+	if rf, ok := ret.Get(0).(func(bookings.Booking) *bookings.Payment); ok {
+		r0 = rf(data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.Payment)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bookings.Booking) error); ok {
+		r1 = rf(data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RefundBookingPayment provides a mock function with given fields: code, refundId, amount, reason
+func (_m *Fake) RefundBookingPayment(code string, refundId uint, amount float64, reason string) error {
+	ret := _m.Called(code, refundId, amount, reason)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, uint, float64, string) error); ok {
+		r0 = rf(code, refundId, amount, reason)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Settle provides a mock function with given fields: code
+func (_m *Fake) Settle(code string) error {
+	ret := _m.Called(code)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(code)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// VerifyNotification provides a mock function with given fields: data
+func (_m *Fake) VerifyNotification(data bookings.PaymentNotification) (*bookings.PaymentNotification, error) {
+	ret := _m.Called(data)
+
+	var r0 *bookings.PaymentNotification
+	var r1 error
+	if rf, ok := ret.Get(0).(func(bookings.PaymentNotification) (*bookings.PaymentNotification, error)); ok {
+		return rf(data)
+	}
+	if rf, ok := ret.Get(0).(func(bookings.PaymentNotification) *bookings.PaymentNotification); ok {
+		r0 = rf(data)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*bookings.PaymentNotification)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(bookings.PaymentNotification) error); ok {
+		r1 = rf(data)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewFake creates a new instance of Fake. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFake(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Fake {
+	mock := &Fake{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,52 @@
+package scheduler
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+type Job func(ctx context.Context) error
+
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Scheduler runs jobs on a fixed interval until its context is canceled.
+type Scheduler struct {
+	wg sync.WaitGroup
+}
+
+// Every runs job once per interval in its own goroutine. A run is never
+// started while the previous one is still in progress, and errors are handed
+// to onError instead of stopping the job. A non-positive interval disables it.
+func (sch *Scheduler) Every(ctx context.Context, interval time.Duration, job Job, onError func(error)) {
+	if interval <= 0 {
+		return
+	}
+
+	sch.wg.Add(1)
+	go func() {
+		defer sch.wg.Done()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := job(ctx); err != nil && onError != nil {
+					onError(err)
+				}
+			}
+		}
+	}()
+}
+
+// Wait blocks until every job has returned after its context was canceled,
+// so a run in progress can finish before the process exits.
+func (sch *Scheduler) Wait() {
+	sch.wg.Wait()
+}
@@ -0,0 +1,72 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchedulerEvery(t *testing.T) {
+	t.Run("run until canceled", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		sch := New()
+
+		var runs, failures atomic.Int32
+		sch.Every(ctx, 5*time.Millisecond, func(ctx context.Context) error {
+			if runs.Add(1)%2 == 0 {
+				return errors.New("some error from job")
+			}
+			return nil
+		}, func(err error) {
+			failures.Add(1)
+		})
+
+		assert.Eventually(t, func() bool { return runs.Load() >= 4 }, time.Second, time.Millisecond)
+
+		cancel()
+		sch.Wait()
+
+		stopped := runs.Load()
+		time.Sleep(20 * time.Millisecond)
+
+		assert.Equal(t, stopped, runs.Load())
+		assert.Equal(t, stopped/2, failures.Load())
+	})
+
+	t.Run("wait for running job", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		sch := New()
+
+		var started = make(chan struct{})
+		var finished atomic.Bool
+		sch.Every(ctx, time.Millisecond, func(ctx context.Context) error {
+			if finished.Load() {
+				return nil
+			}
+			close(started)
+			time.Sleep(20 * time.Millisecond)
+			finished.Store(true)
+			return nil
+		}, nil)
+
+		<-started
+		cancel()
+		sch.Wait()
+
+		assert.True(t, finished.Load())
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		sch := New()
+		sch.Every(context.Background(), 0, func(ctx context.Context) error {
+			t.Fatal("job shouldn't run")
+			return nil
+		}, nil)
+
+		sch.Wait()
+	})
+}